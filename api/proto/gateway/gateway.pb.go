@@ -22,34 +22,38 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// MessageType 消息类型枚举
+// Message type enumeration for gateway communication
 type MessageType int32
 
 const (
-	MessageType_PING         MessageType = 0
-	MessageType_PONG         MessageType = 1
-	MessageType_AUTH         MessageType = 2
-	MessageType_CHAT         MessageType = 3
-	MessageType_NOTIFICATION MessageType = 4
-	MessageType_ACK          MessageType = 5
-	MessageType_ERROR        MessageType = 6
-	MessageType_TYPING       MessageType = 7
-	MessageType_READ_RECEIPT MessageType = 8
-	MessageType_PRESENCE     MessageType = 9
+	MessageType_PING         MessageType = 0  // 心跳ping / Heartbeat ping
+	MessageType_PONG         MessageType = 1  // 心跳pong / Heartbeat pong
+	MessageType_AUTH         MessageType = 2  // 认证消息 / Authentication message
+	MessageType_CHAT         MessageType = 3  // 聊天消息 / Chat message
+	MessageType_NOTIFICATION MessageType = 4  // 通知消息 / Notification message
+	MessageType_ACK          MessageType = 5  // 确认消息 / Acknowledgment message
+	MessageType_ERROR        MessageType = 6  // 错误消息 / Error message
+	MessageType_TYPING       MessageType = 7  // 正在输入状态 / Typing status
+	MessageType_READ_RECEIPT MessageType = 8  // 已读回执 / Read receipt
+	MessageType_PRESENCE     MessageType = 9  // 在线状态 / Presence status
+	MessageType_KICKED       MessageType = 10 // 被踢下线 (发送后服务端关闭连接) / Kicked offline (the server closes the stream afterwards)
 )
 
 // Enum value maps for MessageType.
 var (
 	MessageType_name = map[int32]string{
-		0: "PING",
-		1: "PONG",
-		2: "AUTH",
-		3: "CHAT",
-		4: "NOTIFICATION",
-		5: "ACK",
-		6: "ERROR",
-		7: "TYPING",
-		8: "READ_RECEIPT",
-		9: "PRESENCE",
+		0:  "PING",
+		1:  "PONG",
+		2:  "AUTH",
+		3:  "CHAT",
+		4:  "NOTIFICATION",
+		5:  "ACK",
+		6:  "ERROR",
+		7:  "TYPING",
+		8:  "READ_RECEIPT",
+		9:  "PRESENCE",
+		10: "KICKED",
 	}
 	MessageType_value = map[string]int32{
 		"PING":         0,
@@ -62,6 +66,7 @@ var (
 		"TYPING":       7,
 		"READ_RECEIPT": 8,
 		"PRESENCE":     9,
+		"KICKED":       10,
 	}
 )
 
@@ -92,14 +97,16 @@ func (MessageType) EnumDescriptor() ([]byte, []int) {
 	return file_gateway_gateway_proto_rawDescGZIP(), []int{0}
 }
 
+// GatewayMessage 网关消息 (用于双向流通信)
+// Gateway message (for bidirectional streaming communication)
 type GatewayMessage struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Type          MessageType            `protobuf:"varint,1,opt,name=type,proto3,enum=gateway.MessageType" json:"type,omitempty"`
-	Payload       *structpb.Struct       `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
-	Timestamp     int64                  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	MsgId         *string                `protobuf:"bytes,4,opt,name=msg_id,json=msgId,proto3,oneof" json:"msg_id,omitempty"`
-	ErrorCode     *int32                 `protobuf:"varint,5,opt,name=error_code,json=errorCode,proto3,oneof" json:"error_code,omitempty"`
-	ErrorMsg      *string                `protobuf:"bytes,6,opt,name=error_msg,json=errorMsg,proto3,oneof" json:"error_msg,omitempty"`
+	Type          MessageType            `protobuf:"varint,1,opt,name=type,proto3,enum=gateway.MessageType" json:"type,omitempty"`         // 消息类型 / Message type
+	Payload       *structpb.Struct       `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`                             // 消息负载 (JSON格式) / Message payload (JSON format)
+	Timestamp     int64                  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                        // 时间戳 / Timestamp
+	MsgId         *string                `protobuf:"bytes,4,opt,name=msg_id,json=msgId,proto3,oneof" json:"msg_id,omitempty"`              // 消息ID (可选) / Message ID (optional)
+	ErrorCode     *int32                 `protobuf:"varint,5,opt,name=error_code,json=errorCode,proto3,oneof" json:"error_code,omitempty"` // 错误代码 (仅ERROR类型) / Error code (for ERROR type only)
+	ErrorMsg      *string                `protobuf:"bytes,6,opt,name=error_msg,json=errorMsg,proto3,oneof" json:"error_msg,omitempty"`     // 错误消息 (仅ERROR类型) / Error message (for ERROR type only)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -176,15 +183,18 @@ func (x *GatewayMessage) GetErrorMsg() string {
 	return ""
 }
 
+// SendRequest 发送消息请求 (通过网关)
+// Send message request (via gateway)
 type SendRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`
-	ConvType      string                 `protobuf:"bytes,2,opt,name=conv_type,json=convType,proto3" json:"conv_type,omitempty"`
-	Body          *structpb.Struct       `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
-	ReplyTo       *string                `protobuf:"bytes,4,opt,name=reply_to,json=replyTo,proto3,oneof" json:"reply_to,omitempty"`
-	Mentions      []int64                `protobuf:"varint,5,rep,packed,name=mentions,proto3" json:"mentions,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConvId         int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`                              // 会话ID / Conversation ID
+	ConvType       string                 `protobuf:"bytes,2,opt,name=conv_type,json=convType,proto3" json:"conv_type,omitempty"`                         // 会话类型 / Conversation type
+	Body           *structpb.Struct       `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`                                                 // 消息体 / Message body
+	ReplyTo        *string                `protobuf:"bytes,4,opt,name=reply_to,json=replyTo,proto3,oneof" json:"reply_to,omitempty"`                      // 回复的消息ID / Reply to message ID
+	Mentions       []int64                `protobuf:"varint,5,rep,packed,name=mentions,proto3" json:"mentions,omitempty"`                                 // @提到的用户列表 / Mentioned users
+	IdempotencyKey *string                `protobuf:"bytes,6,opt,name=idempotency_key,json=idempotencyKey,proto3,oneof" json:"idempotency_key,omitempty"` // 幂等键 (也可通过 x-idempotency-key 元数据传递) / Idempotency key (may also be sent as x-idempotency-key metadata)
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *SendRequest) Reset() {
@@ -252,11 +262,20 @@ func (x *SendRequest) GetMentions() []int64 {
 	return nil
 }
 
+func (x *SendRequest) GetIdempotencyKey() string {
+	if x != nil && x.IdempotencyKey != nil {
+		return *x.IdempotencyKey
+	}
+	return ""
+}
+
+// SendResponse 发送消息响应
+// Send message response
 type SendResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	MsgId         string                 `protobuf:"bytes,1,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`
-	Seq           int64                  `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
-	CreatedAt     int64                  `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	MsgId         string                 `protobuf:"bytes,1,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`              // 消息ID / Message ID
+	Seq           int64                  `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`                              // 消息序列号 / Message sequence number
+	CreatedAt     int64                  `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // 创建时间 / Creation time
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -312,9 +331,11 @@ func (x *SendResponse) GetCreatedAt() int64 {
 	return 0
 }
 
+// SyncRequest 同步消息请求
+// Sync messages request
 type SyncRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Conversations []*ConvSync            `protobuf:"bytes,1,rep,name=conversations,proto3" json:"conversations,omitempty"`
+	Conversations []*ConvSync            `protobuf:"bytes,1,rep,name=conversations,proto3" json:"conversations,omitempty"` // 需要同步的会话列表 / List of conversations to sync
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -356,10 +377,15 @@ func (x *SyncRequest) GetConversations() []*ConvSync {
 	return nil
 }
 
+// ConvSync 会话同步信息
+// Conversation sync information
 type ConvSync struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`
-	SinceSeq      int64                  `protobuf:"varint,2,opt,name=since_seq,json=sinceSeq,proto3" json:"since_seq,omitempty"`
+	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`          // 会话ID / Conversation ID
+	SinceSeq      int64                  `protobuf:"varint,2,opt,name=since_seq,json=sinceSeq,proto3" json:"since_seq,omitempty"`    // 从此序列号之后开始同步 (forward) / Sync from this sequence number onwards (forward)
+	Direction     string                 `protobuf:"bytes,3,opt,name=direction,proto3" json:"direction,omitempty"`                   // 同步方向: forward (默认) / backward / around / Sync direction: forward (default), backward or around
+	BeforeSeq     int64                  `protobuf:"varint,4,opt,name=before_seq,json=beforeSeq,proto3" json:"before_seq,omitempty"` // backward: 拉取此序列号之前的历史消息 (0表示从最新开始) / backward: load history before this sequence (0 starts from the latest)
+	AroundSeq     int64                  `protobuf:"varint,5,opt,name=around_seq,json=aroundSeq,proto3" json:"around_seq,omitempty"` // around: 定位的序列号 (用于跳转到引用的消息) / around: anchor sequence (for jumping to a quoted message)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -408,9 +434,33 @@ func (x *ConvSync) GetSinceSeq() int64 {
 	return 0
 }
 
+func (x *ConvSync) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *ConvSync) GetBeforeSeq() int64 {
+	if x != nil {
+		return x.BeforeSeq
+	}
+	return 0
+}
+
+func (x *ConvSync) GetAroundSeq() int64 {
+	if x != nil {
+		return x.AroundSeq
+	}
+	return 0
+}
+
+// SyncResponse 同步消息响应
+// Sync messages response
 type SyncResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ConvMessages  []*ConvMessages        `protobuf:"bytes,1,rep,name=conv_messages,json=convMessages,proto3" json:"conv_messages,omitempty"`
+	ConvMessages  []*ConvMessages        `protobuf:"bytes,1,rep,name=conv_messages,json=convMessages,proto3" json:"conv_messages,omitempty"`              // 各会话的消息列表 / Messages for each conversation
+	DeniedConvIds []int64                `protobuf:"varint,2,rep,packed,name=denied_conv_ids,json=deniedConvIds,proto3" json:"denied_conv_ids,omitempty"` // 无权读取或不存在的会话ID (如已被移出) / Conversations the caller may not read or that do not exist (e.g. after being removed)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -452,11 +502,22 @@ func (x *SyncResponse) GetConvMessages() []*ConvMessages {
 	return nil
 }
 
+func (x *SyncResponse) GetDeniedConvIds() []int64 {
+	if x != nil {
+		return x.DeniedConvIds
+	}
+	return nil
+}
+
+// ConvMessages 会话消息集合
+// Conversation messages collection
 type ConvMessages struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`
-	Messages      []*ChatMessage         `protobuf:"bytes,2,rep,name=messages,proto3" json:"messages,omitempty"`
-	HasMore       bool                   `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`                        // 会话ID / Conversation ID
+	Messages      []*ChatMessage         `protobuf:"bytes,2,rep,name=messages,proto3" json:"messages,omitempty"`                                   // 消息列表 (按seq升序) / Message list (ascending seq)
+	HasMore       bool                   `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`                     // 同步方向上是否还有更多消息 / Whether there are more messages in the sync direction
+	HasMoreBefore bool                   `protobuf:"varint,4,opt,name=has_more_before,json=hasMoreBefore,proto3" json:"has_more_before,omitempty"` // 是否还有更早的消息 / Whether older messages remain
+	HasMoreAfter  bool                   `protobuf:"varint,5,opt,name=has_more_after,json=hasMoreAfter,proto3" json:"has_more_after,omitempty"`    // 是否还有更新的消息 / Whether newer messages remain
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -512,17 +573,35 @@ func (x *ConvMessages) GetHasMore() bool {
 	return false
 }
 
+func (x *ConvMessages) GetHasMoreBefore() bool {
+	if x != nil {
+		return x.HasMoreBefore
+	}
+	return false
+}
+
+func (x *ConvMessages) GetHasMoreAfter() bool {
+	if x != nil {
+		return x.HasMoreAfter
+	}
+	return false
+}
+
+// ChatMessage 聊天消息
+// Chat message
 type ChatMessage struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	MsgId         string                 `protobuf:"bytes,1,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`
-	ConvId        int64                  `protobuf:"varint,2,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`
-	Seq           int64                  `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`
-	SenderId      int64                  `protobuf:"varint,4,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
-	ConvType      string                 `protobuf:"bytes,5,opt,name=conv_type,json=convType,proto3" json:"conv_type,omitempty"`
-	Body          *structpb.Struct       `protobuf:"bytes,6,opt,name=body,proto3" json:"body,omitempty"`
-	ReplyTo       *string                `protobuf:"bytes,7,opt,name=reply_to,json=replyTo,proto3,oneof" json:"reply_to,omitempty"`
-	Mentions      []int64                `protobuf:"varint,8,rep,packed,name=mentions,proto3" json:"mentions,omitempty"`
-	CreatedAt     int64                  `protobuf:"varint,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	MsgId         string                 `protobuf:"bytes,1,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`                  // 消息ID / Message ID
+	ConvId        int64                  `protobuf:"varint,2,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`              // 会话ID / Conversation ID
+	Seq           int64                  `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`                                  // 消息序列号 / Message sequence number
+	SenderId      int64                  `protobuf:"varint,4,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`        // 发送者ID / Sender ID
+	ConvType      string                 `protobuf:"bytes,5,opt,name=conv_type,json=convType,proto3" json:"conv_type,omitempty"`         // 会话类型 / Conversation type
+	Body          *structpb.Struct       `protobuf:"bytes,6,opt,name=body,proto3" json:"body,omitempty"`                                 // 消息体 / Message body
+	ReplyTo       *string                `protobuf:"bytes,7,opt,name=reply_to,json=replyTo,proto3,oneof" json:"reply_to,omitempty"`      // 回复的消息ID / Reply to message ID
+	Mentions      []int64                `protobuf:"varint,8,rep,packed,name=mentions,proto3" json:"mentions,omitempty"`                 // @提到的用户 / Mentioned users
+	CreatedAt     int64                  `protobuf:"varint,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`     // 创建时间 / Creation time
+	Visibility    string                 `protobuf:"bytes,10,opt,name=visibility,proto3" json:"visibility,omitempty"`                    // 可见性 (normal/recalled) / Visibility (normal/recalled)
+	EditedAt      *int64                 `protobuf:"varint,11,opt,name=edited_at,json=editedAt,proto3,oneof" json:"edited_at,omitempty"` // 最后编辑时间 (未编辑时为空) / Last edit time (unset if never edited)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -620,74 +699,1814 @@ func (x *ChatMessage) GetCreatedAt() int64 {
 	return 0
 }
 
-var File_gateway_gateway_proto protoreflect.FileDescriptor
+func (x *ChatMessage) GetVisibility() string {
+	if x != nil {
+		return x.Visibility
+	}
+	return ""
+}
 
-const file_gateway_gateway_proto_rawDesc = "" +
-	"\n" +
-	"\x15gateway/gateway.proto\x12\agateway\x1a\x1cgoogle/protobuf/struct.proto\"\x95\x02\n" +
-	"\x0eGatewayMessage\x12(\n" +
-	"\x04type\x18\x01 \x01(\x0e2\x14.gateway.MessageTypeR\x04type\x121\n" +
-	"\apayload\x18\x02 \x01(\v2\x17.google.protobuf.StructR\apayload\x12\x1c\n" +
-	"\ttimestamp\x18\x03 \x01(\x03R\ttimestamp\x12\x1a\n" +
-	"\x06msg_id\x18\x04 \x01(\tH\x00R\x05msgId\x88\x01\x01\x12\"\n" +
-	"\n" +
-	"error_code\x18\x05 \x01(\x05H\x01R\terrorCode\x88\x01\x01\x12 \n" +
-	"\terror_msg\x18\x06 \x01(\tH\x02R\berrorMsg\x88\x01\x01B\t\n" +
-	"\a_msg_idB\r\n" +
-	"\v_error_codeB\f\n" +
-	"\n" +
-	"_error_msg\"\xb9\x01\n" +
-	"\vSendRequest\x12\x17\n" +
-	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x1b\n" +
-	"\tconv_type\x18\x02 \x01(\tR\bconvType\x12+\n" +
-	"\x04body\x18\x03 \x01(\v2\x17.google.protobuf.StructR\x04body\x12\x1e\n" +
-	"\breply_to\x18\x04 \x01(\tH\x00R\areplyTo\x88\x01\x01\x12\x1a\n" +
-	"\bmentions\x18\x05 \x03(\x03R\bmentionsB\v\n" +
-	"\t_reply_to\"V\n" +
-	"\fSendResponse\x12\x15\n" +
-	"\x06msg_id\x18\x01 \x01(\tR\x05msgId\x12\x10\n" +
-	"\x03seq\x18\x02 \x01(\x03R\x03seq\x12\x1d\n" +
-	"\n" +
-	"created_at\x18\x03 \x01(\x03R\tcreatedAt\"F\n" +
-	"\vSyncRequest\x127\n" +
-	"\rconversations\x18\x01 \x03(\v2\x11.gateway.ConvSyncR\rconversations\"@\n" +
-	"\bConvSync\x12\x17\n" +
-	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x1b\n" +
-	"\tsince_seq\x18\x02 \x01(\x03R\bsinceSeq\"J\n" +
-	"\fSyncResponse\x12:\n" +
-	"\rconv_messages\x18\x01 \x03(\v2\x15.gateway.ConvMessagesR\fconvMessages\"t\n" +
-	"\fConvMessages\x12\x17\n" +
-	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x120\n" +
-	"\bmessages\x18\x02 \x03(\v2\x14.gateway.ChatMessageR\bmessages\x12\x19\n" +
-	"\bhas_more\x18\x03 \x01(\bR\ahasMore\"\x9e\x02\n" +
-	"\vChatMessage\x12\x15\n" +
-	"\x06msg_id\x18\x01 \x01(\tR\x05msgId\x12\x17\n" +
-	"\aconv_id\x18\x02 \x01(\x03R\x06convId\x12\x10\n" +
-	"\x03seq\x18\x03 \x01(\x03R\x03seq\x12\x1b\n" +
-	"\tsender_id\x18\x04 \x01(\x03R\bsenderId\x12\x1b\n" +
-	"\tconv_type\x18\x05 \x01(\tR\bconvType\x12+\n" +
-	"\x04body\x18\x06 \x01(\v2\x17.google.protobuf.StructR\x04body\x12\x1e\n" +
-	"\breply_to\x18\a \x01(\tH\x00R\areplyTo\x88\x01\x01\x12\x1a\n" +
-	"\bmentions\x18\b \x03(\x03R\bmentions\x12\x1d\n" +
-	"\n" +
-	"created_at\x18\t \x01(\x03R\tcreatedAtB\v\n" +
-	"\t_reply_to*\x87\x01\n" +
-	"\vMessageType\x12\b\n" +
-	"\x04PING\x10\x00\x12\b\n" +
-	"\x04PONG\x10\x01\x12\b\n" +
-	"\x04AUTH\x10\x02\x12\b\n" +
-	"\x04CHAT\x10\x03\x12\x10\n" +
-	"\fNOTIFICATION\x10\x04\x12\a\n" +
-	"\x03ACK\x10\x05\x12\t\n" +
-	"\x05ERROR\x10\x06\x12\n" +
-	"\n" +
-	"\x06TYPING\x10\a\x12\x10\n" +
-	"\fREAD_RECEIPT\x10\b\x12\f\n" +
-	"\bPRESENCE\x10\t2\xbb\x01\n" +
-	"\x0eGatewayService\x12?\n" +
-	"\aConnect\x12\x17.gateway.GatewayMessage\x1a\x17.gateway.GatewayMessage(\x010\x01\x123\n" +
-	"\x04Send\x12\x14.gateway.SendRequest\x1a\x15.gateway.SendResponse\x123\n" +
-	"\x04Sync\x12\x14.gateway.SyncRequest\x1a\x15.gateway.SyncResponseB@Z>github.com/dollarkillerx/im-system/api/proto/gateway;gatewaypbb\x06proto3"
+func (x *ChatMessage) GetEditedAt() int64 {
+	if x != nil && x.EditedAt != nil {
+		return *x.EditedAt
+	}
+	return 0
+}
+
+// ListConversationsRequest 获取会话列表请求
+// List conversations request
+type ListConversationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cursor        string                 `protobuf:"bytes,1,opt,name=cursor,proto3" json:"cursor,omitempty"` // 分页游标 (为空表示第一页) / Pagination cursor (empty for the first page)
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`  // 限制数量 / Limit count
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConversationsRequest) Reset() {
+	*x = ListConversationsRequest{}
+	mi := &file_gateway_gateway_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConversationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConversationsRequest) ProtoMessage() {}
+
+func (x *ListConversationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConversationsRequest.ProtoReflect.Descriptor instead.
+func (*ListConversationsRequest) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListConversationsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListConversationsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// ListConversationsResponse 获取会话列表响应
+// List conversations response
+type ListConversationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Conversations []*ConversationSummary `protobuf:"bytes,1,rep,name=conversations,proto3" json:"conversations,omitempty"`             // 会话列表 (按最后消息时间倒序) / Conversations ordered by last message time
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"` // 下一页游标 / Cursor for the next page
+	HasMore       bool                   `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`         // 是否还有更多会话 / Whether there are more conversations
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConversationsResponse) Reset() {
+	*x = ListConversationsResponse{}
+	mi := &file_gateway_gateway_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConversationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConversationsResponse) ProtoMessage() {}
+
+func (x *ListConversationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConversationsResponse.ProtoReflect.Descriptor instead.
+func (*ListConversationsResponse) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListConversationsResponse) GetConversations() []*ConversationSummary {
+	if x != nil {
+		return x.Conversations
+	}
+	return nil
+}
+
+func (x *ListConversationsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *ListConversationsResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+// ConversationSummary 会话摘要
+// Conversation summary
+type ConversationSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`                      // 会话ID / Conversation ID
+	ConvType      string                 `protobuf:"bytes,2,opt,name=conv_type,json=convType,proto3" json:"conv_type,omitempty"`                 // 会话类型 / Conversation type
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`                                       // 会话标题 / Conversation title
+	OwnerId       int64                  `protobuf:"varint,4,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`                   // 所有者用户ID / Owner user ID
+	Role          string                 `protobuf:"bytes,5,opt,name=role,proto3" json:"role,omitempty"`                                         // 当前用户的角色 / Caller's role
+	Muted         bool                   `protobuf:"varint,6,opt,name=muted,proto3" json:"muted,omitempty"`                                      // 当前用户是否被禁言 / Whether the caller is muted
+	CurrentSeq    int64                  `protobuf:"varint,7,opt,name=current_seq,json=currentSeq,proto3" json:"current_seq,omitempty"`          // 会话当前序列号 / Current conversation sequence number
+	LastReadSeq   int64                  `protobuf:"varint,8,opt,name=last_read_seq,json=lastReadSeq,proto3" json:"last_read_seq,omitempty"`     // 已读序列号 / Last read sequence number
+	UnreadCount   int64                  `protobuf:"varint,9,opt,name=unread_count,json=unreadCount,proto3" json:"unread_count,omitempty"`       // 未读消息数，不含自己的消息和系统消息 / Unread messages from others, excluding system messages
+	LastMessage   *ChatMessage           `protobuf:"bytes,10,opt,name=last_message,json=lastMessage,proto3" json:"last_message,omitempty"`       // 最后一条消息 (无消息时为空) / Last message preview (unset if none)
+	LastActiveAt  int64                  `protobuf:"varint,11,opt,name=last_active_at,json=lastActiveAt,proto3" json:"last_active_at,omitempty"` // 最后活跃时间 / Last activity time
+	CreatedAt     int64                  `protobuf:"varint,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`            // 创建时间 / Creation time
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConversationSummary) Reset() {
+	*x = ConversationSummary{}
+	mi := &file_gateway_gateway_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConversationSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConversationSummary) ProtoMessage() {}
+
+func (x *ConversationSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConversationSummary.ProtoReflect.Descriptor instead.
+func (*ConversationSummary) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ConversationSummary) GetConvId() int64 {
+	if x != nil {
+		return x.ConvId
+	}
+	return 0
+}
+
+func (x *ConversationSummary) GetConvType() string {
+	if x != nil {
+		return x.ConvType
+	}
+	return ""
+}
+
+func (x *ConversationSummary) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ConversationSummary) GetOwnerId() int64 {
+	if x != nil {
+		return x.OwnerId
+	}
+	return 0
+}
+
+func (x *ConversationSummary) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *ConversationSummary) GetMuted() bool {
+	if x != nil {
+		return x.Muted
+	}
+	return false
+}
+
+func (x *ConversationSummary) GetCurrentSeq() int64 {
+	if x != nil {
+		return x.CurrentSeq
+	}
+	return 0
+}
+
+func (x *ConversationSummary) GetLastReadSeq() int64 {
+	if x != nil {
+		return x.LastReadSeq
+	}
+	return 0
+}
+
+func (x *ConversationSummary) GetUnreadCount() int64 {
+	if x != nil {
+		return x.UnreadCount
+	}
+	return 0
+}
+
+func (x *ConversationSummary) GetLastMessage() *ChatMessage {
+	if x != nil {
+		return x.LastMessage
+	}
+	return nil
+}
+
+func (x *ConversationSummary) GetLastActiveAt() int64 {
+	if x != nil {
+		return x.LastActiveAt
+	}
+	return 0
+}
+
+func (x *ConversationSummary) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+// GetOrCreateDirectConversationRequest 获取或创建单聊请求
+// Get or create direct conversation request
+type GetOrCreateDirectConversationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PeerId        int64                  `protobuf:"varint,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"` // 对方用户ID / Peer user ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrCreateDirectConversationRequest) Reset() {
+	*x = GetOrCreateDirectConversationRequest{}
+	mi := &file_gateway_gateway_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrCreateDirectConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrCreateDirectConversationRequest) ProtoMessage() {}
+
+func (x *GetOrCreateDirectConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrCreateDirectConversationRequest.ProtoReflect.Descriptor instead.
+func (*GetOrCreateDirectConversationRequest) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetOrCreateDirectConversationRequest) GetPeerId() int64 {
+	if x != nil {
+		return x.PeerId
+	}
+	return 0
+}
+
+// GetOrCreateDirectConversationResponse 获取或创建单聊响应
+// Get or create direct conversation response
+type GetOrCreateDirectConversationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"` // 单聊会话ID / Direct conversation ID
+	Created       bool                   `protobuf:"varint,2,opt,name=created,proto3" json:"created,omitempty"`             // 是否为新创建 / Whether the conversation was newly created
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrCreateDirectConversationResponse) Reset() {
+	*x = GetOrCreateDirectConversationResponse{}
+	mi := &file_gateway_gateway_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrCreateDirectConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrCreateDirectConversationResponse) ProtoMessage() {}
+
+func (x *GetOrCreateDirectConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrCreateDirectConversationResponse.ProtoReflect.Descriptor instead.
+func (*GetOrCreateDirectConversationResponse) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetOrCreateDirectConversationResponse) GetConvId() int64 {
+	if x != nil {
+		return x.ConvId
+	}
+	return 0
+}
+
+func (x *GetOrCreateDirectConversationResponse) GetCreated() bool {
+	if x != nil {
+		return x.Created
+	}
+	return false
+}
+
+// RecallMessageRequest 撤回消息请求
+// Recall message request
+type RecallMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"` // 会话ID / Conversation ID
+	MsgId         string                 `protobuf:"bytes,2,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`     // 被撤回的消息ID / ID of the message to recall
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecallMessageRequest) Reset() {
+	*x = RecallMessageRequest{}
+	mi := &file_gateway_gateway_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecallMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecallMessageRequest) ProtoMessage() {}
+
+func (x *RecallMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecallMessageRequest.ProtoReflect.Descriptor instead.
+func (*RecallMessageRequest) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RecallMessageRequest) GetConvId() int64 {
+	if x != nil {
+		return x.ConvId
+	}
+	return 0
+}
+
+func (x *RecallMessageRequest) GetMsgId() string {
+	if x != nil {
+		return x.MsgId
+	}
+	return ""
+}
+
+// RecallMessageResponse 撤回消息响应
+// Recall message response
+type RecallMessageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           int64                  `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"` // 撤回事件序列号 / Recall event sequence number
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecallMessageResponse) Reset() {
+	*x = RecallMessageResponse{}
+	mi := &file_gateway_gateway_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecallMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecallMessageResponse) ProtoMessage() {}
+
+func (x *RecallMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecallMessageResponse.ProtoReflect.Descriptor instead.
+func (*RecallMessageResponse) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RecallMessageResponse) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+// EditMessageRequest 编辑消息请求
+// Edit message request
+type EditMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"` // 会话ID / Conversation ID
+	MsgId         string                 `protobuf:"bytes,2,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`     // 被编辑的消息ID / ID of the message to edit
+	Body          *structpb.Struct       `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`                    // 新消息体 / New message body
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EditMessageRequest) Reset() {
+	*x = EditMessageRequest{}
+	mi := &file_gateway_gateway_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EditMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EditMessageRequest) ProtoMessage() {}
+
+func (x *EditMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EditMessageRequest.ProtoReflect.Descriptor instead.
+func (*EditMessageRequest) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *EditMessageRequest) GetConvId() int64 {
+	if x != nil {
+		return x.ConvId
+	}
+	return 0
+}
+
+func (x *EditMessageRequest) GetMsgId() string {
+	if x != nil {
+		return x.MsgId
+	}
+	return ""
+}
+
+func (x *EditMessageRequest) GetBody() *structpb.Struct {
+	if x != nil {
+		return x.Body
+	}
+	return nil
+}
+
+// EditMessageResponse 编辑消息响应
+// Edit message response
+type EditMessageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           int64                  `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`                           // 编辑事件序列号 / Edit event sequence number
+	EditedAt      int64                  `protobuf:"varint,2,opt,name=edited_at,json=editedAt,proto3" json:"edited_at,omitempty"` // 编辑时间 / Edit time
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EditMessageResponse) Reset() {
+	*x = EditMessageResponse{}
+	mi := &file_gateway_gateway_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EditMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EditMessageResponse) ProtoMessage() {}
+
+func (x *EditMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EditMessageResponse.ProtoReflect.Descriptor instead.
+func (*EditMessageResponse) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *EditMessageResponse) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *EditMessageResponse) GetEditedAt() int64 {
+	if x != nil {
+		return x.EditedAt
+	}
+	return 0
+}
+
+// SubscribePresenceRequest 订阅在线状态请求
+// Subscribe presence request
+type SubscribePresenceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserIds       []int64                `protobuf:"varint,1,rep,packed,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"` // 被订阅的用户ID列表，仅限共同会话的成员，其余忽略 / User IDs to watch; only users sharing a conversation are kept, others are ignored
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribePresenceRequest) Reset() {
+	*x = SubscribePresenceRequest{}
+	mi := &file_gateway_gateway_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribePresenceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribePresenceRequest) ProtoMessage() {}
+
+func (x *SubscribePresenceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribePresenceRequest.ProtoReflect.Descriptor instead.
+func (*SubscribePresenceRequest) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SubscribePresenceRequest) GetUserIds() []int64 {
+	if x != nil {
+		return x.UserIds
+	}
+	return nil
+}
+
+// SubscribePresenceResponse 订阅在线状态响应
+// Subscribe presence response
+type SubscribePresenceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Statuses      []*PresenceStatus      `protobuf:"bytes,1,rep,name=statuses,proto3" json:"statuses,omitempty"` // 被订阅用户的当前状态 / Current status of the watched users
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribePresenceResponse) Reset() {
+	*x = SubscribePresenceResponse{}
+	mi := &file_gateway_gateway_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribePresenceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribePresenceResponse) ProtoMessage() {}
+
+func (x *SubscribePresenceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribePresenceResponse.ProtoReflect.Descriptor instead.
+func (*SubscribePresenceResponse) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SubscribePresenceResponse) GetStatuses() []*PresenceStatus {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+// PresenceStatus 用户在线状态
+// User presence status
+type PresenceStatus struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	UserId                int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                                                  // 用户ID / User ID
+	Online                bool                   `protobuf:"varint,2,opt,name=online,proto3" json:"online,omitempty"`                                                                // 是否在线 / Whether online
+	Status                string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`                                                                 // 状态: online/away/busy/invisible/offline / Status: online/away/busy/invisible/offline
+	CustomStatus          string                 `protobuf:"bytes,4,opt,name=custom_status,json=customStatus,proto3" json:"custom_status,omitempty"`                                 // 自定义状态文本 / Custom status text
+	CustomStatusExpiresAt int64                  `protobuf:"varint,5,opt,name=custom_status_expires_at,json=customStatusExpiresAt,proto3" json:"custom_status_expires_at,omitempty"` // 自定义状态过期时间 (0表示不过期) / Custom status expiry (0 means never)
+	LastSeen              int64                  `protobuf:"varint,6,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`                                            // 最后在线时间 (离线时) / Last seen time (when offline)
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *PresenceStatus) Reset() {
+	*x = PresenceStatus{}
+	mi := &file_gateway_gateway_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PresenceStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PresenceStatus) ProtoMessage() {}
+
+func (x *PresenceStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PresenceStatus.ProtoReflect.Descriptor instead.
+func (*PresenceStatus) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *PresenceStatus) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *PresenceStatus) GetOnline() bool {
+	if x != nil {
+		return x.Online
+	}
+	return false
+}
+
+func (x *PresenceStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *PresenceStatus) GetCustomStatus() string {
+	if x != nil {
+		return x.CustomStatus
+	}
+	return ""
+}
+
+func (x *PresenceStatus) GetCustomStatusExpiresAt() int64 {
+	if x != nil {
+		return x.CustomStatusExpiresAt
+	}
+	return 0
+}
+
+func (x *PresenceStatus) GetLastSeen() int64 {
+	if x != nil {
+		return x.LastSeen
+	}
+	return 0
+}
+
+// SetPresenceRequest 设置状态请求
+// Set presence request
+type SetPresenceRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Status          string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`                                             // 手动状态: online/away/busy/invisible / Manual status: online/away/busy/invisible
+	CustomStatus    string                 `protobuf:"bytes,2,opt,name=custom_status,json=customStatus,proto3" json:"custom_status,omitempty"`             // 自定义状态文本，为空表示清除 / Custom status text, empty clears it
+	CustomStatusTtl int64                  `protobuf:"varint,3,opt,name=custom_status_ttl,json=customStatusTtl,proto3" json:"custom_status_ttl,omitempty"` // 自定义状态有效期 (秒，0表示不过期) / Custom status lifetime in seconds (0 means never)
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SetPresenceRequest) Reset() {
+	*x = SetPresenceRequest{}
+	mi := &file_gateway_gateway_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetPresenceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetPresenceRequest) ProtoMessage() {}
+
+func (x *SetPresenceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetPresenceRequest.ProtoReflect.Descriptor instead.
+func (*SetPresenceRequest) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SetPresenceRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SetPresenceRequest) GetCustomStatus() string {
+	if x != nil {
+		return x.CustomStatus
+	}
+	return ""
+}
+
+func (x *SetPresenceRequest) GetCustomStatusTtl() int64 {
+	if x != nil {
+		return x.CustomStatusTtl
+	}
+	return 0
+}
+
+// SetPresenceResponse 设置状态响应
+// Set presence response
+type SetPresenceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Presence      *PresenceStatus        `protobuf:"bytes,1,opt,name=presence,proto3" json:"presence,omitempty"` // 设置后的状态 (本人视角) / Resulting presence as seen by the user
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetPresenceResponse) Reset() {
+	*x = SetPresenceResponse{}
+	mi := &file_gateway_gateway_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetPresenceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetPresenceResponse) ProtoMessage() {}
+
+func (x *SetPresenceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetPresenceResponse.ProtoReflect.Descriptor instead.
+func (*SetPresenceResponse) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SetPresenceResponse) GetPresence() *PresenceStatus {
+	if x != nil {
+		return x.Presence
+	}
+	return nil
+}
+
+// UnsubscribePresenceRequest 取消订阅在线状态请求
+// Unsubscribe presence request
+type UnsubscribePresenceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserIds       []int64                `protobuf:"varint,1,rep,packed,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"` // 取消订阅的用户ID列表 / User IDs to stop watching
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnsubscribePresenceRequest) Reset() {
+	*x = UnsubscribePresenceRequest{}
+	mi := &file_gateway_gateway_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnsubscribePresenceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnsubscribePresenceRequest) ProtoMessage() {}
+
+func (x *UnsubscribePresenceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnsubscribePresenceRequest.ProtoReflect.Descriptor instead.
+func (*UnsubscribePresenceRequest) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *UnsubscribePresenceRequest) GetUserIds() []int64 {
+	if x != nil {
+		return x.UserIds
+	}
+	return nil
+}
+
+// UnsubscribePresenceResponse 取消订阅在线状态响应
+// Unsubscribe presence response
+type UnsubscribePresenceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"` // 是否成功 / Success status
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnsubscribePresenceResponse) Reset() {
+	*x = UnsubscribePresenceResponse{}
+	mi := &file_gateway_gateway_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnsubscribePresenceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnsubscribePresenceResponse) ProtoMessage() {}
+
+func (x *UnsubscribePresenceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnsubscribePresenceResponse.ProtoReflect.Descriptor instead.
+func (*UnsubscribePresenceResponse) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *UnsubscribePresenceResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// ListDevicesRequest 获取在线设备请求
+// List devices request
+type ListDevicesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDevicesRequest) Reset() {
+	*x = ListDevicesRequest{}
+	mi := &file_gateway_gateway_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDevicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDevicesRequest) ProtoMessage() {}
+
+func (x *ListDevicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDevicesRequest.ProtoReflect.Descriptor instead.
+func (*ListDevicesRequest) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{24}
+}
+
+// ListDevicesResponse 获取在线设备响应
+// List devices response
+type ListDevicesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Devices       []*DeviceInfo          `protobuf:"bytes,1,rep,name=devices,proto3" json:"devices,omitempty"` // 在线设备列表 / Connected devices
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDevicesResponse) Reset() {
+	*x = ListDevicesResponse{}
+	mi := &file_gateway_gateway_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDevicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDevicesResponse) ProtoMessage() {}
+
+func (x *ListDevicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDevicesResponse.ProtoReflect.Descriptor instead.
+func (*ListDevicesResponse) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ListDevicesResponse) GetDevices() []*DeviceInfo {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+// DeviceInfo 在线设备信息
+// Connected device information
+type DeviceInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`           // 设备ID / Device ID
+	Platform      string                 `protobuf:"bytes,2,opt,name=platform,proto3" json:"platform,omitempty"`                           // 平台类型 (如 mobile/desktop/web) / Platform class (e.g. mobile/desktop/web)
+	ConnectedAt   int64                  `protobuf:"varint,3,opt,name=connected_at,json=connectedAt,proto3" json:"connected_at,omitempty"` // 连接时间 / Connection time
+	LastActive    int64                  `protobuf:"varint,4,opt,name=last_active,json=lastActive,proto3" json:"last_active,omitempty"`    // 最后活跃时间 / Last active time
+	Current       bool                   `protobuf:"varint,5,opt,name=current,proto3" json:"current,omitempty"`                            // 是否为当前请求的设备 / Whether this is the calling device
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceInfo) Reset() {
+	*x = DeviceInfo{}
+	mi := &file_gateway_gateway_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceInfo) ProtoMessage() {}
+
+func (x *DeviceInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceInfo.ProtoReflect.Descriptor instead.
+func (*DeviceInfo) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *DeviceInfo) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *DeviceInfo) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *DeviceInfo) GetConnectedAt() int64 {
+	if x != nil {
+		return x.ConnectedAt
+	}
+	return 0
+}
+
+func (x *DeviceInfo) GetLastActive() int64 {
+	if x != nil {
+		return x.LastActive
+	}
+	return 0
+}
+
+func (x *DeviceInfo) GetCurrent() bool {
+	if x != nil {
+		return x.Current
+	}
+	return false
+}
+
+// KickDeviceRequest 踢设备下线请求
+// Kick device request
+type KickDeviceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"` // 被踢的设备ID (不能是当前设备) / Device to kick (must not be the calling device)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KickDeviceRequest) Reset() {
+	*x = KickDeviceRequest{}
+	mi := &file_gateway_gateway_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KickDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KickDeviceRequest) ProtoMessage() {}
+
+func (x *KickDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KickDeviceRequest.ProtoReflect.Descriptor instead.
+func (*KickDeviceRequest) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *KickDeviceRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+// KickDeviceResponse 踢设备下线响应
+// Kick device response
+type KickDeviceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"` // 设备是否在线并已被踢下线 / Whether the device was connected and has been kicked
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KickDeviceResponse) Reset() {
+	*x = KickDeviceResponse{}
+	mi := &file_gateway_gateway_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KickDeviceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KickDeviceResponse) ProtoMessage() {}
+
+func (x *KickDeviceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KickDeviceResponse.ProtoReflect.Descriptor instead.
+func (*KickDeviceResponse) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *KickDeviceResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// PushToUsersRequest 按用户批量推送请求 (内部服务调用)
+// Push to users request (internal service call)
+type PushToUsersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserIds       []int64                `protobuf:"varint,1,rep,packed,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"` // 目标用户ID列表 / Target user IDs
+	Message       *GatewayMessage        `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`                        // 推送的消息 / Message to push
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PushToUsersRequest) Reset() {
+	*x = PushToUsersRequest{}
+	mi := &file_gateway_gateway_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PushToUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushToUsersRequest) ProtoMessage() {}
+
+func (x *PushToUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushToUsersRequest.ProtoReflect.Descriptor instead.
+func (*PushToUsersRequest) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *PushToUsersRequest) GetUserIds() []int64 {
+	if x != nil {
+		return x.UserIds
+	}
+	return nil
+}
+
+func (x *PushToUsersRequest) GetMessage() *GatewayMessage {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+// PushToDevicesRequest 按设备批量推送请求 (内部服务调用)
+// Push to devices request (internal service call)
+type PushToDevicesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Targets       []*DeviceTarget        `protobuf:"bytes,1,rep,name=targets,proto3" json:"targets,omitempty"` // 目标设备列表 / Target devices
+	Message       *GatewayMessage        `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"` // 推送的消息 / Message to push
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PushToDevicesRequest) Reset() {
+	*x = PushToDevicesRequest{}
+	mi := &file_gateway_gateway_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PushToDevicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushToDevicesRequest) ProtoMessage() {}
+
+func (x *PushToDevicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushToDevicesRequest.ProtoReflect.Descriptor instead.
+func (*PushToDevicesRequest) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *PushToDevicesRequest) GetTargets() []*DeviceTarget {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+func (x *PushToDevicesRequest) GetMessage() *GatewayMessage {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+// DeviceTarget 推送目标设备
+// Push target device
+type DeviceTarget struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`      // 用户ID / User ID
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"` // 设备ID / Device ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceTarget) Reset() {
+	*x = DeviceTarget{}
+	mi := &file_gateway_gateway_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceTarget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceTarget) ProtoMessage() {}
+
+func (x *DeviceTarget) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceTarget.ProtoReflect.Descriptor instead.
+func (*DeviceTarget) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *DeviceTarget) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *DeviceTarget) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+// PushResponse 批量推送响应
+// Batch push response
+type PushResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Results          []*UserPushResult      `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`                                            // 各用户的投递结果 / Per-user delivery results
+	DeliveredCount   int32                  `protobuf:"varint,2,opt,name=delivered_count,json=deliveredCount,proto3" json:"delivered_count,omitempty"`       // 成功投递的连接总数 / Total connections delivered to
+	UndeliveredCount int32                  `protobuf:"varint,3,opt,name=undelivered_count,json=undeliveredCount,proto3" json:"undelivered_count,omitempty"` // 未投递的连接总数 / Total connections not delivered to
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *PushResponse) Reset() {
+	*x = PushResponse{}
+	mi := &file_gateway_gateway_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PushResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushResponse) ProtoMessage() {}
+
+func (x *PushResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushResponse.ProtoReflect.Descriptor instead.
+func (*PushResponse) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *PushResponse) GetResults() []*UserPushResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *PushResponse) GetDeliveredCount() int32 {
+	if x != nil {
+		return x.DeliveredCount
+	}
+	return 0
+}
+
+func (x *PushResponse) GetUndeliveredCount() int32 {
+	if x != nil {
+		return x.UndeliveredCount
+	}
+	return 0
+}
+
+// UserPushResult 单个用户的投递结果
+// Per-user delivery result
+type UserPushResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // 用户ID / User ID
+	Delivered     int32                  `protobuf:"varint,2,opt,name=delivered,proto3" json:"delivered,omitempty"`         // 成功投递的连接数 / Connections delivered to
+	Undelivered   int32                  `protobuf:"varint,3,opt,name=undelivered,proto3" json:"undelivered,omitempty"`     // 未投递的连接数 (连接已关闭、发送队列已满或设备不在线) / Connections not delivered to (closed, queue full or device offline)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserPushResult) Reset() {
+	*x = UserPushResult{}
+	mi := &file_gateway_gateway_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserPushResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserPushResult) ProtoMessage() {}
+
+func (x *UserPushResult) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserPushResult.ProtoReflect.Descriptor instead.
+func (*UserPushResult) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *UserPushResult) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *UserPushResult) GetDelivered() int32 {
+	if x != nil {
+		return x.Delivered
+	}
+	return 0
+}
+
+func (x *UserPushResult) GetUndelivered() int32 {
+	if x != nil {
+		return x.Undelivered
+	}
+	return 0
+}
+
+// KickDevicesRequest 踢设备下线请求 (内部服务调用)
+// Kick devices request (internal service call)
+type KickDevicesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`         // 用户ID / User ID
+	DeviceIds     []string               `protobuf:"bytes,2,rep,name=device_ids,json=deviceIds,proto3" json:"device_ids,omitempty"` // 被踢的设备ID列表 / Devices to kick
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`                        // 下线原因 (如 kicked/device_limit) / Kick reason (e.g. kicked/device_limit)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KickDevicesRequest) Reset() {
+	*x = KickDevicesRequest{}
+	mi := &file_gateway_gateway_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KickDevicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KickDevicesRequest) ProtoMessage() {}
+
+func (x *KickDevicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KickDevicesRequest.ProtoReflect.Descriptor instead.
+func (*KickDevicesRequest) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *KickDevicesRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *KickDevicesRequest) GetDeviceIds() []string {
+	if x != nil {
+		return x.DeviceIds
+	}
+	return nil
+}
+
+func (x *KickDevicesRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// KickDevicesResponse 踢设备下线响应
+// Kick devices response
+type KickDevicesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	KickedCount   int32                  `protobuf:"varint,1,opt,name=kicked_count,json=kickedCount,proto3" json:"kicked_count,omitempty"` // 本网关上被关闭的连接数 / Connections closed on this gateway
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KickDevicesResponse) Reset() {
+	*x = KickDevicesResponse{}
+	mi := &file_gateway_gateway_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KickDevicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KickDevicesResponse) ProtoMessage() {}
+
+func (x *KickDevicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_gateway_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KickDevicesResponse.ProtoReflect.Descriptor instead.
+func (*KickDevicesResponse) Descriptor() ([]byte, []int) {
+	return file_gateway_gateway_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *KickDevicesResponse) GetKickedCount() int32 {
+	if x != nil {
+		return x.KickedCount
+	}
+	return 0
+}
+
+var File_gateway_gateway_proto protoreflect.FileDescriptor
+
+const file_gateway_gateway_proto_rawDesc = "" +
+	"\n" +
+	"\x15gateway/gateway.proto\x12\agateway\x1a\x1cgoogle/protobuf/struct.proto\"\x95\x02\n" +
+	"\x0eGatewayMessage\x12(\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x14.gateway.MessageTypeR\x04type\x121\n" +
+	"\apayload\x18\x02 \x01(\v2\x17.google.protobuf.StructR\apayload\x12\x1c\n" +
+	"\ttimestamp\x18\x03 \x01(\x03R\ttimestamp\x12\x1a\n" +
+	"\x06msg_id\x18\x04 \x01(\tH\x00R\x05msgId\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"error_code\x18\x05 \x01(\x05H\x01R\terrorCode\x88\x01\x01\x12 \n" +
+	"\terror_msg\x18\x06 \x01(\tH\x02R\berrorMsg\x88\x01\x01B\t\n" +
+	"\a_msg_idB\r\n" +
+	"\v_error_codeB\f\n" +
+	"\n" +
+	"_error_msg\"\xfb\x01\n" +
+	"\vSendRequest\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x1b\n" +
+	"\tconv_type\x18\x02 \x01(\tR\bconvType\x12+\n" +
+	"\x04body\x18\x03 \x01(\v2\x17.google.protobuf.StructR\x04body\x12\x1e\n" +
+	"\breply_to\x18\x04 \x01(\tH\x00R\areplyTo\x88\x01\x01\x12\x1a\n" +
+	"\bmentions\x18\x05 \x03(\x03R\bmentions\x12,\n" +
+	"\x0fidempotency_key\x18\x06 \x01(\tH\x01R\x0eidempotencyKey\x88\x01\x01B\v\n" +
+	"\t_reply_toB\x12\n" +
+	"\x10_idempotency_key\"V\n" +
+	"\fSendResponse\x12\x15\n" +
+	"\x06msg_id\x18\x01 \x01(\tR\x05msgId\x12\x10\n" +
+	"\x03seq\x18\x02 \x01(\x03R\x03seq\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\x03R\tcreatedAt\"F\n" +
+	"\vSyncRequest\x127\n" +
+	"\rconversations\x18\x01 \x03(\v2\x11.gateway.ConvSyncR\rconversations\"\x9c\x01\n" +
+	"\bConvSync\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x1b\n" +
+	"\tsince_seq\x18\x02 \x01(\x03R\bsinceSeq\x12\x1c\n" +
+	"\tdirection\x18\x03 \x01(\tR\tdirection\x12\x1d\n" +
+	"\n" +
+	"before_seq\x18\x04 \x01(\x03R\tbeforeSeq\x12\x1d\n" +
+	"\n" +
+	"around_seq\x18\x05 \x01(\x03R\taroundSeq\"r\n" +
+	"\fSyncResponse\x12:\n" +
+	"\rconv_messages\x18\x01 \x03(\v2\x15.gateway.ConvMessagesR\fconvMessages\x12&\n" +
+	"\x0fdenied_conv_ids\x18\x02 \x03(\x03R\rdeniedConvIds\"\xc2\x01\n" +
+	"\fConvMessages\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x120\n" +
+	"\bmessages\x18\x02 \x03(\v2\x14.gateway.ChatMessageR\bmessages\x12\x19\n" +
+	"\bhas_more\x18\x03 \x01(\bR\ahasMore\x12&\n" +
+	"\x0fhas_more_before\x18\x04 \x01(\bR\rhasMoreBefore\x12$\n" +
+	"\x0ehas_more_after\x18\x05 \x01(\bR\fhasMoreAfter\"\xee\x02\n" +
+	"\vChatMessage\x12\x15\n" +
+	"\x06msg_id\x18\x01 \x01(\tR\x05msgId\x12\x17\n" +
+	"\aconv_id\x18\x02 \x01(\x03R\x06convId\x12\x10\n" +
+	"\x03seq\x18\x03 \x01(\x03R\x03seq\x12\x1b\n" +
+	"\tsender_id\x18\x04 \x01(\x03R\bsenderId\x12\x1b\n" +
+	"\tconv_type\x18\x05 \x01(\tR\bconvType\x12+\n" +
+	"\x04body\x18\x06 \x01(\v2\x17.google.protobuf.StructR\x04body\x12\x1e\n" +
+	"\breply_to\x18\a \x01(\tH\x00R\areplyTo\x88\x01\x01\x12\x1a\n" +
+	"\bmentions\x18\b \x03(\x03R\bmentions\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\t \x01(\x03R\tcreatedAt\x12\x1e\n" +
+	"\n" +
+	"visibility\x18\n" +
+	" \x01(\tR\n" +
+	"visibility\x12 \n" +
+	"\tedited_at\x18\v \x01(\x03H\x01R\beditedAt\x88\x01\x01B\v\n" +
+	"\t_reply_toB\f\n" +
+	"\n" +
+	"_edited_at\"H\n" +
+	"\x18ListConversationsRequest\x12\x16\n" +
+	"\x06cursor\x18\x01 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"\x9b\x01\n" +
+	"\x19ListConversationsResponse\x12B\n" +
+	"\rconversations\x18\x01 \x03(\v2\x1c.gateway.ConversationSummaryR\rconversations\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\x12\x19\n" +
+	"\bhas_more\x18\x03 \x01(\bR\ahasMore\"\x8c\x03\n" +
+	"\x13ConversationSummary\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x1b\n" +
+	"\tconv_type\x18\x02 \x01(\tR\bconvType\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x19\n" +
+	"\bowner_id\x18\x04 \x01(\x03R\aownerId\x12\x12\n" +
+	"\x04role\x18\x05 \x01(\tR\x04role\x12\x14\n" +
+	"\x05muted\x18\x06 \x01(\bR\x05muted\x12\x1f\n" +
+	"\vcurrent_seq\x18\a \x01(\x03R\n" +
+	"currentSeq\x12\"\n" +
+	"\rlast_read_seq\x18\b \x01(\x03R\vlastReadSeq\x12!\n" +
+	"\funread_count\x18\t \x01(\x03R\vunreadCount\x127\n" +
+	"\flast_message\x18\n" +
+	" \x01(\v2\x14.gateway.ChatMessageR\vlastMessage\x12$\n" +
+	"\x0elast_active_at\x18\v \x01(\x03R\flastActiveAt\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\f \x01(\x03R\tcreatedAt\"?\n" +
+	"$GetOrCreateDirectConversationRequest\x12\x17\n" +
+	"\apeer_id\x18\x01 \x01(\x03R\x06peerId\"Z\n" +
+	"%GetOrCreateDirectConversationResponse\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x18\n" +
+	"\acreated\x18\x02 \x01(\bR\acreated\"F\n" +
+	"\x14RecallMessageRequest\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x15\n" +
+	"\x06msg_id\x18\x02 \x01(\tR\x05msgId\")\n" +
+	"\x15RecallMessageResponse\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x03R\x03seq\"q\n" +
+	"\x12EditMessageRequest\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x15\n" +
+	"\x06msg_id\x18\x02 \x01(\tR\x05msgId\x12+\n" +
+	"\x04body\x18\x03 \x01(\v2\x17.google.protobuf.StructR\x04body\"D\n" +
+	"\x13EditMessageResponse\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x03R\x03seq\x12\x1b\n" +
+	"\tedited_at\x18\x02 \x01(\x03R\beditedAt\"5\n" +
+	"\x18SubscribePresenceRequest\x12\x19\n" +
+	"\buser_ids\x18\x01 \x03(\x03R\auserIds\"P\n" +
+	"\x19SubscribePresenceResponse\x123\n" +
+	"\bstatuses\x18\x01 \x03(\v2\x17.gateway.PresenceStatusR\bstatuses\"\xd4\x01\n" +
+	"\x0ePresenceStatus\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x16\n" +
+	"\x06online\x18\x02 \x01(\bR\x06online\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12#\n" +
+	"\rcustom_status\x18\x04 \x01(\tR\fcustomStatus\x127\n" +
+	"\x18custom_status_expires_at\x18\x05 \x01(\x03R\x15customStatusExpiresAt\x12\x1b\n" +
+	"\tlast_seen\x18\x06 \x01(\x03R\blastSeen\"}\n" +
+	"\x12SetPresenceRequest\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12#\n" +
+	"\rcustom_status\x18\x02 \x01(\tR\fcustomStatus\x12*\n" +
+	"\x11custom_status_ttl\x18\x03 \x01(\x03R\x0fcustomStatusTtl\"J\n" +
+	"\x13SetPresenceResponse\x123\n" +
+	"\bpresence\x18\x01 \x01(\v2\x17.gateway.PresenceStatusR\bpresence\"7\n" +
+	"\x1aUnsubscribePresenceRequest\x12\x19\n" +
+	"\buser_ids\x18\x01 \x03(\x03R\auserIds\"7\n" +
+	"\x1bUnsubscribePresenceResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x14\n" +
+	"\x12ListDevicesRequest\"D\n" +
+	"\x13ListDevicesResponse\x12-\n" +
+	"\adevices\x18\x01 \x03(\v2\x13.gateway.DeviceInfoR\adevices\"\xa3\x01\n" +
+	"\n" +
+	"DeviceInfo\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x1a\n" +
+	"\bplatform\x18\x02 \x01(\tR\bplatform\x12!\n" +
+	"\fconnected_at\x18\x03 \x01(\x03R\vconnectedAt\x12\x1f\n" +
+	"\vlast_active\x18\x04 \x01(\x03R\n" +
+	"lastActive\x12\x18\n" +
+	"\acurrent\x18\x05 \x01(\bR\acurrent\"0\n" +
+	"\x11KickDeviceRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\".\n" +
+	"\x12KickDeviceResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"b\n" +
+	"\x12PushToUsersRequest\x12\x19\n" +
+	"\buser_ids\x18\x01 \x03(\x03R\auserIds\x121\n" +
+	"\amessage\x18\x02 \x01(\v2\x17.gateway.GatewayMessageR\amessage\"z\n" +
+	"\x14PushToDevicesRequest\x12/\n" +
+	"\atargets\x18\x01 \x03(\v2\x15.gateway.DeviceTargetR\atargets\x121\n" +
+	"\amessage\x18\x02 \x01(\v2\x17.gateway.GatewayMessageR\amessage\"D\n" +
+	"\fDeviceTarget\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\"\x97\x01\n" +
+	"\fPushResponse\x121\n" +
+	"\aresults\x18\x01 \x03(\v2\x17.gateway.UserPushResultR\aresults\x12'\n" +
+	"\x0fdelivered_count\x18\x02 \x01(\x05R\x0edeliveredCount\x12+\n" +
+	"\x11undelivered_count\x18\x03 \x01(\x05R\x10undeliveredCount\"i\n" +
+	"\x0eUserPushResult\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1c\n" +
+	"\tdelivered\x18\x02 \x01(\x05R\tdelivered\x12 \n" +
+	"\vundelivered\x18\x03 \x01(\x05R\vundelivered\"d\n" +
+	"\x12KickDevicesRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1d\n" +
+	"\n" +
+	"device_ids\x18\x02 \x03(\tR\tdeviceIds\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"8\n" +
+	"\x13KickDevicesResponse\x12!\n" +
+	"\fkicked_count\x18\x01 \x01(\x05R\vkickedCount*\x93\x01\n" +
+	"\vMessageType\x12\b\n" +
+	"\x04PING\x10\x00\x12\b\n" +
+	"\x04PONG\x10\x01\x12\b\n" +
+	"\x04AUTH\x10\x02\x12\b\n" +
+	"\x04CHAT\x10\x03\x12\x10\n" +
+	"\fNOTIFICATION\x10\x04\x12\a\n" +
+	"\x03ACK\x10\x05\x12\t\n" +
+	"\x05ERROR\x10\x06\x12\n" +
+	"\n" +
+	"\x06TYPING\x10\a\x12\x10\n" +
+	"\fREAD_RECEIPT\x10\b\x12\f\n" +
+	"\bPRESENCE\x10\t\x12\n" +
+	"\n" +
+	"\x06KICKED\x10\n" +
+	"2\x9e\t\n" +
+	"\x0eGatewayService\x12?\n" +
+	"\aConnect\x12\x17.gateway.GatewayMessage\x1a\x17.gateway.GatewayMessage(\x010\x01\x123\n" +
+	"\x04Send\x12\x14.gateway.SendRequest\x1a\x15.gateway.SendResponse\x123\n" +
+	"\x04Sync\x12\x14.gateway.SyncRequest\x1a\x15.gateway.SyncResponse\x12Z\n" +
+	"\x11ListConversations\x12!.gateway.ListConversationsRequest\x1a\".gateway.ListConversationsResponse\x12~\n" +
+	"\x1dGetOrCreateDirectConversation\x12-.gateway.GetOrCreateDirectConversationRequest\x1a..gateway.GetOrCreateDirectConversationResponse\x12N\n" +
+	"\rRecallMessage\x12\x1d.gateway.RecallMessageRequest\x1a\x1e.gateway.RecallMessageResponse\x12H\n" +
+	"\vEditMessage\x12\x1b.gateway.EditMessageRequest\x1a\x1c.gateway.EditMessageResponse\x12Z\n" +
+	"\x11SubscribePresence\x12!.gateway.SubscribePresenceRequest\x1a\".gateway.SubscribePresenceResponse\x12`\n" +
+	"\x13UnsubscribePresence\x12#.gateway.UnsubscribePresenceRequest\x1a$.gateway.UnsubscribePresenceResponse\x12H\n" +
+	"\vSetPresence\x12\x1b.gateway.SetPresenceRequest\x1a\x1c.gateway.SetPresenceResponse\x12H\n" +
+	"\vListDevices\x12\x1b.gateway.ListDevicesRequest\x1a\x1c.gateway.ListDevicesResponse\x12E\n" +
+	"\n" +
+	"KickDevice\x12\x1a.gateway.KickDeviceRequest\x1a\x1b.gateway.KickDeviceResponse\x12A\n" +
+	"\vPushToUsers\x12\x1b.gateway.PushToUsersRequest\x1a\x15.gateway.PushResponse\x12E\n" +
+	"\rPushToDevices\x12\x1d.gateway.PushToDevicesRequest\x1a\x15.gateway.PushResponse\x12H\n" +
+	"\vKickDevices\x12\x1b.gateway.KickDevicesRequest\x1a\x1c.gateway.KickDevicesResponseB@Z>github.com/dollarkillerx/im-system/api/proto/gateway;gatewaypbb\x06proto3"
 
 var (
 	file_gateway_gateway_proto_rawDescOnce sync.Once
@@ -702,38 +2521,100 @@ func file_gateway_gateway_proto_rawDescGZIP() []byte {
 }
 
 var file_gateway_gateway_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_gateway_gateway_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_gateway_gateway_proto_msgTypes = make([]protoimpl.MessageInfo, 36)
 var file_gateway_gateway_proto_goTypes = []any{
-	(MessageType)(0),        // 0: gateway.MessageType
-	(*GatewayMessage)(nil),  // 1: gateway.GatewayMessage
-	(*SendRequest)(nil),     // 2: gateway.SendRequest
-	(*SendResponse)(nil),    // 3: gateway.SendResponse
-	(*SyncRequest)(nil),     // 4: gateway.SyncRequest
-	(*ConvSync)(nil),        // 5: gateway.ConvSync
-	(*SyncResponse)(nil),    // 6: gateway.SyncResponse
-	(*ConvMessages)(nil),    // 7: gateway.ConvMessages
-	(*ChatMessage)(nil),     // 8: gateway.ChatMessage
-	(*structpb.Struct)(nil), // 9: google.protobuf.Struct
+	(MessageType)(0),                              // 0: gateway.MessageType
+	(*GatewayMessage)(nil),                        // 1: gateway.GatewayMessage
+	(*SendRequest)(nil),                           // 2: gateway.SendRequest
+	(*SendResponse)(nil),                          // 3: gateway.SendResponse
+	(*SyncRequest)(nil),                           // 4: gateway.SyncRequest
+	(*ConvSync)(nil),                              // 5: gateway.ConvSync
+	(*SyncResponse)(nil),                          // 6: gateway.SyncResponse
+	(*ConvMessages)(nil),                          // 7: gateway.ConvMessages
+	(*ChatMessage)(nil),                           // 8: gateway.ChatMessage
+	(*ListConversationsRequest)(nil),              // 9: gateway.ListConversationsRequest
+	(*ListConversationsResponse)(nil),             // 10: gateway.ListConversationsResponse
+	(*ConversationSummary)(nil),                   // 11: gateway.ConversationSummary
+	(*GetOrCreateDirectConversationRequest)(nil),  // 12: gateway.GetOrCreateDirectConversationRequest
+	(*GetOrCreateDirectConversationResponse)(nil), // 13: gateway.GetOrCreateDirectConversationResponse
+	(*RecallMessageRequest)(nil),                  // 14: gateway.RecallMessageRequest
+	(*RecallMessageResponse)(nil),                 // 15: gateway.RecallMessageResponse
+	(*EditMessageRequest)(nil),                    // 16: gateway.EditMessageRequest
+	(*EditMessageResponse)(nil),                   // 17: gateway.EditMessageResponse
+	(*SubscribePresenceRequest)(nil),              // 18: gateway.SubscribePresenceRequest
+	(*SubscribePresenceResponse)(nil),             // 19: gateway.SubscribePresenceResponse
+	(*PresenceStatus)(nil),                        // 20: gateway.PresenceStatus
+	(*SetPresenceRequest)(nil),                    // 21: gateway.SetPresenceRequest
+	(*SetPresenceResponse)(nil),                   // 22: gateway.SetPresenceResponse
+	(*UnsubscribePresenceRequest)(nil),            // 23: gateway.UnsubscribePresenceRequest
+	(*UnsubscribePresenceResponse)(nil),           // 24: gateway.UnsubscribePresenceResponse
+	(*ListDevicesRequest)(nil),                    // 25: gateway.ListDevicesRequest
+	(*ListDevicesResponse)(nil),                   // 26: gateway.ListDevicesResponse
+	(*DeviceInfo)(nil),                            // 27: gateway.DeviceInfo
+	(*KickDeviceRequest)(nil),                     // 28: gateway.KickDeviceRequest
+	(*KickDeviceResponse)(nil),                    // 29: gateway.KickDeviceResponse
+	(*PushToUsersRequest)(nil),                    // 30: gateway.PushToUsersRequest
+	(*PushToDevicesRequest)(nil),                  // 31: gateway.PushToDevicesRequest
+	(*DeviceTarget)(nil),                          // 32: gateway.DeviceTarget
+	(*PushResponse)(nil),                          // 33: gateway.PushResponse
+	(*UserPushResult)(nil),                        // 34: gateway.UserPushResult
+	(*KickDevicesRequest)(nil),                    // 35: gateway.KickDevicesRequest
+	(*KickDevicesResponse)(nil),                   // 36: gateway.KickDevicesResponse
+	(*structpb.Struct)(nil),                       // 37: google.protobuf.Struct
 }
 var file_gateway_gateway_proto_depIdxs = []int32{
 	0,  // 0: gateway.GatewayMessage.type:type_name -> gateway.MessageType
-	9,  // 1: gateway.GatewayMessage.payload:type_name -> google.protobuf.Struct
-	9,  // 2: gateway.SendRequest.body:type_name -> google.protobuf.Struct
+	37, // 1: gateway.GatewayMessage.payload:type_name -> google.protobuf.Struct
+	37, // 2: gateway.SendRequest.body:type_name -> google.protobuf.Struct
 	5,  // 3: gateway.SyncRequest.conversations:type_name -> gateway.ConvSync
 	7,  // 4: gateway.SyncResponse.conv_messages:type_name -> gateway.ConvMessages
 	8,  // 5: gateway.ConvMessages.messages:type_name -> gateway.ChatMessage
-	9,  // 6: gateway.ChatMessage.body:type_name -> google.protobuf.Struct
-	1,  // 7: gateway.GatewayService.Connect:input_type -> gateway.GatewayMessage
-	2,  // 8: gateway.GatewayService.Send:input_type -> gateway.SendRequest
-	4,  // 9: gateway.GatewayService.Sync:input_type -> gateway.SyncRequest
-	1,  // 10: gateway.GatewayService.Connect:output_type -> gateway.GatewayMessage
-	3,  // 11: gateway.GatewayService.Send:output_type -> gateway.SendResponse
-	6,  // 12: gateway.GatewayService.Sync:output_type -> gateway.SyncResponse
-	10, // [10:13] is the sub-list for method output_type
-	7,  // [7:10] is the sub-list for method input_type
-	7,  // [7:7] is the sub-list for extension type_name
-	7,  // [7:7] is the sub-list for extension extendee
-	0,  // [0:7] is the sub-list for field type_name
+	37, // 6: gateway.ChatMessage.body:type_name -> google.protobuf.Struct
+	11, // 7: gateway.ListConversationsResponse.conversations:type_name -> gateway.ConversationSummary
+	8,  // 8: gateway.ConversationSummary.last_message:type_name -> gateway.ChatMessage
+	37, // 9: gateway.EditMessageRequest.body:type_name -> google.protobuf.Struct
+	20, // 10: gateway.SubscribePresenceResponse.statuses:type_name -> gateway.PresenceStatus
+	20, // 11: gateway.SetPresenceResponse.presence:type_name -> gateway.PresenceStatus
+	27, // 12: gateway.ListDevicesResponse.devices:type_name -> gateway.DeviceInfo
+	1,  // 13: gateway.PushToUsersRequest.message:type_name -> gateway.GatewayMessage
+	32, // 14: gateway.PushToDevicesRequest.targets:type_name -> gateway.DeviceTarget
+	1,  // 15: gateway.PushToDevicesRequest.message:type_name -> gateway.GatewayMessage
+	34, // 16: gateway.PushResponse.results:type_name -> gateway.UserPushResult
+	1,  // 17: gateway.GatewayService.Connect:input_type -> gateway.GatewayMessage
+	2,  // 18: gateway.GatewayService.Send:input_type -> gateway.SendRequest
+	4,  // 19: gateway.GatewayService.Sync:input_type -> gateway.SyncRequest
+	9,  // 20: gateway.GatewayService.ListConversations:input_type -> gateway.ListConversationsRequest
+	12, // 21: gateway.GatewayService.GetOrCreateDirectConversation:input_type -> gateway.GetOrCreateDirectConversationRequest
+	14, // 22: gateway.GatewayService.RecallMessage:input_type -> gateway.RecallMessageRequest
+	16, // 23: gateway.GatewayService.EditMessage:input_type -> gateway.EditMessageRequest
+	18, // 24: gateway.GatewayService.SubscribePresence:input_type -> gateway.SubscribePresenceRequest
+	23, // 25: gateway.GatewayService.UnsubscribePresence:input_type -> gateway.UnsubscribePresenceRequest
+	21, // 26: gateway.GatewayService.SetPresence:input_type -> gateway.SetPresenceRequest
+	25, // 27: gateway.GatewayService.ListDevices:input_type -> gateway.ListDevicesRequest
+	28, // 28: gateway.GatewayService.KickDevice:input_type -> gateway.KickDeviceRequest
+	30, // 29: gateway.GatewayService.PushToUsers:input_type -> gateway.PushToUsersRequest
+	31, // 30: gateway.GatewayService.PushToDevices:input_type -> gateway.PushToDevicesRequest
+	35, // 31: gateway.GatewayService.KickDevices:input_type -> gateway.KickDevicesRequest
+	1,  // 32: gateway.GatewayService.Connect:output_type -> gateway.GatewayMessage
+	3,  // 33: gateway.GatewayService.Send:output_type -> gateway.SendResponse
+	6,  // 34: gateway.GatewayService.Sync:output_type -> gateway.SyncResponse
+	10, // 35: gateway.GatewayService.ListConversations:output_type -> gateway.ListConversationsResponse
+	13, // 36: gateway.GatewayService.GetOrCreateDirectConversation:output_type -> gateway.GetOrCreateDirectConversationResponse
+	15, // 37: gateway.GatewayService.RecallMessage:output_type -> gateway.RecallMessageResponse
+	17, // 38: gateway.GatewayService.EditMessage:output_type -> gateway.EditMessageResponse
+	19, // 39: gateway.GatewayService.SubscribePresence:output_type -> gateway.SubscribePresenceResponse
+	24, // 40: gateway.GatewayService.UnsubscribePresence:output_type -> gateway.UnsubscribePresenceResponse
+	22, // 41: gateway.GatewayService.SetPresence:output_type -> gateway.SetPresenceResponse
+	26, // 42: gateway.GatewayService.ListDevices:output_type -> gateway.ListDevicesResponse
+	29, // 43: gateway.GatewayService.KickDevice:output_type -> gateway.KickDeviceResponse
+	33, // 44: gateway.GatewayService.PushToUsers:output_type -> gateway.PushResponse
+	33, // 45: gateway.GatewayService.PushToDevices:output_type -> gateway.PushResponse
+	36, // 46: gateway.GatewayService.KickDevices:output_type -> gateway.KickDevicesResponse
+	32, // [32:47] is the sub-list for method output_type
+	17, // [17:32] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
 }
 
 func init() { file_gateway_gateway_proto_init() }
@@ -750,7 +2631,7 @@ func file_gateway_gateway_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_gateway_gateway_proto_rawDesc), len(file_gateway_gateway_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   8,
+			NumMessages:   36,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,18 +19,60 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	GatewayService_Connect_FullMethodName = "/gateway.GatewayService/Connect"
-	GatewayService_Send_FullMethodName    = "/gateway.GatewayService/Send"
-	GatewayService_Sync_FullMethodName    = "/gateway.GatewayService/Sync"
+	GatewayService_Connect_FullMethodName                       = "/gateway.GatewayService/Connect"
+	GatewayService_Send_FullMethodName                          = "/gateway.GatewayService/Send"
+	GatewayService_Sync_FullMethodName                          = "/gateway.GatewayService/Sync"
+	GatewayService_ListConversations_FullMethodName             = "/gateway.GatewayService/ListConversations"
+	GatewayService_GetOrCreateDirectConversation_FullMethodName = "/gateway.GatewayService/GetOrCreateDirectConversation"
+	GatewayService_RecallMessage_FullMethodName                 = "/gateway.GatewayService/RecallMessage"
+	GatewayService_EditMessage_FullMethodName                   = "/gateway.GatewayService/EditMessage"
+	GatewayService_SubscribePresence_FullMethodName             = "/gateway.GatewayService/SubscribePresence"
+	GatewayService_UnsubscribePresence_FullMethodName           = "/gateway.GatewayService/UnsubscribePresence"
+	GatewayService_SetPresence_FullMethodName                   = "/gateway.GatewayService/SetPresence"
+	GatewayService_ListDevices_FullMethodName                   = "/gateway.GatewayService/ListDevices"
+	GatewayService_KickDevice_FullMethodName                    = "/gateway.GatewayService/KickDevice"
+	GatewayService_PushToUsers_FullMethodName                   = "/gateway.GatewayService/PushToUsers"
+	GatewayService_PushToDevices_FullMethodName                 = "/gateway.GatewayService/PushToDevices"
+	GatewayService_KickDevices_FullMethodName                   = "/gateway.GatewayService/KickDevices"
 )
 
 // GatewayServiceClient is the client API for GatewayService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// GatewayService 网关服务
+// Gateway service for real-time bidirectional communication
 type GatewayServiceClient interface {
+	// Connect 建立双向流连接 / Establish bidirectional streaming connection
 	Connect(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[GatewayMessage, GatewayMessage], error)
+	// Send 发送消息 (单次调用) / Send message (unary call)
 	Send(ctx context.Context, in *SendRequest, opts ...grpc.CallOption) (*SendResponse, error)
+	// Sync 同步消息 / Sync messages
 	Sync(ctx context.Context, in *SyncRequest, opts ...grpc.CallOption) (*SyncResponse, error)
+	// ListConversations 获取当前用户的会话列表 / List the current user's conversations
+	ListConversations(ctx context.Context, in *ListConversationsRequest, opts ...grpc.CallOption) (*ListConversationsResponse, error)
+	// GetOrCreateDirectConversation 获取或创建与指定用户的单聊 / Get or create the direct conversation with a peer
+	GetOrCreateDirectConversation(ctx context.Context, in *GetOrCreateDirectConversationRequest, opts ...grpc.CallOption) (*GetOrCreateDirectConversationResponse, error)
+	// RecallMessage 撤回消息 / Recall a message
+	RecallMessage(ctx context.Context, in *RecallMessageRequest, opts ...grpc.CallOption) (*RecallMessageResponse, error)
+	// EditMessage 编辑消息 / Edit a message
+	EditMessage(ctx context.Context, in *EditMessageRequest, opts ...grpc.CallOption) (*EditMessageResponse, error)
+	// SubscribePresence 订阅用户在线状态，变化通过 PRESENCE 消息推送 / Subscribe to users' presence; changes are pushed as PRESENCE messages
+	SubscribePresence(ctx context.Context, in *SubscribePresenceRequest, opts ...grpc.CallOption) (*SubscribePresenceResponse, error)
+	// UnsubscribePresence 取消订阅用户在线状态 / Unsubscribe from users' presence
+	UnsubscribePresence(ctx context.Context, in *UnsubscribePresenceRequest, opts ...grpc.CallOption) (*UnsubscribePresenceResponse, error)
+	// SetPresence 设置手动状态 (离开/忙碌/隐身) 和自定义状态 / Set manual status (away/busy/invisible) and custom status
+	SetPresence(ctx context.Context, in *SetPresenceRequest, opts ...grpc.CallOption) (*SetPresenceResponse, error)
+	// ListDevices 获取当前用户的在线设备 / List the current user's connected devices
+	ListDevices(ctx context.Context, in *ListDevicesRequest, opts ...grpc.CallOption) (*ListDevicesResponse, error)
+	// KickDevice 将当前用户的其他设备踢下线并吊销其令牌 / Disconnect another of the current user's devices and revoke its token
+	KickDevice(ctx context.Context, in *KickDeviceRequest, opts ...grpc.CallOption) (*KickDeviceResponse, error)
+	// PushToUsers 批量推送消息到本网关上指定用户的所有连接 (内部调用) / Batch push to all connections of the given users on this gateway (internal call)
+	PushToUsers(ctx context.Context, in *PushToUsersRequest, opts ...grpc.CallOption) (*PushResponse, error)
+	// PushToDevices 批量推送消息到本网关上指定设备的连接 (内部调用) / Batch push to the given device connections on this gateway (internal call)
+	PushToDevices(ctx context.Context, in *PushToDevicesRequest, opts ...grpc.CallOption) (*PushResponse, error)
+	// KickDevices 向本网关上指定设备发送 KICKED 消息并关闭连接 (内部调用) / Send KICKED to the given device connections on this gateway and close them (internal call)
+	KickDevices(ctx context.Context, in *KickDevicesRequest, opts ...grpc.CallOption) (*KickDevicesResponse, error)
 }
 
 type gatewayServiceClient struct {
@@ -74,13 +116,163 @@ func (c *gatewayServiceClient) Sync(ctx context.Context, in *SyncRequest, opts .
 	return out, nil
 }
 
+func (c *gatewayServiceClient) ListConversations(ctx context.Context, in *ListConversationsRequest, opts ...grpc.CallOption) (*ListConversationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListConversationsResponse)
+	err := c.cc.Invoke(ctx, GatewayService_ListConversations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayServiceClient) GetOrCreateDirectConversation(ctx context.Context, in *GetOrCreateDirectConversationRequest, opts ...grpc.CallOption) (*GetOrCreateDirectConversationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetOrCreateDirectConversationResponse)
+	err := c.cc.Invoke(ctx, GatewayService_GetOrCreateDirectConversation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayServiceClient) RecallMessage(ctx context.Context, in *RecallMessageRequest, opts ...grpc.CallOption) (*RecallMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecallMessageResponse)
+	err := c.cc.Invoke(ctx, GatewayService_RecallMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayServiceClient) EditMessage(ctx context.Context, in *EditMessageRequest, opts ...grpc.CallOption) (*EditMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EditMessageResponse)
+	err := c.cc.Invoke(ctx, GatewayService_EditMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayServiceClient) SubscribePresence(ctx context.Context, in *SubscribePresenceRequest, opts ...grpc.CallOption) (*SubscribePresenceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubscribePresenceResponse)
+	err := c.cc.Invoke(ctx, GatewayService_SubscribePresence_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayServiceClient) UnsubscribePresence(ctx context.Context, in *UnsubscribePresenceRequest, opts ...grpc.CallOption) (*UnsubscribePresenceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnsubscribePresenceResponse)
+	err := c.cc.Invoke(ctx, GatewayService_UnsubscribePresence_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayServiceClient) SetPresence(ctx context.Context, in *SetPresenceRequest, opts ...grpc.CallOption) (*SetPresenceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetPresenceResponse)
+	err := c.cc.Invoke(ctx, GatewayService_SetPresence_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayServiceClient) ListDevices(ctx context.Context, in *ListDevicesRequest, opts ...grpc.CallOption) (*ListDevicesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDevicesResponse)
+	err := c.cc.Invoke(ctx, GatewayService_ListDevices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayServiceClient) KickDevice(ctx context.Context, in *KickDeviceRequest, opts ...grpc.CallOption) (*KickDeviceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(KickDeviceResponse)
+	err := c.cc.Invoke(ctx, GatewayService_KickDevice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayServiceClient) PushToUsers(ctx context.Context, in *PushToUsersRequest, opts ...grpc.CallOption) (*PushResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PushResponse)
+	err := c.cc.Invoke(ctx, GatewayService_PushToUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayServiceClient) PushToDevices(ctx context.Context, in *PushToDevicesRequest, opts ...grpc.CallOption) (*PushResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PushResponse)
+	err := c.cc.Invoke(ctx, GatewayService_PushToDevices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gatewayServiceClient) KickDevices(ctx context.Context, in *KickDevicesRequest, opts ...grpc.CallOption) (*KickDevicesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(KickDevicesResponse)
+	err := c.cc.Invoke(ctx, GatewayService_KickDevices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // GatewayServiceServer is the server API for GatewayService service.
 // All implementations must embed UnimplementedGatewayServiceServer
 // for forward compatibility.
+//
+// GatewayService 网关服务
+// Gateway service for real-time bidirectional communication
 type GatewayServiceServer interface {
+	// Connect 建立双向流连接 / Establish bidirectional streaming connection
 	Connect(grpc.BidiStreamingServer[GatewayMessage, GatewayMessage]) error
+	// Send 发送消息 (单次调用) / Send message (unary call)
 	Send(context.Context, *SendRequest) (*SendResponse, error)
+	// Sync 同步消息 / Sync messages
 	Sync(context.Context, *SyncRequest) (*SyncResponse, error)
+	// ListConversations 获取当前用户的会话列表 / List the current user's conversations
+	ListConversations(context.Context, *ListConversationsRequest) (*ListConversationsResponse, error)
+	// GetOrCreateDirectConversation 获取或创建与指定用户的单聊 / Get or create the direct conversation with a peer
+	GetOrCreateDirectConversation(context.Context, *GetOrCreateDirectConversationRequest) (*GetOrCreateDirectConversationResponse, error)
+	// RecallMessage 撤回消息 / Recall a message
+	RecallMessage(context.Context, *RecallMessageRequest) (*RecallMessageResponse, error)
+	// EditMessage 编辑消息 / Edit a message
+	EditMessage(context.Context, *EditMessageRequest) (*EditMessageResponse, error)
+	// SubscribePresence 订阅用户在线状态，变化通过 PRESENCE 消息推送 / Subscribe to users' presence; changes are pushed as PRESENCE messages
+	SubscribePresence(context.Context, *SubscribePresenceRequest) (*SubscribePresenceResponse, error)
+	// UnsubscribePresence 取消订阅用户在线状态 / Unsubscribe from users' presence
+	UnsubscribePresence(context.Context, *UnsubscribePresenceRequest) (*UnsubscribePresenceResponse, error)
+	// SetPresence 设置手动状态 (离开/忙碌/隐身) 和自定义状态 / Set manual status (away/busy/invisible) and custom status
+	SetPresence(context.Context, *SetPresenceRequest) (*SetPresenceResponse, error)
+	// ListDevices 获取当前用户的在线设备 / List the current user's connected devices
+	ListDevices(context.Context, *ListDevicesRequest) (*ListDevicesResponse, error)
+	// KickDevice 将当前用户的其他设备踢下线并吊销其令牌 / Disconnect another of the current user's devices and revoke its token
+	KickDevice(context.Context, *KickDeviceRequest) (*KickDeviceResponse, error)
+	// PushToUsers 批量推送消息到本网关上指定用户的所有连接 (内部调用) / Batch push to all connections of the given users on this gateway (internal call)
+	PushToUsers(context.Context, *PushToUsersRequest) (*PushResponse, error)
+	// PushToDevices 批量推送消息到本网关上指定设备的连接 (内部调用) / Batch push to the given device connections on this gateway (internal call)
+	PushToDevices(context.Context, *PushToDevicesRequest) (*PushResponse, error)
+	// KickDevices 向本网关上指定设备发送 KICKED 消息并关闭连接 (内部调用) / Send KICKED to the given device connections on this gateway and close them (internal call)
+	KickDevices(context.Context, *KickDevicesRequest) (*KickDevicesResponse, error)
 	mustEmbedUnimplementedGatewayServiceServer()
 }
 
@@ -100,6 +292,42 @@ func (UnimplementedGatewayServiceServer) Send(context.Context, *SendRequest) (*S
 func (UnimplementedGatewayServiceServer) Sync(context.Context, *SyncRequest) (*SyncResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Sync not implemented")
 }
+func (UnimplementedGatewayServiceServer) ListConversations(context.Context, *ListConversationsRequest) (*ListConversationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListConversations not implemented")
+}
+func (UnimplementedGatewayServiceServer) GetOrCreateDirectConversation(context.Context, *GetOrCreateDirectConversationRequest) (*GetOrCreateDirectConversationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrCreateDirectConversation not implemented")
+}
+func (UnimplementedGatewayServiceServer) RecallMessage(context.Context, *RecallMessageRequest) (*RecallMessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecallMessage not implemented")
+}
+func (UnimplementedGatewayServiceServer) EditMessage(context.Context, *EditMessageRequest) (*EditMessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EditMessage not implemented")
+}
+func (UnimplementedGatewayServiceServer) SubscribePresence(context.Context, *SubscribePresenceRequest) (*SubscribePresenceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubscribePresence not implemented")
+}
+func (UnimplementedGatewayServiceServer) UnsubscribePresence(context.Context, *UnsubscribePresenceRequest) (*UnsubscribePresenceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnsubscribePresence not implemented")
+}
+func (UnimplementedGatewayServiceServer) SetPresence(context.Context, *SetPresenceRequest) (*SetPresenceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPresence not implemented")
+}
+func (UnimplementedGatewayServiceServer) ListDevices(context.Context, *ListDevicesRequest) (*ListDevicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDevices not implemented")
+}
+func (UnimplementedGatewayServiceServer) KickDevice(context.Context, *KickDeviceRequest) (*KickDeviceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method KickDevice not implemented")
+}
+func (UnimplementedGatewayServiceServer) PushToUsers(context.Context, *PushToUsersRequest) (*PushResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PushToUsers not implemented")
+}
+func (UnimplementedGatewayServiceServer) PushToDevices(context.Context, *PushToDevicesRequest) (*PushResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PushToDevices not implemented")
+}
+func (UnimplementedGatewayServiceServer) KickDevices(context.Context, *KickDevicesRequest) (*KickDevicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method KickDevices not implemented")
+}
 func (UnimplementedGatewayServiceServer) mustEmbedUnimplementedGatewayServiceServer() {}
 func (UnimplementedGatewayServiceServer) testEmbeddedByValue()                        {}
 
@@ -164,6 +392,222 @@ func _GatewayService_Sync_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GatewayService_ListConversations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListConversationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServiceServer).ListConversations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatewayService_ListConversations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServiceServer).ListConversations(ctx, req.(*ListConversationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayService_GetOrCreateDirectConversation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrCreateDirectConversationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServiceServer).GetOrCreateDirectConversation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatewayService_GetOrCreateDirectConversation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServiceServer).GetOrCreateDirectConversation(ctx, req.(*GetOrCreateDirectConversationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayService_RecallMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecallMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServiceServer).RecallMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatewayService_RecallMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServiceServer).RecallMessage(ctx, req.(*RecallMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayService_EditMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EditMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServiceServer).EditMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatewayService_EditMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServiceServer).EditMessage(ctx, req.(*EditMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayService_SubscribePresence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubscribePresenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServiceServer).SubscribePresence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatewayService_SubscribePresence_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServiceServer).SubscribePresence(ctx, req.(*SubscribePresenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayService_UnsubscribePresence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnsubscribePresenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServiceServer).UnsubscribePresence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatewayService_UnsubscribePresence_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServiceServer).UnsubscribePresence(ctx, req.(*UnsubscribePresenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayService_SetPresence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPresenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServiceServer).SetPresence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatewayService_SetPresence_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServiceServer).SetPresence(ctx, req.(*SetPresenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayService_ListDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServiceServer).ListDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatewayService_ListDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServiceServer).ListDevices(ctx, req.(*ListDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayService_KickDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KickDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServiceServer).KickDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatewayService_KickDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServiceServer).KickDevice(ctx, req.(*KickDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayService_PushToUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PushToUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServiceServer).PushToUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatewayService_PushToUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServiceServer).PushToUsers(ctx, req.(*PushToUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayService_PushToDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PushToDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServiceServer).PushToDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatewayService_PushToDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServiceServer).PushToDevices(ctx, req.(*PushToDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GatewayService_KickDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KickDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GatewayServiceServer).KickDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GatewayService_KickDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GatewayServiceServer).KickDevices(ctx, req.(*KickDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // GatewayService_ServiceDesc is the grpc.ServiceDesc for GatewayService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -179,6 +623,54 @@ var GatewayService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Sync",
 			Handler:    _GatewayService_Sync_Handler,
 		},
+		{
+			MethodName: "ListConversations",
+			Handler:    _GatewayService_ListConversations_Handler,
+		},
+		{
+			MethodName: "GetOrCreateDirectConversation",
+			Handler:    _GatewayService_GetOrCreateDirectConversation_Handler,
+		},
+		{
+			MethodName: "RecallMessage",
+			Handler:    _GatewayService_RecallMessage_Handler,
+		},
+		{
+			MethodName: "EditMessage",
+			Handler:    _GatewayService_EditMessage_Handler,
+		},
+		{
+			MethodName: "SubscribePresence",
+			Handler:    _GatewayService_SubscribePresence_Handler,
+		},
+		{
+			MethodName: "UnsubscribePresence",
+			Handler:    _GatewayService_UnsubscribePresence_Handler,
+		},
+		{
+			MethodName: "SetPresence",
+			Handler:    _GatewayService_SetPresence_Handler,
+		},
+		{
+			MethodName: "ListDevices",
+			Handler:    _GatewayService_ListDevices_Handler,
+		},
+		{
+			MethodName: "KickDevice",
+			Handler:    _GatewayService_KickDevice_Handler,
+		},
+		{
+			MethodName: "PushToUsers",
+			Handler:    _GatewayService_PushToUsers_Handler,
+		},
+		{
+			MethodName: "PushToDevices",
+			Handler:    _GatewayService_PushToDevices_Handler,
+		},
+		{
+			MethodName: "KickDevices",
+			Handler:    _GatewayService_KickDevices_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -22,12 +22,14 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// ConversationType 会话类型
+// Conversation type enumeration
 type ConversationType int32
 
 const (
-	ConversationType_DIRECT  ConversationType = 0
-	ConversationType_GROUP   ConversationType = 1
-	ConversationType_CHANNEL ConversationType = 2
+	ConversationType_DIRECT  ConversationType = 0 // 单聊 / Direct message (one-to-one)
+	ConversationType_GROUP   ConversationType = 1 // 群聊 / Group chat
+	ConversationType_CHANNEL ConversationType = 2 // 频道 (广播式) / Channel (broadcast)
 )
 
 // Enum value maps for ConversationType.
@@ -71,14 +73,16 @@ func (ConversationType) EnumDescriptor() ([]byte, []int) {
 	return file_message_message_proto_rawDescGZIP(), []int{0}
 }
 
+// ConversationRole 会话成员角色
+// Conversation member role enumeration
 type ConversationRole int32
 
 const (
-	ConversationRole_OWNER     ConversationRole = 0
-	ConversationRole_ADMIN     ConversationRole = 1
-	ConversationRole_PUBLISHER ConversationRole = 2
-	ConversationRole_MEMBER    ConversationRole = 3
-	ConversationRole_VIEWER    ConversationRole = 4
+	ConversationRole_OWNER     ConversationRole = 0 // 所有者 (完全权限) / Owner (full permissions)
+	ConversationRole_ADMIN     ConversationRole = 1 // 管理员 / Administrator
+	ConversationRole_PUBLISHER ConversationRole = 2 // 发布者 (可发消息) / Publisher (can send messages)
+	ConversationRole_MEMBER    ConversationRole = 3 // 普通成员 / Regular member
+	ConversationRole_VIEWER    ConversationRole = 4 // 观察者 (只读) / Viewer (read-only)
 )
 
 // Enum value maps for ConversationRole.
@@ -126,16 +130,70 @@ func (ConversationRole) EnumDescriptor() ([]byte, []int) {
 	return file_message_message_proto_rawDescGZIP(), []int{1}
 }
 
+// PullDirection 拉取方向
+// Pull direction enumeration
+type PullDirection int32
+
+const (
+	PullDirection_FORWARD  PullDirection = 0 // 拉取 since_seq 之后的新消息 / Newer messages after since_seq
+	PullDirection_BACKWARD PullDirection = 1 // 拉取 before_seq 之前的历史消息 / Older messages before before_seq
+	PullDirection_AROUND   PullDirection = 2 // 拉取 around_seq 附近的消息 / Messages around around_seq
+)
+
+// Enum value maps for PullDirection.
+var (
+	PullDirection_name = map[int32]string{
+		0: "FORWARD",
+		1: "BACKWARD",
+		2: "AROUND",
+	}
+	PullDirection_value = map[string]int32{
+		"FORWARD":  0,
+		"BACKWARD": 1,
+		"AROUND":   2,
+	}
+)
+
+func (x PullDirection) Enum() *PullDirection {
+	p := new(PullDirection)
+	*p = x
+	return p
+}
+
+func (x PullDirection) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PullDirection) Descriptor() protoreflect.EnumDescriptor {
+	return file_message_message_proto_enumTypes[2].Descriptor()
+}
+
+func (PullDirection) Type() protoreflect.EnumType {
+	return &file_message_message_proto_enumTypes[2]
+}
+
+func (x PullDirection) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PullDirection.Descriptor instead.
+func (PullDirection) EnumDescriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{2}
+}
+
+// SendMessageRequest 发送消息请求
+// Send message request
 type SendMessageRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`
-	SenderId      int64                  `protobuf:"varint,2,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
-	ConvType      ConversationType       `protobuf:"varint,3,opt,name=conv_type,json=convType,proto3,enum=message.ConversationType" json:"conv_type,omitempty"`
-	Body          *structpb.Struct       `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
-	ReplyTo       *string                `protobuf:"bytes,5,opt,name=reply_to,json=replyTo,proto3,oneof" json:"reply_to,omitempty"`
-	Mentions      []int64                `protobuf:"varint,6,rep,packed,name=mentions,proto3" json:"mentions,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConvId         int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`                                     // 会话ID / Conversation ID
+	SenderId       int64                  `protobuf:"varint,2,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`                               // 发送者用户ID / Sender user ID
+	ConvType       ConversationType       `protobuf:"varint,3,opt,name=conv_type,json=convType,proto3,enum=message.ConversationType" json:"conv_type,omitempty"` // 会话类型 / Conversation type
+	Body           *structpb.Struct       `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`                                                        // 消息体 (JSON格式，支持文本、图片、文件等) / Message body (JSON format, supports text, images, files, etc.)
+	ReplyTo        *string                `protobuf:"bytes,5,opt,name=reply_to,json=replyTo,proto3,oneof" json:"reply_to,omitempty"`                             // 回复的消息ID (可选) / Reply to message ID (optional)
+	Mentions       []int64                `protobuf:"varint,6,rep,packed,name=mentions,proto3" json:"mentions,omitempty"`                                        // @提到的用户ID列表 / List of mentioned user IDs
+	IdempotencyKey *string                `protobuf:"bytes,7,opt,name=idempotency_key,json=idempotencyKey,proto3,oneof" json:"idempotency_key,omitempty"`        // 幂等键 (客户端消息ID，重试时返回原消息) / Idempotency key (client message ID; retries return the original message)
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *SendMessageRequest) Reset() {
@@ -210,11 +268,20 @@ func (x *SendMessageRequest) GetMentions() []int64 {
 	return nil
 }
 
+func (x *SendMessageRequest) GetIdempotencyKey() string {
+	if x != nil && x.IdempotencyKey != nil {
+		return *x.IdempotencyKey
+	}
+	return ""
+}
+
+// SendMessageResponse 发送消息响应
+// Send message response
 type SendMessageResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	MsgId         string                 `protobuf:"bytes,1,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`
-	Seq           int64                  `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
-	CreatedAt     int64                  `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	MsgId         string                 `protobuf:"bytes,1,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`              // 消息唯一ID / Unique message ID
+	Seq           int64                  `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`                              // 消息序列号 (会话内递增) / Message sequence number (incremental within conversation)
+	CreatedAt     int64                  `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // 创建时间 (Unix时间戳) / Creation time (Unix timestamp)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -270,11 +337,17 @@ func (x *SendMessageResponse) GetCreatedAt() int64 {
 	return 0
 }
 
+// PullMessagesRequest 拉取消息请求
+// Pull messages request
 type PullMessagesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`
-	SinceSeq      int64                  `protobuf:"varint,2,opt,name=since_seq,json=sinceSeq,proto3" json:"since_seq,omitempty"`
-	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`                    // 会话ID / Conversation ID
+	SinceSeq      int64                  `protobuf:"varint,2,opt,name=since_seq,json=sinceSeq,proto3" json:"since_seq,omitempty"`              // 起始序列号 (FORWARD: 拉取此序列号之后的消息) / Start sequence number (FORWARD: pull messages after this sequence)
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`                                    // 限制数量 / Limit count
+	UserId        int64                  `protobuf:"varint,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                    // 调用者用户ID (用于成员校验) / Caller user ID (for membership check)
+	Direction     PullDirection          `protobuf:"varint,5,opt,name=direction,proto3,enum=message.PullDirection" json:"direction,omitempty"` // 拉取方向 (默认FORWARD) / Pull direction (defaults to FORWARD)
+	BeforeSeq     int64                  `protobuf:"varint,6,opt,name=before_seq,json=beforeSeq,proto3" json:"before_seq,omitempty"`           // BACKWARD: 拉取此序列号之前的消息 (0表示从最新开始) / BACKWARD: pull messages before this sequence (0 starts from the latest)
+	AroundSeq     int64                  `protobuf:"varint,7,opt,name=around_seq,json=aroundSeq,proto3" json:"around_seq,omitempty"`           // AROUND: 定位的序列号 (结果包含该消息) / AROUND: anchor sequence (included in the result)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -330,10 +403,42 @@ func (x *PullMessagesRequest) GetLimit() int32 {
 	return 0
 }
 
+func (x *PullMessagesRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *PullMessagesRequest) GetDirection() PullDirection {
+	if x != nil {
+		return x.Direction
+	}
+	return PullDirection_FORWARD
+}
+
+func (x *PullMessagesRequest) GetBeforeSeq() int64 {
+	if x != nil {
+		return x.BeforeSeq
+	}
+	return 0
+}
+
+func (x *PullMessagesRequest) GetAroundSeq() int64 {
+	if x != nil {
+		return x.AroundSeq
+	}
+	return 0
+}
+
+// PullMessagesResponse 拉取消息响应 (消息总是按seq升序排列)
+// Pull messages response (messages are always in ascending seq order)
 type PullMessagesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Messages      []*Message             `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
-	HasMore       bool                   `protobuf:"varint,2,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	Messages      []*Message             `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`                                   // 消息列表 / Message list
+	HasMore       bool                   `protobuf:"varint,2,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`                     // 拉取方向上是否还有更多消息 / Whether there are more messages in the pull direction
+	HasMoreBefore bool                   `protobuf:"varint,3,opt,name=has_more_before,json=hasMoreBefore,proto3" json:"has_more_before,omitempty"` // 是否还有更早的消息 (BACKWARD/AROUND) / Whether older messages remain (BACKWARD/AROUND)
+	HasMoreAfter  bool                   `protobuf:"varint,4,opt,name=has_more_after,json=hasMoreAfter,proto3" json:"has_more_after,omitempty"`    // 是否还有更新的消息 (FORWARD/AROUND) / Whether newer messages remain (FORWARD/AROUND)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -382,18 +487,35 @@ func (x *PullMessagesResponse) GetHasMore() bool {
 	return false
 }
 
+func (x *PullMessagesResponse) GetHasMoreBefore() bool {
+	if x != nil {
+		return x.HasMoreBefore
+	}
+	return false
+}
+
+func (x *PullMessagesResponse) GetHasMoreAfter() bool {
+	if x != nil {
+		return x.HasMoreAfter
+	}
+	return false
+}
+
+// Message 消息实体
+// Message entity
 type Message struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	MsgId         string                 `protobuf:"bytes,1,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`
-	ConvId        int64                  `protobuf:"varint,2,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`
-	Seq           int64                  `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`
-	SenderId      int64                  `protobuf:"varint,4,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
-	ConvType      ConversationType       `protobuf:"varint,5,opt,name=conv_type,json=convType,proto3,enum=message.ConversationType" json:"conv_type,omitempty"`
-	Body          *structpb.Struct       `protobuf:"bytes,6,opt,name=body,proto3" json:"body,omitempty"`
-	ReplyTo       *string                `protobuf:"bytes,7,opt,name=reply_to,json=replyTo,proto3,oneof" json:"reply_to,omitempty"`
-	Mentions      []int64                `protobuf:"varint,8,rep,packed,name=mentions,proto3" json:"mentions,omitempty"`
-	Visibility    string                 `protobuf:"bytes,9,opt,name=visibility,proto3" json:"visibility,omitempty"`
-	CreatedAt     int64                  `protobuf:"varint,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	MsgId         string                 `protobuf:"bytes,1,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`                                         // 消息唯一ID / Unique message ID
+	ConvId        int64                  `protobuf:"varint,2,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`                                     // 会话ID / Conversation ID
+	Seq           int64                  `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`                                                         // 消息序列号 / Message sequence number
+	SenderId      int64                  `protobuf:"varint,4,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`                               // 发送者用户ID / Sender user ID
+	ConvType      ConversationType       `protobuf:"varint,5,opt,name=conv_type,json=convType,proto3,enum=message.ConversationType" json:"conv_type,omitempty"` // 会话类型 / Conversation type
+	Body          *structpb.Struct       `protobuf:"bytes,6,opt,name=body,proto3" json:"body,omitempty"`                                                        // 消息体 / Message body
+	ReplyTo       *string                `protobuf:"bytes,7,opt,name=reply_to,json=replyTo,proto3,oneof" json:"reply_to,omitempty"`                             // 回复的消息ID / Reply to message ID
+	Mentions      []int64                `protobuf:"varint,8,rep,packed,name=mentions,proto3" json:"mentions,omitempty"`                                        // @提到的用户ID列表 / Mentioned user IDs
+	Visibility    string                 `protobuf:"bytes,9,opt,name=visibility,proto3" json:"visibility,omitempty"`                                            // 可见性 (normal/recalled) / Visibility (normal/recalled)
+	CreatedAt     int64                  `protobuf:"varint,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`                           // 创建时间 / Creation time
+	EditedAt      *int64                 `protobuf:"varint,11,opt,name=edited_at,json=editedAt,proto3,oneof" json:"edited_at,omitempty"`                        // 最后编辑时间 (未编辑时为空) / Last edit time (unset if never edited)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -498,9 +620,19 @@ func (x *Message) GetCreatedAt() int64 {
 	return 0
 }
 
+func (x *Message) GetEditedAt() int64 {
+	if x != nil && x.EditedAt != nil {
+		return *x.EditedAt
+	}
+	return 0
+}
+
+// GetConversationRequest 获取会话请求
+// Get conversation request
 type GetConversationRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`
+	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"` // 会话ID / Conversation ID
+	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // 调用者用户ID (用于成员校验) / Caller user ID (for membership check)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -542,9 +674,18 @@ func (x *GetConversationRequest) GetConvId() int64 {
 	return 0
 }
 
+func (x *GetConversationRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+// GetConversationResponse 获取会话响应
+// Get conversation response
 type GetConversationResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Conversation  *Conversation          `protobuf:"bytes,1,opt,name=conversation,proto3" json:"conversation,omitempty"`
+	Conversation  *Conversation          `protobuf:"bytes,1,opt,name=conversation,proto3" json:"conversation,omitempty"` // 会话详情 / Conversation details
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -586,14 +727,16 @@ func (x *GetConversationResponse) GetConversation() *Conversation {
 	return nil
 }
 
+// Conversation 会话实体
+// Conversation entity
 type Conversation struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Type          ConversationType       `protobuf:"varint,2,opt,name=type,proto3,enum=message.ConversationType" json:"type,omitempty"`
-	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
-	OwnerId       int64                  `protobuf:"varint,4,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`
-	CreatedAt     int64                  `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	Members       []*ConversationMember  `protobuf:"bytes,6,rep,name=members,proto3" json:"members,omitempty"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                                   // 会话ID / Conversation ID
+	Type          ConversationType       `protobuf:"varint,2,opt,name=type,proto3,enum=message.ConversationType" json:"type,omitempty"` // 会话类型 / Conversation type
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`                              // 会话标题 / Conversation title
+	OwnerId       int64                  `protobuf:"varint,4,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`          // 所有者用户ID / Owner user ID
+	CreatedAt     int64                  `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`    // 创建时间 / Creation time
+	Members       []*ConversationMember  `protobuf:"bytes,6,rep,name=members,proto3" json:"members,omitempty"`                          // 成员列表 / Member list
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -670,13 +813,15 @@ func (x *Conversation) GetMembers() []*ConversationMember {
 	return nil
 }
 
+// ConversationMember 会话成员
+// Conversation member
 type ConversationMember struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Role          ConversationRole       `protobuf:"varint,2,opt,name=role,proto3,enum=message.ConversationRole" json:"role,omitempty"`
-	Muted         bool                   `protobuf:"varint,3,opt,name=muted,proto3" json:"muted,omitempty"`
-	LastReadSeq   int64                  `protobuf:"varint,4,opt,name=last_read_seq,json=lastReadSeq,proto3" json:"last_read_seq,omitempty"`
-	JoinedAt      int64                  `protobuf:"varint,5,opt,name=joined_at,json=joinedAt,proto3" json:"joined_at,omitempty"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                  // 用户ID / User ID
+	Role          ConversationRole       `protobuf:"varint,2,opt,name=role,proto3,enum=message.ConversationRole" json:"role,omitempty"`      // 成员角色 / Member role
+	Muted         bool                   `protobuf:"varint,3,opt,name=muted,proto3" json:"muted,omitempty"`                                  // 是否静音 / Whether muted
+	LastReadSeq   int64                  `protobuf:"varint,4,opt,name=last_read_seq,json=lastReadSeq,proto3" json:"last_read_seq,omitempty"` // 最后已读序列号 / Last read sequence number
+	JoinedAt      int64                  `protobuf:"varint,5,opt,name=joined_at,json=joinedAt,proto3" json:"joined_at,omitempty"`            // 加入时间 / Join time
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -746,12 +891,14 @@ func (x *ConversationMember) GetJoinedAt() int64 {
 	return 0
 }
 
+// CreateConversationRequest 创建会话请求
+// Create conversation request
 type CreateConversationRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Type          ConversationType       `protobuf:"varint,1,opt,name=type,proto3,enum=message.ConversationType" json:"type,omitempty"`
-	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	OwnerId       int64                  `protobuf:"varint,3,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`
-	MemberIds     []int64                `protobuf:"varint,4,rep,packed,name=member_ids,json=memberIds,proto3" json:"member_ids,omitempty"`
+	Type          ConversationType       `protobuf:"varint,1,opt,name=type,proto3,enum=message.ConversationType" json:"type,omitempty"`     // 会话类型 / Conversation type
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`                                  // 会话标题 / Conversation title
+	OwnerId       int64                  `protobuf:"varint,3,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`              // 所有者用户ID / Owner user ID
+	MemberIds     []int64                `protobuf:"varint,4,rep,packed,name=member_ids,json=memberIds,proto3" json:"member_ids,omitempty"` // 初始成员ID列表 / Initial member ID list
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -814,10 +961,12 @@ func (x *CreateConversationRequest) GetMemberIds() []int64 {
 	return nil
 }
 
+// CreateConversationResponse 创建会话响应
+// Create conversation response
 type CreateConversationResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"` // 新创建的会话ID / Newly created conversation ID
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`              // 响应消息 / Response message
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -866,18 +1015,128 @@ func (x *CreateConversationResponse) GetMessage() string {
 	return ""
 }
 
+// GetOrCreateDirectConversationRequest 获取或创建单聊会话请求
+// Get or create direct conversation request
+type GetOrCreateDirectConversationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // 调用者用户ID / Caller user ID
+	PeerId        int64                  `protobuf:"varint,2,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"` // 对方用户ID / Peer user ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrCreateDirectConversationRequest) Reset() {
+	*x = GetOrCreateDirectConversationRequest{}
+	mi := &file_message_message_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrCreateDirectConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrCreateDirectConversationRequest) ProtoMessage() {}
+
+func (x *GetOrCreateDirectConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrCreateDirectConversationRequest.ProtoReflect.Descriptor instead.
+func (*GetOrCreateDirectConversationRequest) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetOrCreateDirectConversationRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *GetOrCreateDirectConversationRequest) GetPeerId() int64 {
+	if x != nil {
+		return x.PeerId
+	}
+	return 0
+}
+
+// GetOrCreateDirectConversationResponse 获取或创建单聊会话响应
+// Get or create direct conversation response
+type GetOrCreateDirectConversationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"` // 单聊会话ID / Direct conversation ID
+	Created       bool                   `protobuf:"varint,2,opt,name=created,proto3" json:"created,omitempty"`             // 是否为新创建 / Whether the conversation was newly created
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrCreateDirectConversationResponse) Reset() {
+	*x = GetOrCreateDirectConversationResponse{}
+	mi := &file_message_message_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrCreateDirectConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrCreateDirectConversationResponse) ProtoMessage() {}
+
+func (x *GetOrCreateDirectConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrCreateDirectConversationResponse.ProtoReflect.Descriptor instead.
+func (*GetOrCreateDirectConversationResponse) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetOrCreateDirectConversationResponse) GetConvId() int64 {
+	if x != nil {
+		return x.ConvId
+	}
+	return 0
+}
+
+func (x *GetOrCreateDirectConversationResponse) GetCreated() bool {
+	if x != nil {
+		return x.Created
+	}
+	return false
+}
+
+// UpdateReadSeqRequest 更新已读序列号请求
+// Update read sequence request
 type UpdateReadSeqRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`
-	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Seq           int64                  `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`
+	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"` // 会话ID / Conversation ID
+	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // 用户ID / User ID
+	Seq           int64                  `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`                     // 已读序列号 / Read sequence number
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *UpdateReadSeqRequest) Reset() {
 	*x = UpdateReadSeqRequest{}
-	mi := &file_message_message_proto_msgTypes[11]
+	mi := &file_message_message_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -889,7 +1148,7 @@ func (x *UpdateReadSeqRequest) String() string {
 func (*UpdateReadSeqRequest) ProtoMessage() {}
 
 func (x *UpdateReadSeqRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_message_message_proto_msgTypes[11]
+	mi := &file_message_message_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -902,7 +1161,7 @@ func (x *UpdateReadSeqRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateReadSeqRequest.ProtoReflect.Descriptor instead.
 func (*UpdateReadSeqRequest) Descriptor() ([]byte, []int) {
-	return file_message_message_proto_rawDescGZIP(), []int{11}
+	return file_message_message_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *UpdateReadSeqRequest) GetConvId() int64 {
@@ -926,16 +1185,18 @@ func (x *UpdateReadSeqRequest) GetSeq() int64 {
 	return 0
 }
 
+// UpdateReadSeqResponse 更新已读序列号响应
+// Update read sequence response
 type UpdateReadSeqResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"` // 是否成功 / Success status
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *UpdateReadSeqResponse) Reset() {
 	*x = UpdateReadSeqResponse{}
-	mi := &file_message_message_proto_msgTypes[12]
+	mi := &file_message_message_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -947,7 +1208,7 @@ func (x *UpdateReadSeqResponse) String() string {
 func (*UpdateReadSeqResponse) ProtoMessage() {}
 
 func (x *UpdateReadSeqResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_message_message_proto_msgTypes[12]
+	mi := &file_message_message_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -960,7 +1221,7 @@ func (x *UpdateReadSeqResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateReadSeqResponse.ProtoReflect.Descriptor instead.
 func (*UpdateReadSeqResponse) Descriptor() ([]byte, []int) {
-	return file_message_message_proto_rawDescGZIP(), []int{12}
+	return file_message_message_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *UpdateReadSeqResponse) GetSuccess() bool {
@@ -970,20 +1231,22 @@ func (x *UpdateReadSeqResponse) GetSuccess() bool {
 	return false
 }
 
+// NotifyNewMessageRequest 通知新消息请求 (内部服务间调用)
+// Notify new message request (internal service call)
 type NotifyNewMessageRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`
-	MsgId         string                 `protobuf:"bytes,2,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`
-	Seq           int64                  `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`
-	SenderId      int64                  `protobuf:"varint,4,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
-	RecipientIds  []int64                `protobuf:"varint,5,rep,packed,name=recipient_ids,json=recipientIds,proto3" json:"recipient_ids,omitempty"`
+	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`                          // 会话ID / Conversation ID
+	MsgId         string                 `protobuf:"bytes,2,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`                              // 消息ID / Message ID
+	Seq           int64                  `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`                                              // 消息序列号 / Message sequence number
+	SenderId      int64                  `protobuf:"varint,4,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`                    // 发送者ID / Sender ID
+	RecipientIds  []int64                `protobuf:"varint,5,rep,packed,name=recipient_ids,json=recipientIds,proto3" json:"recipient_ids,omitempty"` // 接收者ID列表 / Recipient ID list
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *NotifyNewMessageRequest) Reset() {
 	*x = NotifyNewMessageRequest{}
-	mi := &file_message_message_proto_msgTypes[13]
+	mi := &file_message_message_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -995,7 +1258,7 @@ func (x *NotifyNewMessageRequest) String() string {
 func (*NotifyNewMessageRequest) ProtoMessage() {}
 
 func (x *NotifyNewMessageRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_message_message_proto_msgTypes[13]
+	mi := &file_message_message_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1008,7 +1271,7 @@ func (x *NotifyNewMessageRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NotifyNewMessageRequest.ProtoReflect.Descriptor instead.
 func (*NotifyNewMessageRequest) Descriptor() ([]byte, []int) {
-	return file_message_message_proto_rawDescGZIP(), []int{13}
+	return file_message_message_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *NotifyNewMessageRequest) GetConvId() int64 {
@@ -1046,17 +1309,19 @@ func (x *NotifyNewMessageRequest) GetRecipientIds() []int64 {
 	return nil
 }
 
+// NotifyNewMessageResponse 通知新消息响应
+// Notify new message response
 type NotifyNewMessageResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	NotifiedCount int32                  `protobuf:"varint,2,opt,name=notified_count,json=notifiedCount,proto3" json:"notified_count,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`                                  // 是否成功 / Success status
+	NotifiedCount int32                  `protobuf:"varint,2,opt,name=notified_count,json=notifiedCount,proto3" json:"notified_count,omitempty"` // 成功通知的用户数 / Number of users successfully notified
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *NotifyNewMessageResponse) Reset() {
 	*x = NotifyNewMessageResponse{}
-	mi := &file_message_message_proto_msgTypes[14]
+	mi := &file_message_message_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1068,7 +1333,7 @@ func (x *NotifyNewMessageResponse) String() string {
 func (*NotifyNewMessageResponse) ProtoMessage() {}
 
 func (x *NotifyNewMessageResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_message_message_proto_msgTypes[14]
+	mi := &file_message_message_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1081,7 +1346,7 @@ func (x *NotifyNewMessageResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NotifyNewMessageResponse.ProtoReflect.Descriptor instead.
 func (*NotifyNewMessageResponse) Descriptor() ([]byte, []int) {
-	return file_message_message_proto_rawDescGZIP(), []int{14}
+	return file_message_message_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *NotifyNewMessageResponse) GetSuccess() bool {
@@ -1098,109 +1363,1432 @@ func (x *NotifyNewMessageResponse) GetNotifiedCount() int32 {
 	return 0
 }
 
-var File_message_message_proto protoreflect.FileDescriptor
+// AddMembersRequest 添加成员请求
+// Add members request
+type AddMembersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`                   // 会话ID / Conversation ID
+	OperatorId    int64                  `protobuf:"varint,2,opt,name=operator_id,json=operatorId,proto3" json:"operator_id,omitempty"`       // 操作者用户ID / Operator user ID
+	MemberIds     []int64                `protobuf:"varint,3,rep,packed,name=member_ids,json=memberIds,proto3" json:"member_ids,omitempty"`   // 待添加的用户ID列表 / User IDs to add
+	Role          *ConversationRole      `protobuf:"varint,4,opt,name=role,proto3,enum=message.ConversationRole,oneof" json:"role,omitempty"` // 新成员角色 (默认MEMBER) / Role of new members (defaults to MEMBER)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_message_message_proto_rawDesc = "" +
-	"\n" +
-	"\x15message/message.proto\x12\amessage\x1a\x1cgoogle/protobuf/struct.proto\"\xf8\x01\n" +
-	"\x12SendMessageRequest\x12\x17\n" +
-	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x1b\n" +
-	"\tsender_id\x18\x02 \x01(\x03R\bsenderId\x126\n" +
-	"\tconv_type\x18\x03 \x01(\x0e2\x19.message.ConversationTypeR\bconvType\x12+\n" +
-	"\x04body\x18\x04 \x01(\v2\x17.google.protobuf.StructR\x04body\x12\x1e\n" +
-	"\breply_to\x18\x05 \x01(\tH\x00R\areplyTo\x88\x01\x01\x12\x1a\n" +
-	"\bmentions\x18\x06 \x03(\x03R\bmentionsB\v\n" +
-	"\t_reply_to\"]\n" +
-	"\x13SendMessageResponse\x12\x15\n" +
-	"\x06msg_id\x18\x01 \x01(\tR\x05msgId\x12\x10\n" +
-	"\x03seq\x18\x02 \x01(\x03R\x03seq\x12\x1d\n" +
-	"\n" +
-	"created_at\x18\x03 \x01(\x03R\tcreatedAt\"a\n" +
-	"\x13PullMessagesRequest\x12\x17\n" +
-	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x1b\n" +
-	"\tsince_seq\x18\x02 \x01(\x03R\bsinceSeq\x12\x14\n" +
-	"\x05limit\x18\x03 \x01(\x05R\x05limit\"_\n" +
-	"\x14PullMessagesResponse\x12,\n" +
-	"\bmessages\x18\x01 \x03(\v2\x10.message.MessageR\bmessages\x12\x19\n" +
-	"\bhas_more\x18\x02 \x01(\bR\ahasMore\"\xd5\x02\n" +
-	"\aMessage\x12\x15\n" +
-	"\x06msg_id\x18\x01 \x01(\tR\x05msgId\x12\x17\n" +
-	"\aconv_id\x18\x02 \x01(\x03R\x06convId\x12\x10\n" +
-	"\x03seq\x18\x03 \x01(\x03R\x03seq\x12\x1b\n" +
-	"\tsender_id\x18\x04 \x01(\x03R\bsenderId\x126\n" +
-	"\tconv_type\x18\x05 \x01(\x0e2\x19.message.ConversationTypeR\bconvType\x12+\n" +
-	"\x04body\x18\x06 \x01(\v2\x17.google.protobuf.StructR\x04body\x12\x1e\n" +
-	"\breply_to\x18\a \x01(\tH\x00R\areplyTo\x88\x01\x01\x12\x1a\n" +
-	"\bmentions\x18\b \x03(\x03R\bmentions\x12\x1e\n" +
-	"\n" +
-	"visibility\x18\t \x01(\tR\n" +
-	"visibility\x12\x1d\n" +
-	"\n" +
-	"created_at\x18\n" +
-	" \x01(\x03R\tcreatedAtB\v\n" +
-	"\t_reply_to\"1\n" +
-	"\x16GetConversationRequest\x12\x17\n" +
-	"\aconv_id\x18\x01 \x01(\x03R\x06convId\"T\n" +
-	"\x17GetConversationResponse\x129\n" +
-	"\fconversation\x18\x01 \x01(\v2\x15.message.ConversationR\fconversation\"\xd4\x01\n" +
-	"\fConversation\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x03R\x02id\x12-\n" +
-	"\x04type\x18\x02 \x01(\x0e2\x19.message.ConversationTypeR\x04type\x12\x14\n" +
-	"\x05title\x18\x03 \x01(\tR\x05title\x12\x19\n" +
-	"\bowner_id\x18\x04 \x01(\x03R\aownerId\x12\x1d\n" +
-	"\n" +
-	"created_at\x18\x05 \x01(\x03R\tcreatedAt\x125\n" +
-	"\amembers\x18\x06 \x03(\v2\x1b.message.ConversationMemberR\amembers\"\xb3\x01\n" +
-	"\x12ConversationMember\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12-\n" +
-	"\x04role\x18\x02 \x01(\x0e2\x19.message.ConversationRoleR\x04role\x12\x14\n" +
-	"\x05muted\x18\x03 \x01(\bR\x05muted\x12\"\n" +
-	"\rlast_read_seq\x18\x04 \x01(\x03R\vlastReadSeq\x12\x1b\n" +
-	"\tjoined_at\x18\x05 \x01(\x03R\bjoinedAt\"\x9a\x01\n" +
-	"\x19CreateConversationRequest\x12-\n" +
-	"\x04type\x18\x01 \x01(\x0e2\x19.message.ConversationTypeR\x04type\x12\x14\n" +
-	"\x05title\x18\x02 \x01(\tR\x05title\x12\x19\n" +
-	"\bowner_id\x18\x03 \x01(\x03R\aownerId\x12\x1d\n" +
-	"\n" +
-	"member_ids\x18\x04 \x03(\x03R\tmemberIds\"O\n" +
-	"\x1aCreateConversationResponse\x12\x17\n" +
-	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"Z\n" +
-	"\x14UpdateReadSeqRequest\x12\x17\n" +
-	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12\x10\n" +
-	"\x03seq\x18\x03 \x01(\x03R\x03seq\"1\n" +
-	"\x15UpdateReadSeqResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x9d\x01\n" +
-	"\x17NotifyNewMessageRequest\x12\x17\n" +
-	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x15\n" +
-	"\x06msg_id\x18\x02 \x01(\tR\x05msgId\x12\x10\n" +
-	"\x03seq\x18\x03 \x01(\x03R\x03seq\x12\x1b\n" +
-	"\tsender_id\x18\x04 \x01(\x03R\bsenderId\x12#\n" +
-	"\rrecipient_ids\x18\x05 \x03(\x03R\frecipientIds\"[\n" +
-	"\x18NotifyNewMessageResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12%\n" +
-	"\x0enotified_count\x18\x02 \x01(\x05R\rnotifiedCount*6\n" +
-	"\x10ConversationType\x12\n" +
-	"\n" +
-	"\x06DIRECT\x10\x00\x12\t\n" +
-	"\x05GROUP\x10\x01\x12\v\n" +
-	"\aCHANNEL\x10\x02*O\n" +
-	"\x10ConversationRole\x12\t\n" +
-	"\x05OWNER\x10\x00\x12\t\n" +
-	"\x05ADMIN\x10\x01\x12\r\n" +
-	"\tPUBLISHER\x10\x02\x12\n" +
-	"\n" +
-	"\x06MEMBER\x10\x03\x12\n" +
-	"\n" +
-	"\x06VIEWER\x10\x042\x85\x04\n" +
+func (x *AddMembersRequest) Reset() {
+	*x = AddMembersRequest{}
+	mi := &file_message_message_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddMembersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddMembersRequest) ProtoMessage() {}
+
+func (x *AddMembersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddMembersRequest.ProtoReflect.Descriptor instead.
+func (*AddMembersRequest) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *AddMembersRequest) GetConvId() int64 {
+	if x != nil {
+		return x.ConvId
+	}
+	return 0
+}
+
+func (x *AddMembersRequest) GetOperatorId() int64 {
+	if x != nil {
+		return x.OperatorId
+	}
+	return 0
+}
+
+func (x *AddMembersRequest) GetMemberIds() []int64 {
+	if x != nil {
+		return x.MemberIds
+	}
+	return nil
+}
+
+func (x *AddMembersRequest) GetRole() ConversationRole {
+	if x != nil && x.Role != nil {
+		return *x.Role
+	}
+	return ConversationRole_OWNER
+}
+
+// AddMembersResponse 添加成员响应
+// Add members response
+type AddMembersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AddedIds      []int64                `protobuf:"varint,1,rep,packed,name=added_ids,json=addedIds,proto3" json:"added_ids,omitempty"` // 实际新增的用户ID (已是成员的会被跳过) / User IDs actually added (existing members are skipped)
+	Seq           int64                  `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`                                  // 系统消息序列号 (无变更时为0) / System message sequence number (0 if nothing changed)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddMembersResponse) Reset() {
+	*x = AddMembersResponse{}
+	mi := &file_message_message_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddMembersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddMembersResponse) ProtoMessage() {}
+
+func (x *AddMembersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddMembersResponse.ProtoReflect.Descriptor instead.
+func (*AddMembersResponse) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *AddMembersResponse) GetAddedIds() []int64 {
+	if x != nil {
+		return x.AddedIds
+	}
+	return nil
+}
+
+func (x *AddMembersResponse) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+// RemoveMembersRequest 移除成员请求
+// Remove members request
+type RemoveMembersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`                 // 会话ID / Conversation ID
+	OperatorId    int64                  `protobuf:"varint,2,opt,name=operator_id,json=operatorId,proto3" json:"operator_id,omitempty"`     // 操作者用户ID / Operator user ID
+	MemberIds     []int64                `protobuf:"varint,3,rep,packed,name=member_ids,json=memberIds,proto3" json:"member_ids,omitempty"` // 待移除的用户ID列表 / User IDs to remove
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveMembersRequest) Reset() {
+	*x = RemoveMembersRequest{}
+	mi := &file_message_message_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveMembersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveMembersRequest) ProtoMessage() {}
+
+func (x *RemoveMembersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveMembersRequest.ProtoReflect.Descriptor instead.
+func (*RemoveMembersRequest) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RemoveMembersRequest) GetConvId() int64 {
+	if x != nil {
+		return x.ConvId
+	}
+	return 0
+}
+
+func (x *RemoveMembersRequest) GetOperatorId() int64 {
+	if x != nil {
+		return x.OperatorId
+	}
+	return 0
+}
+
+func (x *RemoveMembersRequest) GetMemberIds() []int64 {
+	if x != nil {
+		return x.MemberIds
+	}
+	return nil
+}
+
+// RemoveMembersResponse 移除成员响应
+// Remove members response
+type RemoveMembersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RemovedIds    []int64                `protobuf:"varint,1,rep,packed,name=removed_ids,json=removedIds,proto3" json:"removed_ids,omitempty"` // 实际移除的用户ID / User IDs actually removed
+	Seq           int64                  `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`                                        // 系统消息序列号 (无变更时为0) / System message sequence number (0 if nothing changed)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveMembersResponse) Reset() {
+	*x = RemoveMembersResponse{}
+	mi := &file_message_message_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveMembersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveMembersResponse) ProtoMessage() {}
+
+func (x *RemoveMembersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveMembersResponse.ProtoReflect.Descriptor instead.
+func (*RemoveMembersResponse) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RemoveMembersResponse) GetRemovedIds() []int64 {
+	if x != nil {
+		return x.RemovedIds
+	}
+	return nil
+}
+
+func (x *RemoveMembersResponse) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+// UpdateMemberRoleRequest 修改成员角色请求
+// Update member role request
+type UpdateMemberRoleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`             // 会话ID / Conversation ID
+	OperatorId    int64                  `protobuf:"varint,2,opt,name=operator_id,json=operatorId,proto3" json:"operator_id,omitempty"` // 操作者用户ID / Operator user ID
+	UserId        int64                  `protobuf:"varint,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`             // 目标成员用户ID / Target member user ID
+	Role          ConversationRole       `protobuf:"varint,4,opt,name=role,proto3,enum=message.ConversationRole" json:"role,omitempty"` // 新角色 (不能为OWNER) / New role (cannot be OWNER)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateMemberRoleRequest) Reset() {
+	*x = UpdateMemberRoleRequest{}
+	mi := &file_message_message_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateMemberRoleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateMemberRoleRequest) ProtoMessage() {}
+
+func (x *UpdateMemberRoleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateMemberRoleRequest.ProtoReflect.Descriptor instead.
+func (*UpdateMemberRoleRequest) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *UpdateMemberRoleRequest) GetConvId() int64 {
+	if x != nil {
+		return x.ConvId
+	}
+	return 0
+}
+
+func (x *UpdateMemberRoleRequest) GetOperatorId() int64 {
+	if x != nil {
+		return x.OperatorId
+	}
+	return 0
+}
+
+func (x *UpdateMemberRoleRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *UpdateMemberRoleRequest) GetRole() ConversationRole {
+	if x != nil {
+		return x.Role
+	}
+	return ConversationRole_OWNER
+}
+
+// UpdateMemberRoleResponse 修改成员角色响应
+// Update member role response
+type UpdateMemberRoleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"` // 是否成功 / Success status
+	Seq           int64                  `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`         // 系统消息序列号 / System message sequence number
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateMemberRoleResponse) Reset() {
+	*x = UpdateMemberRoleResponse{}
+	mi := &file_message_message_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateMemberRoleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateMemberRoleResponse) ProtoMessage() {}
+
+func (x *UpdateMemberRoleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateMemberRoleResponse.ProtoReflect.Descriptor instead.
+func (*UpdateMemberRoleResponse) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *UpdateMemberRoleResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *UpdateMemberRoleResponse) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+// LeaveConversationRequest 退出会话请求
+// Leave conversation request
+type LeaveConversationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"` // 会话ID / Conversation ID
+	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // 退出的用户ID / Leaving user ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaveConversationRequest) Reset() {
+	*x = LeaveConversationRequest{}
+	mi := &file_message_message_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaveConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaveConversationRequest) ProtoMessage() {}
+
+func (x *LeaveConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaveConversationRequest.ProtoReflect.Descriptor instead.
+func (*LeaveConversationRequest) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *LeaveConversationRequest) GetConvId() int64 {
+	if x != nil {
+		return x.ConvId
+	}
+	return 0
+}
+
+func (x *LeaveConversationRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+// LeaveConversationResponse 退出会话响应
+// Leave conversation response
+type LeaveConversationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"` // 是否成功 / Success status
+	Seq           int64                  `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`         // 系统消息序列号 / System message sequence number
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaveConversationResponse) Reset() {
+	*x = LeaveConversationResponse{}
+	mi := &file_message_message_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaveConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaveConversationResponse) ProtoMessage() {}
+
+func (x *LeaveConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaveConversationResponse.ProtoReflect.Descriptor instead.
+func (*LeaveConversationResponse) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *LeaveConversationResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *LeaveConversationResponse) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+// TransferOwnershipRequest 转让所有权请求
+// Transfer ownership request
+type TransferOwnershipRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`               // 会话ID / Conversation ID
+	OwnerId       int64                  `protobuf:"varint,2,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`            // 当前所有者用户ID / Current owner user ID
+	NewOwnerId    int64                  `protobuf:"varint,3,opt,name=new_owner_id,json=newOwnerId,proto3" json:"new_owner_id,omitempty"` // 新所有者用户ID (必须已是成员) / New owner user ID (must already be a member)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransferOwnershipRequest) Reset() {
+	*x = TransferOwnershipRequest{}
+	mi := &file_message_message_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransferOwnershipRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferOwnershipRequest) ProtoMessage() {}
+
+func (x *TransferOwnershipRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferOwnershipRequest.ProtoReflect.Descriptor instead.
+func (*TransferOwnershipRequest) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *TransferOwnershipRequest) GetConvId() int64 {
+	if x != nil {
+		return x.ConvId
+	}
+	return 0
+}
+
+func (x *TransferOwnershipRequest) GetOwnerId() int64 {
+	if x != nil {
+		return x.OwnerId
+	}
+	return 0
+}
+
+func (x *TransferOwnershipRequest) GetNewOwnerId() int64 {
+	if x != nil {
+		return x.NewOwnerId
+	}
+	return 0
+}
+
+// TransferOwnershipResponse 转让所有权响应
+// Transfer ownership response
+type TransferOwnershipResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"` // 是否成功 / Success status
+	Seq           int64                  `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`         // 系统消息序列号 / System message sequence number
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransferOwnershipResponse) Reset() {
+	*x = TransferOwnershipResponse{}
+	mi := &file_message_message_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransferOwnershipResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferOwnershipResponse) ProtoMessage() {}
+
+func (x *TransferOwnershipResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferOwnershipResponse.ProtoReflect.Descriptor instead.
+func (*TransferOwnershipResponse) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *TransferOwnershipResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *TransferOwnershipResponse) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+// ListConversationsRequest 获取会话列表请求
+// List conversations request
+type ListConversationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // 用户ID / User ID
+	Cursor        string                 `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`                // 分页游标 (为空表示第一页) / Pagination cursor (empty for the first page)
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`                 // 限制数量 / Limit count
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConversationsRequest) Reset() {
+	*x = ListConversationsRequest{}
+	mi := &file_message_message_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConversationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConversationsRequest) ProtoMessage() {}
+
+func (x *ListConversationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConversationsRequest.ProtoReflect.Descriptor instead.
+func (*ListConversationsRequest) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ListConversationsRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ListConversationsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListConversationsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// ListConversationsResponse 获取会话列表响应
+// List conversations response
+type ListConversationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Conversations []*ConversationSummary `protobuf:"bytes,1,rep,name=conversations,proto3" json:"conversations,omitempty"`             // 会话列表 / Conversation list
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"` // 下一页游标 / Cursor for the next page
+	HasMore       bool                   `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`         // 是否还有更多会话 / Whether there are more conversations
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConversationsResponse) Reset() {
+	*x = ListConversationsResponse{}
+	mi := &file_message_message_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConversationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConversationsResponse) ProtoMessage() {}
+
+func (x *ListConversationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConversationsResponse.ProtoReflect.Descriptor instead.
+func (*ListConversationsResponse) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ListConversationsResponse) GetConversations() []*ConversationSummary {
+	if x != nil {
+		return x.Conversations
+	}
+	return nil
+}
+
+func (x *ListConversationsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *ListConversationsResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+// ConversationSummary 会话摘要 (会话列表项)
+// Conversation summary (inbox entry)
+type ConversationSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`                      // 会话ID / Conversation ID
+	Type          ConversationType       `protobuf:"varint,2,opt,name=type,proto3,enum=message.ConversationType" json:"type,omitempty"`          // 会话类型 / Conversation type
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`                                       // 会话标题 / Conversation title
+	OwnerId       int64                  `protobuf:"varint,4,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`                   // 所有者用户ID / Owner user ID
+	Role          ConversationRole       `protobuf:"varint,5,opt,name=role,proto3,enum=message.ConversationRole" json:"role,omitempty"`          // 当前用户的角色 / Caller's role
+	Muted         bool                   `protobuf:"varint,6,opt,name=muted,proto3" json:"muted,omitempty"`                                      // 当前用户是否被禁言 / Whether the caller is muted
+	CurrentSeq    int64                  `protobuf:"varint,7,opt,name=current_seq,json=currentSeq,proto3" json:"current_seq,omitempty"`          // 会话当前序列号 / Current conversation sequence number
+	LastReadSeq   int64                  `protobuf:"varint,8,opt,name=last_read_seq,json=lastReadSeq,proto3" json:"last_read_seq,omitempty"`     // 当前用户已读序列号 / Caller's last read sequence number
+	UnreadCount   int64                  `protobuf:"varint,9,opt,name=unread_count,json=unreadCount,proto3" json:"unread_count,omitempty"`       // 未读消息数，不含自己的消息和系统消息 / Unread messages from others, excluding system messages
+	LastMessage   *Message               `protobuf:"bytes,10,opt,name=last_message,json=lastMessage,proto3" json:"last_message,omitempty"`       // 最后一条消息 (无消息时为空) / Last message preview (unset if none)
+	LastActiveAt  int64                  `protobuf:"varint,11,opt,name=last_active_at,json=lastActiveAt,proto3" json:"last_active_at,omitempty"` // 最后活跃时间 (最后消息时间或创建时间) / Last activity time (last message or creation time)
+	CreatedAt     int64                  `protobuf:"varint,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`            // 创建时间 / Creation time
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConversationSummary) Reset() {
+	*x = ConversationSummary{}
+	mi := &file_message_message_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConversationSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConversationSummary) ProtoMessage() {}
+
+func (x *ConversationSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConversationSummary.ProtoReflect.Descriptor instead.
+func (*ConversationSummary) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ConversationSummary) GetConvId() int64 {
+	if x != nil {
+		return x.ConvId
+	}
+	return 0
+}
+
+func (x *ConversationSummary) GetType() ConversationType {
+	if x != nil {
+		return x.Type
+	}
+	return ConversationType_DIRECT
+}
+
+func (x *ConversationSummary) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ConversationSummary) GetOwnerId() int64 {
+	if x != nil {
+		return x.OwnerId
+	}
+	return 0
+}
+
+func (x *ConversationSummary) GetRole() ConversationRole {
+	if x != nil {
+		return x.Role
+	}
+	return ConversationRole_OWNER
+}
+
+func (x *ConversationSummary) GetMuted() bool {
+	if x != nil {
+		return x.Muted
+	}
+	return false
+}
+
+func (x *ConversationSummary) GetCurrentSeq() int64 {
+	if x != nil {
+		return x.CurrentSeq
+	}
+	return 0
+}
+
+func (x *ConversationSummary) GetLastReadSeq() int64 {
+	if x != nil {
+		return x.LastReadSeq
+	}
+	return 0
+}
+
+func (x *ConversationSummary) GetUnreadCount() int64 {
+	if x != nil {
+		return x.UnreadCount
+	}
+	return 0
+}
+
+func (x *ConversationSummary) GetLastMessage() *Message {
+	if x != nil {
+		return x.LastMessage
+	}
+	return nil
+}
+
+func (x *ConversationSummary) GetLastActiveAt() int64 {
+	if x != nil {
+		return x.LastActiveAt
+	}
+	return 0
+}
+
+func (x *ConversationSummary) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+// RecallMessageRequest 撤回消息请求
+// Recall message request
+type RecallMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`             // 会话ID / Conversation ID
+	MsgId         string                 `protobuf:"bytes,2,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`                 // 被撤回的消息ID / ID of the message to recall
+	OperatorId    int64                  `protobuf:"varint,3,opt,name=operator_id,json=operatorId,proto3" json:"operator_id,omitempty"` // 操作者用户ID / Operator user ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecallMessageRequest) Reset() {
+	*x = RecallMessageRequest{}
+	mi := &file_message_message_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecallMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecallMessageRequest) ProtoMessage() {}
+
+func (x *RecallMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecallMessageRequest.ProtoReflect.Descriptor instead.
+func (*RecallMessageRequest) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *RecallMessageRequest) GetConvId() int64 {
+	if x != nil {
+		return x.ConvId
+	}
+	return 0
+}
+
+func (x *RecallMessageRequest) GetMsgId() string {
+	if x != nil {
+		return x.MsgId
+	}
+	return ""
+}
+
+func (x *RecallMessageRequest) GetOperatorId() int64 {
+	if x != nil {
+		return x.OperatorId
+	}
+	return 0
+}
+
+// RecallMessageResponse 撤回消息响应
+// Recall message response
+type RecallMessageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"` // 是否成功 / Success status
+	Seq           int64                  `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`         // 撤回事件序列号 / Recall event sequence number
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecallMessageResponse) Reset() {
+	*x = RecallMessageResponse{}
+	mi := &file_message_message_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecallMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecallMessageResponse) ProtoMessage() {}
+
+func (x *RecallMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecallMessageResponse.ProtoReflect.Descriptor instead.
+func (*RecallMessageResponse) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *RecallMessageResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RecallMessageResponse) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+// EditMessageRequest 编辑消息请求
+// Edit message request
+type EditMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`             // 会话ID / Conversation ID
+	MsgId         string                 `protobuf:"bytes,2,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`                 // 被编辑的消息ID / ID of the message to edit
+	OperatorId    int64                  `protobuf:"varint,3,opt,name=operator_id,json=operatorId,proto3" json:"operator_id,omitempty"` // 操作者用户ID (必须是发送者) / Operator user ID (must be the sender)
+	Body          *structpb.Struct       `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`                                // 新消息体 / New message body
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EditMessageRequest) Reset() {
+	*x = EditMessageRequest{}
+	mi := &file_message_message_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EditMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EditMessageRequest) ProtoMessage() {}
+
+func (x *EditMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EditMessageRequest.ProtoReflect.Descriptor instead.
+func (*EditMessageRequest) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *EditMessageRequest) GetConvId() int64 {
+	if x != nil {
+		return x.ConvId
+	}
+	return 0
+}
+
+func (x *EditMessageRequest) GetMsgId() string {
+	if x != nil {
+		return x.MsgId
+	}
+	return ""
+}
+
+func (x *EditMessageRequest) GetOperatorId() int64 {
+	if x != nil {
+		return x.OperatorId
+	}
+	return 0
+}
+
+func (x *EditMessageRequest) GetBody() *structpb.Struct {
+	if x != nil {
+		return x.Body
+	}
+	return nil
+}
+
+// EditMessageResponse 编辑消息响应
+// Edit message response
+type EditMessageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`                   // 是否成功 / Success status
+	Seq           int64                  `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`                           // 编辑事件序列号 / Edit event sequence number
+	EditedAt      int64                  `protobuf:"varint,3,opt,name=edited_at,json=editedAt,proto3" json:"edited_at,omitempty"` // 编辑时间 (Unix时间戳) / Edit time (Unix timestamp)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EditMessageResponse) Reset() {
+	*x = EditMessageResponse{}
+	mi := &file_message_message_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EditMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EditMessageResponse) ProtoMessage() {}
+
+func (x *EditMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EditMessageResponse.ProtoReflect.Descriptor instead.
+func (*EditMessageResponse) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *EditMessageResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *EditMessageResponse) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *EditMessageResponse) GetEditedAt() int64 {
+	if x != nil {
+		return x.EditedAt
+	}
+	return 0
+}
+
+// FilterPeersRequest 筛选会话伙伴请求
+// Filter peers request
+type FilterPeersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`           // 用户ID / User ID
+	UserIds       []int64                `protobuf:"varint,2,rep,packed,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"` // 待筛选的用户ID / User IDs to filter
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FilterPeersRequest) Reset() {
+	*x = FilterPeersRequest{}
+	mi := &file_message_message_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FilterPeersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilterPeersRequest) ProtoMessage() {}
+
+func (x *FilterPeersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FilterPeersRequest.ProtoReflect.Descriptor instead.
+func (*FilterPeersRequest) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *FilterPeersRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *FilterPeersRequest) GetUserIds() []int64 {
+	if x != nil {
+		return x.UserIds
+	}
+	return nil
+}
+
+// FilterPeersResponse 筛选会话伙伴响应
+// Filter peers response
+type FilterPeersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserIds       []int64                `protobuf:"varint,1,rep,packed,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"` // 与用户共同在某个会话中的用户ID / User IDs sharing a conversation with the user
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FilterPeersResponse) Reset() {
+	*x = FilterPeersResponse{}
+	mi := &file_message_message_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FilterPeersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilterPeersResponse) ProtoMessage() {}
+
+func (x *FilterPeersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_message_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FilterPeersResponse.ProtoReflect.Descriptor instead.
+func (*FilterPeersResponse) Descriptor() ([]byte, []int) {
+	return file_message_message_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *FilterPeersResponse) GetUserIds() []int64 {
+	if x != nil {
+		return x.UserIds
+	}
+	return nil
+}
+
+var File_message_message_proto protoreflect.FileDescriptor
+
+const file_message_message_proto_rawDesc = "" +
+	"\n" +
+	"\x15message/message.proto\x12\amessage\x1a\x1cgoogle/protobuf/struct.proto\"\xba\x02\n" +
+	"\x12SendMessageRequest\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x1b\n" +
+	"\tsender_id\x18\x02 \x01(\x03R\bsenderId\x126\n" +
+	"\tconv_type\x18\x03 \x01(\x0e2\x19.message.ConversationTypeR\bconvType\x12+\n" +
+	"\x04body\x18\x04 \x01(\v2\x17.google.protobuf.StructR\x04body\x12\x1e\n" +
+	"\breply_to\x18\x05 \x01(\tH\x00R\areplyTo\x88\x01\x01\x12\x1a\n" +
+	"\bmentions\x18\x06 \x03(\x03R\bmentions\x12,\n" +
+	"\x0fidempotency_key\x18\a \x01(\tH\x01R\x0eidempotencyKey\x88\x01\x01B\v\n" +
+	"\t_reply_toB\x12\n" +
+	"\x10_idempotency_key\"]\n" +
+	"\x13SendMessageResponse\x12\x15\n" +
+	"\x06msg_id\x18\x01 \x01(\tR\x05msgId\x12\x10\n" +
+	"\x03seq\x18\x02 \x01(\x03R\x03seq\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\x03R\tcreatedAt\"\xee\x01\n" +
+	"\x13PullMessagesRequest\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x1b\n" +
+	"\tsince_seq\x18\x02 \x01(\x03R\bsinceSeq\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x17\n" +
+	"\auser_id\x18\x04 \x01(\x03R\x06userId\x124\n" +
+	"\tdirection\x18\x05 \x01(\x0e2\x16.message.PullDirectionR\tdirection\x12\x1d\n" +
+	"\n" +
+	"before_seq\x18\x06 \x01(\x03R\tbeforeSeq\x12\x1d\n" +
+	"\n" +
+	"around_seq\x18\a \x01(\x03R\taroundSeq\"\xad\x01\n" +
+	"\x14PullMessagesResponse\x12,\n" +
+	"\bmessages\x18\x01 \x03(\v2\x10.message.MessageR\bmessages\x12\x19\n" +
+	"\bhas_more\x18\x02 \x01(\bR\ahasMore\x12&\n" +
+	"\x0fhas_more_before\x18\x03 \x01(\bR\rhasMoreBefore\x12$\n" +
+	"\x0ehas_more_after\x18\x04 \x01(\bR\fhasMoreAfter\"\x85\x03\n" +
+	"\aMessage\x12\x15\n" +
+	"\x06msg_id\x18\x01 \x01(\tR\x05msgId\x12\x17\n" +
+	"\aconv_id\x18\x02 \x01(\x03R\x06convId\x12\x10\n" +
+	"\x03seq\x18\x03 \x01(\x03R\x03seq\x12\x1b\n" +
+	"\tsender_id\x18\x04 \x01(\x03R\bsenderId\x126\n" +
+	"\tconv_type\x18\x05 \x01(\x0e2\x19.message.ConversationTypeR\bconvType\x12+\n" +
+	"\x04body\x18\x06 \x01(\v2\x17.google.protobuf.StructR\x04body\x12\x1e\n" +
+	"\breply_to\x18\a \x01(\tH\x00R\areplyTo\x88\x01\x01\x12\x1a\n" +
+	"\bmentions\x18\b \x03(\x03R\bmentions\x12\x1e\n" +
+	"\n" +
+	"visibility\x18\t \x01(\tR\n" +
+	"visibility\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\x03R\tcreatedAt\x12 \n" +
+	"\tedited_at\x18\v \x01(\x03H\x01R\beditedAt\x88\x01\x01B\v\n" +
+	"\t_reply_toB\f\n" +
+	"\n" +
+	"_edited_at\"J\n" +
+	"\x16GetConversationRequest\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\"T\n" +
+	"\x17GetConversationResponse\x129\n" +
+	"\fconversation\x18\x01 \x01(\v2\x15.message.ConversationR\fconversation\"\xd4\x01\n" +
+	"\fConversation\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12-\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x19.message.ConversationTypeR\x04type\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x19\n" +
+	"\bowner_id\x18\x04 \x01(\x03R\aownerId\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\x03R\tcreatedAt\x125\n" +
+	"\amembers\x18\x06 \x03(\v2\x1b.message.ConversationMemberR\amembers\"\xb3\x01\n" +
+	"\x12ConversationMember\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12-\n" +
+	"\x04role\x18\x02 \x01(\x0e2\x19.message.ConversationRoleR\x04role\x12\x14\n" +
+	"\x05muted\x18\x03 \x01(\bR\x05muted\x12\"\n" +
+	"\rlast_read_seq\x18\x04 \x01(\x03R\vlastReadSeq\x12\x1b\n" +
+	"\tjoined_at\x18\x05 \x01(\x03R\bjoinedAt\"\x9a\x01\n" +
+	"\x19CreateConversationRequest\x12-\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x19.message.ConversationTypeR\x04type\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x19\n" +
+	"\bowner_id\x18\x03 \x01(\x03R\aownerId\x12\x1d\n" +
+	"\n" +
+	"member_ids\x18\x04 \x03(\x03R\tmemberIds\"O\n" +
+	"\x1aCreateConversationResponse\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"X\n" +
+	"$GetOrCreateDirectConversationRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x17\n" +
+	"\apeer_id\x18\x02 \x01(\x03R\x06peerId\"Z\n" +
+	"%GetOrCreateDirectConversationResponse\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x18\n" +
+	"\acreated\x18\x02 \x01(\bR\acreated\"Z\n" +
+	"\x14UpdateReadSeqRequest\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12\x10\n" +
+	"\x03seq\x18\x03 \x01(\x03R\x03seq\"1\n" +
+	"\x15UpdateReadSeqResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x9d\x01\n" +
+	"\x17NotifyNewMessageRequest\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x15\n" +
+	"\x06msg_id\x18\x02 \x01(\tR\x05msgId\x12\x10\n" +
+	"\x03seq\x18\x03 \x01(\x03R\x03seq\x12\x1b\n" +
+	"\tsender_id\x18\x04 \x01(\x03R\bsenderId\x12#\n" +
+	"\rrecipient_ids\x18\x05 \x03(\x03R\frecipientIds\"[\n" +
+	"\x18NotifyNewMessageResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12%\n" +
+	"\x0enotified_count\x18\x02 \x01(\x05R\rnotifiedCount\"\xa9\x01\n" +
+	"\x11AddMembersRequest\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x1f\n" +
+	"\voperator_id\x18\x02 \x01(\x03R\n" +
+	"operatorId\x12\x1d\n" +
+	"\n" +
+	"member_ids\x18\x03 \x03(\x03R\tmemberIds\x122\n" +
+	"\x04role\x18\x04 \x01(\x0e2\x19.message.ConversationRoleH\x00R\x04role\x88\x01\x01B\a\n" +
+	"\x05_role\"C\n" +
+	"\x12AddMembersResponse\x12\x1b\n" +
+	"\tadded_ids\x18\x01 \x03(\x03R\baddedIds\x12\x10\n" +
+	"\x03seq\x18\x02 \x01(\x03R\x03seq\"o\n" +
+	"\x14RemoveMembersRequest\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x1f\n" +
+	"\voperator_id\x18\x02 \x01(\x03R\n" +
+	"operatorId\x12\x1d\n" +
+	"\n" +
+	"member_ids\x18\x03 \x03(\x03R\tmemberIds\"J\n" +
+	"\x15RemoveMembersResponse\x12\x1f\n" +
+	"\vremoved_ids\x18\x01 \x03(\x03R\n" +
+	"removedIds\x12\x10\n" +
+	"\x03seq\x18\x02 \x01(\x03R\x03seq\"\x9b\x01\n" +
+	"\x17UpdateMemberRoleRequest\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x1f\n" +
+	"\voperator_id\x18\x02 \x01(\x03R\n" +
+	"operatorId\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\x03R\x06userId\x12-\n" +
+	"\x04role\x18\x04 \x01(\x0e2\x19.message.ConversationRoleR\x04role\"F\n" +
+	"\x18UpdateMemberRoleResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x10\n" +
+	"\x03seq\x18\x02 \x01(\x03R\x03seq\"L\n" +
+	"\x18LeaveConversationRequest\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\"G\n" +
+	"\x19LeaveConversationResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x10\n" +
+	"\x03seq\x18\x02 \x01(\x03R\x03seq\"p\n" +
+	"\x18TransferOwnershipRequest\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x19\n" +
+	"\bowner_id\x18\x02 \x01(\x03R\aownerId\x12 \n" +
+	"\fnew_owner_id\x18\x03 \x01(\x03R\n" +
+	"newOwnerId\"G\n" +
+	"\x19TransferOwnershipResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x10\n" +
+	"\x03seq\x18\x02 \x01(\x03R\x03seq\"a\n" +
+	"\x18ListConversationsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"\x9b\x01\n" +
+	"\x19ListConversationsResponse\x12B\n" +
+	"\rconversations\x18\x01 \x03(\v2\x1c.message.ConversationSummaryR\rconversations\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\x12\x19\n" +
+	"\bhas_more\x18\x03 \x01(\bR\ahasMore\"\xb5\x03\n" +
+	"\x13ConversationSummary\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12-\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x19.message.ConversationTypeR\x04type\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x19\n" +
+	"\bowner_id\x18\x04 \x01(\x03R\aownerId\x12-\n" +
+	"\x04role\x18\x05 \x01(\x0e2\x19.message.ConversationRoleR\x04role\x12\x14\n" +
+	"\x05muted\x18\x06 \x01(\bR\x05muted\x12\x1f\n" +
+	"\vcurrent_seq\x18\a \x01(\x03R\n" +
+	"currentSeq\x12\"\n" +
+	"\rlast_read_seq\x18\b \x01(\x03R\vlastReadSeq\x12!\n" +
+	"\funread_count\x18\t \x01(\x03R\vunreadCount\x123\n" +
+	"\flast_message\x18\n" +
+	" \x01(\v2\x10.message.MessageR\vlastMessage\x12$\n" +
+	"\x0elast_active_at\x18\v \x01(\x03R\flastActiveAt\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\f \x01(\x03R\tcreatedAt\"g\n" +
+	"\x14RecallMessageRequest\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x15\n" +
+	"\x06msg_id\x18\x02 \x01(\tR\x05msgId\x12\x1f\n" +
+	"\voperator_id\x18\x03 \x01(\x03R\n" +
+	"operatorId\"C\n" +
+	"\x15RecallMessageResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x10\n" +
+	"\x03seq\x18\x02 \x01(\x03R\x03seq\"\x92\x01\n" +
+	"\x12EditMessageRequest\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x15\n" +
+	"\x06msg_id\x18\x02 \x01(\tR\x05msgId\x12\x1f\n" +
+	"\voperator_id\x18\x03 \x01(\x03R\n" +
+	"operatorId\x12+\n" +
+	"\x04body\x18\x04 \x01(\v2\x17.google.protobuf.StructR\x04body\"^\n" +
+	"\x13EditMessageResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x10\n" +
+	"\x03seq\x18\x02 \x01(\x03R\x03seq\x12\x1b\n" +
+	"\tedited_at\x18\x03 \x01(\x03R\beditedAt\"H\n" +
+	"\x12FilterPeersRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x19\n" +
+	"\buser_ids\x18\x02 \x03(\x03R\auserIds\"0\n" +
+	"\x13FilterPeersResponse\x12\x19\n" +
+	"\buser_ids\x18\x01 \x03(\x03R\auserIds*6\n" +
+	"\x10ConversationType\x12\n" +
+	"\n" +
+	"\x06DIRECT\x10\x00\x12\t\n" +
+	"\x05GROUP\x10\x01\x12\v\n" +
+	"\aCHANNEL\x10\x02*O\n" +
+	"\x10ConversationRole\x12\t\n" +
+	"\x05OWNER\x10\x00\x12\t\n" +
+	"\x05ADMIN\x10\x01\x12\r\n" +
+	"\tPUBLISHER\x10\x02\x12\n" +
+	"\n" +
+	"\x06MEMBER\x10\x03\x12\n" +
+	"\n" +
+	"\x06VIEWER\x10\x04*6\n" +
+	"\rPullDirection\x12\v\n" +
+	"\aFORWARD\x10\x00\x12\f\n" +
+	"\bBACKWARD\x10\x01\x12\n" +
+	"\n" +
+	"\x06AROUND\x10\x022\xed\n" +
+	"\n" +
 	"\x0eMessageService\x12H\n" +
 	"\vSendMessage\x12\x1b.message.SendMessageRequest\x1a\x1c.message.SendMessageResponse\x12K\n" +
 	"\fPullMessages\x12\x1c.message.PullMessagesRequest\x1a\x1d.message.PullMessagesResponse\x12T\n" +
 	"\x0fGetConversation\x12\x1f.message.GetConversationRequest\x1a .message.GetConversationResponse\x12]\n" +
-	"\x12CreateConversation\x12\".message.CreateConversationRequest\x1a#.message.CreateConversationResponse\x12N\n" +
+	"\x12CreateConversation\x12\".message.CreateConversationRequest\x1a#.message.CreateConversationResponse\x12~\n" +
+	"\x1dGetOrCreateDirectConversation\x12-.message.GetOrCreateDirectConversationRequest\x1a..message.GetOrCreateDirectConversationResponse\x12N\n" +
 	"\rUpdateReadSeq\x12\x1d.message.UpdateReadSeqRequest\x1a\x1e.message.UpdateReadSeqResponse\x12W\n" +
-	"\x10NotifyNewMessage\x12 .message.NotifyNewMessageRequest\x1a!.message.NotifyNewMessageResponseB@Z>github.com/dollarkillerx/im-system/api/proto/message;messagepbb\x06proto3"
+	"\x10NotifyNewMessage\x12 .message.NotifyNewMessageRequest\x1a!.message.NotifyNewMessageResponse\x12E\n" +
+	"\n" +
+	"AddMembers\x12\x1a.message.AddMembersRequest\x1a\x1b.message.AddMembersResponse\x12N\n" +
+	"\rRemoveMembers\x12\x1d.message.RemoveMembersRequest\x1a\x1e.message.RemoveMembersResponse\x12W\n" +
+	"\x10UpdateMemberRole\x12 .message.UpdateMemberRoleRequest\x1a!.message.UpdateMemberRoleResponse\x12Z\n" +
+	"\x11LeaveConversation\x12!.message.LeaveConversationRequest\x1a\".message.LeaveConversationResponse\x12Z\n" +
+	"\x11TransferOwnership\x12!.message.TransferOwnershipRequest\x1a\".message.TransferOwnershipResponse\x12Z\n" +
+	"\x11ListConversations\x12!.message.ListConversationsRequest\x1a\".message.ListConversationsResponse\x12N\n" +
+	"\rRecallMessage\x12\x1d.message.RecallMessageRequest\x1a\x1e.message.RecallMessageResponse\x12H\n" +
+	"\vEditMessage\x12\x1b.message.EditMessageRequest\x1a\x1c.message.EditMessageResponse\x12H\n" +
+	"\vFilterPeers\x12\x1b.message.FilterPeersRequest\x1a\x1c.message.FilterPeersResponseB@Z>github.com/dollarkillerx/im-system/api/proto/message;messagepbb\x06proto3"
 
 var (
 	file_message_message_proto_rawDescOnce sync.Once
@@ -1214,56 +2802,106 @@ func file_message_message_proto_rawDescGZIP() []byte {
 	return file_message_message_proto_rawDescData
 }
 
-var file_message_message_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_message_message_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_message_message_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_message_message_proto_msgTypes = make([]protoimpl.MessageInfo, 36)
 var file_message_message_proto_goTypes = []any{
-	(ConversationType)(0),              // 0: message.ConversationType
-	(ConversationRole)(0),              // 1: message.ConversationRole
-	(*SendMessageRequest)(nil),         // 2: message.SendMessageRequest
-	(*SendMessageResponse)(nil),        // 3: message.SendMessageResponse
-	(*PullMessagesRequest)(nil),        // 4: message.PullMessagesRequest
-	(*PullMessagesResponse)(nil),       // 5: message.PullMessagesResponse
-	(*Message)(nil),                    // 6: message.Message
-	(*GetConversationRequest)(nil),     // 7: message.GetConversationRequest
-	(*GetConversationResponse)(nil),    // 8: message.GetConversationResponse
-	(*Conversation)(nil),               // 9: message.Conversation
-	(*ConversationMember)(nil),         // 10: message.ConversationMember
-	(*CreateConversationRequest)(nil),  // 11: message.CreateConversationRequest
-	(*CreateConversationResponse)(nil), // 12: message.CreateConversationResponse
-	(*UpdateReadSeqRequest)(nil),       // 13: message.UpdateReadSeqRequest
-	(*UpdateReadSeqResponse)(nil),      // 14: message.UpdateReadSeqResponse
-	(*NotifyNewMessageRequest)(nil),    // 15: message.NotifyNewMessageRequest
-	(*NotifyNewMessageResponse)(nil),   // 16: message.NotifyNewMessageResponse
-	(*structpb.Struct)(nil),            // 17: google.protobuf.Struct
+	(ConversationType)(0),                         // 0: message.ConversationType
+	(ConversationRole)(0),                         // 1: message.ConversationRole
+	(PullDirection)(0),                            // 2: message.PullDirection
+	(*SendMessageRequest)(nil),                    // 3: message.SendMessageRequest
+	(*SendMessageResponse)(nil),                   // 4: message.SendMessageResponse
+	(*PullMessagesRequest)(nil),                   // 5: message.PullMessagesRequest
+	(*PullMessagesResponse)(nil),                  // 6: message.PullMessagesResponse
+	(*Message)(nil),                               // 7: message.Message
+	(*GetConversationRequest)(nil),                // 8: message.GetConversationRequest
+	(*GetConversationResponse)(nil),               // 9: message.GetConversationResponse
+	(*Conversation)(nil),                          // 10: message.Conversation
+	(*ConversationMember)(nil),                    // 11: message.ConversationMember
+	(*CreateConversationRequest)(nil),             // 12: message.CreateConversationRequest
+	(*CreateConversationResponse)(nil),            // 13: message.CreateConversationResponse
+	(*GetOrCreateDirectConversationRequest)(nil),  // 14: message.GetOrCreateDirectConversationRequest
+	(*GetOrCreateDirectConversationResponse)(nil), // 15: message.GetOrCreateDirectConversationResponse
+	(*UpdateReadSeqRequest)(nil),                  // 16: message.UpdateReadSeqRequest
+	(*UpdateReadSeqResponse)(nil),                 // 17: message.UpdateReadSeqResponse
+	(*NotifyNewMessageRequest)(nil),               // 18: message.NotifyNewMessageRequest
+	(*NotifyNewMessageResponse)(nil),              // 19: message.NotifyNewMessageResponse
+	(*AddMembersRequest)(nil),                     // 20: message.AddMembersRequest
+	(*AddMembersResponse)(nil),                    // 21: message.AddMembersResponse
+	(*RemoveMembersRequest)(nil),                  // 22: message.RemoveMembersRequest
+	(*RemoveMembersResponse)(nil),                 // 23: message.RemoveMembersResponse
+	(*UpdateMemberRoleRequest)(nil),               // 24: message.UpdateMemberRoleRequest
+	(*UpdateMemberRoleResponse)(nil),              // 25: message.UpdateMemberRoleResponse
+	(*LeaveConversationRequest)(nil),              // 26: message.LeaveConversationRequest
+	(*LeaveConversationResponse)(nil),             // 27: message.LeaveConversationResponse
+	(*TransferOwnershipRequest)(nil),              // 28: message.TransferOwnershipRequest
+	(*TransferOwnershipResponse)(nil),             // 29: message.TransferOwnershipResponse
+	(*ListConversationsRequest)(nil),              // 30: message.ListConversationsRequest
+	(*ListConversationsResponse)(nil),             // 31: message.ListConversationsResponse
+	(*ConversationSummary)(nil),                   // 32: message.ConversationSummary
+	(*RecallMessageRequest)(nil),                  // 33: message.RecallMessageRequest
+	(*RecallMessageResponse)(nil),                 // 34: message.RecallMessageResponse
+	(*EditMessageRequest)(nil),                    // 35: message.EditMessageRequest
+	(*EditMessageResponse)(nil),                   // 36: message.EditMessageResponse
+	(*FilterPeersRequest)(nil),                    // 37: message.FilterPeersRequest
+	(*FilterPeersResponse)(nil),                   // 38: message.FilterPeersResponse
+	(*structpb.Struct)(nil),                       // 39: google.protobuf.Struct
 }
 var file_message_message_proto_depIdxs = []int32{
 	0,  // 0: message.SendMessageRequest.conv_type:type_name -> message.ConversationType
-	17, // 1: message.SendMessageRequest.body:type_name -> google.protobuf.Struct
-	6,  // 2: message.PullMessagesResponse.messages:type_name -> message.Message
-	0,  // 3: message.Message.conv_type:type_name -> message.ConversationType
-	17, // 4: message.Message.body:type_name -> google.protobuf.Struct
-	9,  // 5: message.GetConversationResponse.conversation:type_name -> message.Conversation
-	0,  // 6: message.Conversation.type:type_name -> message.ConversationType
-	10, // 7: message.Conversation.members:type_name -> message.ConversationMember
-	1,  // 8: message.ConversationMember.role:type_name -> message.ConversationRole
-	0,  // 9: message.CreateConversationRequest.type:type_name -> message.ConversationType
-	2,  // 10: message.MessageService.SendMessage:input_type -> message.SendMessageRequest
-	4,  // 11: message.MessageService.PullMessages:input_type -> message.PullMessagesRequest
-	7,  // 12: message.MessageService.GetConversation:input_type -> message.GetConversationRequest
-	11, // 13: message.MessageService.CreateConversation:input_type -> message.CreateConversationRequest
-	13, // 14: message.MessageService.UpdateReadSeq:input_type -> message.UpdateReadSeqRequest
-	15, // 15: message.MessageService.NotifyNewMessage:input_type -> message.NotifyNewMessageRequest
-	3,  // 16: message.MessageService.SendMessage:output_type -> message.SendMessageResponse
-	5,  // 17: message.MessageService.PullMessages:output_type -> message.PullMessagesResponse
-	8,  // 18: message.MessageService.GetConversation:output_type -> message.GetConversationResponse
-	12, // 19: message.MessageService.CreateConversation:output_type -> message.CreateConversationResponse
-	14, // 20: message.MessageService.UpdateReadSeq:output_type -> message.UpdateReadSeqResponse
-	16, // 21: message.MessageService.NotifyNewMessage:output_type -> message.NotifyNewMessageResponse
-	16, // [16:22] is the sub-list for method output_type
-	10, // [10:16] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	39, // 1: message.SendMessageRequest.body:type_name -> google.protobuf.Struct
+	2,  // 2: message.PullMessagesRequest.direction:type_name -> message.PullDirection
+	7,  // 3: message.PullMessagesResponse.messages:type_name -> message.Message
+	0,  // 4: message.Message.conv_type:type_name -> message.ConversationType
+	39, // 5: message.Message.body:type_name -> google.protobuf.Struct
+	10, // 6: message.GetConversationResponse.conversation:type_name -> message.Conversation
+	0,  // 7: message.Conversation.type:type_name -> message.ConversationType
+	11, // 8: message.Conversation.members:type_name -> message.ConversationMember
+	1,  // 9: message.ConversationMember.role:type_name -> message.ConversationRole
+	0,  // 10: message.CreateConversationRequest.type:type_name -> message.ConversationType
+	1,  // 11: message.AddMembersRequest.role:type_name -> message.ConversationRole
+	1,  // 12: message.UpdateMemberRoleRequest.role:type_name -> message.ConversationRole
+	32, // 13: message.ListConversationsResponse.conversations:type_name -> message.ConversationSummary
+	0,  // 14: message.ConversationSummary.type:type_name -> message.ConversationType
+	1,  // 15: message.ConversationSummary.role:type_name -> message.ConversationRole
+	7,  // 16: message.ConversationSummary.last_message:type_name -> message.Message
+	39, // 17: message.EditMessageRequest.body:type_name -> google.protobuf.Struct
+	3,  // 18: message.MessageService.SendMessage:input_type -> message.SendMessageRequest
+	5,  // 19: message.MessageService.PullMessages:input_type -> message.PullMessagesRequest
+	8,  // 20: message.MessageService.GetConversation:input_type -> message.GetConversationRequest
+	12, // 21: message.MessageService.CreateConversation:input_type -> message.CreateConversationRequest
+	14, // 22: message.MessageService.GetOrCreateDirectConversation:input_type -> message.GetOrCreateDirectConversationRequest
+	16, // 23: message.MessageService.UpdateReadSeq:input_type -> message.UpdateReadSeqRequest
+	18, // 24: message.MessageService.NotifyNewMessage:input_type -> message.NotifyNewMessageRequest
+	20, // 25: message.MessageService.AddMembers:input_type -> message.AddMembersRequest
+	22, // 26: message.MessageService.RemoveMembers:input_type -> message.RemoveMembersRequest
+	24, // 27: message.MessageService.UpdateMemberRole:input_type -> message.UpdateMemberRoleRequest
+	26, // 28: message.MessageService.LeaveConversation:input_type -> message.LeaveConversationRequest
+	28, // 29: message.MessageService.TransferOwnership:input_type -> message.TransferOwnershipRequest
+	30, // 30: message.MessageService.ListConversations:input_type -> message.ListConversationsRequest
+	33, // 31: message.MessageService.RecallMessage:input_type -> message.RecallMessageRequest
+	35, // 32: message.MessageService.EditMessage:input_type -> message.EditMessageRequest
+	37, // 33: message.MessageService.FilterPeers:input_type -> message.FilterPeersRequest
+	4,  // 34: message.MessageService.SendMessage:output_type -> message.SendMessageResponse
+	6,  // 35: message.MessageService.PullMessages:output_type -> message.PullMessagesResponse
+	9,  // 36: message.MessageService.GetConversation:output_type -> message.GetConversationResponse
+	13, // 37: message.MessageService.CreateConversation:output_type -> message.CreateConversationResponse
+	15, // 38: message.MessageService.GetOrCreateDirectConversation:output_type -> message.GetOrCreateDirectConversationResponse
+	17, // 39: message.MessageService.UpdateReadSeq:output_type -> message.UpdateReadSeqResponse
+	19, // 40: message.MessageService.NotifyNewMessage:output_type -> message.NotifyNewMessageResponse
+	21, // 41: message.MessageService.AddMembers:output_type -> message.AddMembersResponse
+	23, // 42: message.MessageService.RemoveMembers:output_type -> message.RemoveMembersResponse
+	25, // 43: message.MessageService.UpdateMemberRole:output_type -> message.UpdateMemberRoleResponse
+	27, // 44: message.MessageService.LeaveConversation:output_type -> message.LeaveConversationResponse
+	29, // 45: message.MessageService.TransferOwnership:output_type -> message.TransferOwnershipResponse
+	31, // 46: message.MessageService.ListConversations:output_type -> message.ListConversationsResponse
+	34, // 47: message.MessageService.RecallMessage:output_type -> message.RecallMessageResponse
+	36, // 48: message.MessageService.EditMessage:output_type -> message.EditMessageResponse
+	38, // 49: message.MessageService.FilterPeers:output_type -> message.FilterPeersResponse
+	34, // [34:50] is the sub-list for method output_type
+	18, // [18:34] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
 }
 
 func init() { file_message_message_proto_init() }
@@ -1273,13 +2911,14 @@ func file_message_message_proto_init() {
 	}
 	file_message_message_proto_msgTypes[0].OneofWrappers = []any{}
 	file_message_message_proto_msgTypes[4].OneofWrappers = []any{}
+	file_message_message_proto_msgTypes[17].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_message_message_proto_rawDesc), len(file_message_message_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   15,
+			NumEnums:      3,
+			NumMessages:   36,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
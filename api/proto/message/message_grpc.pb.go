@@ -19,24 +19,63 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	MessageService_SendMessage_FullMethodName        = "/message.MessageService/SendMessage"
-	MessageService_PullMessages_FullMethodName       = "/message.MessageService/PullMessages"
-	MessageService_GetConversation_FullMethodName    = "/message.MessageService/GetConversation"
-	MessageService_CreateConversation_FullMethodName = "/message.MessageService/CreateConversation"
-	MessageService_UpdateReadSeq_FullMethodName      = "/message.MessageService/UpdateReadSeq"
-	MessageService_NotifyNewMessage_FullMethodName   = "/message.MessageService/NotifyNewMessage"
+	MessageService_SendMessage_FullMethodName                   = "/message.MessageService/SendMessage"
+	MessageService_PullMessages_FullMethodName                  = "/message.MessageService/PullMessages"
+	MessageService_GetConversation_FullMethodName               = "/message.MessageService/GetConversation"
+	MessageService_CreateConversation_FullMethodName            = "/message.MessageService/CreateConversation"
+	MessageService_GetOrCreateDirectConversation_FullMethodName = "/message.MessageService/GetOrCreateDirectConversation"
+	MessageService_UpdateReadSeq_FullMethodName                 = "/message.MessageService/UpdateReadSeq"
+	MessageService_NotifyNewMessage_FullMethodName              = "/message.MessageService/NotifyNewMessage"
+	MessageService_AddMembers_FullMethodName                    = "/message.MessageService/AddMembers"
+	MessageService_RemoveMembers_FullMethodName                 = "/message.MessageService/RemoveMembers"
+	MessageService_UpdateMemberRole_FullMethodName              = "/message.MessageService/UpdateMemberRole"
+	MessageService_LeaveConversation_FullMethodName             = "/message.MessageService/LeaveConversation"
+	MessageService_TransferOwnership_FullMethodName             = "/message.MessageService/TransferOwnership"
+	MessageService_ListConversations_FullMethodName             = "/message.MessageService/ListConversations"
+	MessageService_RecallMessage_FullMethodName                 = "/message.MessageService/RecallMessage"
+	MessageService_EditMessage_FullMethodName                   = "/message.MessageService/EditMessage"
+	MessageService_FilterPeers_FullMethodName                   = "/message.MessageService/FilterPeers"
 )
 
 // MessageServiceClient is the client API for MessageService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// MessageService 消息服务
+// Message service for sending, receiving, and managing messages
 type MessageServiceClient interface {
+	// SendMessage 发送消息 / Send a message
 	SendMessage(ctx context.Context, in *SendMessageRequest, opts ...grpc.CallOption) (*SendMessageResponse, error)
+	// PullMessages 拉取消息 / Pull messages from a conversation
 	PullMessages(ctx context.Context, in *PullMessagesRequest, opts ...grpc.CallOption) (*PullMessagesResponse, error)
+	// GetConversation 获取会话信息 / Get conversation information
 	GetConversation(ctx context.Context, in *GetConversationRequest, opts ...grpc.CallOption) (*GetConversationResponse, error)
+	// CreateConversation 创建会话 / Create a new conversation
 	CreateConversation(ctx context.Context, in *CreateConversationRequest, opts ...grpc.CallOption) (*CreateConversationResponse, error)
+	// GetOrCreateDirectConversation 获取或创建单聊会话 (同一对用户只有一个单聊) / Get or create the direct conversation between two users (one per pair)
+	GetOrCreateDirectConversation(ctx context.Context, in *GetOrCreateDirectConversationRequest, opts ...grpc.CallOption) (*GetOrCreateDirectConversationResponse, error)
+	// UpdateReadSeq 更新已读序列号 / Update read sequence number
 	UpdateReadSeq(ctx context.Context, in *UpdateReadSeqRequest, opts ...grpc.CallOption) (*UpdateReadSeqResponse, error)
+	// NotifyNewMessage 通知新消息 (内部调用) / Notify new message (internal call)
 	NotifyNewMessage(ctx context.Context, in *NotifyNewMessageRequest, opts ...grpc.CallOption) (*NotifyNewMessageResponse, error)
+	// AddMembers 添加会话成员 / Add conversation members
+	AddMembers(ctx context.Context, in *AddMembersRequest, opts ...grpc.CallOption) (*AddMembersResponse, error)
+	// RemoveMembers 移除会话成员 / Remove conversation members
+	RemoveMembers(ctx context.Context, in *RemoveMembersRequest, opts ...grpc.CallOption) (*RemoveMembersResponse, error)
+	// UpdateMemberRole 修改成员角色 / Change a member's role
+	UpdateMemberRole(ctx context.Context, in *UpdateMemberRoleRequest, opts ...grpc.CallOption) (*UpdateMemberRoleResponse, error)
+	// LeaveConversation 退出会话 / Leave a conversation
+	LeaveConversation(ctx context.Context, in *LeaveConversationRequest, opts ...grpc.CallOption) (*LeaveConversationResponse, error)
+	// TransferOwnership 转让会话所有权 / Transfer conversation ownership
+	TransferOwnership(ctx context.Context, in *TransferOwnershipRequest, opts ...grpc.CallOption) (*TransferOwnershipResponse, error)
+	// ListConversations 获取用户的会话列表 (按最后消息时间倒序) / List the user's conversations ordered by last message time
+	ListConversations(ctx context.Context, in *ListConversationsRequest, opts ...grpc.CallOption) (*ListConversationsResponse, error)
+	// RecallMessage 撤回消息 (发送者限时撤回，管理员可随时撤回) / Recall a message (sender within the recall window, admins at any time)
+	RecallMessage(ctx context.Context, in *RecallMessageRequest, opts ...grpc.CallOption) (*RecallMessageResponse, error)
+	// EditMessage 编辑消息 (仅发送者) / Edit a message (sender only)
+	EditMessage(ctx context.Context, in *EditMessageRequest, opts ...grpc.CallOption) (*EditMessageResponse, error)
+	// FilterPeers 筛选出与用户至少共同在一个会话中的用户 / Keep only the users who share at least one conversation with the user
+	FilterPeers(ctx context.Context, in *FilterPeersRequest, opts ...grpc.CallOption) (*FilterPeersResponse, error)
 }
 
 type messageServiceClient struct {
@@ -87,6 +126,16 @@ func (c *messageServiceClient) CreateConversation(ctx context.Context, in *Creat
 	return out, nil
 }
 
+func (c *messageServiceClient) GetOrCreateDirectConversation(ctx context.Context, in *GetOrCreateDirectConversationRequest, opts ...grpc.CallOption) (*GetOrCreateDirectConversationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetOrCreateDirectConversationResponse)
+	err := c.cc.Invoke(ctx, MessageService_GetOrCreateDirectConversation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *messageServiceClient) UpdateReadSeq(ctx context.Context, in *UpdateReadSeqRequest, opts ...grpc.CallOption) (*UpdateReadSeqResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(UpdateReadSeqResponse)
@@ -107,16 +156,135 @@ func (c *messageServiceClient) NotifyNewMessage(ctx context.Context, in *NotifyN
 	return out, nil
 }
 
+func (c *messageServiceClient) AddMembers(ctx context.Context, in *AddMembersRequest, opts ...grpc.CallOption) (*AddMembersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddMembersResponse)
+	err := c.cc.Invoke(ctx, MessageService_AddMembers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) RemoveMembers(ctx context.Context, in *RemoveMembersRequest, opts ...grpc.CallOption) (*RemoveMembersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveMembersResponse)
+	err := c.cc.Invoke(ctx, MessageService_RemoveMembers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) UpdateMemberRole(ctx context.Context, in *UpdateMemberRoleRequest, opts ...grpc.CallOption) (*UpdateMemberRoleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateMemberRoleResponse)
+	err := c.cc.Invoke(ctx, MessageService_UpdateMemberRole_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) LeaveConversation(ctx context.Context, in *LeaveConversationRequest, opts ...grpc.CallOption) (*LeaveConversationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LeaveConversationResponse)
+	err := c.cc.Invoke(ctx, MessageService_LeaveConversation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) TransferOwnership(ctx context.Context, in *TransferOwnershipRequest, opts ...grpc.CallOption) (*TransferOwnershipResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransferOwnershipResponse)
+	err := c.cc.Invoke(ctx, MessageService_TransferOwnership_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) ListConversations(ctx context.Context, in *ListConversationsRequest, opts ...grpc.CallOption) (*ListConversationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListConversationsResponse)
+	err := c.cc.Invoke(ctx, MessageService_ListConversations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) RecallMessage(ctx context.Context, in *RecallMessageRequest, opts ...grpc.CallOption) (*RecallMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecallMessageResponse)
+	err := c.cc.Invoke(ctx, MessageService_RecallMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) EditMessage(ctx context.Context, in *EditMessageRequest, opts ...grpc.CallOption) (*EditMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EditMessageResponse)
+	err := c.cc.Invoke(ctx, MessageService_EditMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) FilterPeers(ctx context.Context, in *FilterPeersRequest, opts ...grpc.CallOption) (*FilterPeersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FilterPeersResponse)
+	err := c.cc.Invoke(ctx, MessageService_FilterPeers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MessageServiceServer is the server API for MessageService service.
 // All implementations must embed UnimplementedMessageServiceServer
 // for forward compatibility.
+//
+// MessageService 消息服务
+// Message service for sending, receiving, and managing messages
 type MessageServiceServer interface {
+	// SendMessage 发送消息 / Send a message
 	SendMessage(context.Context, *SendMessageRequest) (*SendMessageResponse, error)
+	// PullMessages 拉取消息 / Pull messages from a conversation
 	PullMessages(context.Context, *PullMessagesRequest) (*PullMessagesResponse, error)
+	// GetConversation 获取会话信息 / Get conversation information
 	GetConversation(context.Context, *GetConversationRequest) (*GetConversationResponse, error)
+	// CreateConversation 创建会话 / Create a new conversation
 	CreateConversation(context.Context, *CreateConversationRequest) (*CreateConversationResponse, error)
+	// GetOrCreateDirectConversation 获取或创建单聊会话 (同一对用户只有一个单聊) / Get or create the direct conversation between two users (one per pair)
+	GetOrCreateDirectConversation(context.Context, *GetOrCreateDirectConversationRequest) (*GetOrCreateDirectConversationResponse, error)
+	// UpdateReadSeq 更新已读序列号 / Update read sequence number
 	UpdateReadSeq(context.Context, *UpdateReadSeqRequest) (*UpdateReadSeqResponse, error)
+	// NotifyNewMessage 通知新消息 (内部调用) / Notify new message (internal call)
 	NotifyNewMessage(context.Context, *NotifyNewMessageRequest) (*NotifyNewMessageResponse, error)
+	// AddMembers 添加会话成员 / Add conversation members
+	AddMembers(context.Context, *AddMembersRequest) (*AddMembersResponse, error)
+	// RemoveMembers 移除会话成员 / Remove conversation members
+	RemoveMembers(context.Context, *RemoveMembersRequest) (*RemoveMembersResponse, error)
+	// UpdateMemberRole 修改成员角色 / Change a member's role
+	UpdateMemberRole(context.Context, *UpdateMemberRoleRequest) (*UpdateMemberRoleResponse, error)
+	// LeaveConversation 退出会话 / Leave a conversation
+	LeaveConversation(context.Context, *LeaveConversationRequest) (*LeaveConversationResponse, error)
+	// TransferOwnership 转让会话所有权 / Transfer conversation ownership
+	TransferOwnership(context.Context, *TransferOwnershipRequest) (*TransferOwnershipResponse, error)
+	// ListConversations 获取用户的会话列表 (按最后消息时间倒序) / List the user's conversations ordered by last message time
+	ListConversations(context.Context, *ListConversationsRequest) (*ListConversationsResponse, error)
+	// RecallMessage 撤回消息 (发送者限时撤回，管理员可随时撤回) / Recall a message (sender within the recall window, admins at any time)
+	RecallMessage(context.Context, *RecallMessageRequest) (*RecallMessageResponse, error)
+	// EditMessage 编辑消息 (仅发送者) / Edit a message (sender only)
+	EditMessage(context.Context, *EditMessageRequest) (*EditMessageResponse, error)
+	// FilterPeers 筛选出与用户至少共同在一个会话中的用户 / Keep only the users who share at least one conversation with the user
+	FilterPeers(context.Context, *FilterPeersRequest) (*FilterPeersResponse, error)
 	mustEmbedUnimplementedMessageServiceServer()
 }
 
@@ -139,12 +307,42 @@ func (UnimplementedMessageServiceServer) GetConversation(context.Context, *GetCo
 func (UnimplementedMessageServiceServer) CreateConversation(context.Context, *CreateConversationRequest) (*CreateConversationResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateConversation not implemented")
 }
+func (UnimplementedMessageServiceServer) GetOrCreateDirectConversation(context.Context, *GetOrCreateDirectConversationRequest) (*GetOrCreateDirectConversationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrCreateDirectConversation not implemented")
+}
 func (UnimplementedMessageServiceServer) UpdateReadSeq(context.Context, *UpdateReadSeqRequest) (*UpdateReadSeqResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateReadSeq not implemented")
 }
 func (UnimplementedMessageServiceServer) NotifyNewMessage(context.Context, *NotifyNewMessageRequest) (*NotifyNewMessageResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method NotifyNewMessage not implemented")
 }
+func (UnimplementedMessageServiceServer) AddMembers(context.Context, *AddMembersRequest) (*AddMembersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddMembers not implemented")
+}
+func (UnimplementedMessageServiceServer) RemoveMembers(context.Context, *RemoveMembersRequest) (*RemoveMembersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveMembers not implemented")
+}
+func (UnimplementedMessageServiceServer) UpdateMemberRole(context.Context, *UpdateMemberRoleRequest) (*UpdateMemberRoleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateMemberRole not implemented")
+}
+func (UnimplementedMessageServiceServer) LeaveConversation(context.Context, *LeaveConversationRequest) (*LeaveConversationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LeaveConversation not implemented")
+}
+func (UnimplementedMessageServiceServer) TransferOwnership(context.Context, *TransferOwnershipRequest) (*TransferOwnershipResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TransferOwnership not implemented")
+}
+func (UnimplementedMessageServiceServer) ListConversations(context.Context, *ListConversationsRequest) (*ListConversationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListConversations not implemented")
+}
+func (UnimplementedMessageServiceServer) RecallMessage(context.Context, *RecallMessageRequest) (*RecallMessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecallMessage not implemented")
+}
+func (UnimplementedMessageServiceServer) EditMessage(context.Context, *EditMessageRequest) (*EditMessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EditMessage not implemented")
+}
+func (UnimplementedMessageServiceServer) FilterPeers(context.Context, *FilterPeersRequest) (*FilterPeersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FilterPeers not implemented")
+}
 func (UnimplementedMessageServiceServer) mustEmbedUnimplementedMessageServiceServer() {}
 func (UnimplementedMessageServiceServer) testEmbeddedByValue()                        {}
 
@@ -238,6 +436,24 @@ func _MessageService_CreateConversation_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MessageService_GetOrCreateDirectConversation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrCreateDirectConversationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).GetOrCreateDirectConversation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_GetOrCreateDirectConversation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).GetOrCreateDirectConversation(ctx, req.(*GetOrCreateDirectConversationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _MessageService_UpdateReadSeq_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UpdateReadSeqRequest)
 	if err := dec(in); err != nil {
@@ -274,6 +490,168 @@ func _MessageService_NotifyNewMessage_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MessageService_AddMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).AddMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_AddMembers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).AddMembers(ctx, req.(*AddMembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_RemoveMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).RemoveMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_RemoveMembers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).RemoveMembers(ctx, req.(*RemoveMembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_UpdateMemberRole_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateMemberRoleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).UpdateMemberRole(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_UpdateMemberRole_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).UpdateMemberRole(ctx, req.(*UpdateMemberRoleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_LeaveConversation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaveConversationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).LeaveConversation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_LeaveConversation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).LeaveConversation(ctx, req.(*LeaveConversationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_TransferOwnership_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransferOwnershipRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).TransferOwnership(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_TransferOwnership_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).TransferOwnership(ctx, req.(*TransferOwnershipRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_ListConversations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListConversationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).ListConversations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_ListConversations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).ListConversations(ctx, req.(*ListConversationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_RecallMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecallMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).RecallMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_RecallMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).RecallMessage(ctx, req.(*RecallMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_EditMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EditMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).EditMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_EditMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).EditMessage(ctx, req.(*EditMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_FilterPeers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FilterPeersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).FilterPeers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_FilterPeers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).FilterPeers(ctx, req.(*FilterPeersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MessageService_ServiceDesc is the grpc.ServiceDesc for MessageService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -297,6 +675,10 @@ var MessageService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateConversation",
 			Handler:    _MessageService_CreateConversation_Handler,
 		},
+		{
+			MethodName: "GetOrCreateDirectConversation",
+			Handler:    _MessageService_GetOrCreateDirectConversation_Handler,
+		},
 		{
 			MethodName: "UpdateReadSeq",
 			Handler:    _MessageService_UpdateReadSeq_Handler,
@@ -305,6 +687,42 @@ var MessageService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "NotifyNewMessage",
 			Handler:    _MessageService_NotifyNewMessage_Handler,
 		},
+		{
+			MethodName: "AddMembers",
+			Handler:    _MessageService_AddMembers_Handler,
+		},
+		{
+			MethodName: "RemoveMembers",
+			Handler:    _MessageService_RemoveMembers_Handler,
+		},
+		{
+			MethodName: "UpdateMemberRole",
+			Handler:    _MessageService_UpdateMemberRole_Handler,
+		},
+		{
+			MethodName: "LeaveConversation",
+			Handler:    _MessageService_LeaveConversation_Handler,
+		},
+		{
+			MethodName: "TransferOwnership",
+			Handler:    _MessageService_TransferOwnership_Handler,
+		},
+		{
+			MethodName: "ListConversations",
+			Handler:    _MessageService_ListConversations_Handler,
+		},
+		{
+			MethodName: "RecallMessage",
+			Handler:    _MessageService_RecallMessage_Handler,
+		},
+		{
+			MethodName: "EditMessage",
+			Handler:    _MessageService_EditMessage_Handler,
+		},
+		{
+			MethodName: "FilterPeers",
+			Handler:    _MessageService_FilterPeers_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "message/message.proto",
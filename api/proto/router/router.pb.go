@@ -7,6 +7,7 @@
 package routerpb
 
 import (
+	gateway "github.com/dollarkillerx/im-system/api/proto/gateway"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -21,11 +22,14 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// RegisterRouteRequest 注册路由请求
+// Register route request
 type RegisterRouteRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
-	GatewayAddr   string                 `protobuf:"bytes,3,opt,name=gateway_addr,json=gatewayAddr,proto3" json:"gateway_addr,omitempty"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`               // 用户ID / User ID
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`          // 设备ID / Device ID
+	GatewayAddr   string                 `protobuf:"bytes,3,opt,name=gateway_addr,json=gatewayAddr,proto3" json:"gateway_addr,omitempty"` // Gateway服务器地址 / Gateway server address
+	Platform      string                 `protobuf:"bytes,4,opt,name=platform,proto3" json:"platform,omitempty"`                          // 平台类型 (如 mobile/desktop/web)，用于多设备策略 / Platform class (e.g. mobile/desktop/web) used by the device policy
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -81,12 +85,22 @@ func (x *RegisterRouteRequest) GetGatewayAddr() string {
 	return ""
 }
 
+func (x *RegisterRouteRequest) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+// RegisterRouteResponse 注册路由响应
+// Register route response
 type RegisterRouteResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Success          bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`                                            // 是否成功 / Success status
+	Message          string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`                                             // 响应消息 / Response message
+	EvictedDeviceIds []string               `protobuf:"bytes,3,rep,name=evicted_device_ids,json=evictedDeviceIds,proto3" json:"evicted_device_ids,omitempty"` // 因设备数量限制被踢下线的设备 / Devices kicked to satisfy the device policy
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *RegisterRouteResponse) Reset() {
@@ -133,10 +147,19 @@ func (x *RegisterRouteResponse) GetMessage() string {
 	return ""
 }
 
+func (x *RegisterRouteResponse) GetEvictedDeviceIds() []string {
+	if x != nil {
+		return x.EvictedDeviceIds
+	}
+	return nil
+}
+
+// KeepAliveRequest 心跳请求
+// Keep alive request (heartbeat)
 type KeepAliveRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`      // 用户ID / User ID
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"` // 设备ID / Device ID
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -185,9 +208,11 @@ func (x *KeepAliveRequest) GetDeviceId() string {
 	return ""
 }
 
+// KeepAliveResponse 心跳响应
+// Keep alive response
 type KeepAliveResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"` // 是否成功 / Success status
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -229,9 +254,11 @@ func (x *KeepAliveResponse) GetSuccess() bool {
 	return false
 }
 
+// GetRouteRequest 获取路由请求
+// Get route request
 type GetRouteRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // 用户ID / User ID
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -273,9 +300,11 @@ func (x *GetRouteRequest) GetUserId() int64 {
 	return 0
 }
 
+// GetRouteResponse 获取路由响应
+// Get route response
 type GetRouteResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Routes        []*DeviceRoute         `protobuf:"bytes,1,rep,name=routes,proto3" json:"routes,omitempty"`
+	Routes        []*DeviceRoute         `protobuf:"bytes,1,rep,name=routes,proto3" json:"routes,omitempty"` // 该用户的所有设备路由 / All device routes for this user
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -317,11 +346,15 @@ func (x *GetRouteResponse) GetRoutes() []*DeviceRoute {
 	return nil
 }
 
+// DeviceRoute 设备路由信息
+// Device routing information
 type DeviceRoute struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
-	GatewayAddr   string                 `protobuf:"bytes,2,opt,name=gateway_addr,json=gatewayAddr,proto3" json:"gateway_addr,omitempty"`
-	LastActive    int64                  `protobuf:"varint,3,opt,name=last_active,json=lastActive,proto3" json:"last_active,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`           // 设备ID / Device ID
+	GatewayAddr   string                 `protobuf:"bytes,2,opt,name=gateway_addr,json=gatewayAddr,proto3" json:"gateway_addr,omitempty"`  // 连接的Gateway地址 / Connected Gateway address
+	LastActive    int64                  `protobuf:"varint,3,opt,name=last_active,json=lastActive,proto3" json:"last_active,omitempty"`    // 最后活跃时间 (Unix时间戳) / Last active time (Unix timestamp)
+	Platform      string                 `protobuf:"bytes,4,opt,name=platform,proto3" json:"platform,omitempty"`                           // 平台类型 / Platform class
+	ConnectedAt   int64                  `protobuf:"varint,5,opt,name=connected_at,json=connectedAt,proto3" json:"connected_at,omitempty"` // 连接时间 (Unix时间戳) / Connection time (Unix timestamp)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -377,10 +410,27 @@ func (x *DeviceRoute) GetLastActive() int64 {
 	return 0
 }
 
+func (x *DeviceRoute) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *DeviceRoute) GetConnectedAt() int64 {
+	if x != nil {
+		return x.ConnectedAt
+	}
+	return 0
+}
+
+// UnregisterRouteRequest 注销路由请求
+// Unregister route request
 type UnregisterRouteRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`               // 用户ID / User ID
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`          // 设备ID / Device ID
+	GatewayAddr   string                 `protobuf:"bytes,3,opt,name=gateway_addr,json=gatewayAddr,proto3" json:"gateway_addr,omitempty"` // 注销方 Gateway 地址，路由已属于其他 Gateway 时不删除 / Caller's gateway address; a route owned by another gateway is kept
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -429,9 +479,18 @@ func (x *UnregisterRouteRequest) GetDeviceId() string {
 	return ""
 }
 
+func (x *UnregisterRouteRequest) GetGatewayAddr() string {
+	if x != nil {
+		return x.GatewayAddr
+	}
+	return ""
+}
+
+// UnregisterRouteResponse 注销路由响应
+// Unregister route response
 type UnregisterRouteResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"` // 是否成功 / Success status
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -473,9 +532,12 @@ func (x *UnregisterRouteResponse) GetSuccess() bool {
 	return false
 }
 
+// GetOnlineStatusRequest 获取在线状态请求
+// Get online status request
 type GetOnlineStatusRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`       // 用户ID / User ID
+	ViewerId      int64                  `protobuf:"varint,2,opt,name=viewer_id,json=viewerId,proto3" json:"viewer_id,omitempty"` // 查看者ID，隐身用户对他人显示为离线 / Viewer ID; invisible users appear offline to others
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -517,12 +579,25 @@ func (x *GetOnlineStatusRequest) GetUserId() int64 {
 	return 0
 }
 
+func (x *GetOnlineStatusRequest) GetViewerId() int64 {
+	if x != nil {
+		return x.ViewerId
+	}
+	return 0
+}
+
+// GetOnlineStatusResponse 获取在线状态响应
+// Get online status response
 type GetOnlineStatusResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Online        bool                   `protobuf:"varint,1,opt,name=online,proto3" json:"online,omitempty"`
-	DeviceIds     []string               `protobuf:"bytes,2,rep,name=device_ids,json=deviceIds,proto3" json:"device_ids,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Online                bool                   `protobuf:"varint,1,opt,name=online,proto3" json:"online,omitempty"`                                                                // 是否在线 / Whether online
+	DeviceIds             []string               `protobuf:"bytes,2,rep,name=device_ids,json=deviceIds,proto3" json:"device_ids,omitempty"`                                          // 在线设备ID列表 / List of online device IDs
+	Status                string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`                                                                 // 状态: online/away/busy/invisible/offline / Status: online/away/busy/invisible/offline
+	CustomStatus          string                 `protobuf:"bytes,4,opt,name=custom_status,json=customStatus,proto3" json:"custom_status,omitempty"`                                 // 自定义状态文本 / Custom status text
+	CustomStatusExpiresAt int64                  `protobuf:"varint,5,opt,name=custom_status_expires_at,json=customStatusExpiresAt,proto3" json:"custom_status_expires_at,omitempty"` // 自定义状态过期时间 (0表示不过期) / Custom status expiry (0 means never)
+	LastSeen              int64                  `protobuf:"varint,6,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`                                            // 最后在线时间 (离线时) / Last seen time (when offline)
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
 }
 
 func (x *GetOnlineStatusResponse) Reset() {
@@ -569,49 +644,1253 @@ func (x *GetOnlineStatusResponse) GetDeviceIds() []string {
 	return nil
 }
 
-var File_router_router_proto protoreflect.FileDescriptor
+func (x *GetOnlineStatusResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
 
-const file_router_router_proto_rawDesc = "" +
-	"\n" +
-	"\x13router/router.proto\x12\x06router\"o\n" +
-	"\x14RegisterRouteRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1b\n" +
-	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12!\n" +
-	"\fgateway_addr\x18\x03 \x01(\tR\vgatewayAddr\"K\n" +
-	"\x15RegisterRouteResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"H\n" +
-	"\x10KeepAliveRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1b\n" +
-	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\"-\n" +
-	"\x11KeepAliveResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"*\n" +
-	"\x0fGetRouteRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\x03R\x06userId\"?\n" +
-	"\x10GetRouteResponse\x12+\n" +
-	"\x06routes\x18\x01 \x03(\v2\x13.router.DeviceRouteR\x06routes\"n\n" +
-	"\vDeviceRoute\x12\x1b\n" +
-	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12!\n" +
-	"\fgateway_addr\x18\x02 \x01(\tR\vgatewayAddr\x12\x1f\n" +
-	"\vlast_active\x18\x03 \x01(\x03R\n" +
-	"lastActive\"N\n" +
-	"\x16UnregisterRouteRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1b\n" +
-	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\"3\n" +
-	"\x17UnregisterRouteResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"1\n" +
-	"\x16GetOnlineStatusRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\x03R\x06userId\"P\n" +
-	"\x17GetOnlineStatusResponse\x12\x16\n" +
-	"\x06online\x18\x01 \x01(\bR\x06online\x12\x1d\n" +
-	"\n" +
-	"device_ids\x18\x02 \x03(\tR\tdeviceIds2\x86\x03\n" +
-	"\rRouterService\x12L\n" +
-	"\rRegisterRoute\x12\x1c.router.RegisterRouteRequest\x1a\x1d.router.RegisterRouteResponse\x12@\n" +
-	"\tKeepAlive\x12\x18.router.KeepAliveRequest\x1a\x19.router.KeepAliveResponse\x12=\n" +
-	"\bGetRoute\x12\x17.router.GetRouteRequest\x1a\x18.router.GetRouteResponse\x12R\n" +
-	"\x0fUnregisterRoute\x12\x1e.router.UnregisterRouteRequest\x1a\x1f.router.UnregisterRouteResponse\x12R\n" +
-	"\x0fGetOnlineStatus\x12\x1e.router.GetOnlineStatusRequest\x1a\x1f.router.GetOnlineStatusResponseB>Z<github.com/dollarkillerx/im-system/api/proto/router;routerpbb\x06proto3"
+func (x *GetOnlineStatusResponse) GetCustomStatus() string {
+	if x != nil {
+		return x.CustomStatus
+	}
+	return ""
+}
+
+func (x *GetOnlineStatusResponse) GetCustomStatusExpiresAt() int64 {
+	if x != nil {
+		return x.CustomStatusExpiresAt
+	}
+	return 0
+}
+
+func (x *GetOnlineStatusResponse) GetLastSeen() int64 {
+	if x != nil {
+		return x.LastSeen
+	}
+	return 0
+}
+
+// SetPresenceRequest 设置状态请求
+// Set presence request
+type SetPresenceRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	UserId          int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                              // 用户ID / User ID
+	Status          string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`                                             // 手动状态: online/away/busy/invisible / Manual status: online/away/busy/invisible
+	CustomStatus    string                 `protobuf:"bytes,3,opt,name=custom_status,json=customStatus,proto3" json:"custom_status,omitempty"`             // 自定义状态文本，为空表示清除 / Custom status text, empty clears it
+	CustomStatusTtl int64                  `protobuf:"varint,4,opt,name=custom_status_ttl,json=customStatusTtl,proto3" json:"custom_status_ttl,omitempty"` // 自定义状态有效期 (秒，0表示不过期) / Custom status lifetime in seconds (0 means never)
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SetPresenceRequest) Reset() {
+	*x = SetPresenceRequest{}
+	mi := &file_router_router_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetPresenceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetPresenceRequest) ProtoMessage() {}
+
+func (x *SetPresenceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_router_router_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetPresenceRequest.ProtoReflect.Descriptor instead.
+func (*SetPresenceRequest) Descriptor() ([]byte, []int) {
+	return file_router_router_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SetPresenceRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *SetPresenceRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SetPresenceRequest) GetCustomStatus() string {
+	if x != nil {
+		return x.CustomStatus
+	}
+	return ""
+}
+
+func (x *SetPresenceRequest) GetCustomStatusTtl() int64 {
+	if x != nil {
+		return x.CustomStatusTtl
+	}
+	return 0
+}
+
+// SetPresenceResponse 设置状态响应
+// Set presence response
+type SetPresenceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Presence      *PresenceStatus        `protobuf:"bytes,1,opt,name=presence,proto3" json:"presence,omitempty"` // 设置后的状态 (本人视角) / Resulting presence as seen by the user
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetPresenceResponse) Reset() {
+	*x = SetPresenceResponse{}
+	mi := &file_router_router_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetPresenceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetPresenceResponse) ProtoMessage() {}
+
+func (x *SetPresenceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_router_router_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetPresenceResponse.ProtoReflect.Descriptor instead.
+func (*SetPresenceResponse) Descriptor() ([]byte, []int) {
+	return file_router_router_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SetPresenceResponse) GetPresence() *PresenceStatus {
+	if x != nil {
+		return x.Presence
+	}
+	return nil
+}
+
+// BatchGetRoutesRequest 批量获取路由请求
+// Batch get routes request
+type BatchGetRoutesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserIds       []int64                `protobuf:"varint,1,rep,packed,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"` // 用户ID列表 (最多10000个) / User IDs (at most 10000)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetRoutesRequest) Reset() {
+	*x = BatchGetRoutesRequest{}
+	mi := &file_router_router_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetRoutesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetRoutesRequest) ProtoMessage() {}
+
+func (x *BatchGetRoutesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_router_router_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetRoutesRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetRoutesRequest) Descriptor() ([]byte, []int) {
+	return file_router_router_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *BatchGetRoutesRequest) GetUserIds() []int64 {
+	if x != nil {
+		return x.UserIds
+	}
+	return nil
+}
+
+// BatchGetRoutesResponse 批量获取路由响应
+// Batch get routes response
+type BatchGetRoutesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*UserRoutes          `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"` // 按请求顺序返回每个用户的路由 / Routes of each user, in request order
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetRoutesResponse) Reset() {
+	*x = BatchGetRoutesResponse{}
+	mi := &file_router_router_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetRoutesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetRoutesResponse) ProtoMessage() {}
+
+func (x *BatchGetRoutesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_router_router_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetRoutesResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetRoutesResponse) Descriptor() ([]byte, []int) {
+	return file_router_router_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *BatchGetRoutesResponse) GetResults() []*UserRoutes {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+// UserRoutes 单个用户的路由信息
+// Routes of a single user
+type UserRoutes struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // 用户ID / User ID
+	Routes        []*DeviceRoute         `protobuf:"bytes,2,rep,name=routes,proto3" json:"routes,omitempty"`                // 该用户的所有设备路由 / All device routes for this user
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserRoutes) Reset() {
+	*x = UserRoutes{}
+	mi := &file_router_router_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserRoutes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserRoutes) ProtoMessage() {}
+
+func (x *UserRoutes) ProtoReflect() protoreflect.Message {
+	mi := &file_router_router_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserRoutes.ProtoReflect.Descriptor instead.
+func (*UserRoutes) Descriptor() ([]byte, []int) {
+	return file_router_router_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *UserRoutes) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *UserRoutes) GetRoutes() []*DeviceRoute {
+	if x != nil {
+		return x.Routes
+	}
+	return nil
+}
+
+// BatchGetOnlineStatusRequest 批量获取在线状态请求
+// Batch get online status request
+type BatchGetOnlineStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserIds       []int64                `protobuf:"varint,1,rep,packed,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"` // 用户ID列表 (最多10000个) / User IDs (at most 10000)
+	ViewerId      int64                  `protobuf:"varint,2,opt,name=viewer_id,json=viewerId,proto3" json:"viewer_id,omitempty"`     // 查看者ID，隐身用户对他人显示为离线 / Viewer ID; invisible users appear offline to others
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetOnlineStatusRequest) Reset() {
+	*x = BatchGetOnlineStatusRequest{}
+	mi := &file_router_router_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetOnlineStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetOnlineStatusRequest) ProtoMessage() {}
+
+func (x *BatchGetOnlineStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_router_router_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetOnlineStatusRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetOnlineStatusRequest) Descriptor() ([]byte, []int) {
+	return file_router_router_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *BatchGetOnlineStatusRequest) GetUserIds() []int64 {
+	if x != nil {
+		return x.UserIds
+	}
+	return nil
+}
+
+func (x *BatchGetOnlineStatusRequest) GetViewerId() int64 {
+	if x != nil {
+		return x.ViewerId
+	}
+	return 0
+}
+
+// BatchGetOnlineStatusResponse 批量获取在线状态响应
+// Batch get online status response
+type BatchGetOnlineStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*UserOnlineStatus    `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"` // 按请求顺序返回每个用户的在线状态 / Online status of each user, in request order
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetOnlineStatusResponse) Reset() {
+	*x = BatchGetOnlineStatusResponse{}
+	mi := &file_router_router_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetOnlineStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetOnlineStatusResponse) ProtoMessage() {}
+
+func (x *BatchGetOnlineStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_router_router_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetOnlineStatusResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetOnlineStatusResponse) Descriptor() ([]byte, []int) {
+	return file_router_router_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *BatchGetOnlineStatusResponse) GetResults() []*UserOnlineStatus {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+// UserOnlineStatus 单个用户的在线状态
+// Online status of a single user
+type UserOnlineStatus struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	UserId                int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                                                  // 用户ID / User ID
+	Online                bool                   `protobuf:"varint,2,opt,name=online,proto3" json:"online,omitempty"`                                                                // 是否在线 / Whether online
+	DeviceIds             []string               `protobuf:"bytes,3,rep,name=device_ids,json=deviceIds,proto3" json:"device_ids,omitempty"`                                          // 在线设备ID列表 / List of online device IDs
+	Status                string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`                                                                 // 状态: online/away/busy/invisible/offline / Status: online/away/busy/invisible/offline
+	CustomStatus          string                 `protobuf:"bytes,5,opt,name=custom_status,json=customStatus,proto3" json:"custom_status,omitempty"`                                 // 自定义状态文本 / Custom status text
+	CustomStatusExpiresAt int64                  `protobuf:"varint,6,opt,name=custom_status_expires_at,json=customStatusExpiresAt,proto3" json:"custom_status_expires_at,omitempty"` // 自定义状态过期时间 (0表示不过期) / Custom status expiry (0 means never)
+	LastSeen              int64                  `protobuf:"varint,7,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`                                            // 最后在线时间 (离线时) / Last seen time (when offline)
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *UserOnlineStatus) Reset() {
+	*x = UserOnlineStatus{}
+	mi := &file_router_router_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserOnlineStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserOnlineStatus) ProtoMessage() {}
+
+func (x *UserOnlineStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_router_router_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserOnlineStatus.ProtoReflect.Descriptor instead.
+func (*UserOnlineStatus) Descriptor() ([]byte, []int) {
+	return file_router_router_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *UserOnlineStatus) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *UserOnlineStatus) GetOnline() bool {
+	if x != nil {
+		return x.Online
+	}
+	return false
+}
+
+func (x *UserOnlineStatus) GetDeviceIds() []string {
+	if x != nil {
+		return x.DeviceIds
+	}
+	return nil
+}
+
+func (x *UserOnlineStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *UserOnlineStatus) GetCustomStatus() string {
+	if x != nil {
+		return x.CustomStatus
+	}
+	return ""
+}
+
+func (x *UserOnlineStatus) GetCustomStatusExpiresAt() int64 {
+	if x != nil {
+		return x.CustomStatusExpiresAt
+	}
+	return 0
+}
+
+func (x *UserOnlineStatus) GetLastSeen() int64 {
+	if x != nil {
+		return x.LastSeen
+	}
+	return 0
+}
+
+// PushMessageRequest 推送新消息请求 (由 Message 服务调用)
+// Push new message request (called by Message service)
+type PushMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConvId        int64                  `protobuf:"varint,1,opt,name=conv_id,json=convId,proto3" json:"conv_id,omitempty"`                          // 会话ID / Conversation ID
+	MsgId         string                 `protobuf:"bytes,2,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`                              // 消息ID / Message ID
+	Seq           int64                  `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`                                              // 消息序列号 / Message sequence number
+	SenderId      int64                  `protobuf:"varint,4,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`                    // 发送者ID / Sender ID
+	RecipientIds  []int64                `protobuf:"varint,5,rep,packed,name=recipient_ids,json=recipientIds,proto3" json:"recipient_ids,omitempty"` // 接收者ID列表 / Recipient ID list
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PushMessageRequest) Reset() {
+	*x = PushMessageRequest{}
+	mi := &file_router_router_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PushMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushMessageRequest) ProtoMessage() {}
+
+func (x *PushMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_router_router_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushMessageRequest.ProtoReflect.Descriptor instead.
+func (*PushMessageRequest) Descriptor() ([]byte, []int) {
+	return file_router_router_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *PushMessageRequest) GetConvId() int64 {
+	if x != nil {
+		return x.ConvId
+	}
+	return 0
+}
+
+func (x *PushMessageRequest) GetMsgId() string {
+	if x != nil {
+		return x.MsgId
+	}
+	return ""
+}
+
+func (x *PushMessageRequest) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *PushMessageRequest) GetSenderId() int64 {
+	if x != nil {
+		return x.SenderId
+	}
+	return 0
+}
+
+func (x *PushMessageRequest) GetRecipientIds() []int64 {
+	if x != nil {
+		return x.RecipientIds
+	}
+	return nil
+}
+
+// PushMessageResponse 推送新消息响应
+// Push new message response
+type PushMessageResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	OnlineCount    int32                  `protobuf:"varint,1,opt,name=online_count,json=onlineCount,proto3" json:"online_count,omitempty"`          // 在线接收者数量 / Number of online recipients
+	DeliveredCount int32                  `protobuf:"varint,2,opt,name=delivered_count,json=deliveredCount,proto3" json:"delivered_count,omitempty"` // 成功投递的设备连接数 / Number of device connections delivered to
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PushMessageResponse) Reset() {
+	*x = PushMessageResponse{}
+	mi := &file_router_router_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PushMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushMessageResponse) ProtoMessage() {}
+
+func (x *PushMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_router_router_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushMessageResponse.ProtoReflect.Descriptor instead.
+func (*PushMessageResponse) Descriptor() ([]byte, []int) {
+	return file_router_router_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *PushMessageResponse) GetOnlineCount() int32 {
+	if x != nil {
+		return x.OnlineCount
+	}
+	return 0
+}
+
+func (x *PushMessageResponse) GetDeliveredCount() int32 {
+	if x != nil {
+		return x.DeliveredCount
+	}
+	return 0
+}
+
+// PushEventRequest 推送实时事件请求 (由 Gateway 调用)
+// Push transient event request (called by Gateway)
+type PushEventRequest struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	RecipientIds  []int64                 `protobuf:"varint,1,rep,packed,name=recipient_ids,json=recipientIds,proto3" json:"recipient_ids,omitempty"` // 接收者ID列表 / Recipient ID list
+	Event         *gateway.GatewayMessage `protobuf:"bytes,2,opt,name=event,proto3" json:"event,omitempty"`                                           // 推送的事件 / Event to push
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PushEventRequest) Reset() {
+	*x = PushEventRequest{}
+	mi := &file_router_router_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PushEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushEventRequest) ProtoMessage() {}
+
+func (x *PushEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_router_router_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushEventRequest.ProtoReflect.Descriptor instead.
+func (*PushEventRequest) Descriptor() ([]byte, []int) {
+	return file_router_router_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *PushEventRequest) GetRecipientIds() []int64 {
+	if x != nil {
+		return x.RecipientIds
+	}
+	return nil
+}
+
+func (x *PushEventRequest) GetEvent() *gateway.GatewayMessage {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+// PushEventResponse 推送实时事件响应
+// Push transient event response
+type PushEventResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	OnlineCount    int32                  `protobuf:"varint,1,opt,name=online_count,json=onlineCount,proto3" json:"online_count,omitempty"`          // 在线接收者数量 / Number of online recipients
+	DeliveredCount int32                  `protobuf:"varint,2,opt,name=delivered_count,json=deliveredCount,proto3" json:"delivered_count,omitempty"` // 成功投递的设备连接数 / Number of device connections delivered to
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PushEventResponse) Reset() {
+	*x = PushEventResponse{}
+	mi := &file_router_router_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PushEventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushEventResponse) ProtoMessage() {}
+
+func (x *PushEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_router_router_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushEventResponse.ProtoReflect.Descriptor instead.
+func (*PushEventResponse) Descriptor() ([]byte, []int) {
+	return file_router_router_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *PushEventResponse) GetOnlineCount() int32 {
+	if x != nil {
+		return x.OnlineCount
+	}
+	return 0
+}
+
+func (x *PushEventResponse) GetDeliveredCount() int32 {
+	if x != nil {
+		return x.DeliveredCount
+	}
+	return 0
+}
+
+// SubscribePresenceRequest 订阅在线状态请求 (由 Gateway 调用)
+// Subscribe presence request (called by Gateway)
+type SubscribePresenceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SubscriberId  int64                  `protobuf:"varint,1,opt,name=subscriber_id,json=subscriberId,proto3" json:"subscriber_id,omitempty"` // 订阅者ID / Subscriber user ID
+	UserIds       []int64                `protobuf:"varint,2,rep,packed,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"`         // 被订阅的用户ID列表 / User IDs to watch
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribePresenceRequest) Reset() {
+	*x = SubscribePresenceRequest{}
+	mi := &file_router_router_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribePresenceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribePresenceRequest) ProtoMessage() {}
+
+func (x *SubscribePresenceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_router_router_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribePresenceRequest.ProtoReflect.Descriptor instead.
+func (*SubscribePresenceRequest) Descriptor() ([]byte, []int) {
+	return file_router_router_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SubscribePresenceRequest) GetSubscriberId() int64 {
+	if x != nil {
+		return x.SubscriberId
+	}
+	return 0
+}
+
+func (x *SubscribePresenceRequest) GetUserIds() []int64 {
+	if x != nil {
+		return x.UserIds
+	}
+	return nil
+}
+
+// SubscribePresenceResponse 订阅在线状态响应
+// Subscribe presence response
+type SubscribePresenceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Statuses      []*PresenceStatus      `protobuf:"bytes,1,rep,name=statuses,proto3" json:"statuses,omitempty"` // 被订阅用户的当前状态 / Current status of the watched users
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribePresenceResponse) Reset() {
+	*x = SubscribePresenceResponse{}
+	mi := &file_router_router_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribePresenceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribePresenceResponse) ProtoMessage() {}
+
+func (x *SubscribePresenceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_router_router_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribePresenceResponse.ProtoReflect.Descriptor instead.
+func (*SubscribePresenceResponse) Descriptor() ([]byte, []int) {
+	return file_router_router_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *SubscribePresenceResponse) GetStatuses() []*PresenceStatus {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+// PresenceStatus 用户在线状态
+// User presence status
+type PresenceStatus struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	UserId                int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                                                  // 用户ID / User ID
+	Online                bool                   `protobuf:"varint,2,opt,name=online,proto3" json:"online,omitempty"`                                                                // 是否在线 / Whether online
+	Status                string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`                                                                 // 状态: online/away/busy/invisible/offline / Status: online/away/busy/invisible/offline
+	CustomStatus          string                 `protobuf:"bytes,4,opt,name=custom_status,json=customStatus,proto3" json:"custom_status,omitempty"`                                 // 自定义状态文本 / Custom status text
+	CustomStatusExpiresAt int64                  `protobuf:"varint,5,opt,name=custom_status_expires_at,json=customStatusExpiresAt,proto3" json:"custom_status_expires_at,omitempty"` // 自定义状态过期时间 (0表示不过期) / Custom status expiry (0 means never)
+	LastSeen              int64                  `protobuf:"varint,6,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`                                            // 最后在线时间 (离线时) / Last seen time (when offline)
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *PresenceStatus) Reset() {
+	*x = PresenceStatus{}
+	mi := &file_router_router_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PresenceStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PresenceStatus) ProtoMessage() {}
+
+func (x *PresenceStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_router_router_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PresenceStatus.ProtoReflect.Descriptor instead.
+func (*PresenceStatus) Descriptor() ([]byte, []int) {
+	return file_router_router_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *PresenceStatus) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *PresenceStatus) GetOnline() bool {
+	if x != nil {
+		return x.Online
+	}
+	return false
+}
+
+func (x *PresenceStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *PresenceStatus) GetCustomStatus() string {
+	if x != nil {
+		return x.CustomStatus
+	}
+	return ""
+}
+
+func (x *PresenceStatus) GetCustomStatusExpiresAt() int64 {
+	if x != nil {
+		return x.CustomStatusExpiresAt
+	}
+	return 0
+}
+
+func (x *PresenceStatus) GetLastSeen() int64 {
+	if x != nil {
+		return x.LastSeen
+	}
+	return 0
+}
+
+// UnsubscribePresenceRequest 取消订阅在线状态请求
+// Unsubscribe presence request
+type UnsubscribePresenceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SubscriberId  int64                  `protobuf:"varint,1,opt,name=subscriber_id,json=subscriberId,proto3" json:"subscriber_id,omitempty"` // 订阅者ID / Subscriber user ID
+	UserIds       []int64                `protobuf:"varint,2,rep,packed,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"`         // 取消订阅的用户ID列表 / User IDs to stop watching
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnsubscribePresenceRequest) Reset() {
+	*x = UnsubscribePresenceRequest{}
+	mi := &file_router_router_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnsubscribePresenceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnsubscribePresenceRequest) ProtoMessage() {}
+
+func (x *UnsubscribePresenceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_router_router_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnsubscribePresenceRequest.ProtoReflect.Descriptor instead.
+func (*UnsubscribePresenceRequest) Descriptor() ([]byte, []int) {
+	return file_router_router_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *UnsubscribePresenceRequest) GetSubscriberId() int64 {
+	if x != nil {
+		return x.SubscriberId
+	}
+	return 0
+}
+
+func (x *UnsubscribePresenceRequest) GetUserIds() []int64 {
+	if x != nil {
+		return x.UserIds
+	}
+	return nil
+}
+
+// UnsubscribePresenceResponse 取消订阅在线状态响应
+// Unsubscribe presence response
+type UnsubscribePresenceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"` // 是否成功 / Success status
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnsubscribePresenceResponse) Reset() {
+	*x = UnsubscribePresenceResponse{}
+	mi := &file_router_router_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnsubscribePresenceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnsubscribePresenceResponse) ProtoMessage() {}
+
+func (x *UnsubscribePresenceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_router_router_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnsubscribePresenceResponse.ProtoReflect.Descriptor instead.
+func (*UnsubscribePresenceResponse) Descriptor() ([]byte, []int) {
+	return file_router_router_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *UnsubscribePresenceResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// KickDeviceRequest 踢设备下线请求
+// Kick device request
+type KickDeviceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`      // 用户ID / User ID
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"` // 设备ID / Device ID
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`                     // 下线原因，为空时为 kicked / Kick reason, defaults to kicked
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KickDeviceRequest) Reset() {
+	*x = KickDeviceRequest{}
+	mi := &file_router_router_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KickDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KickDeviceRequest) ProtoMessage() {}
+
+func (x *KickDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_router_router_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KickDeviceRequest.ProtoReflect.Descriptor instead.
+func (*KickDeviceRequest) Descriptor() ([]byte, []int) {
+	return file_router_router_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *KickDeviceRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *KickDeviceRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *KickDeviceRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// KickDeviceResponse 踢设备下线响应
+// Kick device response
+type KickDeviceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kicked        bool                   `protobuf:"varint,1,opt,name=kicked,proto3" json:"kicked,omitempty"` // 设备是否在线并已被踢下线 / Whether the device was connected and has been kicked
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KickDeviceResponse) Reset() {
+	*x = KickDeviceResponse{}
+	mi := &file_router_router_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KickDeviceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KickDeviceResponse) ProtoMessage() {}
+
+func (x *KickDeviceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_router_router_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KickDeviceResponse.ProtoReflect.Descriptor instead.
+func (*KickDeviceResponse) Descriptor() ([]byte, []int) {
+	return file_router_router_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *KickDeviceResponse) GetKicked() bool {
+	if x != nil {
+		return x.Kicked
+	}
+	return false
+}
+
+var File_router_router_proto protoreflect.FileDescriptor
+
+const file_router_router_proto_rawDesc = "" +
+	"\n" +
+	"\x13router/router.proto\x12\x06router\x1a\x15gateway/gateway.proto\"\x8b\x01\n" +
+	"\x14RegisterRouteRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12!\n" +
+	"\fgateway_addr\x18\x03 \x01(\tR\vgatewayAddr\x12\x1a\n" +
+	"\bplatform\x18\x04 \x01(\tR\bplatform\"y\n" +
+	"\x15RegisterRouteResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12,\n" +
+	"\x12evicted_device_ids\x18\x03 \x03(\tR\x10evictedDeviceIds\"H\n" +
+	"\x10KeepAliveRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\"-\n" +
+	"\x11KeepAliveResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"*\n" +
+	"\x0fGetRouteRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\"?\n" +
+	"\x10GetRouteResponse\x12+\n" +
+	"\x06routes\x18\x01 \x03(\v2\x13.router.DeviceRouteR\x06routes\"\xad\x01\n" +
+	"\vDeviceRoute\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12!\n" +
+	"\fgateway_addr\x18\x02 \x01(\tR\vgatewayAddr\x12\x1f\n" +
+	"\vlast_active\x18\x03 \x01(\x03R\n" +
+	"lastActive\x12\x1a\n" +
+	"\bplatform\x18\x04 \x01(\tR\bplatform\x12!\n" +
+	"\fconnected_at\x18\x05 \x01(\x03R\vconnectedAt\"q\n" +
+	"\x16UnregisterRouteRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12!\n" +
+	"\fgateway_addr\x18\x03 \x01(\tR\vgatewayAddr\"3\n" +
+	"\x17UnregisterRouteResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"N\n" +
+	"\x16GetOnlineStatusRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1b\n" +
+	"\tviewer_id\x18\x02 \x01(\x03R\bviewerId\"\xe3\x01\n" +
+	"\x17GetOnlineStatusResponse\x12\x16\n" +
+	"\x06online\x18\x01 \x01(\bR\x06online\x12\x1d\n" +
+	"\n" +
+	"device_ids\x18\x02 \x03(\tR\tdeviceIds\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12#\n" +
+	"\rcustom_status\x18\x04 \x01(\tR\fcustomStatus\x127\n" +
+	"\x18custom_status_expires_at\x18\x05 \x01(\x03R\x15customStatusExpiresAt\x12\x1b\n" +
+	"\tlast_seen\x18\x06 \x01(\x03R\blastSeen\"\x96\x01\n" +
+	"\x12SetPresenceRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12#\n" +
+	"\rcustom_status\x18\x03 \x01(\tR\fcustomStatus\x12*\n" +
+	"\x11custom_status_ttl\x18\x04 \x01(\x03R\x0fcustomStatusTtl\"I\n" +
+	"\x13SetPresenceResponse\x122\n" +
+	"\bpresence\x18\x01 \x01(\v2\x16.router.PresenceStatusR\bpresence\"2\n" +
+	"\x15BatchGetRoutesRequest\x12\x19\n" +
+	"\buser_ids\x18\x01 \x03(\x03R\auserIds\"F\n" +
+	"\x16BatchGetRoutesResponse\x12,\n" +
+	"\aresults\x18\x01 \x03(\v2\x12.router.UserRoutesR\aresults\"R\n" +
+	"\n" +
+	"UserRoutes\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12+\n" +
+	"\x06routes\x18\x02 \x03(\v2\x13.router.DeviceRouteR\x06routes\"U\n" +
+	"\x1bBatchGetOnlineStatusRequest\x12\x19\n" +
+	"\buser_ids\x18\x01 \x03(\x03R\auserIds\x12\x1b\n" +
+	"\tviewer_id\x18\x02 \x01(\x03R\bviewerId\"R\n" +
+	"\x1cBatchGetOnlineStatusResponse\x122\n" +
+	"\aresults\x18\x01 \x03(\v2\x18.router.UserOnlineStatusR\aresults\"\xf5\x01\n" +
+	"\x10UserOnlineStatus\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x16\n" +
+	"\x06online\x18\x02 \x01(\bR\x06online\x12\x1d\n" +
+	"\n" +
+	"device_ids\x18\x03 \x03(\tR\tdeviceIds\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12#\n" +
+	"\rcustom_status\x18\x05 \x01(\tR\fcustomStatus\x127\n" +
+	"\x18custom_status_expires_at\x18\x06 \x01(\x03R\x15customStatusExpiresAt\x12\x1b\n" +
+	"\tlast_seen\x18\a \x01(\x03R\blastSeen\"\x98\x01\n" +
+	"\x12PushMessageRequest\x12\x17\n" +
+	"\aconv_id\x18\x01 \x01(\x03R\x06convId\x12\x15\n" +
+	"\x06msg_id\x18\x02 \x01(\tR\x05msgId\x12\x10\n" +
+	"\x03seq\x18\x03 \x01(\x03R\x03seq\x12\x1b\n" +
+	"\tsender_id\x18\x04 \x01(\x03R\bsenderId\x12#\n" +
+	"\rrecipient_ids\x18\x05 \x03(\x03R\frecipientIds\"a\n" +
+	"\x13PushMessageResponse\x12!\n" +
+	"\fonline_count\x18\x01 \x01(\x05R\vonlineCount\x12'\n" +
+	"\x0fdelivered_count\x18\x02 \x01(\x05R\x0edeliveredCount\"f\n" +
+	"\x10PushEventRequest\x12#\n" +
+	"\rrecipient_ids\x18\x01 \x03(\x03R\frecipientIds\x12-\n" +
+	"\x05event\x18\x02 \x01(\v2\x17.gateway.GatewayMessageR\x05event\"_\n" +
+	"\x11PushEventResponse\x12!\n" +
+	"\fonline_count\x18\x01 \x01(\x05R\vonlineCount\x12'\n" +
+	"\x0fdelivered_count\x18\x02 \x01(\x05R\x0edeliveredCount\"Z\n" +
+	"\x18SubscribePresenceRequest\x12#\n" +
+	"\rsubscriber_id\x18\x01 \x01(\x03R\fsubscriberId\x12\x19\n" +
+	"\buser_ids\x18\x02 \x03(\x03R\auserIds\"O\n" +
+	"\x19SubscribePresenceResponse\x122\n" +
+	"\bstatuses\x18\x01 \x03(\v2\x16.router.PresenceStatusR\bstatuses\"\xd4\x01\n" +
+	"\x0ePresenceStatus\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x16\n" +
+	"\x06online\x18\x02 \x01(\bR\x06online\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12#\n" +
+	"\rcustom_status\x18\x04 \x01(\tR\fcustomStatus\x127\n" +
+	"\x18custom_status_expires_at\x18\x05 \x01(\x03R\x15customStatusExpiresAt\x12\x1b\n" +
+	"\tlast_seen\x18\x06 \x01(\x03R\blastSeen\"\\\n" +
+	"\x1aUnsubscribePresenceRequest\x12#\n" +
+	"\rsubscriber_id\x18\x01 \x01(\x03R\fsubscriberId\x12\x19\n" +
+	"\buser_ids\x18\x02 \x03(\x03R\auserIds\"7\n" +
+	"\x1bUnsubscribePresenceResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"a\n" +
+	"\x11KickDeviceRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\",\n" +
+	"\x12KickDeviceResponse\x12\x16\n" +
+	"\x06kicked\x18\x01 \x01(\bR\x06kicked2\x8b\b\n" +
+	"\rRouterService\x12L\n" +
+	"\rRegisterRoute\x12\x1c.router.RegisterRouteRequest\x1a\x1d.router.RegisterRouteResponse\x12@\n" +
+	"\tKeepAlive\x12\x18.router.KeepAliveRequest\x1a\x19.router.KeepAliveResponse\x12=\n" +
+	"\bGetRoute\x12\x17.router.GetRouteRequest\x1a\x18.router.GetRouteResponse\x12R\n" +
+	"\x0fUnregisterRoute\x12\x1e.router.UnregisterRouteRequest\x1a\x1f.router.UnregisterRouteResponse\x12R\n" +
+	"\x0fGetOnlineStatus\x12\x1e.router.GetOnlineStatusRequest\x1a\x1f.router.GetOnlineStatusResponse\x12F\n" +
+	"\vSetPresence\x12\x1a.router.SetPresenceRequest\x1a\x1b.router.SetPresenceResponse\x12O\n" +
+	"\x0eBatchGetRoutes\x12\x1d.router.BatchGetRoutesRequest\x1a\x1e.router.BatchGetRoutesResponse\x12a\n" +
+	"\x14BatchGetOnlineStatus\x12#.router.BatchGetOnlineStatusRequest\x1a$.router.BatchGetOnlineStatusResponse\x12F\n" +
+	"\vPushMessage\x12\x1a.router.PushMessageRequest\x1a\x1b.router.PushMessageResponse\x12@\n" +
+	"\tPushEvent\x12\x18.router.PushEventRequest\x1a\x19.router.PushEventResponse\x12X\n" +
+	"\x11SubscribePresence\x12 .router.SubscribePresenceRequest\x1a!.router.SubscribePresenceResponse\x12^\n" +
+	"\x13UnsubscribePresence\x12\".router.UnsubscribePresenceRequest\x1a#.router.UnsubscribePresenceResponse\x12C\n" +
+	"\n" +
+	"KickDevice\x12\x19.router.KickDeviceRequest\x1a\x1a.router.KickDeviceResponseB>Z<github.com/dollarkillerx/im-system/api/proto/router;routerpbb\x06proto3"
 
 var (
 	file_router_router_proto_rawDescOnce sync.Once
@@ -625,37 +1904,79 @@ func file_router_router_proto_rawDescGZIP() []byte {
 	return file_router_router_proto_rawDescData
 }
 
-var file_router_router_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_router_router_proto_msgTypes = make([]protoimpl.MessageInfo, 30)
 var file_router_router_proto_goTypes = []any{
-	(*RegisterRouteRequest)(nil),    // 0: router.RegisterRouteRequest
-	(*RegisterRouteResponse)(nil),   // 1: router.RegisterRouteResponse
-	(*KeepAliveRequest)(nil),        // 2: router.KeepAliveRequest
-	(*KeepAliveResponse)(nil),       // 3: router.KeepAliveResponse
-	(*GetRouteRequest)(nil),         // 4: router.GetRouteRequest
-	(*GetRouteResponse)(nil),        // 5: router.GetRouteResponse
-	(*DeviceRoute)(nil),             // 6: router.DeviceRoute
-	(*UnregisterRouteRequest)(nil),  // 7: router.UnregisterRouteRequest
-	(*UnregisterRouteResponse)(nil), // 8: router.UnregisterRouteResponse
-	(*GetOnlineStatusRequest)(nil),  // 9: router.GetOnlineStatusRequest
-	(*GetOnlineStatusResponse)(nil), // 10: router.GetOnlineStatusResponse
+	(*RegisterRouteRequest)(nil),         // 0: router.RegisterRouteRequest
+	(*RegisterRouteResponse)(nil),        // 1: router.RegisterRouteResponse
+	(*KeepAliveRequest)(nil),             // 2: router.KeepAliveRequest
+	(*KeepAliveResponse)(nil),            // 3: router.KeepAliveResponse
+	(*GetRouteRequest)(nil),              // 4: router.GetRouteRequest
+	(*GetRouteResponse)(nil),             // 5: router.GetRouteResponse
+	(*DeviceRoute)(nil),                  // 6: router.DeviceRoute
+	(*UnregisterRouteRequest)(nil),       // 7: router.UnregisterRouteRequest
+	(*UnregisterRouteResponse)(nil),      // 8: router.UnregisterRouteResponse
+	(*GetOnlineStatusRequest)(nil),       // 9: router.GetOnlineStatusRequest
+	(*GetOnlineStatusResponse)(nil),      // 10: router.GetOnlineStatusResponse
+	(*SetPresenceRequest)(nil),           // 11: router.SetPresenceRequest
+	(*SetPresenceResponse)(nil),          // 12: router.SetPresenceResponse
+	(*BatchGetRoutesRequest)(nil),        // 13: router.BatchGetRoutesRequest
+	(*BatchGetRoutesResponse)(nil),       // 14: router.BatchGetRoutesResponse
+	(*UserRoutes)(nil),                   // 15: router.UserRoutes
+	(*BatchGetOnlineStatusRequest)(nil),  // 16: router.BatchGetOnlineStatusRequest
+	(*BatchGetOnlineStatusResponse)(nil), // 17: router.BatchGetOnlineStatusResponse
+	(*UserOnlineStatus)(nil),             // 18: router.UserOnlineStatus
+	(*PushMessageRequest)(nil),           // 19: router.PushMessageRequest
+	(*PushMessageResponse)(nil),          // 20: router.PushMessageResponse
+	(*PushEventRequest)(nil),             // 21: router.PushEventRequest
+	(*PushEventResponse)(nil),            // 22: router.PushEventResponse
+	(*SubscribePresenceRequest)(nil),     // 23: router.SubscribePresenceRequest
+	(*SubscribePresenceResponse)(nil),    // 24: router.SubscribePresenceResponse
+	(*PresenceStatus)(nil),               // 25: router.PresenceStatus
+	(*UnsubscribePresenceRequest)(nil),   // 26: router.UnsubscribePresenceRequest
+	(*UnsubscribePresenceResponse)(nil),  // 27: router.UnsubscribePresenceResponse
+	(*KickDeviceRequest)(nil),            // 28: router.KickDeviceRequest
+	(*KickDeviceResponse)(nil),           // 29: router.KickDeviceResponse
+	(*gateway.GatewayMessage)(nil),       // 30: gateway.GatewayMessage
 }
 var file_router_router_proto_depIdxs = []int32{
 	6,  // 0: router.GetRouteResponse.routes:type_name -> router.DeviceRoute
-	0,  // 1: router.RouterService.RegisterRoute:input_type -> router.RegisterRouteRequest
-	2,  // 2: router.RouterService.KeepAlive:input_type -> router.KeepAliveRequest
-	4,  // 3: router.RouterService.GetRoute:input_type -> router.GetRouteRequest
-	7,  // 4: router.RouterService.UnregisterRoute:input_type -> router.UnregisterRouteRequest
-	9,  // 5: router.RouterService.GetOnlineStatus:input_type -> router.GetOnlineStatusRequest
-	1,  // 6: router.RouterService.RegisterRoute:output_type -> router.RegisterRouteResponse
-	3,  // 7: router.RouterService.KeepAlive:output_type -> router.KeepAliveResponse
-	5,  // 8: router.RouterService.GetRoute:output_type -> router.GetRouteResponse
-	8,  // 9: router.RouterService.UnregisterRoute:output_type -> router.UnregisterRouteResponse
-	10, // 10: router.RouterService.GetOnlineStatus:output_type -> router.GetOnlineStatusResponse
-	6,  // [6:11] is the sub-list for method output_type
-	1,  // [1:6] is the sub-list for method input_type
-	1,  // [1:1] is the sub-list for extension type_name
-	1,  // [1:1] is the sub-list for extension extendee
-	0,  // [0:1] is the sub-list for field type_name
+	25, // 1: router.SetPresenceResponse.presence:type_name -> router.PresenceStatus
+	15, // 2: router.BatchGetRoutesResponse.results:type_name -> router.UserRoutes
+	6,  // 3: router.UserRoutes.routes:type_name -> router.DeviceRoute
+	18, // 4: router.BatchGetOnlineStatusResponse.results:type_name -> router.UserOnlineStatus
+	30, // 5: router.PushEventRequest.event:type_name -> gateway.GatewayMessage
+	25, // 6: router.SubscribePresenceResponse.statuses:type_name -> router.PresenceStatus
+	0,  // 7: router.RouterService.RegisterRoute:input_type -> router.RegisterRouteRequest
+	2,  // 8: router.RouterService.KeepAlive:input_type -> router.KeepAliveRequest
+	4,  // 9: router.RouterService.GetRoute:input_type -> router.GetRouteRequest
+	7,  // 10: router.RouterService.UnregisterRoute:input_type -> router.UnregisterRouteRequest
+	9,  // 11: router.RouterService.GetOnlineStatus:input_type -> router.GetOnlineStatusRequest
+	11, // 12: router.RouterService.SetPresence:input_type -> router.SetPresenceRequest
+	13, // 13: router.RouterService.BatchGetRoutes:input_type -> router.BatchGetRoutesRequest
+	16, // 14: router.RouterService.BatchGetOnlineStatus:input_type -> router.BatchGetOnlineStatusRequest
+	19, // 15: router.RouterService.PushMessage:input_type -> router.PushMessageRequest
+	21, // 16: router.RouterService.PushEvent:input_type -> router.PushEventRequest
+	23, // 17: router.RouterService.SubscribePresence:input_type -> router.SubscribePresenceRequest
+	26, // 18: router.RouterService.UnsubscribePresence:input_type -> router.UnsubscribePresenceRequest
+	28, // 19: router.RouterService.KickDevice:input_type -> router.KickDeviceRequest
+	1,  // 20: router.RouterService.RegisterRoute:output_type -> router.RegisterRouteResponse
+	3,  // 21: router.RouterService.KeepAlive:output_type -> router.KeepAliveResponse
+	5,  // 22: router.RouterService.GetRoute:output_type -> router.GetRouteResponse
+	8,  // 23: router.RouterService.UnregisterRoute:output_type -> router.UnregisterRouteResponse
+	10, // 24: router.RouterService.GetOnlineStatus:output_type -> router.GetOnlineStatusResponse
+	12, // 25: router.RouterService.SetPresence:output_type -> router.SetPresenceResponse
+	14, // 26: router.RouterService.BatchGetRoutes:output_type -> router.BatchGetRoutesResponse
+	17, // 27: router.RouterService.BatchGetOnlineStatus:output_type -> router.BatchGetOnlineStatusResponse
+	20, // 28: router.RouterService.PushMessage:output_type -> router.PushMessageResponse
+	22, // 29: router.RouterService.PushEvent:output_type -> router.PushEventResponse
+	24, // 30: router.RouterService.SubscribePresence:output_type -> router.SubscribePresenceResponse
+	27, // 31: router.RouterService.UnsubscribePresence:output_type -> router.UnsubscribePresenceResponse
+	29, // 32: router.RouterService.KickDevice:output_type -> router.KickDeviceResponse
+	20, // [20:33] is the sub-list for method output_type
+	7,  // [7:20] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_router_router_proto_init() }
@@ -669,7 +1990,7 @@ func file_router_router_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_router_router_proto_rawDesc), len(file_router_router_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   11,
+			NumMessages:   30,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,22 +19,54 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	RouterService_RegisterRoute_FullMethodName   = "/router.RouterService/RegisterRoute"
-	RouterService_KeepAlive_FullMethodName       = "/router.RouterService/KeepAlive"
-	RouterService_GetRoute_FullMethodName        = "/router.RouterService/GetRoute"
-	RouterService_UnregisterRoute_FullMethodName = "/router.RouterService/UnregisterRoute"
-	RouterService_GetOnlineStatus_FullMethodName = "/router.RouterService/GetOnlineStatus"
+	RouterService_RegisterRoute_FullMethodName        = "/router.RouterService/RegisterRoute"
+	RouterService_KeepAlive_FullMethodName            = "/router.RouterService/KeepAlive"
+	RouterService_GetRoute_FullMethodName             = "/router.RouterService/GetRoute"
+	RouterService_UnregisterRoute_FullMethodName      = "/router.RouterService/UnregisterRoute"
+	RouterService_GetOnlineStatus_FullMethodName      = "/router.RouterService/GetOnlineStatus"
+	RouterService_SetPresence_FullMethodName          = "/router.RouterService/SetPresence"
+	RouterService_BatchGetRoutes_FullMethodName       = "/router.RouterService/BatchGetRoutes"
+	RouterService_BatchGetOnlineStatus_FullMethodName = "/router.RouterService/BatchGetOnlineStatus"
+	RouterService_PushMessage_FullMethodName          = "/router.RouterService/PushMessage"
+	RouterService_PushEvent_FullMethodName            = "/router.RouterService/PushEvent"
+	RouterService_SubscribePresence_FullMethodName    = "/router.RouterService/SubscribePresence"
+	RouterService_UnsubscribePresence_FullMethodName  = "/router.RouterService/UnsubscribePresence"
+	RouterService_KickDevice_FullMethodName           = "/router.RouterService/KickDevice"
 )
 
 // RouterServiceClient is the client API for RouterService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// RouterService 路由服务
+// Router service for managing user connections and online status
 type RouterServiceClient interface {
+	// RegisterRoute 注册路由 (用户连接到Gateway时调用) / Register route (called when user connects to Gateway)
 	RegisterRoute(ctx context.Context, in *RegisterRouteRequest, opts ...grpc.CallOption) (*RegisterRouteResponse, error)
+	// KeepAlive 保持连接活跃 / Keep connection alive
 	KeepAlive(ctx context.Context, in *KeepAliveRequest, opts ...grpc.CallOption) (*KeepAliveResponse, error)
+	// GetRoute 获取用户路由信息 / Get user routing information
 	GetRoute(ctx context.Context, in *GetRouteRequest, opts ...grpc.CallOption) (*GetRouteResponse, error)
+	// UnregisterRoute 注销路由 (用户断开连接时调用) / Unregister route (called when user disconnects)
 	UnregisterRoute(ctx context.Context, in *UnregisterRouteRequest, opts ...grpc.CallOption) (*UnregisterRouteResponse, error)
+	// GetOnlineStatus 获取用户在线状态 / Get user online status
 	GetOnlineStatus(ctx context.Context, in *GetOnlineStatusRequest, opts ...grpc.CallOption) (*GetOnlineStatusResponse, error)
+	// SetPresence 设置手动状态和自定义状态 / Set manual status and custom status
+	SetPresence(ctx context.Context, in *SetPresenceRequest, opts ...grpc.CallOption) (*SetPresenceResponse, error)
+	// BatchGetRoutes 批量获取多个用户的路由信息 / Get routing information of many users in one call
+	BatchGetRoutes(ctx context.Context, in *BatchGetRoutesRequest, opts ...grpc.CallOption) (*BatchGetRoutesResponse, error)
+	// BatchGetOnlineStatus 批量获取多个用户的在线状态 / Get online status of many users in one call
+	BatchGetOnlineStatus(ctx context.Context, in *BatchGetOnlineStatusRequest, opts ...grpc.CallOption) (*BatchGetOnlineStatusResponse, error)
+	// PushMessage 将新消息通知推送到接收者所在的Gateway / Push new message notification to the recipients' gateways
+	PushMessage(ctx context.Context, in *PushMessageRequest, opts ...grpc.CallOption) (*PushMessageResponse, error)
+	// PushEvent 将实时事件 (如输入状态) 推送到接收者所在的Gateway，不落库 / Push a transient event (e.g. typing) to the recipients' gateways without persisting it
+	PushEvent(ctx context.Context, in *PushEventRequest, opts ...grpc.CallOption) (*PushEventResponse, error)
+	// SubscribePresence 订阅用户在线状态变化并返回当前状态 / Subscribe to presence changes of users and return their current status
+	SubscribePresence(ctx context.Context, in *SubscribePresenceRequest, opts ...grpc.CallOption) (*SubscribePresenceResponse, error)
+	// UnsubscribePresence 取消订阅用户在线状态 / Unsubscribe from presence changes of users
+	UnsubscribePresence(ctx context.Context, in *UnsubscribePresenceRequest, opts ...grpc.CallOption) (*UnsubscribePresenceResponse, error)
+	// KickDevice 将设备踢下线、吊销其令牌并移除路由 / Disconnect a device, revoke its token and remove its route
+	KickDevice(ctx context.Context, in *KickDeviceRequest, opts ...grpc.CallOption) (*KickDeviceResponse, error)
 }
 
 type routerServiceClient struct {
@@ -95,15 +127,119 @@ func (c *routerServiceClient) GetOnlineStatus(ctx context.Context, in *GetOnline
 	return out, nil
 }
 
+func (c *routerServiceClient) SetPresence(ctx context.Context, in *SetPresenceRequest, opts ...grpc.CallOption) (*SetPresenceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetPresenceResponse)
+	err := c.cc.Invoke(ctx, RouterService_SetPresence_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerServiceClient) BatchGetRoutes(ctx context.Context, in *BatchGetRoutesRequest, opts ...grpc.CallOption) (*BatchGetRoutesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetRoutesResponse)
+	err := c.cc.Invoke(ctx, RouterService_BatchGetRoutes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerServiceClient) BatchGetOnlineStatus(ctx context.Context, in *BatchGetOnlineStatusRequest, opts ...grpc.CallOption) (*BatchGetOnlineStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetOnlineStatusResponse)
+	err := c.cc.Invoke(ctx, RouterService_BatchGetOnlineStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerServiceClient) PushMessage(ctx context.Context, in *PushMessageRequest, opts ...grpc.CallOption) (*PushMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PushMessageResponse)
+	err := c.cc.Invoke(ctx, RouterService_PushMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerServiceClient) PushEvent(ctx context.Context, in *PushEventRequest, opts ...grpc.CallOption) (*PushEventResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PushEventResponse)
+	err := c.cc.Invoke(ctx, RouterService_PushEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerServiceClient) SubscribePresence(ctx context.Context, in *SubscribePresenceRequest, opts ...grpc.CallOption) (*SubscribePresenceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubscribePresenceResponse)
+	err := c.cc.Invoke(ctx, RouterService_SubscribePresence_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerServiceClient) UnsubscribePresence(ctx context.Context, in *UnsubscribePresenceRequest, opts ...grpc.CallOption) (*UnsubscribePresenceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnsubscribePresenceResponse)
+	err := c.cc.Invoke(ctx, RouterService_UnsubscribePresence_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerServiceClient) KickDevice(ctx context.Context, in *KickDeviceRequest, opts ...grpc.CallOption) (*KickDeviceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(KickDeviceResponse)
+	err := c.cc.Invoke(ctx, RouterService_KickDevice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RouterServiceServer is the server API for RouterService service.
 // All implementations must embed UnimplementedRouterServiceServer
 // for forward compatibility.
+//
+// RouterService 路由服务
+// Router service for managing user connections and online status
 type RouterServiceServer interface {
+	// RegisterRoute 注册路由 (用户连接到Gateway时调用) / Register route (called when user connects to Gateway)
 	RegisterRoute(context.Context, *RegisterRouteRequest) (*RegisterRouteResponse, error)
+	// KeepAlive 保持连接活跃 / Keep connection alive
 	KeepAlive(context.Context, *KeepAliveRequest) (*KeepAliveResponse, error)
+	// GetRoute 获取用户路由信息 / Get user routing information
 	GetRoute(context.Context, *GetRouteRequest) (*GetRouteResponse, error)
+	// UnregisterRoute 注销路由 (用户断开连接时调用) / Unregister route (called when user disconnects)
 	UnregisterRoute(context.Context, *UnregisterRouteRequest) (*UnregisterRouteResponse, error)
+	// GetOnlineStatus 获取用户在线状态 / Get user online status
 	GetOnlineStatus(context.Context, *GetOnlineStatusRequest) (*GetOnlineStatusResponse, error)
+	// SetPresence 设置手动状态和自定义状态 / Set manual status and custom status
+	SetPresence(context.Context, *SetPresenceRequest) (*SetPresenceResponse, error)
+	// BatchGetRoutes 批量获取多个用户的路由信息 / Get routing information of many users in one call
+	BatchGetRoutes(context.Context, *BatchGetRoutesRequest) (*BatchGetRoutesResponse, error)
+	// BatchGetOnlineStatus 批量获取多个用户的在线状态 / Get online status of many users in one call
+	BatchGetOnlineStatus(context.Context, *BatchGetOnlineStatusRequest) (*BatchGetOnlineStatusResponse, error)
+	// PushMessage 将新消息通知推送到接收者所在的Gateway / Push new message notification to the recipients' gateways
+	PushMessage(context.Context, *PushMessageRequest) (*PushMessageResponse, error)
+	// PushEvent 将实时事件 (如输入状态) 推送到接收者所在的Gateway，不落库 / Push a transient event (e.g. typing) to the recipients' gateways without persisting it
+	PushEvent(context.Context, *PushEventRequest) (*PushEventResponse, error)
+	// SubscribePresence 订阅用户在线状态变化并返回当前状态 / Subscribe to presence changes of users and return their current status
+	SubscribePresence(context.Context, *SubscribePresenceRequest) (*SubscribePresenceResponse, error)
+	// UnsubscribePresence 取消订阅用户在线状态 / Unsubscribe from presence changes of users
+	UnsubscribePresence(context.Context, *UnsubscribePresenceRequest) (*UnsubscribePresenceResponse, error)
+	// KickDevice 将设备踢下线、吊销其令牌并移除路由 / Disconnect a device, revoke its token and remove its route
+	KickDevice(context.Context, *KickDeviceRequest) (*KickDeviceResponse, error)
 	mustEmbedUnimplementedRouterServiceServer()
 }
 
@@ -129,6 +265,30 @@ func (UnimplementedRouterServiceServer) UnregisterRoute(context.Context, *Unregi
 func (UnimplementedRouterServiceServer) GetOnlineStatus(context.Context, *GetOnlineStatusRequest) (*GetOnlineStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetOnlineStatus not implemented")
 }
+func (UnimplementedRouterServiceServer) SetPresence(context.Context, *SetPresenceRequest) (*SetPresenceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPresence not implemented")
+}
+func (UnimplementedRouterServiceServer) BatchGetRoutes(context.Context, *BatchGetRoutesRequest) (*BatchGetRoutesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetRoutes not implemented")
+}
+func (UnimplementedRouterServiceServer) BatchGetOnlineStatus(context.Context, *BatchGetOnlineStatusRequest) (*BatchGetOnlineStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetOnlineStatus not implemented")
+}
+func (UnimplementedRouterServiceServer) PushMessage(context.Context, *PushMessageRequest) (*PushMessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PushMessage not implemented")
+}
+func (UnimplementedRouterServiceServer) PushEvent(context.Context, *PushEventRequest) (*PushEventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PushEvent not implemented")
+}
+func (UnimplementedRouterServiceServer) SubscribePresence(context.Context, *SubscribePresenceRequest) (*SubscribePresenceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubscribePresence not implemented")
+}
+func (UnimplementedRouterServiceServer) UnsubscribePresence(context.Context, *UnsubscribePresenceRequest) (*UnsubscribePresenceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnsubscribePresence not implemented")
+}
+func (UnimplementedRouterServiceServer) KickDevice(context.Context, *KickDeviceRequest) (*KickDeviceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method KickDevice not implemented")
+}
 func (UnimplementedRouterServiceServer) mustEmbedUnimplementedRouterServiceServer() {}
 func (UnimplementedRouterServiceServer) testEmbeddedByValue()                       {}
 
@@ -240,6 +400,150 @@ func _RouterService_GetOnlineStatus_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RouterService_SetPresence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPresenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServiceServer).SetPresence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RouterService_SetPresence_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServiceServer).SetPresence(ctx, req.(*SetPresenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RouterService_BatchGetRoutes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetRoutesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServiceServer).BatchGetRoutes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RouterService_BatchGetRoutes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServiceServer).BatchGetRoutes(ctx, req.(*BatchGetRoutesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RouterService_BatchGetOnlineStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetOnlineStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServiceServer).BatchGetOnlineStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RouterService_BatchGetOnlineStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServiceServer).BatchGetOnlineStatus(ctx, req.(*BatchGetOnlineStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RouterService_PushMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PushMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServiceServer).PushMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RouterService_PushMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServiceServer).PushMessage(ctx, req.(*PushMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RouterService_PushEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PushEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServiceServer).PushEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RouterService_PushEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServiceServer).PushEvent(ctx, req.(*PushEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RouterService_SubscribePresence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubscribePresenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServiceServer).SubscribePresence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RouterService_SubscribePresence_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServiceServer).SubscribePresence(ctx, req.(*SubscribePresenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RouterService_UnsubscribePresence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnsubscribePresenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServiceServer).UnsubscribePresence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RouterService_UnsubscribePresence_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServiceServer).UnsubscribePresence(ctx, req.(*UnsubscribePresenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RouterService_KickDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KickDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServiceServer).KickDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RouterService_KickDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServiceServer).KickDevice(ctx, req.(*KickDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // RouterService_ServiceDesc is the grpc.ServiceDesc for RouterService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -267,6 +571,38 @@ var RouterService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetOnlineStatus",
 			Handler:    _RouterService_GetOnlineStatus_Handler,
 		},
+		{
+			MethodName: "SetPresence",
+			Handler:    _RouterService_SetPresence_Handler,
+		},
+		{
+			MethodName: "BatchGetRoutes",
+			Handler:    _RouterService_BatchGetRoutes_Handler,
+		},
+		{
+			MethodName: "BatchGetOnlineStatus",
+			Handler:    _RouterService_BatchGetOnlineStatus_Handler,
+		},
+		{
+			MethodName: "PushMessage",
+			Handler:    _RouterService_PushMessage_Handler,
+		},
+		{
+			MethodName: "PushEvent",
+			Handler:    _RouterService_PushEvent_Handler,
+		},
+		{
+			MethodName: "SubscribePresence",
+			Handler:    _RouterService_SubscribePresence_Handler,
+		},
+		{
+			MethodName: "UnsubscribePresence",
+			Handler:    _RouterService_UnsubscribePresence_Handler,
+		},
+		{
+			MethodName: "KickDevice",
+			Handler:    _RouterService_KickDevice_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "router/router.proto",
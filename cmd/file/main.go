@@ -14,6 +14,7 @@ import (
 	"github.com/dollarkillerx/im-system/pkg/config"
 	"github.com/dollarkillerx/im-system/pkg/database"
 	"github.com/dollarkillerx/im-system/pkg/logger"
+	redisutil "github.com/dollarkillerx/im-system/pkg/redis"
 	"github.com/dollarkillerx/im-system/pkg/registry"
 	"github.com/dollarkillerx/im-system/pkg/s3"
 	"github.com/gin-gonic/gin"
@@ -53,7 +54,23 @@ func main() {
 	}
 
 	// Create JWT manager
-	jwtManager := auth.NewJWTManager(cfg.JWT.Secret, cfg.JWT.Expiry)
+	jwtOptions, err := cfg.JWT.Options()
+	if err != nil {
+		logger.Log.Fatal("Invalid JWT config", zap.Error(err))
+	}
+	jwtManager := auth.NewJWTManager(cfg.JWT.Secret, cfg.JWT.Expiry).WithOptions(jwtOptions)
+	if cfg.JWT.JWKSURL != "" {
+		// Verify asymmetric tokens with the public keys published by the user service
+		jwtManager.WithKeys(auth.NewJWKSClient(cfg.JWT.JWKSURL, cfg.JWT.JWKSRefresh))
+	}
+
+	// Connect to Redis for token revocation checks
+	redisClient, err := redisutil.NewRedisClient(&cfg.Redis)
+	if err != nil {
+		logger.Log.Fatal("Failed to connect to Redis", zap.Error(err))
+	}
+	defer redisClient.Close()
+	revocations := auth.NewRevocationStore(redisClient, cfg.JWT.Expiry)
 
 	// Create repository and service
 	repo := file.NewRepository(db)
@@ -76,7 +93,7 @@ func main() {
 	{
 		// 需要认证的路由
 		files := v1.Group("/files")
-		files.Use(file.AuthMiddleware(jwtManager))
+		files.Use(file.AuthMiddleware(jwtManager, revocations))
 		{
 			files.POST("", handler.UploadFile)               // 上传文件
 			files.GET("", handler.ListUserFiles)             // 获取文件列表
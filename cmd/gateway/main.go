@@ -15,6 +15,7 @@ import (
 	"github.com/dollarkillerx/im-system/pkg/config"
 	"github.com/dollarkillerx/im-system/pkg/interceptor"
 	"github.com/dollarkillerx/im-system/pkg/logger"
+	redisutil "github.com/dollarkillerx/im-system/pkg/redis"
 	"github.com/dollarkillerx/im-system/pkg/registry"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -34,7 +35,22 @@ func main() {
 	defer logger.Sync()
 
 	// Create JWT manager for authentication
-	jwtManager := auth.NewJWTManager(cfg.JWT.Secret, cfg.JWT.Expiry)
+	jwtOptions, err := cfg.JWT.Options()
+	if err != nil {
+		logger.Log.Fatal("Invalid JWT config", zap.Error(err))
+	}
+	jwtManager := auth.NewJWTManager(cfg.JWT.Secret, cfg.JWT.Expiry).WithOptions(jwtOptions)
+	if cfg.JWT.JWKSURL != "" {
+		// Verify asymmetric tokens with the public keys published by the user service
+		jwtManager.WithKeys(auth.NewJWKSClient(cfg.JWT.JWKSURL, cfg.JWT.JWKSRefresh))
+	}
+
+	// Connect to Redis for token revocation checks
+	redisClient, err := redisutil.NewRedisClient(&cfg.Redis)
+	if err != nil {
+		logger.Log.Fatal("Failed to connect to Redis", zap.Error(err))
+	}
+	defer redisClient.Close()
 
 	// Create Consul registry for service discovery
 	consulRegistry, err := registry.NewConsulRegistry(&registry.ServiceConfig{
@@ -67,13 +83,22 @@ func main() {
 	grpcServerImpl := gateway.NewGRPCServer(connMgr, handler, clients, gatewayAddr)
 
 	// Create interceptor config
-	// Gateway 需要认证，所有方法都需要 Token
+	// Gateway 需要认证，客户端方法都需要 Token
+	// 推送和踢下线接口仅供内部服务调用，使用共享密钥认证
+	// 被踢下线设备的令牌已吊销，不能再重新连接
 	interceptorConfig := interceptor.ChainConfig{
-		JWTManager:     jwtManager,
-		PublicMethods:  []string{}, // Gateway 没有公开方法
-		EnableAuth:     true,
-		EnableLogging:  true,
-		EnableRecovery: true,
+		JWTManager:    jwtManager,
+		PublicMethods: []string{}, // Gateway 没有公开方法
+		InternalMethods: []string{
+			"/gateway.GatewayService/PushToUsers",
+			"/gateway.GatewayService/PushToDevices",
+			"/gateway.GatewayService/KickDevices",
+		},
+		InternalToken:     cfg.Internal.Token,
+		RevocationChecker: auth.NewRevocationStore(redisClient, cfg.JWT.Expiry),
+		EnableAuth:        true,
+		EnableLogging:     true,
+		EnableRecovery:    true,
 	}
 
 	// Create gRPC server with interceptors
@@ -58,7 +58,10 @@ func main() {
 
 	// Create service
 	repo := message.NewRepository(db)
-	service := message.NewService(repo, routerClient)
+	service := message.NewService(repo, routerClient).
+		WithIdempotencyWindow(cfg.Message.IdempotencyWindow).
+		WithRecallWindow(cfg.Message.RecallWindow).
+		WithGroupReadReceipts(message.GroupReadReceiptMode(cfg.Message.GroupReadReceipts), cfg.Message.GroupReadReceiptMaxMembers)
 	grpcServer := message.NewGRPCServer(service)
 
 	// Create interceptor config
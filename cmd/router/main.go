@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"os"
@@ -9,6 +10,7 @@ import (
 
 	routerpb "github.com/dollarkillerx/im-system/api/proto/router"
 	"github.com/dollarkillerx/im-system/internal/router"
+	"github.com/dollarkillerx/im-system/pkg/auth"
 	"github.com/dollarkillerx/im-system/pkg/config"
 	"github.com/dollarkillerx/im-system/pkg/logger"
 	redisutil "github.com/dollarkillerx/im-system/pkg/redis"
@@ -38,7 +40,13 @@ func main() {
 	defer redisClient.Close()
 
 	// Create service
-	service := router.NewService(redisClient)
+	service := router.NewService(redisClient, router.NewGatewayClient(cfg.Internal.Token)).
+		WithPresenceDebounce(cfg.Presence.Debounce).
+		WithDevicePolicy(router.DevicePolicy{
+			MaxDevices:     cfg.Devices.MaxDevices,
+			PlatformLimits: cfg.Devices.PlatformLimits,
+		}).
+		WithTokenRevoker(auth.NewRevocationStore(redisClient, cfg.JWT.Expiry))
 	grpcServer := router.NewGRPCServer(service)
 
 	// Create gRPC server
@@ -81,6 +89,11 @@ func main() {
 		}
 	}()
 
+	// Publish offline presence for expired routes and debounced disconnects
+	sweepCtx, stopSweeper := context.WithCancel(context.Background())
+	defer stopSweeper()
+	go service.RunPresenceSweeper(sweepCtx, cfg.Presence.SweepInterval)
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
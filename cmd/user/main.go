@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	userpb "github.com/dollarkillerx/im-system/api/proto/user"
 	"github.com/dollarkillerx/im-system/internal/user"
@@ -13,6 +16,8 @@ import (
 	"github.com/dollarkillerx/im-system/pkg/config"
 	"github.com/dollarkillerx/im-system/pkg/database"
 	"github.com/dollarkillerx/im-system/pkg/logger"
+	"github.com/dollarkillerx/im-system/pkg/mailer"
+	redisutil "github.com/dollarkillerx/im-system/pkg/redis"
 	"github.com/dollarkillerx/im-system/pkg/registry"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -38,24 +43,53 @@ func main() {
 	}
 	defer db.Close()
 
-	// Create JWT manager
-	jwtManager := auth.NewJWTManager(cfg.JWT.Secret, cfg.JWT.Expiry)
-
-	// Create service
-	repo := user.NewRepository(db)
-	service := user.NewService(repo, jwtManager)
-	grpcServer := user.NewGRPCServer(service)
+	// Connect to Redis for refresh tokens and token revocation
+	redisClient, err := redisutil.NewRedisClient(&cfg.Redis)
+	if err != nil {
+		logger.Log.Fatal("Failed to connect to Redis", zap.Error(err))
+	}
+	defer redisClient.Close()
 
-	// Create gRPC server
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.User.GRPCPort))
+	// Create JWT manager, signing with the configured asymmetric keys if any
+	jwtOptions, err := cfg.JWT.Options()
 	if err != nil {
-		logger.Log.Fatal("Failed to listen", zap.Error(err))
+		logger.Log.Fatal("Invalid JWT config", zap.Error(err))
+	}
+	jwtManager := auth.NewJWTManager(cfg.JWT.Secret, cfg.JWT.Expiry).WithOptions(jwtOptions)
+	keyFiles := make([]auth.KeyFile, 0, len(cfg.JWT.Keys))
+	for _, key := range cfg.JWT.Keys {
+		var activeFrom time.Time
+		if key.ActiveFrom != "" {
+			activeFrom, err = time.Parse(time.RFC3339, key.ActiveFrom)
+			if err != nil {
+				logger.Log.Fatal("Invalid active_from for JWT signing key", zap.String("kid", key.ID), zap.Error(err))
+			}
+		}
+		keyFiles = append(keyFiles, auth.KeyFile{
+			ID:         key.ID,
+			Algorithm:  key.Algorithm,
+			Path:       key.PrivateKeyFile,
+			ActiveFrom: activeFrom,
+		})
+	}
+	keySet, err := auth.LoadKeySet(cfg.JWT.Expiry, keyFiles)
+	if err != nil {
+		logger.Log.Fatal("Failed to load JWT signing keys", zap.Error(err))
+	}
+	if keySet != nil {
+		jwtManager.WithKeys(keySet)
 	}
 
-	server := grpc.NewServer()
-	userpb.RegisterUserServiceServer(server, grpcServer)
+	// Create mailer for password reset and email verification
+	mail, err := mailer.New(&cfg.Mail)
+	if err != nil {
+		logger.Log.Fatal("Failed to create mailer", zap.Error(err))
+	}
+	if mail == nil {
+		logger.Log.Warn("No mail driver configured, password reset and email verification are disabled")
+	}
 
-	// Register with Consul
+	// Create Consul registry for service discovery
 	consulRegistry, err := registry.NewConsulRegistry(&registry.ServiceConfig{
 		Address:        cfg.Consul.Address,
 		Scheme:         cfg.Consul.Scheme,
@@ -70,6 +104,56 @@ func main() {
 		logger.Log.Fatal("Failed to create Consul registry", zap.Error(err))
 	}
 
+	// Create service
+	repo := user.NewRepository(db)
+	service := user.NewService(repo, jwtManager).
+		WithTokenStores(
+			auth.NewRefreshStore(redisClient, cfg.JWT.RefreshExpiry),
+			auth.NewRevocationStore(redisClient, cfg.JWT.Expiry),
+		).
+		WithMailer(mail, user.AccountEmails{
+			PasswordResetTTL: cfg.Account.PasswordResetTTL,
+			PasswordResetURL: cfg.Account.PasswordResetURL,
+			EmailVerifyTTL:   cfg.Account.EmailVerifyTTL,
+			EmailVerifyURL:   cfg.Account.EmailVerifyURL,
+
+			EmailVerifyResendInterval: cfg.Account.EmailVerifyResendInterval,
+		}).
+		WithPasswordPolicy(user.PasswordPolicy{
+			MinLength:     cfg.Account.PasswordPolicy.MinLength,
+			MaxLength:     cfg.Account.PasswordPolicy.MaxLength,
+			RequireLetter: cfg.Account.PasswordPolicy.RequireLetter,
+			RequireDigit:  cfg.Account.PasswordPolicy.RequireDigit,
+			RequireSymbol: cfg.Account.PasswordPolicy.RequireSymbol,
+		}).
+		WithDeviceKicker(user.NewRouterClient(consulRegistry)).
+		WithTwoFactor(
+			user.NewChallengeStore(redisClient, cfg.Account.TwoFactor.ChallengeTTL, cfg.Account.TwoFactor.MaxAttempts),
+			cfg.Account.TwoFactor.Issuer,
+		)
+	if throttle := cfg.Account.LoginThrottle; throttle.Enabled {
+		service.WithLoginLimiter(user.NewLoginLimiter(redisClient, user.LoginThrottle{
+			Window:          throttle.Window,
+			MaxUserAttempts: throttle.MaxUserAttempts,
+			MaxIPAttempts:   throttle.MaxIPAttempts,
+			Lockout:         throttle.Lockout,
+			BaseDelay:       throttle.BaseDelay,
+			MaxDelay:        throttle.MaxDelay,
+		}))
+	}
+	grpcServer := user.NewGRPCServer(service).
+		WithTrustForwardedFor(cfg.Account.LoginThrottle.TrustForwardedFor)
+
+	// Create gRPC server
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.User.GRPCPort))
+	if err != nil {
+		logger.Log.Fatal("Failed to listen", zap.Error(err))
+	}
+
+	server := grpc.NewServer()
+	userpb.RegisterUserServiceServer(server, grpcServer)
+
+	// Register with Consul
 	if err := consulRegistry.Register([]string{"grpc", "user"}, map[string]string{"version": "1.0.0"}); err != nil {
 		logger.Log.Fatal("Failed to register with Consul", zap.Error(err))
 	}
@@ -86,11 +170,37 @@ func main() {
 		}
 	}()
 
+	// Publish the JWKS so other services can verify tokens without the private keys
+	var jwksServer *http.Server
+	if keySet != nil && cfg.Server.User.HTTPPort > 0 {
+		mux := http.NewServeMux()
+		mux.Handle(auth.JWKSPath, auth.JWKSHandler(keySet))
+		jwksServer = &http.Server{
+			Addr:    fmt.Sprintf(":%d", cfg.Server.User.HTTPPort),
+			Handler: mux,
+		}
+
+		go func() {
+			if err := jwksServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Log.Fatal("Failed to serve JWKS", zap.Error(err))
+			}
+		}()
+
+		logger.Log.Info("JWKS endpoint started",
+			zap.Int("port", cfg.Server.User.HTTPPort),
+		)
+	}
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	logger.Log.Info("Shutting down user service...")
+	if jwksServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = jwksServer.Shutdown(ctx)
+	}
 	server.GracefulStop()
 }
@@ -9,7 +9,8 @@ import (
 )
 
 // AuthMiddleware JWT 认证中间件
-func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
+// revocation 不为空时拒绝已吊销的令牌，吊销状态无法确认时返回 503
+func AuthMiddleware(jwtManager *auth.JWTManager, revocation auth.RevocationChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 从 Header 获取 Token
 		authHeader := c.GetHeader("Authorization")
@@ -37,6 +38,21 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 			return
 		}
 
+		// 检查令牌是否已吊销 (退出登录、被踢下线等)
+		if revocation != nil {
+			revoked, err := revocation.IsRevoked(c.Request.Context(), claims)
+			if err != nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "failed to check token revocation"})
+				c.Abort()
+				return
+			}
+			if revoked {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "token has been revoked"})
+				c.Abort()
+				return
+			}
+		}
+
 		// 将用户信息注入到 context
 		c.Set("user_id", claims.UserID)
 		c.Set("device_id", claims.DeviceID)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	gatewaypb "github.com/dollarkillerx/im-system/api/proto/gateway"
 	messagepb "github.com/dollarkillerx/im-system/api/proto/message"
 	routerpb "github.com/dollarkillerx/im-system/api/proto/router"
 	"google.golang.org/grpc"
@@ -29,7 +30,7 @@ func NewServiceClients(discovery ServiceDiscovery) *ServiceClients {
 }
 
 // SendMessage 发送消息到 Message 服务
-func (c *ServiceClients) SendMessage(ctx context.Context, convID int64, senderID int64, convType messagepb.ConversationType, body map[string]interface{}, replyTo *string, mentions []int64) (*messagepb.SendMessageResponse, error) {
+func (c *ServiceClients) SendMessage(ctx context.Context, convID int64, senderID int64, convType messagepb.ConversationType, body map[string]interface{}, replyTo *string, mentions []int64, idempotencyKey string) (*messagepb.SendMessageResponse, error) {
 	addr, err := c.discovery.GetServiceAddress("message-service")
 	if err != nil {
 		return nil, fmt.Errorf("failed to discover message service: %w", err)
@@ -48,18 +49,23 @@ func (c *ServiceClients) SendMessage(ctx context.Context, convID int64, senderID
 		return nil, fmt.Errorf("failed to convert body: %w", err)
 	}
 
-	return client.SendMessage(ctx, &messagepb.SendMessageRequest{
+	req := &messagepb.SendMessageRequest{
 		ConvId:   convID,
 		SenderId: senderID,
 		ConvType: convType,
 		Body:     bodyStruct,
 		ReplyTo:  replyTo,
 		Mentions: mentions,
-	})
+	}
+	if idempotencyKey != "" {
+		req.IdempotencyKey = &idempotencyKey
+	}
+
+	return client.SendMessage(ctx, req)
 }
 
 // PullMessages 从 Message 服务拉取消息
-func (c *ServiceClients) PullMessages(ctx context.Context, convID int64, sinceSeq int64, limit int32) (*messagepb.PullMessagesResponse, error) {
+func (c *ServiceClients) PullMessages(ctx context.Context, convID int64, userID int64, direction messagepb.PullDirection, sinceSeq int64, beforeSeq int64, aroundSeq int64, limit int32) (*messagepb.PullMessagesResponse, error) {
 	addr, err := c.discovery.GetServiceAddress("message-service")
 	if err != nil {
 		return nil, fmt.Errorf("failed to discover message service: %w", err)
@@ -74,14 +80,186 @@ func (c *ServiceClients) PullMessages(ctx context.Context, convID int64, sinceSe
 	client := messagepb.NewMessageServiceClient(conn)
 
 	return client.PullMessages(ctx, &messagepb.PullMessagesRequest{
-		ConvId:   convID,
-		SinceSeq: sinceSeq,
-		Limit:    limit,
+		ConvId:    convID,
+		SinceSeq:  sinceSeq,
+		Limit:     limit,
+		UserId:    userID,
+		Direction: direction,
+		BeforeSeq: beforeSeq,
+		AroundSeq: aroundSeq,
+	})
+}
+
+// ListConversations 从 Message 服务获取用户的会话列表
+func (c *ServiceClients) ListConversations(ctx context.Context, userID int64, cursor string, limit int32) (*messagepb.ListConversationsResponse, error) {
+	addr, err := c.discovery.GetServiceAddress("message-service")
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover message service: %w", err)
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to message service: %w", err)
+	}
+	defer conn.Close()
+
+	client := messagepb.NewMessageServiceClient(conn)
+
+	return client.ListConversations(ctx, &messagepb.ListConversationsRequest{
+		UserId: userID,
+		Cursor: cursor,
+		Limit:  limit,
+	})
+}
+
+// FilterPeers 从 Message 服务筛选出与用户至少共同在一个会话中的用户
+func (c *ServiceClients) FilterPeers(ctx context.Context, userID int64, userIDs []int64) ([]int64, error) {
+	addr, err := c.discovery.GetServiceAddress("message-service")
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover message service: %w", err)
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to message service: %w", err)
+	}
+	defer conn.Close()
+
+	client := messagepb.NewMessageServiceClient(conn)
+
+	resp, err := client.FilterPeers(ctx, &messagepb.FilterPeersRequest{
+		UserId:  userID,
+		UserIds: userIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.UserIds, nil
+}
+
+// GetOrCreateDirectConversation 从 Message 服务获取或创建单聊会话
+func (c *ServiceClients) GetOrCreateDirectConversation(ctx context.Context, userID int64, peerID int64) (*messagepb.GetOrCreateDirectConversationResponse, error) {
+	addr, err := c.discovery.GetServiceAddress("message-service")
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover message service: %w", err)
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to message service: %w", err)
+	}
+	defer conn.Close()
+
+	client := messagepb.NewMessageServiceClient(conn)
+
+	return client.GetOrCreateDirectConversation(ctx, &messagepb.GetOrCreateDirectConversationRequest{
+		UserId: userID,
+		PeerId: peerID,
+	})
+}
+
+// RecallMessage 调用 Message 服务撤回消息
+func (c *ServiceClients) RecallMessage(ctx context.Context, convID int64, msgID string, operatorID int64) (*messagepb.RecallMessageResponse, error) {
+	addr, err := c.discovery.GetServiceAddress("message-service")
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover message service: %w", err)
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to message service: %w", err)
+	}
+	defer conn.Close()
+
+	client := messagepb.NewMessageServiceClient(conn)
+
+	return client.RecallMessage(ctx, &messagepb.RecallMessageRequest{
+		ConvId:     convID,
+		MsgId:      msgID,
+		OperatorId: operatorID,
 	})
 }
 
+// EditMessage 调用 Message 服务编辑消息
+func (c *ServiceClients) EditMessage(ctx context.Context, convID int64, msgID string, operatorID int64, body map[string]interface{}) (*messagepb.EditMessageResponse, error) {
+	addr, err := c.discovery.GetServiceAddress("message-service")
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover message service: %w", err)
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to message service: %w", err)
+	}
+	defer conn.Close()
+
+	client := messagepb.NewMessageServiceClient(conn)
+
+	bodyStruct, err := structpb.NewStruct(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert body: %w", err)
+	}
+
+	return client.EditMessage(ctx, &messagepb.EditMessageRequest{
+		ConvId:     convID,
+		MsgId:      msgID,
+		OperatorId: operatorID,
+		Body:       bodyStruct,
+	})
+}
+
+// UpdateReadSeq 更新 Message 服务中的已读位置
+func (c *ServiceClients) UpdateReadSeq(ctx context.Context, convID int64, userID int64, seq int64) error {
+	addr, err := c.discovery.GetServiceAddress("message-service")
+	if err != nil {
+		return fmt.Errorf("failed to discover message service: %w", err)
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("failed to connect to message service: %w", err)
+	}
+	defer conn.Close()
+
+	client := messagepb.NewMessageServiceClient(conn)
+
+	_, err = client.UpdateReadSeq(ctx, &messagepb.UpdateReadSeqRequest{
+		ConvId: convID,
+		UserId: userID,
+		Seq:    seq,
+	})
+
+	return err
+}
+
+// GetConversation 从 Message 服务获取会话及其成员，调用者不是成员时返回 PermissionDenied
+func (c *ServiceClients) GetConversation(ctx context.Context, convID int64, userID int64) (*messagepb.Conversation, error) {
+	addr, err := c.discovery.GetServiceAddress("message-service")
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover message service: %w", err)
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to message service: %w", err)
+	}
+	defer conn.Close()
+
+	client := messagepb.NewMessageServiceClient(conn)
+
+	resp, err := client.GetConversation(ctx, &messagepb.GetConversationRequest{
+		ConvId: convID,
+		UserId: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Conversation, nil
+}
+
 // RegisterRoute 注册路由到 Router 服务
-func (c *ServiceClients) RegisterRoute(ctx context.Context, userID int64, deviceID string, gatewayAddr string) error {
+func (c *ServiceClients) RegisterRoute(ctx context.Context, userID int64, deviceID string, platform string, gatewayAddr string) error {
 	addr, err := c.discovery.GetServiceAddress("router-service")
 	if err != nil {
 		return fmt.Errorf("failed to discover router service: %w", err)
@@ -99,36 +277,40 @@ func (c *ServiceClients) RegisterRoute(ctx context.Context, userID int64, device
 		UserId:      userID,
 		DeviceId:    deviceID,
 		GatewayAddr: gatewayAddr,
+		Platform:    platform,
 	})
 
 	return err
 }
 
-// KeepAlive 发送心跳到 Router 服务
-func (c *ServiceClients) KeepAlive(ctx context.Context, userID int64, deviceID string) error {
+// KeepAlive 发送心跳到 Router 服务，返回路由是否仍然存在
+func (c *ServiceClients) KeepAlive(ctx context.Context, userID int64, deviceID string) (bool, error) {
 	addr, err := c.discovery.GetServiceAddress("router-service")
 	if err != nil {
-		return fmt.Errorf("failed to discover router service: %w", err)
+		return false, fmt.Errorf("failed to discover router service: %w", err)
 	}
 
 	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
-		return fmt.Errorf("failed to connect to router service: %w", err)
+		return false, fmt.Errorf("failed to connect to router service: %w", err)
 	}
 	defer conn.Close()
 
 	client := routerpb.NewRouterServiceClient(conn)
 
-	_, err = client.KeepAlive(ctx, &routerpb.KeepAliveRequest{
+	resp, err := client.KeepAlive(ctx, &routerpb.KeepAliveRequest{
 		UserId:   userID,
 		DeviceId: deviceID,
 	})
+	if err != nil {
+		return false, err
+	}
 
-	return err
+	return resp.Success, nil
 }
 
-// UnregisterRoute 从 Router 服务注销路由
-func (c *ServiceClients) UnregisterRoute(ctx context.Context, userID int64, deviceID string) error {
+// UnregisterRoute 从 Router 服务注销路由，路由已被其他 Gateway 接管时不会删除
+func (c *ServiceClients) UnregisterRoute(ctx context.Context, userID int64, deviceID string, gatewayAddr string) error {
 	addr, err := c.discovery.GetServiceAddress("router-service")
 	if err != nil {
 		return fmt.Errorf("failed to discover router service: %w", err)
@@ -143,9 +325,152 @@ func (c *ServiceClients) UnregisterRoute(ctx context.Context, userID int64, devi
 	client := routerpb.NewRouterServiceClient(conn)
 
 	_, err = client.UnregisterRoute(ctx, &routerpb.UnregisterRouteRequest{
+		UserId:      userID,
+		DeviceId:    deviceID,
+		GatewayAddr: gatewayAddr,
+	})
+
+	return err
+}
+
+// PushEvent 通过 Router 服务将实时事件推送给在线用户
+func (c *ServiceClients) PushEvent(ctx context.Context, recipientIDs []int64, event *gatewaypb.GatewayMessage) error {
+	addr, err := c.discovery.GetServiceAddress("router-service")
+	if err != nil {
+		return fmt.Errorf("failed to discover router service: %w", err)
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("failed to connect to router service: %w", err)
+	}
+	defer conn.Close()
+
+	client := routerpb.NewRouterServiceClient(conn)
+
+	_, err = client.PushEvent(ctx, &routerpb.PushEventRequest{
+		RecipientIds: recipientIDs,
+		Event:        event,
+	})
+
+	return err
+}
+
+// SubscribePresence 通过 Router 服务订阅用户在线状态
+func (c *ServiceClients) SubscribePresence(ctx context.Context, subscriberID int64, userIDs []int64) (*routerpb.SubscribePresenceResponse, error) {
+	addr, err := c.discovery.GetServiceAddress("router-service")
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover router service: %w", err)
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to router service: %w", err)
+	}
+	defer conn.Close()
+
+	client := routerpb.NewRouterServiceClient(conn)
+
+	return client.SubscribePresence(ctx, &routerpb.SubscribePresenceRequest{
+		SubscriberId: subscriberID,
+		UserIds:      userIDs,
+	})
+}
+
+// UnsubscribePresence 通过 Router 服务取消订阅用户在线状态
+func (c *ServiceClients) UnsubscribePresence(ctx context.Context, subscriberID int64, userIDs []int64) error {
+	addr, err := c.discovery.GetServiceAddress("router-service")
+	if err != nil {
+		return fmt.Errorf("failed to discover router service: %w", err)
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("failed to connect to router service: %w", err)
+	}
+	defer conn.Close()
+
+	client := routerpb.NewRouterServiceClient(conn)
+
+	_, err = client.UnsubscribePresence(ctx, &routerpb.UnsubscribePresenceRequest{
+		SubscriberId: subscriberID,
+		UserIds:      userIDs,
+	})
+
+	return err
+}
+
+// SetPresence 通过 Router 服务设置用户状态
+func (c *ServiceClients) SetPresence(ctx context.Context, userID int64, presenceStatus string, customStatus string, customStatusTTL int64) (*routerpb.SetPresenceResponse, error) {
+	addr, err := c.discovery.GetServiceAddress("router-service")
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover router service: %w", err)
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to router service: %w", err)
+	}
+	defer conn.Close()
+
+	client := routerpb.NewRouterServiceClient(conn)
+
+	return client.SetPresence(ctx, &routerpb.SetPresenceRequest{
+		UserId:          userID,
+		Status:          presenceStatus,
+		CustomStatus:    customStatus,
+		CustomStatusTtl: customStatusTTL,
+	})
+}
+
+// GetRoutes 获取用户在线设备的路由
+func (c *ServiceClients) GetRoutes(ctx context.Context, userID int64) ([]*routerpb.DeviceRoute, error) {
+	addr, err := c.discovery.GetServiceAddress("router-service")
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover router service: %w", err)
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to router service: %w", err)
+	}
+	defer conn.Close()
+
+	client := routerpb.NewRouterServiceClient(conn)
+
+	resp, err := client.GetRoute(ctx, &routerpb.GetRouteRequest{
+		UserId: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Routes, nil
+}
+
+// KickDevice 通过 Router 服务将设备踢下线并吊销其令牌，返回设备是否在线
+func (c *ServiceClients) KickDevice(ctx context.Context, userID int64, deviceID string, reason string) (bool, error) {
+	addr, err := c.discovery.GetServiceAddress("router-service")
+	if err != nil {
+		return false, fmt.Errorf("failed to discover router service: %w", err)
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to router service: %w", err)
+	}
+	defer conn.Close()
+
+	client := routerpb.NewRouterServiceClient(conn)
+
+	resp, err := client.KickDevice(ctx, &routerpb.KickDeviceRequest{
 		UserId:   userID,
 		DeviceId: deviceID,
+		Reason:   reason,
 	})
+	if err != nil {
+		return false, err
+	}
 
-	return err
+	return resp.Kicked, nil
 }
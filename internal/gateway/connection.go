@@ -9,17 +9,24 @@ import (
 	gatewaypb "github.com/dollarkillerx/im-system/api/proto/gateway"
 	"github.com/dollarkillerx/im-system/pkg/logger"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
+// KickReasonReplaced 同一设备建立新连接时，旧连接收到的下线原因
+const KickReasonReplaced = "replaced"
+
 // Connection 表示一个客户端连接
 type Connection struct {
 	UserID     int64
 	DeviceID   string
+	Platform   string
 	Stream     gatewaypb.GatewayService_ConnectServer
 	SendChan   chan *gatewaypb.GatewayMessage
 	CloseChan  chan struct{}
 	LastActive time.Time
 	mu         sync.RWMutex
+	closeOnce  sync.Once
+	closeMsg   *gatewaypb.GatewayMessage // 关闭连接前发送的最后一条消息 (如 KICKED)
 }
 
 // NewConnection 创建新连接
@@ -34,34 +41,71 @@ func NewConnection(userID int64, deviceID string, stream gatewaypb.GatewayServic
 	}
 }
 
-// Send 发送消息到客户端
-func (c *Connection) Send(msg *gatewaypb.GatewayMessage) {
+// Send 发送消息到客户端，返回消息是否已进入发送队列
+func (c *Connection) Send(msg *gatewaypb.GatewayMessage) bool {
+	if c.Closed() {
+		return false
+	}
+
 	select {
 	case c.SendChan <- msg:
+		return true
 	case <-c.CloseChan:
 		logger.Log.Warn("Connection closed, cannot send message",
 			zap.Int64("user_id", c.UserID),
 			zap.String("device_id", c.DeviceID),
 		)
+		return false
 	default:
 		logger.Log.Warn("Send channel full, dropping message",
 			zap.Int64("user_id", c.UserID),
 			zap.String("device_id", c.DeviceID),
 		)
+		return false
 	}
 }
 
 // Close 关闭连接
+// SendChan 不关闭，避免与并发的 Send 竞争；发送 goroutine 通过 CloseChan 退出
 func (c *Connection) Close() {
+	c.closeOnce.Do(func() {
+		close(c.CloseChan)
+	})
+}
+
+// Closed 连接是否已关闭
+func (c *Connection) Closed() bool {
 	select {
 	case <-c.CloseChan:
-		// Already closed
+		return true
 	default:
-		close(c.CloseChan)
-		close(c.SendChan)
+		return false
 	}
 }
 
+// Kick 关闭连接，并在流结束前向客户端发送终止消息
+// 连接已关闭时不再改变终止消息
+func (c *Connection) Kick(msg *gatewaypb.GatewayMessage) {
+	c.mu.Lock()
+	select {
+	case <-c.CloseChan:
+	default:
+		if c.closeMsg == nil {
+			c.closeMsg = msg
+		}
+	}
+	c.mu.Unlock()
+
+	c.Close()
+}
+
+// CloseMessage 返回连接关闭前需要发送的终止消息，没有时返回 nil
+func (c *Connection) CloseMessage() *gatewaypb.GatewayMessage {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.closeMsg
+}
+
 // UpdateActivity 更新活跃时间
 func (c *Connection) UpdateActivity() {
 	c.mu.Lock()
@@ -89,9 +133,9 @@ func (cm *ConnectionManager) AddConnection(conn *Connection) {
 
 	key := cm.getKey(conn.UserID, conn.DeviceID)
 
-	// 如果已存在，先关闭旧连接
+	// 如果已存在，通知旧连接已被替换并关闭
 	if oldConn, exists := cm.connections[key]; exists {
-		oldConn.Close()
+		oldConn.Kick(NewKickMessage(KickReasonReplaced))
 		logger.Log.Info("Replacing existing connection",
 			zap.Int64("user_id", conn.UserID),
 			zap.String("device_id", conn.DeviceID),
@@ -125,6 +169,54 @@ func (cm *ConnectionManager) RemoveConnection(userID int64, deviceID string) {
 	}
 }
 
+// ReleaseConnection 连接结束时移除连接
+// 同一设备已有新连接时保留新连接并返回 false，表示设备的路由已属于新连接
+func (cm *ConnectionManager) ReleaseConnection(conn *Connection) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	key := cm.getKey(conn.UserID, conn.DeviceID)
+	current, exists := cm.connections[key]
+	if exists && current != conn {
+		return false
+	}
+
+	if exists {
+		delete(cm.connections, key)
+		logger.Log.Info("Connection released",
+			zap.Int64("user_id", conn.UserID),
+			zap.String("device_id", conn.DeviceID),
+			zap.Int("total_connections", len(cm.connections)),
+		)
+	}
+	conn.Close()
+	return true
+}
+
+// KickDevice 向设备发送 KICKED 消息并关闭连接，设备不在本网关时返回 false
+func (cm *ConnectionManager) KickDevice(userID int64, deviceID string, reason string) bool {
+	cm.mu.Lock()
+	key := cm.getKey(userID, deviceID)
+	conn, exists := cm.connections[key]
+	if exists {
+		delete(cm.connections, key)
+	}
+	cm.mu.Unlock()
+
+	if !exists {
+		return false
+	}
+
+	conn.Kick(NewKickMessage(reason))
+
+	logger.Log.Info("Connection kicked",
+		zap.Int64("user_id", userID),
+		zap.String("device_id", deviceID),
+		zap.String("reason", reason),
+	)
+	return true
+}
+
 // GetConnection 获取连接
 func (cm *ConnectionManager) GetConnection(userID int64, deviceID string) (*Connection, bool) {
 	cm.mu.RLock()
@@ -149,15 +241,33 @@ func (cm *ConnectionManager) GetUserConnections(userID int64) []*Connection {
 	return conns
 }
 
-// BroadcastToUser 向用户的所有设备广播消息
+// BroadcastToUser 向用户的所有设备广播消息，返回成功投递的连接数
 func (cm *ConnectionManager) BroadcastToUser(userID int64, msg *gatewaypb.GatewayMessage) int {
+	delivered, _ := cm.PushToUser(userID, msg)
+	return delivered
+}
+
+// PushToUser 向用户的所有设备推送消息，返回成功和失败的连接数
+func (cm *ConnectionManager) PushToUser(userID int64, msg *gatewaypb.GatewayMessage) (int, int) {
 	conns := cm.GetUserConnections(userID)
-	count := 0
+	delivered, undelivered := 0, 0
 	for _, conn := range conns {
-		conn.Send(msg)
-		count++
+		if conn.Send(msg) {
+			delivered++
+		} else {
+			undelivered++
+		}
 	}
-	return count
+	return delivered, undelivered
+}
+
+// PushToDevice 向用户的指定设备推送消息，设备不在本网关时返回 false
+func (cm *ConnectionManager) PushToDevice(userID int64, deviceID string, msg *gatewaypb.GatewayMessage) bool {
+	conn, exists := cm.GetConnection(userID, deviceID)
+	if !exists {
+		return false
+	}
+	return conn.Send(msg)
 }
 
 // GetTotalConnections 获取总连接数
@@ -221,3 +331,16 @@ func (cm *ConnectionManager) cleanupOnce(timeout time.Duration) {
 func (cm *ConnectionManager) getKey(userID int64, deviceID string) string {
 	return fmt.Sprintf("%d:%s", userID, deviceID)
 }
+
+// NewKickMessage 创建 KICKED 终止消息
+func NewKickMessage(reason string) *gatewaypb.GatewayMessage {
+	payload, _ := structpb.NewStruct(map[string]interface{}{
+		"reason": reason,
+	})
+
+	return &gatewaypb.GatewayMessage{
+		Type:      gatewaypb.MessageType_KICKED,
+		Payload:   payload,
+		Timestamp: time.Now().Unix(),
+	}
+}
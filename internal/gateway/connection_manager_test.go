@@ -8,6 +8,7 @@ import (
 	gatewaypb "github.com/dollarkillerx/im-system/api/proto/gateway"
 	"github.com/dollarkillerx/im-system/pkg/logger"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func init() {
@@ -200,6 +201,53 @@ func TestConnectionManager_ReplaceConnection(t *testing.T) {
 	retrieved, exists := mgr.GetConnection(100, "device-001")
 	assert.True(t, exists)
 	assert.Equal(t, conn2.LastActive, retrieved.LastActive)
+
+	// The old connection is closed with a KICKED message
+	assert.True(t, conn1.Closed())
+	closeMsg := conn1.CloseMessage()
+	require.NotNil(t, closeMsg)
+	assert.Equal(t, gatewaypb.MessageType_KICKED, closeMsg.Type)
+	assert.Equal(t, KickReasonReplaced, closeMsg.Payload.AsMap()["reason"])
+
+	// Releasing the replaced connection keeps the new one
+	assert.False(t, mgr.ReleaseConnection(conn1))
+	_, exists = mgr.GetConnection(100, "device-001")
+	assert.True(t, exists)
+
+	assert.True(t, mgr.ReleaseConnection(conn2))
+	assert.True(t, conn2.Closed())
+	assert.Equal(t, 0, mgr.GetTotalConnections())
+}
+
+func TestConnectionManager_KickDevice(t *testing.T) {
+	mgr := NewConnectionManager()
+
+	conn := &Connection{
+		UserID:     100,
+		DeviceID:   "device-001",
+		SendChan:   make(chan *gatewaypb.GatewayMessage, 100),
+		CloseChan:  make(chan struct{}),
+		LastActive: time.Now(),
+	}
+	mgr.AddConnection(conn)
+
+	assert.False(t, mgr.KickDevice(100, "device-002", "kicked"))
+	assert.False(t, conn.Closed())
+
+	assert.True(t, mgr.KickDevice(100, "device-001", "kicked"))
+	assert.True(t, conn.Closed())
+	assert.Equal(t, 0, mgr.GetTotalConnections())
+
+	closeMsg := conn.CloseMessage()
+	require.NotNil(t, closeMsg)
+	assert.Equal(t, gatewaypb.MessageType_KICKED, closeMsg.Type)
+	assert.Equal(t, "kicked", closeMsg.Payload.AsMap()["reason"])
+
+	// The connection was already removed, so its own release still unregisters the route
+	assert.True(t, mgr.ReleaseConnection(conn))
+
+	// A closed connection accepts no more messages
+	assert.False(t, conn.Send(&gatewaypb.GatewayMessage{Type: gatewaypb.MessageType_PING}))
 }
 
 func TestConnectionManager_ConcurrentAccess(t *testing.T) {
@@ -308,6 +356,64 @@ func TestConnectionManager_CleanupInactive_Cancellation(t *testing.T) {
 	}
 }
 
+func TestConnectionManager_PushToUser(t *testing.T) {
+	mgr := NewConnectionManager()
+
+	// Two healthy devices and one device whose send queue is full
+	for _, deviceID := range []string{"device-001", "device-002"} {
+		mgr.AddConnection(&Connection{
+			UserID:     100,
+			DeviceID:   deviceID,
+			SendChan:   make(chan *gatewaypb.GatewayMessage, 100),
+			CloseChan:  make(chan struct{}),
+			LastActive: time.Now(),
+		})
+	}
+	fullConn := &Connection{
+		UserID:     100,
+		DeviceID:   "device-003",
+		SendChan:   make(chan *gatewaypb.GatewayMessage, 1),
+		CloseChan:  make(chan struct{}),
+		LastActive: time.Now(),
+	}
+	fullConn.SendChan <- &gatewaypb.GatewayMessage{Type: gatewaypb.MessageType_PING}
+	mgr.AddConnection(fullConn)
+
+	msg := &gatewaypb.GatewayMessage{Type: gatewaypb.MessageType_NOTIFICATION}
+
+	delivered, undelivered := mgr.PushToUser(100, msg)
+	assert.Equal(t, 2, delivered)
+	assert.Equal(t, 1, undelivered)
+
+	// User without connections on this gateway
+	delivered, undelivered = mgr.PushToUser(200, msg)
+	assert.Equal(t, 0, delivered)
+	assert.Equal(t, 0, undelivered)
+
+	assert.Equal(t, 2, mgr.BroadcastToUser(100, msg))
+}
+
+func TestConnectionManager_PushToDevice(t *testing.T) {
+	mgr := NewConnectionManager()
+
+	conn := &Connection{
+		UserID:     100,
+		DeviceID:   "device-001",
+		SendChan:   make(chan *gatewaypb.GatewayMessage, 100),
+		CloseChan:  make(chan struct{}),
+		LastActive: time.Now(),
+	}
+	mgr.AddConnection(conn)
+
+	msg := &gatewaypb.GatewayMessage{Type: gatewaypb.MessageType_NOTIFICATION}
+
+	assert.True(t, mgr.PushToDevice(100, "device-001", msg))
+	assert.Len(t, conn.SendChan, 1)
+
+	assert.False(t, mgr.PushToDevice(100, "device-002", msg))
+	assert.False(t, mgr.PushToDevice(200, "device-001", msg))
+}
+
 func TestConnectionManager_getKey(t *testing.T) {
 	mgr := NewConnectionManager()
 
@@ -8,13 +8,25 @@ import (
 
 	gatewaypb "github.com/dollarkillerx/im-system/api/proto/gateway"
 	messagepb "github.com/dollarkillerx/im-system/api/proto/message"
+	routerpb "github.com/dollarkillerx/im-system/api/proto/router"
 	"github.com/dollarkillerx/im-system/pkg/interceptor"
 	"github.com/dollarkillerx/im-system/pkg/logger"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
+const (
+	// IdempotencyKeyHeader 发送消息的幂等键元数据
+	IdempotencyKeyHeader = "x-idempotency-key"
+	// PlatformHeader 建立连接时声明的平台类型元数据 (如 mobile/desktop/web)，用于多设备策略
+	// 未声明平台的连接按 unknown 类计算设备上限
+	PlatformHeader = "x-device-platform"
+	// maxPresenceTargets 单次订阅在线状态的用户数上限，与 Router 服务一致
+	maxPresenceTargets = 500
+)
+
 // GRPCServer Gateway gRPC 服务器
 type GRPCServer struct {
 	gatewaypb.UnimplementedGatewayServiceServer
@@ -49,25 +61,39 @@ func (s *GRPCServer) Connect(stream gatewaypb.GatewayService_ConnectServer) erro
 		return status.Errorf(codes.Unauthenticated, "device not identified")
 	}
 
+	var platform string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(PlatformHeader); len(values) > 0 {
+			platform = values[0]
+		}
+	}
+
 	logger.Log.Info("Client connecting",
 		zap.Int64("user_id", userID),
 		zap.String("device_id", deviceID),
+		zap.String("platform", platform),
 	)
 
 	// 创建连接
 	conn := NewConnection(userID, deviceID, stream)
+	conn.Platform = platform
 	s.connMgr.AddConnection(conn)
-	defer s.connMgr.RemoveConnection(userID, deviceID)
 
-	// 注册路由到 Router 服务
-	if err := s.clients.RegisterRoute(ctx, userID, deviceID, s.gatewayAddr); err != nil {
+	// 连接被同一设备的新连接替换时，路由已属于新连接，不能注销
+	defer func() {
+		if s.connMgr.ReleaseConnection(conn) {
+			s.clients.UnregisterRoute(context.Background(), userID, deviceID, s.gatewayAddr)
+		}
+	}()
+
+	// 注册路由到 Router 服务，超出多设备策略的旧设备会被踢下线
+	if err := s.clients.RegisterRoute(ctx, userID, deviceID, platform, s.gatewayAddr); err != nil {
 		logger.Log.Error("Failed to register route",
 			zap.Int64("user_id", userID),
 			zap.String("device_id", deviceID),
 			zap.Error(err),
 		)
 	}
-	defer s.clients.UnregisterRoute(context.Background(), userID, deviceID)
 
 	// 启动发送 goroutine
 	sendDone := make(chan struct{})
@@ -78,33 +104,57 @@ func (s *GRPCServer) Connect(stream gatewaypb.GatewayService_ConnectServer) erro
 	go s.keepAliveLoop(ctx, conn, keepAliveDone)
 
 	// 接收客户端消息
-	for {
-		msg, err := stream.Recv()
-		if err == io.EOF {
-			logger.Log.Info("Client disconnected (EOF)",
-				zap.Int64("user_id", userID),
-				zap.String("device_id", deviceID),
-			)
-			break
-		}
-		if err != nil {
-			logger.Log.Error("Receive error",
-				zap.Int64("user_id", userID),
-				zap.String("device_id", deviceID),
-				zap.Error(err),
-			)
-			break
+	recvDone := make(chan struct{})
+	go func() {
+		defer close(recvDone)
+		for {
+			msg, err := stream.Recv()
+			if err == io.EOF {
+				logger.Log.Info("Client disconnected (EOF)",
+					zap.Int64("user_id", userID),
+					zap.String("device_id", deviceID),
+				)
+				return
+			}
+			if err != nil {
+				logger.Log.Error("Receive error",
+					zap.Int64("user_id", userID),
+					zap.String("device_id", deviceID),
+					zap.Error(err),
+				)
+				return
+			}
+
+			// 处理消息
+			s.handler.HandleClientMessage(ctx, conn, msg)
 		}
+	}()
 
-		// 处理消息
-		s.handler.HandleClientMessage(ctx, conn, msg)
+	// 客户端断开，或连接被服务端关闭 (被踢下线、被替换或长时间不活跃)
+	// 服务端关闭时直接返回结束流，阻塞中的 Recv 随流结束返回
+	select {
+	case <-recvDone:
+	case <-conn.CloseChan:
 	}
 
+	s.handler.HandleDisconnect(conn)
+
 	// 等待发送和心跳 goroutine 结束
 	conn.Close()
 	<-sendDone
 	<-keepAliveDone
 
+	// 发送终止消息，此时发送 goroutine 已退出，可以直接写流
+	if msg := conn.CloseMessage(); msg != nil {
+		if err := stream.Send(msg); err != nil {
+			logger.Log.Warn("Failed to send close message",
+				zap.Int64("user_id", userID),
+				zap.String("device_id", deviceID),
+				zap.Error(err),
+			)
+		}
+	}
+
 	logger.Log.Info("Client connection closed",
 		zap.Int64("user_id", userID),
 		zap.String("device_id", deviceID),
@@ -133,10 +183,20 @@ func (s *GRPCServer) Send(ctx context.Context, req *gatewaypb.SendRequest) (*gat
 		return nil, status.Errorf(codes.InvalidArgument, "invalid conv_type")
 	}
 
+	// 幂等键优先取请求字段，其次取 x-idempotency-key 元数据
+	idempotencyKey := req.GetIdempotencyKey()
+	if idempotencyKey == "" {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(IdempotencyKeyHeader); len(values) > 0 {
+				idempotencyKey = values[0]
+			}
+		}
+	}
+
 	// 调用 Message 服务
-	resp, err := s.clients.SendMessage(ctx, req.ConvId, userID, convType, req.Body.AsMap(), req.ReplyTo, req.Mentions)
+	resp, err := s.clients.SendMessage(ctx, req.ConvId, userID, convType, req.Body.AsMap(), req.ReplyTo, req.Mentions, idempotencyKey)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to send message: %v", err)
+		return nil, status.Errorf(upstreamCode(err), "failed to send message: %s", status.Convert(err).Message())
 	}
 
 	return &gatewaypb.SendResponse{
@@ -154,47 +214,361 @@ func (s *GRPCServer) Sync(ctx context.Context, req *gatewaypb.SyncRequest) (*gat
 	}
 
 	var convMessages []*gatewaypb.ConvMessages
+	var deniedConvIDs []int64
 
 	for _, convSync := range req.Conversations {
-		// 从 Message 服务拉取消息
-		resp, err := s.clients.PullMessages(ctx, convSync.ConvId, convSync.SinceSeq, 100)
+		var direction messagepb.PullDirection
+		switch convSync.Direction {
+		case "", "forward":
+			direction = messagepb.PullDirection_FORWARD
+		case "backward":
+			direction = messagepb.PullDirection_BACKWARD
+		case "around":
+			direction = messagepb.PullDirection_AROUND
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "invalid direction for conversation %d", convSync.ConvId)
+		}
+
+		// 从 Message 服务拉取消息，Message 服务会校验调用者的成员身份
+		resp, err := s.clients.PullMessages(ctx, convSync.ConvId, userID, direction, convSync.SinceSeq, convSync.BeforeSeq, convSync.AroundSeq, 100)
 		if err != nil {
-			logger.Log.Error("Failed to pull messages",
-				zap.Int64("user_id", userID),
-				zap.Int64("conv_id", convSync.ConvId),
-				zap.Error(err),
-			)
+			switch status.Code(err) {
+			case codes.PermissionDenied, codes.NotFound:
+				// 无权读取的会话在响应中返回，客户端据此清理本地会话
+				logger.Log.Warn("Sync rejected for conversation",
+					zap.Int64("user_id", userID),
+					zap.Int64("conv_id", convSync.ConvId),
+					zap.Error(err),
+				)
+				deniedConvIDs = append(deniedConvIDs, convSync.ConvId)
+			default:
+				logger.Log.Error("Failed to pull messages",
+					zap.Int64("user_id", userID),
+					zap.Int64("conv_id", convSync.ConvId),
+					zap.Error(err),
+				)
+			}
 			continue
 		}
 
 		// 转换消息格式
 		var chatMessages []*gatewaypb.ChatMessage
 		for _, msg := range resp.Messages {
-			chatMessages = append(chatMessages, &gatewaypb.ChatMessage{
-				MsgId:     msg.MsgId,
-				ConvId:    msg.ConvId,
-				Seq:       msg.Seq,
-				SenderId:  msg.SenderId,
-				ConvType:  msg.ConvType.String(),
-				Body:      msg.Body,
-				ReplyTo:   msg.ReplyTo,
-				Mentions:  msg.Mentions,
-				CreatedAt: msg.CreatedAt,
-			})
+			chatMessages = append(chatMessages, toChatMessage(msg))
 		}
 
 		convMessages = append(convMessages, &gatewaypb.ConvMessages{
-			ConvId:   convSync.ConvId,
-			Messages: chatMessages,
-			HasMore:  resp.HasMore,
+			ConvId:        convSync.ConvId,
+			Messages:      chatMessages,
+			HasMore:       resp.HasMore,
+			HasMoreBefore: resp.HasMoreBefore,
+			HasMoreAfter:  resp.HasMoreAfter,
 		})
 	}
 
 	return &gatewaypb.SyncResponse{
-		ConvMessages: convMessages,
+		ConvMessages:  convMessages,
+		DeniedConvIds: deniedConvIDs,
 	}, nil
 }
 
+// ListConversations 获取当前用户的会话列表
+func (s *GRPCServer) ListConversations(ctx context.Context, req *gatewaypb.ListConversationsRequest) (*gatewaypb.ListConversationsResponse, error) {
+	userID, ok := interceptor.GetUserID(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "user not authenticated")
+	}
+
+	resp, err := s.clients.ListConversations(ctx, userID, req.Cursor, req.Limit)
+	if err != nil {
+		return nil, status.Errorf(upstreamCode(err), "failed to list conversations: %s", status.Convert(err).Message())
+	}
+
+	summaries := make([]*gatewaypb.ConversationSummary, 0, len(resp.Conversations))
+	for _, conv := range resp.Conversations {
+		summary := &gatewaypb.ConversationSummary{
+			ConvId:       conv.ConvId,
+			ConvType:     conv.Type.String(),
+			Title:        conv.Title,
+			OwnerId:      conv.OwnerId,
+			Role:         conv.Role.String(),
+			Muted:        conv.Muted,
+			CurrentSeq:   conv.CurrentSeq,
+			LastReadSeq:  conv.LastReadSeq,
+			UnreadCount:  conv.UnreadCount,
+			LastActiveAt: conv.LastActiveAt,
+			CreatedAt:    conv.CreatedAt,
+		}
+		if conv.LastMessage != nil {
+			summary.LastMessage = toChatMessage(conv.LastMessage)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return &gatewaypb.ListConversationsResponse{
+		Conversations: summaries,
+		NextCursor:    resp.NextCursor,
+		HasMore:       resp.HasMore,
+	}, nil
+}
+
+// GetOrCreateDirectConversation 获取或创建当前用户与 peer 的单聊
+func (s *GRPCServer) GetOrCreateDirectConversation(ctx context.Context, req *gatewaypb.GetOrCreateDirectConversationRequest) (*gatewaypb.GetOrCreateDirectConversationResponse, error) {
+	userID, ok := interceptor.GetUserID(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "user not authenticated")
+	}
+
+	resp, err := s.clients.GetOrCreateDirectConversation(ctx, userID, req.PeerId)
+	if err != nil {
+		return nil, status.Errorf(upstreamCode(err), "failed to get or create direct conversation: %s", status.Convert(err).Message())
+	}
+
+	return &gatewaypb.GetOrCreateDirectConversationResponse{
+		ConvId:  resp.ConvId,
+		Created: resp.Created,
+	}, nil
+}
+
+// RecallMessage 撤回消息
+func (s *GRPCServer) RecallMessage(ctx context.Context, req *gatewaypb.RecallMessageRequest) (*gatewaypb.RecallMessageResponse, error) {
+	userID, ok := interceptor.GetUserID(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "user not authenticated")
+	}
+
+	resp, err := s.clients.RecallMessage(ctx, req.ConvId, req.MsgId, userID)
+	if err != nil {
+		return nil, status.Errorf(upstreamCode(err), "failed to recall message: %s", status.Convert(err).Message())
+	}
+
+	return &gatewaypb.RecallMessageResponse{
+		Seq: resp.Seq,
+	}, nil
+}
+
+// EditMessage 编辑消息
+func (s *GRPCServer) EditMessage(ctx context.Context, req *gatewaypb.EditMessageRequest) (*gatewaypb.EditMessageResponse, error) {
+	userID, ok := interceptor.GetUserID(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "user not authenticated")
+	}
+
+	resp, err := s.clients.EditMessage(ctx, req.ConvId, req.MsgId, userID, req.Body.AsMap())
+	if err != nil {
+		return nil, status.Errorf(upstreamCode(err), "failed to edit message: %s", status.Convert(err).Message())
+	}
+
+	return &gatewaypb.EditMessageResponse{
+		Seq:      resp.Seq,
+		EditedAt: resp.EditedAt,
+	}, nil
+}
+
+// SubscribePresence 订阅用户在线状态，之后的上下线变化通过 PRESENCE 消息推送
+// 只能订阅与自己至少共同在一个会话中的用户，其余用户被忽略，不会出现在返回结果中
+// 订阅在用户完全离线后失效，重连后需要重新订阅
+func (s *GRPCServer) SubscribePresence(ctx context.Context, req *gatewaypb.SubscribePresenceRequest) (*gatewaypb.SubscribePresenceResponse, error) {
+	userID, ok := interceptor.GetUserID(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "user not authenticated")
+	}
+
+	if len(req.UserIds) > maxPresenceTargets {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d users per request", maxPresenceTargets)
+	}
+
+	peerIDs, err := s.clients.FilterPeers(ctx, userID, req.UserIds)
+	if err != nil {
+		return nil, status.Errorf(upstreamCode(err), "failed to subscribe presence: %s", status.Convert(err).Message())
+	}
+	if len(peerIDs) == 0 {
+		return &gatewaypb.SubscribePresenceResponse{}, nil
+	}
+
+	resp, err := s.clients.SubscribePresence(ctx, userID, peerIDs)
+	if err != nil {
+		return nil, status.Errorf(upstreamCode(err), "failed to subscribe presence: %s", status.Convert(err).Message())
+	}
+
+	statuses := make([]*gatewaypb.PresenceStatus, 0, len(resp.Statuses))
+	for _, st := range resp.Statuses {
+		statuses = append(statuses, toPresenceStatus(st))
+	}
+
+	return &gatewaypb.SubscribePresenceResponse{
+		Statuses: statuses,
+	}, nil
+}
+
+// UnsubscribePresence 取消订阅用户在线状态
+func (s *GRPCServer) UnsubscribePresence(ctx context.Context, req *gatewaypb.UnsubscribePresenceRequest) (*gatewaypb.UnsubscribePresenceResponse, error) {
+	userID, ok := interceptor.GetUserID(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "user not authenticated")
+	}
+
+	if err := s.clients.UnsubscribePresence(ctx, userID, req.UserIds); err != nil {
+		return nil, status.Errorf(upstreamCode(err), "failed to unsubscribe presence: %s", status.Convert(err).Message())
+	}
+
+	return &gatewaypb.UnsubscribePresenceResponse{
+		Success: true,
+	}, nil
+}
+
+// SetPresence 设置手动状态和自定义状态，隐身时对他人显示为离线但仍可正常收消息
+func (s *GRPCServer) SetPresence(ctx context.Context, req *gatewaypb.SetPresenceRequest) (*gatewaypb.SetPresenceResponse, error) {
+	userID, ok := interceptor.GetUserID(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "user not authenticated")
+	}
+
+	resp, err := s.clients.SetPresence(ctx, userID, req.Status, req.CustomStatus, req.CustomStatusTtl)
+	if err != nil {
+		return nil, status.Errorf(upstreamCode(err), "failed to set presence: %s", status.Convert(err).Message())
+	}
+
+	return &gatewaypb.SetPresenceResponse{
+		Presence: toPresenceStatus(resp.Presence),
+	}, nil
+}
+
+// ListDevices 获取当前用户的在线设备
+func (s *GRPCServer) ListDevices(ctx context.Context, req *gatewaypb.ListDevicesRequest) (*gatewaypb.ListDevicesResponse, error) {
+	userID, ok := interceptor.GetUserID(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "user not authenticated")
+	}
+	deviceID, _ := interceptor.GetDeviceID(ctx)
+
+	routes, err := s.clients.GetRoutes(ctx, userID)
+	if err != nil {
+		return nil, status.Errorf(upstreamCode(err), "failed to list devices: %s", status.Convert(err).Message())
+	}
+
+	devices := make([]*gatewaypb.DeviceInfo, 0, len(routes))
+	for _, route := range routes {
+		devices = append(devices, &gatewaypb.DeviceInfo{
+			DeviceId:    route.DeviceId,
+			Platform:    route.Platform,
+			ConnectedAt: route.ConnectedAt,
+			LastActive:  route.LastActive,
+			Current:     route.DeviceId == deviceID,
+		})
+	}
+
+	return &gatewaypb.ListDevicesResponse{
+		Devices: devices,
+	}, nil
+}
+
+// KickDevice 将当前用户的其他设备踢下线并吊销其令牌
+// 被踢设备收到 KICKED 消息后连接关闭，需要重新登录
+func (s *GRPCServer) KickDevice(ctx context.Context, req *gatewaypb.KickDeviceRequest) (*gatewaypb.KickDeviceResponse, error) {
+	userID, ok := interceptor.GetUserID(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "user not authenticated")
+	}
+
+	if req.DeviceId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "device_id is required")
+	}
+	if deviceID, _ := interceptor.GetDeviceID(ctx); req.DeviceId == deviceID {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot kick the current device")
+	}
+
+	kicked, err := s.clients.KickDevice(ctx, userID, req.DeviceId, "")
+	if err != nil {
+		return nil, status.Errorf(upstreamCode(err), "failed to kick device: %s", status.Convert(err).Message())
+	}
+
+	return &gatewaypb.KickDeviceResponse{
+		Success: kicked,
+	}, nil
+}
+
+// PushToUsers 批量推送消息到指定用户的所有连接（内部调用）
+func (s *GRPCServer) PushToUsers(ctx context.Context, req *gatewaypb.PushToUsersRequest) (*gatewaypb.PushResponse, error) {
+	if req.Message == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "message is required")
+	}
+	if req.Message.Timestamp == 0 {
+		req.Message.Timestamp = time.Now().Unix()
+	}
+
+	resp := &gatewaypb.PushResponse{}
+	for _, userID := range req.UserIds {
+		delivered, undelivered := s.connMgr.PushToUser(userID, req.Message)
+		resp.Results = append(resp.Results, &gatewaypb.UserPushResult{
+			UserId:      userID,
+			Delivered:   int32(delivered),
+			Undelivered: int32(undelivered),
+		})
+		resp.DeliveredCount += int32(delivered)
+		resp.UndeliveredCount += int32(undelivered)
+	}
+
+	logger.Log.Debug("Pushed to users",
+		zap.String("type", req.Message.Type.String()),
+		zap.Int("user_count", len(req.UserIds)),
+		zap.Int32("delivered", resp.DeliveredCount),
+		zap.Int32("undelivered", resp.UndeliveredCount),
+	)
+
+	return resp, nil
+}
+
+// PushToDevices 批量推送消息到指定设备的连接（内部调用）
+func (s *GRPCServer) PushToDevices(ctx context.Context, req *gatewaypb.PushToDevicesRequest) (*gatewaypb.PushResponse, error) {
+	if req.Message == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "message is required")
+	}
+	if req.Message.Timestamp == 0 {
+		req.Message.Timestamp = time.Now().Unix()
+	}
+
+	resp := &gatewaypb.PushResponse{}
+	results := make(map[int64]*gatewaypb.UserPushResult)
+	for _, target := range req.Targets {
+		result, ok := results[target.UserId]
+		if !ok {
+			result = &gatewaypb.UserPushResult{UserId: target.UserId}
+			results[target.UserId] = result
+			resp.Results = append(resp.Results, result)
+		}
+
+		if s.connMgr.PushToDevice(target.UserId, target.DeviceId, req.Message) {
+			result.Delivered++
+			resp.DeliveredCount++
+		} else {
+			result.Undelivered++
+			resp.UndeliveredCount++
+		}
+	}
+
+	logger.Log.Debug("Pushed to devices",
+		zap.String("type", req.Message.Type.String()),
+		zap.Int("target_count", len(req.Targets)),
+		zap.Int32("delivered", resp.DeliveredCount),
+		zap.Int32("undelivered", resp.UndeliveredCount),
+	)
+
+	return resp, nil
+}
+
+// KickDevices 向本网关上指定设备发送 KICKED 消息并关闭连接（内部调用）
+func (s *GRPCServer) KickDevices(ctx context.Context, req *gatewaypb.KickDevicesRequest) (*gatewaypb.KickDevicesResponse, error) {
+	resp := &gatewaypb.KickDevicesResponse{}
+	for _, deviceID := range req.DeviceIds {
+		if s.connMgr.KickDevice(req.UserId, deviceID, req.Reason) {
+			resp.KickedCount++
+		}
+	}
+
+	return resp, nil
+}
+
 // sendLoop 发送循环
 func (s *GRPCServer) sendLoop(conn *Connection, done chan struct{}) {
 	defer close(done)
@@ -232,12 +606,26 @@ func (s *GRPCServer) keepAliveLoop(ctx context.Context, conn *Connection, done c
 		select {
 		case <-ticker.C:
 			// 发送心跳到 Router 服务
-			if err := s.clients.KeepAlive(ctx, conn.UserID, conn.DeviceID); err != nil {
+			alive, err := s.clients.KeepAlive(ctx, conn.UserID, conn.DeviceID)
+			if err != nil {
 				logger.Log.Warn("Failed to send keep-alive",
 					zap.Int64("user_id", conn.UserID),
 					zap.String("device_id", conn.DeviceID),
 					zap.Error(err),
 				)
+				continue
+			}
+
+			// 路由已过期 (如心跳曾长时间中断)，连接仍然存活时重新注册
+			// 连接已被踢下线时路由是被主动移除的，不能重新注册
+			if !alive && !conn.Closed() {
+				if err := s.clients.RegisterRoute(ctx, conn.UserID, conn.DeviceID, conn.Platform, s.gatewayAddr); err != nil {
+					logger.Log.Warn("Failed to re-register expired route",
+						zap.Int64("user_id", conn.UserID),
+						zap.String("device_id", conn.DeviceID),
+						zap.Error(err),
+					)
+				}
 			}
 
 		case <-conn.CloseChan:
@@ -249,6 +637,44 @@ func (s *GRPCServer) keepAliveLoop(ctx context.Context, conn *Connection, done c
 	}
 }
 
+// toChatMessage 转换 Message 服务的消息为网关消息格式
+func toChatMessage(msg *messagepb.Message) *gatewaypb.ChatMessage {
+	return &gatewaypb.ChatMessage{
+		MsgId:      msg.MsgId,
+		ConvId:     msg.ConvId,
+		Seq:        msg.Seq,
+		SenderId:   msg.SenderId,
+		ConvType:   msg.ConvType.String(),
+		Body:       msg.Body,
+		ReplyTo:    msg.ReplyTo,
+		Mentions:   msg.Mentions,
+		CreatedAt:  msg.CreatedAt,
+		Visibility: msg.Visibility,
+		EditedAt:   msg.EditedAt,
+	}
+}
+
+// toPresenceStatus 转换 Router 服务的在线状态为网关格式
+func toPresenceStatus(st *routerpb.PresenceStatus) *gatewaypb.PresenceStatus {
+	return &gatewaypb.PresenceStatus{
+		UserId:                st.UserId,
+		Online:                st.Online,
+		Status:                st.Status,
+		CustomStatus:          st.CustomStatus,
+		CustomStatusExpiresAt: st.CustomStatusExpiresAt,
+		LastSeen:              st.LastSeen,
+	}
+}
+
+// upstreamCode 透传下游服务返回的 gRPC 状态码，非 gRPC 错误视为 Internal
+func upstreamCode(err error) codes.Code {
+	code := status.Code(err)
+	if code == codes.Unknown {
+		return codes.Internal
+	}
+	return code
+}
+
 // GetGatewayAddr 获取网关地址
 func GetGatewayAddr(port int) string {
 	// 这里简化实现，实际应该获取外网 IP
@@ -9,6 +9,7 @@ import (
 	messagepb "github.com/dollarkillerx/im-system/api/proto/message"
 	"github.com/dollarkillerx/im-system/pkg/logger"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
@@ -16,6 +17,7 @@ import (
 type Handler struct {
 	connMgr *ConnectionManager
 	clients *ServiceClients
+	typing  *TypingTracker
 }
 
 // NewHandler 创建消息处理器
@@ -23,6 +25,7 @@ func NewHandler(connMgr *ConnectionManager, clients *ServiceClients) *Handler {
 	return &Handler{
 		connMgr: connMgr,
 		clients: clients,
+		typing:  NewTypingTracker(clients, DefaultTypingThrottle, DefaultTypingTimeout),
 	}
 }
 
@@ -36,7 +39,7 @@ func (h *Handler) HandleClientMessage(ctx context.Context, conn *Connection, msg
 	case gatewaypb.MessageType_ACK:
 		h.handleAck(conn, msg)
 	case gatewaypb.MessageType_TYPING:
-		h.handleTyping(conn, msg)
+		h.handleTyping(ctx, conn, msg)
 	case gatewaypb.MessageType_READ_RECEIPT:
 		h.handleReadReceipt(ctx, conn, msg)
 	default:
@@ -118,8 +121,11 @@ func (h *Handler) handleChat(ctx context.Context, conn *Connection, msg *gateway
 		}
 	}
 
+	// 幂等键，客户端重试时携带相同值
+	idempotencyKey, _ := payload["idempotency_key"].(string)
+
 	// 调用 Message 服务发送消息
-	resp, err := h.clients.SendMessage(ctx, int64(convID), conn.UserID, convType, body, replyTo, mentions)
+	resp, err := h.clients.SendMessage(ctx, int64(convID), conn.UserID, convType, body, replyTo, mentions, idempotencyKey)
 	if err != nil {
 		logger.Log.Error("Failed to send message",
 			zap.Int64("user_id", conn.UserID),
@@ -162,39 +168,70 @@ func (h *Handler) handleAck(conn *Connection, msg *gatewaypb.GatewayMessage) {
 	)
 }
 
-// handleTyping 处理输入状态
-func (h *Handler) handleTyping(conn *Connection, msg *gatewaypb.GatewayMessage) {
+// handleTyping 处理输入状态，payload 中 typing 为 false 表示停止输入（默认为 true）
+func (h *Handler) handleTyping(ctx context.Context, conn *Connection, msg *gatewaypb.GatewayMessage) {
 	conn.UpdateActivity()
 
 	payload := msg.Payload.AsMap()
 	convID, ok := payload["conv_id"].(float64)
 	if !ok {
+		h.sendError(conn, "invalid conv_id", msg.MsgId)
 		return
 	}
 
-	// 广播输入状态给会话中的其他成员（简化实现）
-	logger.Log.Debug("User typing",
-		zap.Int64("user_id", conn.UserID),
-		zap.Int64("conv_id", int64(convID)),
-	)
+	typing, ok := payload["typing"].(bool)
+	if !ok {
+		typing = true
+	}
+
+	if !typing {
+		h.typing.Stop(ctx, conn.UserID, int64(convID))
+		return
+	}
+
+	if err := h.typing.Start(ctx, conn.UserID, conn.DeviceID, int64(convID)); err != nil {
+		logger.Log.Warn("Failed to relay typing status",
+			zap.Int64("user_id", conn.UserID),
+			zap.Int64("conv_id", int64(convID)),
+			zap.Error(err),
+		)
+		h.sendError(conn, status.Convert(err).Message(), msg.MsgId)
+	}
+}
+
+// HandleDisconnect 连接断开时清理该设备的输入状态
+func (h *Handler) HandleDisconnect(conn *Connection) {
+	h.typing.StopDevice(conn.UserID, conn.DeviceID)
 }
 
-// handleReadReceipt 处理已读回执
+// handleReadReceipt 处理已读回执，由 Message 服务持久化并广播已读状态
 func (h *Handler) handleReadReceipt(ctx context.Context, conn *Connection, msg *gatewaypb.GatewayMessage) {
 	conn.UpdateActivity()
 
 	payload := msg.Payload.AsMap()
 	convID, ok := payload["conv_id"].(float64)
 	if !ok {
+		h.sendError(conn, "invalid conv_id", msg.MsgId)
 		return
 	}
 
 	seq, ok := payload["seq"].(float64)
-	if !ok {
+	if !ok || seq < 0 {
+		h.sendError(conn, "invalid seq", msg.MsgId)
+		return
+	}
+
+	if err := h.clients.UpdateReadSeq(ctx, int64(convID), conn.UserID, int64(seq)); err != nil {
+		logger.Log.Warn("Failed to update read seq",
+			zap.Int64("user_id", conn.UserID),
+			zap.Int64("conv_id", int64(convID)),
+			zap.Int64("seq", int64(seq)),
+			zap.Error(err),
+		)
+		h.sendError(conn, status.Convert(err).Message(), msg.MsgId)
 		return
 	}
 
-	// 这里可以调用 Message 服务更新已读位置
 	logger.Log.Debug("Read receipt",
 		zap.Int64("user_id", conn.UserID),
 		zap.Int64("conv_id", int64(convID)),
@@ -0,0 +1,260 @@
+package gateway
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	gatewaypb "github.com/dollarkillerx/im-system/api/proto/gateway"
+	messagepb "github.com/dollarkillerx/im-system/api/proto/message"
+	"github.com/dollarkillerx/im-system/pkg/logger"
+	"github.com/dollarkillerx/im-system/pkg/types"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// DefaultTypingThrottle 同一用户在同一会话中转发"正在输入"的最小间隔
+	DefaultTypingThrottle = 3 * time.Second
+	// DefaultTypingTimeout 未收到新的输入事件时自动发送"停止输入"的时间
+	DefaultTypingTimeout = 6 * time.Second
+
+	// typingPushTimeout 过期触发的"停止输入"推送超时
+	typingPushTimeout = 5 * time.Second
+)
+
+// TypingRelay 输入状态转发所需的下游服务
+type TypingRelay interface {
+	// GetConversation 获取会话及其成员，调用者不是成员时返回错误
+	GetConversation(ctx context.Context, convID int64, userID int64) (*messagepb.Conversation, error)
+	// PushEvent 将事件推送给在线用户
+	PushEvent(ctx context.Context, recipientIDs []int64, event *gatewaypb.GatewayMessage) error
+}
+
+type typingKey struct {
+	userID int64
+	convID int64
+}
+
+// typingState 一个用户在一个会话中的输入状态
+type typingState struct {
+	deviceID   string
+	recipients []int64
+	lastRelay  time.Time
+	expiresAt  time.Time
+	timer      *time.Timer
+}
+
+// TypingTracker 转发输入状态，按用户和会话节流，并在超时后自动发送"停止输入"
+// 输入状态只保存在网关内存中，不落库
+type TypingTracker struct {
+	relay    TypingRelay
+	throttle time.Duration
+	timeout  time.Duration
+
+	mu     sync.Mutex
+	states map[typingKey]*typingState
+}
+
+// NewTypingTracker 创建输入状态跟踪器
+func NewTypingTracker(relay TypingRelay, throttle, timeout time.Duration) *TypingTracker {
+	return &TypingTracker{
+		relay:    relay,
+		throttle: throttle,
+		timeout:  timeout,
+		states:   make(map[typingKey]*typingState),
+	}
+}
+
+// Start 处理"正在输入"事件
+// 节流间隔内只刷新过期时间；否则按发消息的权限校验（被禁言或无权发言的成员不能转发），再转发给会话中的其他成员
+func (t *TypingTracker) Start(ctx context.Context, userID int64, deviceID string, convID int64) error {
+	key := typingKey{userID: userID, convID: convID}
+
+	t.mu.Lock()
+	if st, ok := t.states[key]; ok && time.Since(st.lastRelay) < t.throttle {
+		st.deviceID = deviceID
+		t.refresh(key, st)
+		t.mu.Unlock()
+		return nil
+	}
+	t.mu.Unlock()
+
+	// 每次转发前重新获取成员，同时完成成员身份和发言权限校验
+	conv, err := t.relay.GetConversation(ctx, convID, userID)
+	if err != nil {
+		return err
+	}
+
+	recipients := make([]int64, 0, len(conv.GetMembers()))
+	for _, member := range conv.GetMembers() {
+		if member.UserId != userID {
+			recipients = append(recipients, member.UserId)
+			continue
+		}
+		if err := checkTypingPermission(conv.Type, member); err != nil {
+			return err
+		}
+	}
+
+	t.mu.Lock()
+	st, ok := t.states[key]
+	if !ok {
+		st = &typingState{}
+		t.states[key] = st
+	}
+	st.deviceID = deviceID
+	st.recipients = recipients
+	st.lastRelay = time.Now()
+	t.refresh(key, st)
+	t.mu.Unlock()
+
+	t.push(ctx, userID, convID, true, recipients)
+	return nil
+}
+
+// Stop 处理"停止输入"事件，只通知之前收到过"正在输入"的成员
+func (t *TypingTracker) Stop(ctx context.Context, userID int64, convID int64) {
+	key := typingKey{userID: userID, convID: convID}
+
+	t.mu.Lock()
+	st, ok := t.states[key]
+	if ok {
+		st.timer.Stop()
+		delete(t.states, key)
+	}
+	t.mu.Unlock()
+
+	if ok {
+		t.push(ctx, userID, convID, false, st.recipients)
+	}
+}
+
+// StopDevice 设备断开时结束该设备上的所有输入状态
+func (t *TypingTracker) StopDevice(userID int64, deviceID string) {
+	t.mu.Lock()
+	var convIDs []int64
+	for key, st := range t.states {
+		if key.userID == userID && st.deviceID == deviceID {
+			convIDs = append(convIDs, key.convID)
+		}
+	}
+	t.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), typingPushTimeout)
+	defer cancel()
+	for _, convID := range convIDs {
+		t.Stop(ctx, userID, convID)
+	}
+}
+
+// checkTypingPermission 与 Message 服务发消息的权限一致：被禁言或角色无权发言时拒绝
+func checkTypingPermission(convType messagepb.ConversationType, member *messagepb.ConversationMember) error {
+	if member.Muted {
+		return status.Error(codes.PermissionDenied, "member is muted")
+	}
+	if !conversationRole(member.Role).CanSendMessage(conversationType(convType)) {
+		return status.Error(codes.PermissionDenied, "role is not allowed to send messages in this conversation")
+	}
+	return nil
+}
+
+// conversationType 将 proto 会话类型转换为内部类型
+func conversationType(t messagepb.ConversationType) types.ConversationType {
+	switch t {
+	case messagepb.ConversationType_DIRECT:
+		return types.ConversationTypeDirect
+	case messagepb.ConversationType_GROUP:
+		return types.ConversationTypeGroup
+	case messagepb.ConversationType_CHANNEL:
+		return types.ConversationTypeChannel
+	default:
+		return ""
+	}
+}
+
+// conversationRole 将 proto 成员角色转换为内部类型
+func conversationRole(r messagepb.ConversationRole) types.ConversationRole {
+	switch r {
+	case messagepb.ConversationRole_OWNER:
+		return types.ConversationRoleOwner
+	case messagepb.ConversationRole_ADMIN:
+		return types.ConversationRoleAdmin
+	case messagepb.ConversationRole_PUBLISHER:
+		return types.ConversationRolePublisher
+	case messagepb.ConversationRole_VIEWER:
+		return types.ConversationRoleViewer
+	default:
+		return types.ConversationRoleMember
+	}
+}
+
+// refresh 延长输入状态的过期时间，调用方需持有锁
+func (t *TypingTracker) refresh(key typingKey, st *typingState) {
+	st.expiresAt = time.Now().Add(t.timeout)
+	if st.timer == nil {
+		st.timer = time.AfterFunc(t.timeout, func() { t.expire(key, st) })
+		return
+	}
+	st.timer.Reset(t.timeout)
+}
+
+// expire 输入状态超时，自动通知"停止输入"
+func (t *TypingTracker) expire(key typingKey, st *typingState) {
+	t.mu.Lock()
+	if t.states[key] != st {
+		t.mu.Unlock()
+		return
+	}
+	// 定时器触发与刷新并发时，以最新的过期时间为准
+	if remaining := time.Until(st.expiresAt); remaining > 0 {
+		st.timer.Reset(remaining)
+		t.mu.Unlock()
+		return
+	}
+	delete(t.states, key)
+	t.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), typingPushTimeout)
+	defer cancel()
+	t.push(ctx, key.userID, key.convID, false, st.recipients)
+}
+
+// push 推送输入状态事件
+func (t *TypingTracker) push(ctx context.Context, userID int64, convID int64, typing bool, recipients []int64) {
+	if len(recipients) == 0 {
+		return
+	}
+
+	data := map[string]interface{}{
+		"conv_id": convID,
+		"user_id": userID,
+		"typing":  typing,
+	}
+	if typing {
+		data["expires_in"] = int64(t.timeout / time.Second)
+	}
+
+	payload, err := structpb.NewStruct(data)
+	if err != nil {
+		logger.Log.Error("Failed to create typing payload", zap.Error(err))
+		return
+	}
+
+	event := &gatewaypb.GatewayMessage{
+		Type:      gatewaypb.MessageType_TYPING,
+		Payload:   payload,
+		Timestamp: time.Now().Unix(),
+	}
+
+	if err := t.relay.PushEvent(ctx, recipients, event); err != nil {
+		logger.Log.Warn("Failed to relay typing status",
+			zap.Int64("user_id", userID),
+			zap.Int64("conv_id", convID),
+			zap.Bool("typing", typing),
+			zap.Error(err),
+		)
+	}
+}
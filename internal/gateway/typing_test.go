@@ -0,0 +1,235 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	gatewaypb "github.com/dollarkillerx/im-system/api/proto/gateway"
+	messagepb "github.com/dollarkillerx/im-system/api/proto/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mockTypingRelay records pushed typing events
+type mockTypingRelay struct {
+	mu          sync.Mutex
+	convs       map[int64]*messagepb.Conversation
+	memberCalls int
+	events      []typingEvent
+}
+
+type typingEvent struct {
+	recipients []int64
+	userID     int64
+	typing     bool
+}
+
+var errNotMember = errors.New("not a member")
+
+func newMockTypingRelay() *mockTypingRelay {
+	return &mockTypingRelay{
+		convs: map[int64]*messagepb.Conversation{1: groupConversation(1, 100, 200, 300)},
+	}
+}
+
+func groupConversation(convID int64, memberIDs ...int64) *messagepb.Conversation {
+	conv := &messagepb.Conversation{Id: convID, Type: messagepb.ConversationType_GROUP}
+	for _, memberID := range memberIDs {
+		conv.Members = append(conv.Members, &messagepb.ConversationMember{UserId: memberID, Role: messagepb.ConversationRole_MEMBER})
+	}
+	return conv
+}
+
+func (m *mockTypingRelay) GetConversation(ctx context.Context, convID int64, userID int64) (*messagepb.Conversation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.memberCalls++
+	conv := m.convs[convID]
+	for _, member := range conv.GetMembers() {
+		if member.UserId == userID {
+			return conv, nil
+		}
+	}
+	return nil, errNotMember
+}
+
+func (m *mockTypingRelay) PushEvent(ctx context.Context, recipientIDs []int64, event *gatewaypb.GatewayMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	payload := event.Payload.AsMap()
+	m.events = append(m.events, typingEvent{
+		recipients: recipientIDs,
+		userID:     int64(payload["user_id"].(float64)),
+		typing:     payload["typing"].(bool),
+	})
+	return nil
+}
+
+func (m *mockTypingRelay) snapshot() ([]typingEvent, int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]typingEvent(nil), m.events...), m.memberCalls
+}
+
+func TestTypingTracker_RelaysToOtherMembers(t *testing.T) {
+	relay := newMockTypingRelay()
+	tracker := NewTypingTracker(relay, time.Minute, time.Minute)
+
+	require.NoError(t, tracker.Start(context.Background(), 100, "device-1", 1))
+
+	events, _ := relay.snapshot()
+	require.Len(t, events, 1)
+	assert.True(t, events[0].typing)
+	assert.Equal(t, int64(100), events[0].userID)
+	assert.ElementsMatch(t, []int64{200, 300}, events[0].recipients)
+}
+
+func TestTypingTracker_NonMemberRejected(t *testing.T) {
+	relay := newMockTypingRelay()
+	tracker := NewTypingTracker(relay, time.Minute, time.Minute)
+
+	err := tracker.Start(context.Background(), 999, "device-1", 1)
+	assert.ErrorIs(t, err, errNotMember)
+
+	events, _ := relay.snapshot()
+	assert.Empty(t, events)
+
+	// stop without a prior start relays nothing
+	tracker.Stop(context.Background(), 999, 1)
+	events, _ = relay.snapshot()
+	assert.Empty(t, events)
+}
+
+func TestTypingTracker_SendPermissionRequired(t *testing.T) {
+	relay := newMockTypingRelay()
+	relay.convs[2] = &messagepb.Conversation{
+		Id:   2,
+		Type: messagepb.ConversationType_CHANNEL,
+		Members: []*messagepb.ConversationMember{
+			{UserId: 100, Role: messagepb.ConversationRole_OWNER},
+			{UserId: 200, Role: messagepb.ConversationRole_MEMBER},
+		},
+	}
+	relay.convs[1].Members[1].Muted = true
+	tracker := NewTypingTracker(relay, time.Minute, time.Minute)
+	ctx := context.Background()
+
+	// channel subscriber
+	err := tracker.Start(ctx, 200, "device-1", 2)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// muted group member
+	err = tracker.Start(ctx, 200, "device-1", 1)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	events, _ := relay.snapshot()
+	assert.Empty(t, events)
+
+	// the channel owner may still type
+	require.NoError(t, tracker.Start(ctx, 100, "device-1", 2))
+	events, _ = relay.snapshot()
+	require.Len(t, events, 1)
+	assert.Equal(t, []int64{200}, events[0].recipients)
+}
+
+func TestTypingTracker_Throttle(t *testing.T) {
+	relay := newMockTypingRelay()
+	tracker := NewTypingTracker(relay, time.Minute, time.Minute)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, tracker.Start(ctx, 100, "device-1", 1))
+	}
+
+	events, memberCalls := relay.snapshot()
+	assert.Len(t, events, 1)
+	assert.Equal(t, 1, memberCalls)
+
+	// other users are throttled independently
+	require.NoError(t, tracker.Start(ctx, 200, "device-1", 1))
+	events, _ = relay.snapshot()
+	assert.Len(t, events, 2)
+}
+
+func TestTypingTracker_Stop(t *testing.T) {
+	relay := newMockTypingRelay()
+	tracker := NewTypingTracker(relay, time.Minute, time.Minute)
+	ctx := context.Background()
+
+	require.NoError(t, tracker.Start(ctx, 100, "device-1", 1))
+	tracker.Stop(ctx, 100, 1)
+
+	events, _ := relay.snapshot()
+	require.Len(t, events, 2)
+	assert.False(t, events[1].typing)
+	assert.ElementsMatch(t, []int64{200, 300}, events[1].recipients)
+
+	// a new start after stop is relayed immediately
+	require.NoError(t, tracker.Start(ctx, 100, "device-1", 1))
+	events, _ = relay.snapshot()
+	assert.Len(t, events, 3)
+}
+
+func TestTypingTracker_Expiry(t *testing.T) {
+	relay := newMockTypingRelay()
+	tracker := NewTypingTracker(relay, time.Minute, 50*time.Millisecond)
+
+	require.NoError(t, tracker.Start(context.Background(), 100, "device-1", 1))
+
+	assert.Eventually(t, func() bool {
+		events, _ := relay.snapshot()
+		return len(events) == 2 && !events[1].typing
+	}, time.Second, 10*time.Millisecond)
+
+	tracker.mu.Lock()
+	assert.Empty(t, tracker.states)
+	tracker.mu.Unlock()
+}
+
+func TestTypingTracker_RefreshDelaysExpiry(t *testing.T) {
+	relay := newMockTypingRelay()
+	tracker := NewTypingTracker(relay, time.Minute, 100*time.Millisecond)
+	ctx := context.Background()
+
+	require.NoError(t, tracker.Start(ctx, 100, "device-1", 1))
+	time.Sleep(60 * time.Millisecond)
+	require.NoError(t, tracker.Start(ctx, 100, "device-1", 1))
+	time.Sleep(60 * time.Millisecond)
+
+	// the throttled refresh kept the indicator alive past the original timeout
+	events, _ := relay.snapshot()
+	assert.Len(t, events, 1)
+
+	assert.Eventually(t, func() bool {
+		events, _ := relay.snapshot()
+		return len(events) == 2 && !events[1].typing
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestTypingTracker_StopDevice(t *testing.T) {
+	relay := newMockTypingRelay()
+	relay.convs[2] = groupConversation(2, 100, 400)
+	tracker := NewTypingTracker(relay, time.Minute, time.Minute)
+	ctx := context.Background()
+
+	require.NoError(t, tracker.Start(ctx, 100, "device-1", 1))
+	require.NoError(t, tracker.Start(ctx, 100, "device-2", 2))
+
+	tracker.StopDevice(100, "device-1")
+
+	events, _ := relay.snapshot()
+	require.Len(t, events, 3)
+	assert.False(t, events[2].typing)
+	assert.ElementsMatch(t, []int64{200, 300}, events[2].recipients)
+
+	tracker.mu.Lock()
+	assert.Len(t, tracker.states, 1)
+	tracker.mu.Unlock()
+}
@@ -2,6 +2,7 @@ package message
 
 import (
 	"context"
+	"errors"
 
 	messagepb "github.com/dollarkillerx/im-system/api/proto/message"
 	"github.com/dollarkillerx/im-system/pkg/types"
@@ -44,10 +45,11 @@ func (s *GRPCServer) SendMessage(ctx context.Context, req *messagepb.SendMessage
 		body,
 		req.ReplyTo,
 		req.Mentions,
+		req.GetIdempotencyKey(),
 	)
 
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to send message: %v", err)
+		return nil, status.Errorf(errorCode(err), "failed to send message: %v", err)
 	}
 
 	return &messagepb.SendMessageResponse{
@@ -58,90 +60,54 @@ func (s *GRPCServer) SendMessage(ctx context.Context, req *messagepb.SendMessage
 }
 
 func (s *GRPCServer) PullMessages(ctx context.Context, req *messagepb.PullMessagesRequest) (*messagepb.PullMessagesResponse, error) {
-	messages, hasMore, err := s.service.PullMessages(ctx, req.ConvId, req.SinceSeq, req.Limit)
+	var (
+		messages                    []*Message
+		hasMoreBefore, hasMoreAfter bool
+		err                         error
+	)
+	switch req.Direction {
+	case messagepb.PullDirection_FORWARD:
+		messages, hasMoreAfter, err = s.service.PullMessages(ctx, req.ConvId, req.UserId, req.SinceSeq, req.Limit)
+	case messagepb.PullDirection_BACKWARD:
+		messages, hasMoreBefore, err = s.service.PullMessagesBefore(ctx, req.ConvId, req.UserId, req.BeforeSeq, req.Limit)
+	case messagepb.PullDirection_AROUND:
+		messages, hasMoreBefore, hasMoreAfter, err = s.service.PullMessagesAround(ctx, req.ConvId, req.UserId, req.AroundSeq, req.Limit)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid pull direction")
+	}
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to pull messages: %v", err)
+		return nil, status.Errorf(errorCode(err), "failed to pull messages: %v", err)
 	}
 
 	var pbMessages []*messagepb.Message
 	for _, msg := range messages {
-		// 转换 conv_type
-		var pbConvType messagepb.ConversationType
-		switch msg.ConvType {
-		case types.ConversationTypeDirect:
-			pbConvType = messagepb.ConversationType_DIRECT
-		case types.ConversationTypeGroup:
-			pbConvType = messagepb.ConversationType_GROUP
-		case types.ConversationTypeChannel:
-			pbConvType = messagepb.ConversationType_CHANNEL
-		}
-
-		// 转换 body
-		bodyStruct, err := structpb.NewStruct(msg.Body)
+		pbMsg, err := toPBMessage(msg)
 		if err != nil {
 			return nil, status.Errorf(codes.Internal, "failed to convert body: %v", err)
 		}
-
-		pbMsg := &messagepb.Message{
-			MsgId:      msg.MsgID,
-			ConvId:     msg.ConvID,
-			Seq:        msg.Seq,
-			SenderId:   msg.SenderID,
-			ConvType:   pbConvType,
-			Body:       bodyStruct,
-			ReplyTo:    msg.ReplyTo,
-			Mentions:   msg.Mentions,
-			Visibility: msg.Visibility,
-			CreatedAt:  msg.CreatedAt.Unix(),
-		}
-
 		pbMessages = append(pbMessages, pbMsg)
 	}
 
 	return &messagepb.PullMessagesResponse{
-		Messages: pbMessages,
-		HasMore:  hasMore,
+		Messages:      pbMessages,
+		HasMore:       hasMoreBefore || hasMoreAfter,
+		HasMoreBefore: hasMoreBefore,
+		HasMoreAfter:  hasMoreAfter,
 	}, nil
 }
 
 func (s *GRPCServer) GetConversation(ctx context.Context, req *messagepb.GetConversationRequest) (*messagepb.GetConversationResponse, error) {
-	conv, members, err := s.service.GetConversation(ctx, req.ConvId)
+	conv, members, err := s.service.GetConversation(ctx, req.ConvId, req.UserId)
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "conversation not found: %v", err)
-	}
-
-	// 转换 conv_type
-	var pbConvType messagepb.ConversationType
-	switch conv.Type {
-	case types.ConversationTypeDirect:
-		pbConvType = messagepb.ConversationType_DIRECT
-	case types.ConversationTypeGroup:
-		pbConvType = messagepb.ConversationType_GROUP
-	case types.ConversationTypeChannel:
-		pbConvType = messagepb.ConversationType_CHANNEL
+		return nil, status.Errorf(errorCode(err), "failed to get conversation: %v", err)
 	}
 
 	// 转换成员列表
 	var pbMembers []*messagepb.ConversationMember
 	for _, member := range members {
-		// 转换 role
-		var pbRole messagepb.ConversationRole
-		switch member.Role {
-		case types.ConversationRoleOwner:
-			pbRole = messagepb.ConversationRole_OWNER
-		case types.ConversationRoleAdmin:
-			pbRole = messagepb.ConversationRole_ADMIN
-		case types.ConversationRolePublisher:
-			pbRole = messagepb.ConversationRole_PUBLISHER
-		case types.ConversationRoleMember:
-			pbRole = messagepb.ConversationRole_MEMBER
-		case types.ConversationRoleViewer:
-			pbRole = messagepb.ConversationRole_VIEWER
-		}
-
 		pbMembers = append(pbMembers, &messagepb.ConversationMember{
 			UserId:      member.UserID,
-			Role:        pbRole,
+			Role:        toPBRole(member.Role),
 			Muted:       member.Muted,
 			LastReadSeq: member.LastReadSeq,
 			JoinedAt:    member.JoinedAt.Unix(),
@@ -151,7 +117,7 @@ func (s *GRPCServer) GetConversation(ctx context.Context, req *messagepb.GetConv
 	return &messagepb.GetConversationResponse{
 		Conversation: &messagepb.Conversation{
 			Id:        conv.ID,
-			Type:      pbConvType,
+			Type:      toPBConvType(conv.Type),
 			Title:     conv.Title,
 			OwnerId:   conv.OwnerID,
 			CreatedAt: conv.CreatedAt.Unix(),
@@ -176,7 +142,7 @@ func (s *GRPCServer) CreateConversation(ctx context.Context, req *messagepb.Crea
 
 	convID, err := s.service.CreateConversation(ctx, convType, req.Title, req.OwnerId, req.MemberIds)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to create conversation: %v", err)
+		return nil, status.Errorf(errorCode(err), "failed to create conversation: %v", err)
 	}
 
 	return &messagepb.CreateConversationResponse{
@@ -185,10 +151,22 @@ func (s *GRPCServer) CreateConversation(ctx context.Context, req *messagepb.Crea
 	}, nil
 }
 
+func (s *GRPCServer) GetOrCreateDirectConversation(ctx context.Context, req *messagepb.GetOrCreateDirectConversationRequest) (*messagepb.GetOrCreateDirectConversationResponse, error) {
+	convID, created, err := s.service.GetOrCreateDirectConversation(ctx, req.UserId, req.PeerId)
+	if err != nil {
+		return nil, status.Errorf(errorCode(err), "failed to get or create direct conversation: %v", err)
+	}
+
+	return &messagepb.GetOrCreateDirectConversationResponse{
+		ConvId:  convID,
+		Created: created,
+	}, nil
+}
+
 func (s *GRPCServer) UpdateReadSeq(ctx context.Context, req *messagepb.UpdateReadSeqRequest) (*messagepb.UpdateReadSeqResponse, error) {
 	err := s.service.UpdateReadSeq(ctx, req.ConvId, req.UserId, req.Seq)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to update read seq: %v", err)
+		return nil, status.Errorf(errorCode(err), "failed to update read seq: %v", err)
 	}
 
 	return &messagepb.UpdateReadSeqResponse{
@@ -204,3 +182,240 @@ func (s *GRPCServer) NotifyNewMessage(ctx context.Context, req *messagepb.Notify
 		NotifiedCount: int32(len(req.RecipientIds)),
 	}, nil
 }
+
+func (s *GRPCServer) AddMembers(ctx context.Context, req *messagepb.AddMembersRequest) (*messagepb.AddMembersResponse, error) {
+	role := types.ConversationRoleMember
+	if req.Role != nil {
+		role = fromPBRole(*req.Role)
+	}
+
+	added, seq, err := s.service.AddMembers(ctx, req.ConvId, req.OperatorId, req.MemberIds, role)
+	if err != nil {
+		return nil, status.Errorf(errorCode(err), "failed to add members: %v", err)
+	}
+
+	return &messagepb.AddMembersResponse{
+		AddedIds: added,
+		Seq:      seq,
+	}, nil
+}
+
+func (s *GRPCServer) RemoveMembers(ctx context.Context, req *messagepb.RemoveMembersRequest) (*messagepb.RemoveMembersResponse, error) {
+	removed, seq, err := s.service.RemoveMembers(ctx, req.ConvId, req.OperatorId, req.MemberIds)
+	if err != nil {
+		return nil, status.Errorf(errorCode(err), "failed to remove members: %v", err)
+	}
+
+	return &messagepb.RemoveMembersResponse{
+		RemovedIds: removed,
+		Seq:        seq,
+	}, nil
+}
+
+func (s *GRPCServer) UpdateMemberRole(ctx context.Context, req *messagepb.UpdateMemberRoleRequest) (*messagepb.UpdateMemberRoleResponse, error) {
+	seq, err := s.service.UpdateMemberRole(ctx, req.ConvId, req.OperatorId, req.UserId, fromPBRole(req.Role))
+	if err != nil {
+		return nil, status.Errorf(errorCode(err), "failed to update member role: %v", err)
+	}
+
+	return &messagepb.UpdateMemberRoleResponse{
+		Success: true,
+		Seq:     seq,
+	}, nil
+}
+
+func (s *GRPCServer) LeaveConversation(ctx context.Context, req *messagepb.LeaveConversationRequest) (*messagepb.LeaveConversationResponse, error) {
+	seq, err := s.service.LeaveConversation(ctx, req.ConvId, req.UserId)
+	if err != nil {
+		return nil, status.Errorf(errorCode(err), "failed to leave conversation: %v", err)
+	}
+
+	return &messagepb.LeaveConversationResponse{
+		Success: true,
+		Seq:     seq,
+	}, nil
+}
+
+func (s *GRPCServer) TransferOwnership(ctx context.Context, req *messagepb.TransferOwnershipRequest) (*messagepb.TransferOwnershipResponse, error) {
+	seq, err := s.service.TransferOwnership(ctx, req.ConvId, req.OwnerId, req.NewOwnerId)
+	if err != nil {
+		return nil, status.Errorf(errorCode(err), "failed to transfer ownership: %v", err)
+	}
+
+	return &messagepb.TransferOwnershipResponse{
+		Success: true,
+		Seq:     seq,
+	}, nil
+}
+
+func (s *GRPCServer) ListConversations(ctx context.Context, req *messagepb.ListConversationsRequest) (*messagepb.ListConversationsResponse, error) {
+	summaries, nextCursor, hasMore, err := s.service.ListConversations(ctx, req.UserId, req.Cursor, req.Limit)
+	if err != nil {
+		return nil, status.Errorf(errorCode(err), "failed to list conversations: %v", err)
+	}
+
+	pbSummaries := make([]*messagepb.ConversationSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		pbSummary := &messagepb.ConversationSummary{
+			ConvId:       summary.ID,
+			Type:         toPBConvType(summary.Type),
+			Title:        summary.Title,
+			OwnerId:      summary.OwnerID,
+			Role:         toPBRole(summary.Role),
+			Muted:        summary.Muted,
+			CurrentSeq:   summary.CurrentSeq,
+			LastReadSeq:  summary.LastReadSeq,
+			UnreadCount:  summary.UnreadCount,
+			LastActiveAt: summary.LastActiveAt.Unix(),
+			CreatedAt:    summary.CreatedAt.Unix(),
+		}
+
+		if summary.LastMessage != nil {
+			pbMsg, err := toPBMessage(summary.LastMessage)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to convert body: %v", err)
+			}
+			pbSummary.LastMessage = pbMsg
+		}
+
+		pbSummaries = append(pbSummaries, pbSummary)
+	}
+
+	return &messagepb.ListConversationsResponse{
+		Conversations: pbSummaries,
+		NextCursor:    nextCursor,
+		HasMore:       hasMore,
+	}, nil
+}
+
+// RecallMessage 撤回消息
+func (s *GRPCServer) RecallMessage(ctx context.Context, req *messagepb.RecallMessageRequest) (*messagepb.RecallMessageResponse, error) {
+	seq, err := s.service.RecallMessage(ctx, req.ConvId, req.MsgId, req.OperatorId)
+	if err != nil {
+		return nil, status.Errorf(errorCode(err), "failed to recall message: %v", err)
+	}
+
+	return &messagepb.RecallMessageResponse{
+		Success: true,
+		Seq:     seq,
+	}, nil
+}
+
+// EditMessage 编辑消息
+func (s *GRPCServer) EditMessage(ctx context.Context, req *messagepb.EditMessageRequest) (*messagepb.EditMessageResponse, error) {
+	seq, editedAt, err := s.service.EditMessage(ctx, req.ConvId, req.MsgId, req.OperatorId, req.Body.AsMap())
+	if err != nil {
+		return nil, status.Errorf(errorCode(err), "failed to edit message: %v", err)
+	}
+
+	return &messagepb.EditMessageResponse{
+		Success:  true,
+		Seq:      seq,
+		EditedAt: editedAt,
+	}, nil
+}
+
+func (s *GRPCServer) FilterPeers(ctx context.Context, req *messagepb.FilterPeersRequest) (*messagepb.FilterPeersResponse, error) {
+	peerIDs, err := s.service.FilterPeers(ctx, req.UserId, req.UserIds)
+	if err != nil {
+		return nil, status.Errorf(errorCode(err), "failed to filter peers: %v", err)
+	}
+
+	return &messagepb.FilterPeersResponse{
+		UserIds: peerIDs,
+	}, nil
+}
+
+// toPBMessage 转换消息为 protobuf 消息
+func toPBMessage(msg *Message) (*messagepb.Message, error) {
+	bodyStruct, err := structpb.NewStruct(msg.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	pbMsg := &messagepb.Message{
+		MsgId:      msg.MsgID,
+		ConvId:     msg.ConvID,
+		Seq:        msg.Seq,
+		SenderId:   msg.SenderID,
+		ConvType:   toPBConvType(msg.ConvType),
+		Body:       bodyStruct,
+		ReplyTo:    msg.ReplyTo,
+		Mentions:   msg.Mentions,
+		Visibility: msg.Visibility,
+		CreatedAt:  msg.CreatedAt.Unix(),
+	}
+	if msg.EditedAt != nil {
+		editedAt := msg.EditedAt.Unix()
+		pbMsg.EditedAt = &editedAt
+	}
+
+	return pbMsg, nil
+}
+
+// toPBConvType 转换会话类型为 protobuf 枚举
+func toPBConvType(convType types.ConversationType) messagepb.ConversationType {
+	switch convType {
+	case types.ConversationTypeGroup:
+		return messagepb.ConversationType_GROUP
+	case types.ConversationTypeChannel:
+		return messagepb.ConversationType_CHANNEL
+	default:
+		return messagepb.ConversationType_DIRECT
+	}
+}
+
+// toPBRole 转换成员角色为 protobuf 枚举
+func toPBRole(role types.ConversationRole) messagepb.ConversationRole {
+	switch role {
+	case types.ConversationRoleOwner:
+		return messagepb.ConversationRole_OWNER
+	case types.ConversationRoleAdmin:
+		return messagepb.ConversationRole_ADMIN
+	case types.ConversationRolePublisher:
+		return messagepb.ConversationRole_PUBLISHER
+	case types.ConversationRoleViewer:
+		return messagepb.ConversationRole_VIEWER
+	default:
+		return messagepb.ConversationRole_MEMBER
+	}
+}
+
+// fromPBRole 转换 protobuf 枚举为成员角色
+func fromPBRole(role messagepb.ConversationRole) types.ConversationRole {
+	switch role {
+	case messagepb.ConversationRole_OWNER:
+		return types.ConversationRoleOwner
+	case messagepb.ConversationRole_ADMIN:
+		return types.ConversationRoleAdmin
+	case messagepb.ConversationRole_PUBLISHER:
+		return types.ConversationRolePublisher
+	case messagepb.ConversationRole_MEMBER:
+		return types.ConversationRoleMember
+	case messagepb.ConversationRole_VIEWER:
+		return types.ConversationRoleViewer
+	default:
+		return types.ConversationRole("")
+	}
+}
+
+// errorCode 将业务错误映射为 gRPC 状态码
+func errorCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, ErrConversationNotFound), errors.Is(err, ErrMessageNotFound), errors.Is(err, ErrMemberNotFound):
+		return codes.NotFound
+	case errors.Is(err, ErrNotMember), errors.Is(err, ErrMemberMuted), errors.Is(err, ErrSendNotAllowed),
+		errors.Is(err, ErrManageNotAllowed), errors.Is(err, ErrCannotModifyOwner), errors.Is(err, ErrNotMessageSender):
+		return codes.PermissionDenied
+	case errors.Is(err, ErrConvTypeMismatch), errors.Is(err, ErrInvalidRole), errors.Is(err, ErrInvalidTarget),
+		errors.Is(err, ErrInvalidCursor), errors.Is(err, ErrInvalidDirectMembers), errors.Is(err, ErrInvalidIdempotencyKey),
+		errors.Is(err, ErrSystemMessage), errors.Is(err, ErrEmptyBody), errors.Is(err, ErrInvalidAnchor),
+		errors.Is(err, ErrInvalidReadSeq):
+		return codes.InvalidArgument
+	case errors.Is(err, ErrDirectConversation), errors.Is(err, ErrOwnerCannotLeave),
+		errors.Is(err, ErrMessageRecalled), errors.Is(err, ErrRecallWindowExpired):
+		return codes.FailedPrecondition
+	default:
+		return codes.Internal
+	}
+}
@@ -2,30 +2,78 @@ package message
 
 import (
 	"context"
+	"time"
 
 	"github.com/dollarkillerx/im-system/pkg/types"
 )
 
 // MessageRepository defines the interface for message data persistence
 type MessageRepository interface {
-	// SaveMessage saves a message to the database
+	// SaveMessage allocates the next sequence number of the conversation and saves the message in one
+	// transaction, setting msg.Seq on success; a failed save does not consume a sequence number
 	SaveMessage(ctx context.Context, msg *Message) error
 
+	// SaveMessageIdempotent behaves like SaveMessage and also records the idempotency key; if the key was already
+	// used by the same sender in the conversation since the given time, nothing is saved and the original message is returned
+	SaveMessageIdempotent(ctx context.Context, msg *Message, key string, since time.Time) (*Message, error)
+
+	// GetMessage retrieves a single message; recalled content is redacted
+	GetMessage(ctx context.Context, convID int64, msgID string) (*Message, error)
+
+	// RecallMessage marks a message as recalled and appends the recall event in one transaction, setting event.Seq
+	RecallMessage(ctx context.Context, convID int64, msgID string, event *Message) error
+
+	// EditMessage replaces a message body, records the edit history and appends the edit event in one transaction,
+	// setting event.Seq and returning the edit time
+	EditMessage(ctx context.Context, convID int64, msgID string, editorID int64, body map[string]interface{}, event *Message) (time.Time, error)
+
+	// GetIdempotentMessage looks up a message sent with the given idempotency key since the given time
+	GetIdempotentMessage(ctx context.Context, convID int64, senderID int64, key string, since time.Time) (*Message, bool, error)
+
 	// PullMessages retrieves messages from a conversation since a given sequence number
 	PullMessages(ctx context.Context, convID int64, sinceSeq int64, limit int32) ([]*Message, bool, error)
 
-	// GetNextSeq generates the next sequence number for a conversation
-	GetNextSeq(ctx context.Context, convID int64) (int64, error)
+	// PullMessagesBefore retrieves the newest messages below beforeSeq (0 means the latest) in ascending order;
+	// the bool reports whether older messages remain
+	PullMessagesBefore(ctx context.Context, convID int64, beforeSeq int64, limit int32) ([]*Message, bool, error)
 
 	// CreateConversation creates a new conversation with members
 	CreateConversation(ctx context.Context, convType types.ConversationType, title string, ownerID int64, memberIDs []int64) (int64, error)
 
+	// GetOrCreateDirectConversation returns the direct conversation between two users, creating it if needed;
+	// the bool reports whether a new conversation was created
+	GetOrCreateDirectConversation(ctx context.Context, userID int64, peerID int64) (int64, bool, error)
+
 	// GetConversation retrieves conversation details and its members
 	GetConversation(ctx context.Context, convID int64) (*Conversation, []*ConversationMember, error)
 
-	// UpdateReadSeq updates the last read sequence number for a user in a conversation
-	UpdateReadSeq(ctx context.Context, convID int64, userID int64, seq int64) error
+	// UpdateReadSeq moves the last read sequence number of a user forward; the bool reports whether it advanced
+	UpdateReadSeq(ctx context.Context, convID int64, userID int64, seq int64) (bool, error)
 
 	// GetConversationMembers retrieves all member IDs of a conversation
 	GetConversationMembers(ctx context.Context, convID int64) ([]int64, error)
+
+	// FilterPeers returns the given users that share at least one conversation with userID
+	FilterPeers(ctx context.Context, userID int64, userIDs []int64) ([]int64, error)
+
+	// GetMembership retrieves a conversation together with a single user's membership in it
+	GetMembership(ctx context.Context, convID int64, userID int64) (*Conversation, *ConversationMember, error)
+
+	// AddMembers adds users to a conversation with the given role, skipping existing members, and returns the IDs actually added.
+	// If any were added, the event built by newEvent is appended in the same transaction and its Seq set
+	AddMembers(ctx context.Context, convID int64, userIDs []int64, role types.ConversationRole, newEvent func(added []int64) *Message) ([]int64, error)
+
+	// RemoveMembers removes users from a conversation and returns the IDs actually removed.
+	// If any were removed, the event built by newEvent is appended in the same transaction and its Seq set
+	RemoveMembers(ctx context.Context, convID int64, userIDs []int64, newEvent func(removed []int64) *Message) ([]int64, error)
+
+	// UpdateMemberRole changes the role of a conversation member and appends the system event in one transaction, setting event.Seq
+	UpdateMemberRole(ctx context.Context, convID int64, userID int64, role types.ConversationRole, event *Message) error
+
+	// TransferOwnership makes newOwnerID the owner of the conversation, demotes the current owner to admin
+	// and appends the system event in one transaction, setting event.Seq
+	TransferOwnership(ctx context.Context, convID int64, ownerID int64, newOwnerID int64, event *Message) error
+
+	// ListConversations retrieves a page of the user's conversations ordered by last activity, newest first
+	ListConversations(ctx context.Context, userID int64, cursor *ConversationCursor, limit int32) ([]*ConversationSummary, bool, error)
 }
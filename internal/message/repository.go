@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,6 +13,29 @@ import (
 	"github.com/lib/pq"
 )
 
+var (
+	// ErrConversationNotFound 会话不存在
+	ErrConversationNotFound = errors.New("conversation not found")
+	// ErrNotMember 用户不是会话成员
+	ErrNotMember = errors.New("not a member of the conversation")
+	// ErrMemberNotFound 被操作的用户不是会话成员
+	ErrMemberNotFound = errors.New("member not found")
+	// ErrInvalidReadSeq 已读序列号超过会话当前序列号
+	ErrInvalidReadSeq = errors.New("read seq is beyond the latest message")
+	// ErrDuplicateSeq 会话中已存在相同序列号的消息
+	ErrDuplicateSeq = errors.New("duplicate message seq")
+	// ErrMessageNotFound 消息不存在
+	ErrMessageNotFound = errors.New("message not found")
+	// ErrMessageRecalled 消息已被撤回
+	ErrMessageRecalled = errors.New("message has been recalled")
+)
+
+// 消息可见性
+const (
+	VisibilityNormal   = "normal"
+	VisibilityRecalled = "recalled"
+)
+
 type Message struct {
 	MsgID      string
 	ConvID     int64
@@ -23,6 +47,22 @@ type Message struct {
 	Mentions   []int64
 	Visibility string
 	CreatedAt  time.Time
+	EditedAt   *time.Time
+}
+
+// redactRecalled 隐藏已撤回消息的内容，所有读路径返回前都必须调用
+// 被撤回的编辑事件仅去掉其中携带的消息内容
+func redactRecalled(msg *Message) {
+	if msg.Visibility != VisibilityRecalled {
+		return
+	}
+	if msg.Body["type"] == "system" {
+		delete(msg.Body, "body")
+		return
+	}
+	msg.Body = map[string]interface{}{"type": VisibilityRecalled}
+	msg.ReplyTo = nil
+	msg.Mentions = nil
 }
 
 type Conversation struct {
@@ -42,6 +82,24 @@ type ConversationMember struct {
 	JoinedAt    time.Time
 }
 
+// ConversationSummary 会话列表项
+type ConversationSummary struct {
+	Conversation
+	Role         types.ConversationRole
+	Muted        bool
+	CurrentSeq   int64
+	LastReadSeq  int64
+	UnreadCount  int64 // 已读位置之后其他成员发送的普通消息数，不含自己的消息和系统消息
+	LastMessage  *Message
+	LastActiveAt time.Time
+}
+
+// ConversationCursor 会话列表分页游标，指向上一页最后一个会话
+type ConversationCursor struct {
+	LastActiveAt time.Time
+	ConvID       int64
+}
+
 type Repository struct {
 	db *sql.DB
 }
@@ -58,9 +116,96 @@ func (r *Repository) CreateConversation(ctx context.Context, convType types.Conv
 	}
 	defer tx.Rollback()
 
+	convID, err := insertConversation(ctx, tx, convType, title, ownerID, memberIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return convID, nil
+}
+
+// GetOrCreateDirectConversation 获取两个用户之间的单聊会话，不存在时创建
+// 并发创建时由 direct_conversations 主键保证只有一方成功，另一方返回已存在的会话
+func (r *Repository) GetOrCreateDirectConversation(ctx context.Context, userID int64, peerID int64) (int64, bool, error) {
+	low, high := userID, peerID
+	if low > high {
+		low, high = high, low
+	}
+
+	convID, err := r.findDirectConversation(ctx, low, high)
+	if err == nil {
+		return convID, false, nil
+	}
+	if !errors.Is(err, ErrConversationNotFound) {
+		return 0, false, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	convID, err = insertConversation(ctx, tx, types.ConversationTypeDirect, "", userID, []int64{userID, peerID})
+	if err != nil {
+		return 0, false, err
+	}
+
+	var indexedID int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO direct_conversations (user_low, user_high, conv_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_low, user_high) DO NOTHING
+		RETURNING conv_id
+	`, low, high, convID).Scan(&indexedID)
+
+	if err == sql.ErrNoRows {
+		// 另一方已抢先创建，放弃本次创建
+		tx.Rollback()
+		convID, err := r.findDirectConversation(ctx, low, high)
+		if err != nil {
+			return 0, false, err
+		}
+		return convID, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to index direct conversation: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return convID, true, nil
+}
+
+// findDirectConversation 查找用户对对应的单聊会话，low 必须小于 high
+func (r *Repository) findDirectConversation(ctx context.Context, low int64, high int64) (int64, error) {
+	var convID int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT conv_id FROM direct_conversations
+		WHERE user_low = $1 AND user_high = $2
+	`, low, high).Scan(&convID)
+
+	if err == sql.ErrNoRows {
+		return 0, ErrConversationNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to find direct conversation: %w", err)
+	}
+
+	return convID, nil
+}
+
+// insertConversation 在事务中创建会话、初始化序列并添加成员
+func insertConversation(ctx context.Context, tx *sql.Tx, convType types.ConversationType, title string, ownerID int64, memberIDs []int64) (int64, error) {
 	// 创建会话
 	var convID int64
-	err = tx.QueryRowContext(ctx, `
+	err := tx.QueryRowContext(ctx, `
 		INSERT INTO conversations (type, title, owner_id, created_at)
 		VALUES ($1, $2, $3, NOW())
 		RETURNING id
@@ -97,10 +242,6 @@ func (r *Repository) CreateConversation(ctx context.Context, convType types.Conv
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return 0, fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return convID, nil
 }
 
@@ -116,7 +257,7 @@ func (r *Repository) GetConversation(ctx context.Context, convID int64) (*Conver
 	`, convID).Scan(&conv.ID, &convType, &conv.Title, &conv.OwnerID, &conv.CreatedAt)
 
 	if err == sql.ErrNoRows {
-		return nil, nil, fmt.Errorf("conversation not found")
+		return nil, nil, ErrConversationNotFound
 	}
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get conversation: %w", err)
@@ -152,43 +293,145 @@ func (r *Repository) GetConversation(ctx context.Context, convID int64) (*Conver
 	return conv, members, nil
 }
 
-// SaveMessage 保存消息
+// SaveMessage 在同一事务中分配序列号并保存消息，成功后设置 msg.Seq
+// 保存失败时事务回滚，序列号不会被消耗
 func (r *Repository) SaveMessage(ctx context.Context, msg *Message) error {
-	bodyJSON, err := json.Marshal(msg.Body)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to marshal body: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	_, err = r.db.ExecContext(ctx, `
-		INSERT INTO messages (conv_id, seq, msg_id, sender_id, conv_type, body, reply_to, mentions, visibility, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
-	`, msg.ConvID, msg.Seq, msg.MsgID, msg.SenderID, msg.ConvType.String(), bodyJSON, msg.ReplyTo, pq.Array(msg.Mentions), msg.Visibility)
-
+	seq, err := insertMessage(ctx, tx, msg)
 	if err != nil {
-		return fmt.Errorf("failed to save message: %w", err)
+		return err
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	msg.Seq = seq
 	return nil
 }
 
-// GetNextSeq 获取下一个序列号
-func (r *Repository) GetNextSeq(ctx context.Context, convID int64) (int64, error) {
-	var seq int64
-	err := r.db.QueryRowContext(ctx, `SELECT next_conv_seq($1)`, convID).Scan(&seq)
+// GetIdempotentMessage 查找 since 之后使用同一幂等键发送的消息
+func (r *Repository) GetIdempotentMessage(ctx context.Context, convID int64, senderID int64, key string, since time.Time) (*Message, bool, error) {
+	msg := &Message{ConvID: convID, SenderID: senderID}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT msg_id, seq, created_at
+		FROM message_idempotency
+		WHERE conv_id = $1 AND sender_id = $2 AND idempotency_key = $3 AND created_at >= $4
+	`, convID, senderID, key, since).Scan(&msg.MsgID, &msg.Seq, &msg.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get idempotent message: %w", err)
+	}
+
+	return msg, true, nil
+}
+
+// SaveMessageIdempotent 与 SaveMessage 相同，但同时登记幂等键，返回实际生效的消息
+// 若 since 之后已有消息使用同一幂等键（包括并发请求），则回滚本次保存并返回原消息
+func (r *Repository) SaveMessageIdempotent(ctx context.Context, msg *Message, key string, since time.Time) (*Message, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	seq, err := insertMessage(ctx, tx, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	// 登记幂等键，已过期的记录会被覆盖
+	var reservedID string
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO message_idempotency (conv_id, sender_id, idempotency_key, msg_id, seq, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (conv_id, sender_id, idempotency_key) DO UPDATE
+		SET msg_id = EXCLUDED.msg_id, seq = EXCLUDED.seq, created_at = EXCLUDED.created_at
+		WHERE message_idempotency.created_at < $6
+		RETURNING msg_id
+	`, msg.ConvID, msg.SenderID, key, msg.MsgID, seq, since).Scan(&reservedID)
+
+	if err == sql.ErrNoRows {
+		// 回滚后序列号和消息都不会保留
+		tx.Rollback()
+		original, found, err := r.GetIdempotentMessage(ctx, msg.ConvID, msg.SenderID, key, since)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, fmt.Errorf("idempotency key %q conflicted but no message was found", key)
+		}
+		return original, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	msg.Seq = seq
+	return msg, nil
+}
+
+// insertMessage 在事务中分配序列号并插入消息，返回分配的序列号
+// next_conv_seq 会锁定会话序列行直到事务结束，同一会话的写入因此按序列号顺序提交
+func insertMessage(ctx context.Context, tx *sql.Tx, msg *Message) (int64, error) {
+	bodyJSON, err := json.Marshal(msg.Body)
 	if err != nil {
+		return 0, fmt.Errorf("failed to marshal body: %w", err)
+	}
+
+	var seq int64
+	if err := tx.QueryRowContext(ctx, `SELECT next_conv_seq($1)`, msg.ConvID).Scan(&seq); err != nil {
 		return 0, fmt.Errorf("failed to get next seq: %w", err)
 	}
+
+	// message_seqs 主键保证 (conv_id, seq) 唯一
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO message_seqs (conv_id, seq, msg_id, created_at)
+		VALUES ($1, $2, $3, NOW())
+	`, msg.ConvID, seq, msg.MsgID)
+
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return 0, fmt.Errorf("%w: conv %d seq %d", ErrDuplicateSeq, msg.ConvID, seq)
+		}
+		return 0, fmt.Errorf("failed to index message seq: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO messages (conv_id, seq, msg_id, sender_id, conv_type, body, reply_to, mentions, visibility, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+	`, msg.ConvID, seq, msg.MsgID, msg.SenderID, msg.ConvType.String(), bodyJSON, msg.ReplyTo, pq.Array(msg.Mentions), msg.Visibility)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to save message: %w", err)
+	}
+
 	return seq, nil
 }
 
+// MaxPullLimit 单次拉取消息的最大数量
+const MaxPullLimit = 100
+
 // PullMessages 拉取消息
 func (r *Repository) PullMessages(ctx context.Context, convID int64, sinceSeq int64, limit int32) ([]*Message, bool, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 100
+	if limit <= 0 || limit > MaxPullLimit {
+		limit = MaxPullLimit
 	}
 
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT msg_id, conv_id, seq, sender_id, conv_type, body, reply_to, mentions, visibility, created_at
+		SELECT `+messageColumns+`
 		FROM messages
 		WHERE conv_id = $1 AND seq > $2
 		ORDER BY seq ASC
@@ -202,64 +445,270 @@ func (r *Repository) PullMessages(ctx context.Context, convID int64, sinceSeq in
 
 	var messages []*Message
 	for rows.Next() {
-		msg := &Message{}
-		var bodyJSON []byte
-		var convType string
-		var mentions pq.Int64Array
-
-		err := rows.Scan(
-			&msg.MsgID,
-			&msg.ConvID,
-			&msg.Seq,
-			&msg.SenderID,
-			&convType,
-			&bodyJSON,
-			&msg.ReplyTo,
-			&mentions,
-			&msg.Visibility,
-			&msg.CreatedAt,
-		)
-
+		msg, err := scanMessage(rows)
 		if err != nil {
-			return nil, false, fmt.Errorf("failed to scan message: %w", err)
+			return nil, false, err
 		}
+		messages = append(messages, msg)
+	}
 
-		msg.ConvType = types.ConversationType(convType)
-		msg.Mentions = []int64(mentions)
+	// 判断是否还有更多消息
+	hasMore := false
+	if len(messages) > int(limit) {
+		hasMore = true
+		messages = messages[:limit]
+	}
 
-		// 解析 body
-		if err := json.Unmarshal(bodyJSON, &msg.Body); err != nil {
-			return nil, false, fmt.Errorf("failed to unmarshal body: %w", err)
-		}
+	return messages, hasMore, nil
+}
+
+// PullMessagesBefore 向前翻页拉取 beforeSeq 之前的历史消息，beforeSeq 为 0 时从最新消息开始
+// 按 seq 倒序走 idx_messages_conv_seq 索引，返回结果按 seq 升序排列
+func (r *Repository) PullMessagesBefore(ctx context.Context, convID int64, beforeSeq int64, limit int32) ([]*Message, bool, error) {
+	if limit <= 0 || limit > MaxPullLimit {
+		limit = MaxPullLimit
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+messageColumns+`
+		FROM messages
+		WHERE conv_id = $1 AND ($2 = 0 OR seq < $2)
+		ORDER BY seq DESC
+		LIMIT $3
+	`, convID, beforeSeq, limit+1) // 多查一条判断是否还有更早的消息
+
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to pull messages: %w", err)
+	}
+	defer rows.Close()
 
+	var messages []*Message
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, false, err
+		}
 		messages = append(messages, msg)
 	}
 
-	// 判断是否还有更多消息
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("failed to iterate messages: %w", err)
+	}
+
 	hasMore := false
 	if len(messages) > int(limit) {
 		hasMore = true
 		messages = messages[:limit]
 	}
 
+	// 翻转为升序
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+
 	return messages, hasMore, nil
 }
 
-// UpdateReadSeq 更新已读位置
-func (r *Repository) UpdateReadSeq(ctx context.Context, convID int64, userID int64, seq int64) error {
-	_, err := r.db.ExecContext(ctx, `
-		UPDATE conversation_members
-		SET last_read_seq = $1
-		WHERE conv_id = $2 AND user_id = $3 AND last_read_seq < $1
-	`, seq, convID, userID)
+// messageColumns scanMessage 对应的查询列
+const messageColumns = `msg_id, conv_id, seq, sender_id, conv_type, body, reply_to, mentions, visibility, created_at, edited_at`
+
+// rowScanner 兼容 *sql.Row 与 *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanMessage 扫描一行 messageColumns 并隐藏已撤回的内容
+func scanMessage(row rowScanner) (*Message, error) {
+	msg := &Message{}
+	var bodyJSON []byte
+	var convType string
+	var mentions pq.Int64Array
+	var editedAt sql.NullTime
+
+	err := row.Scan(
+		&msg.MsgID,
+		&msg.ConvID,
+		&msg.Seq,
+		&msg.SenderID,
+		&convType,
+		&bodyJSON,
+		&msg.ReplyTo,
+		&mentions,
+		&msg.Visibility,
+		&msg.CreatedAt,
+		&editedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	msg.ConvType = types.ConversationType(convType)
+	msg.Mentions = []int64(mentions)
+	if editedAt.Valid {
+		msg.EditedAt = &editedAt.Time
+	}
+
+	// 解析 body
+	if err := json.Unmarshal(bodyJSON, &msg.Body); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal body: %w", err)
+	}
+
+	redactRecalled(msg)
+	return msg, nil
+}
+
+// GetMessage 获取单条消息
+func (r *Repository) GetMessage(ctx context.Context, convID int64, msgID string) (*Message, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+messageColumns+`
+		FROM messages
+		WHERE conv_id = $1 AND msg_id = $2
+	`, convID, msgID)
+
+	msg, err := scanMessage(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrMessageNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	return msg, nil
+}
+
+// RecallMessage 撤回消息并在同一事务中追加撤回事件，成功后设置 event.Seq
+// 该消息的编辑事件会一并隐藏，避免通过编辑事件读到撤回的内容
+func (r *Repository) RecallMessage(ctx context.Context, convID int64, msgID string, event *Message) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE messages
+		SET visibility = $3
+		WHERE conv_id = $1 AND msg_id = $2 AND visibility <> $3
+	`, convID, msgID, VisibilityRecalled)
+	if err != nil {
+		return fmt.Errorf("failed to recall message: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrMessageRecalled
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE messages
+		SET visibility = $3
+		WHERE conv_id = $1 AND msg_id IN (
+			SELECT event_msg_id FROM message_edits WHERE conv_id = $1 AND msg_id = $2
+		)
+	`, convID, msgID, VisibilityRecalled)
+	if err != nil {
+		return fmt.Errorf("failed to hide edit events: %w", err)
+	}
 
+	seq, err := insertMessage(ctx, tx, event)
 	if err != nil {
-		return fmt.Errorf("failed to update read seq: %w", err)
+		return err
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	event.Seq = seq
 	return nil
 }
 
+// EditMessage 修改消息内容、记录编辑历史并在同一事务中追加编辑事件，成功后设置 event.Seq
+func (r *Repository) EditMessage(ctx context.Context, convID int64, msgID string, editorID int64, body map[string]interface{}, event *Message) (time.Time, error) {
+	newBodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to marshal body: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 锁定消息行，与并发的撤回互斥
+	var oldBodyJSON []byte
+	var visibility string
+	err = tx.QueryRowContext(ctx, `
+		SELECT body, visibility FROM messages
+		WHERE conv_id = $1 AND msg_id = $2
+		FOR UPDATE
+	`, convID, msgID).Scan(&oldBodyJSON, &visibility)
+	if err == sql.ErrNoRows {
+		return time.Time{}, ErrMessageNotFound
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to lock message: %w", err)
+	}
+	if visibility == VisibilityRecalled {
+		return time.Time{}, ErrMessageRecalled
+	}
+
+	var editedAt time.Time
+	err = tx.QueryRowContext(ctx, `
+		UPDATE messages
+		SET body = $3, edited_at = NOW()
+		WHERE conv_id = $1 AND msg_id = $2
+		RETURNING edited_at
+	`, convID, msgID, newBodyJSON).Scan(&editedAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to edit message: %w", err)
+	}
+
+	seq, err := insertMessage(ctx, tx, event)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO message_edits (conv_id, msg_id, editor_id, old_body, new_body, event_msg_id, event_seq, edited_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, convID, msgID, editorID, oldBodyJSON, newBodyJSON, event.MsgID, seq, editedAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to record edit history: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return time.Time{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	event.Seq = seq
+	return editedAt, nil
+}
+
+// UpdateReadSeq 更新已读位置，已读位置只前进不后退，返回是否实际前进
+func (r *Repository) UpdateReadSeq(ctx context.Context, convID int64, userID int64, seq int64) (bool, error) {
+	var currentSeq, updated int64
+	err := r.db.QueryRowContext(ctx, `
+		WITH cs AS (
+			SELECT COALESCE(MAX(current_seq), 0) AS current_seq FROM conversation_seq WHERE conv_id = $2
+		), updated AS (
+			UPDATE conversation_members
+			SET last_read_seq = $1
+			WHERE conv_id = $2 AND user_id = $3 AND last_read_seq < $1 AND $1 <= (SELECT current_seq FROM cs)
+			RETURNING 1
+		)
+		SELECT (SELECT current_seq FROM cs), (SELECT COUNT(*) FROM updated)
+	`, seq, convID, userID).Scan(&currentSeq, &updated)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to update read seq: %w", err)
+	}
+	if seq > currentSeq {
+		return false, ErrInvalidReadSeq
+	}
+
+	return updated > 0, nil
+}
+
 // GetConversationMembers 获取会话成员 ID 列表
 func (r *Repository) GetConversationMembers(ctx context.Context, convID int64) ([]int64, error) {
 	rows, err := r.db.QueryContext(ctx, `
@@ -283,6 +732,385 @@ func (r *Repository) GetConversationMembers(ctx context.Context, convID int64) (
 	return memberIDs, nil
 }
 
+// FilterPeers 返回 userIDs 中与 userID 至少共同在一个会话中的用户
+func (r *Repository) FilterPeers(ctx context.Context, userID int64, userIDs []int64) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT peer.user_id
+		FROM conversation_members me
+		JOIN conversation_members peer ON peer.conv_id = me.conv_id
+		WHERE me.user_id = $1 AND peer.user_id = ANY($2)
+	`, userID, pq.Array(userIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter peers: %w", err)
+	}
+	defer rows.Close()
+
+	var peerIDs []int64
+	for rows.Next() {
+		var peerID int64
+		if err := rows.Scan(&peerID); err != nil {
+			return nil, err
+		}
+		peerIDs = append(peerIDs, peerID)
+	}
+
+	return peerIDs, rows.Err()
+}
+
+// GetMembership 获取会话信息及指定用户的成员信息
+func (r *Repository) GetMembership(ctx context.Context, convID int64, userID int64) (*Conversation, *ConversationMember, error) {
+	conv := &Conversation{}
+	var convType string
+	var memberUserID sql.NullInt64
+	var role sql.NullString
+	var muted sql.NullBool
+	var lastReadSeq sql.NullInt64
+	var joinedAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT c.id, c.type, c.title, c.owner_id, c.created_at,
+		       m.user_id, m.role, m.muted, m.last_read_seq, m.joined_at
+		FROM conversations c
+		LEFT JOIN conversation_members m ON m.conv_id = c.id AND m.user_id = $2
+		WHERE c.id = $1
+	`, convID, userID).Scan(
+		&conv.ID, &convType, &conv.Title, &conv.OwnerID, &conv.CreatedAt,
+		&memberUserID, &role, &muted, &lastReadSeq, &joinedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil, ErrConversationNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get membership: %w", err)
+	}
+
+	conv.Type = types.ConversationType(convType)
+
+	if !memberUserID.Valid {
+		return conv, nil, ErrNotMember
+	}
+
+	member := &ConversationMember{
+		ConvID:      convID,
+		UserID:      memberUserID.Int64,
+		Role:        types.ConversationRole(role.String),
+		Muted:       muted.Bool,
+		LastReadSeq: lastReadSeq.Int64,
+		JoinedAt:    joinedAt.Time,
+	}
+
+	return conv, member, nil
+}
+
+// AddMembers 添加会话成员，已是成员的用户会被跳过
+// 有成员加入时，用 newEvent 按实际新增的成员构造系统消息并在同一事务中追加，成功后设置其 Seq
+func (r *Repository) AddMembers(ctx context.Context, convID int64, userIDs []int64, role types.ConversationRole, newEvent func(added []int64) *Message) ([]int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		INSERT INTO conversation_members (conv_id, user_id, role, muted, last_read_seq, joined_at)
+		SELECT $1, unnest($2::BIGINT[]), $3, false, COALESCE((SELECT current_seq FROM conversation_seq WHERE conv_id = $1), 0), NOW()
+		ON CONFLICT (conv_id, user_id) DO NOTHING
+		RETURNING user_id
+	`, convID, pq.Array(userIDs), role.String())
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to add members: %w", err)
+	}
+	defer rows.Close()
+
+	var added []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan member: %w", err)
+		}
+		added = append(added, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to add members: %w", err)
+	}
+
+	if err := commitMembershipEvent(ctx, tx, added, newEvent); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// RemoveMembers 移除会话成员
+// 有成员被移除时，用 newEvent 按实际移除的成员构造系统消息并在同一事务中追加，成功后设置其 Seq
+func (r *Repository) RemoveMembers(ctx context.Context, convID int64, userIDs []int64, newEvent func(removed []int64) *Message) ([]int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		DELETE FROM conversation_members
+		WHERE conv_id = $1 AND user_id = ANY($2)
+		RETURNING user_id
+	`, convID, pq.Array(userIDs))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove members: %w", err)
+	}
+	defer rows.Close()
+
+	var removed []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan member: %w", err)
+		}
+		removed = append(removed, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to remove members: %w", err)
+	}
+
+	if err := commitMembershipEvent(ctx, tx, removed, newEvent); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// commitMembershipEvent 追加成员变更的系统消息并提交事务，没有成员变化时不追加
+func commitMembershipEvent(ctx context.Context, tx *sql.Tx, userIDs []int64, newEvent func([]int64) *Message) error {
+	var event *Message
+	var seq int64
+	if len(userIDs) > 0 && newEvent != nil {
+		event = newEvent(userIDs)
+		var err error
+		if seq, err = insertMessage(ctx, tx, event); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if event != nil {
+		event.Seq = seq
+	}
+	return nil
+}
+
+// UpdateMemberRole 修改成员角色并在同一事务中追加系统消息，成功后设置 event.Seq
+func (r *Repository) UpdateMemberRole(ctx context.Context, convID int64, userID int64, role types.ConversationRole, event *Message) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE conversation_members
+		SET role = $1
+		WHERE conv_id = $2 AND user_id = $3
+	`, role.String(), convID, userID)
+
+	if err != nil {
+		return fmt.Errorf("failed to update member role: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if affected == 0 {
+		return ErrMemberNotFound
+	}
+
+	seq, err := insertMessage(ctx, tx, event)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	event.Seq = seq
+	return nil
+}
+
+// TransferOwnership 转让会话所有权，原所有者降为管理员，并在同一事务中追加系统消息，成功后设置 event.Seq
+func (r *Repository) TransferOwnership(ctx context.Context, convID int64, ownerID int64, newOwnerID int64, event *Message) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE conversation_members
+		SET role = $1
+		WHERE conv_id = $2 AND user_id = $3
+	`, types.ConversationRoleOwner.String(), convID, newOwnerID)
+
+	if err != nil {
+		return fmt.Errorf("failed to promote new owner: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if affected == 0 {
+		return ErrMemberNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE conversation_members
+		SET role = $1
+		WHERE conv_id = $2 AND user_id = $3
+	`, types.ConversationRoleAdmin.String(), convID, ownerID)
+
+	if err != nil {
+		return fmt.Errorf("failed to demote previous owner: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE conversations SET owner_id = $1 WHERE id = $2
+	`, newOwnerID, convID)
+
+	if err != nil {
+		return fmt.Errorf("failed to update conversation owner: %w", err)
+	}
+
+	seq, err := insertMessage(ctx, tx, event)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	event.Seq = seq
+	return nil
+}
+
+// ListConversations 获取用户的会话列表，按最后活跃时间倒序
+func (r *Repository) ListConversations(ctx context.Context, userID int64, cursor *ConversationCursor, limit int32) ([]*ConversationSummary, bool, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+
+	var cursorTime sql.NullTime
+	var cursorConvID int64
+	if cursor != nil {
+		cursorTime = sql.NullTime{Time: cursor.LastActiveAt, Valid: true}
+		cursorConvID = cursor.ConvID
+	}
+
+	// 先按成员关系和会话活跃时间分页，再只为本页会话统计未读数和查询最后一条消息
+	rows, err := r.db.QueryContext(ctx, `
+		WITH page AS (
+			SELECT c.id, c.type, COALESCE(c.title, '') AS title, COALESCE(c.owner_id, 0) AS owner_id, c.created_at,
+			       cm.role, cm.muted, cm.last_read_seq,
+			       COALESCE(cs.current_seq, 0) AS current_seq,
+			       COALESCE(cs.last_active_at, c.created_at) AS last_active_at
+			FROM conversation_members cm
+			JOIN conversations c ON c.id = cm.conv_id
+			LEFT JOIN conversation_seq cs ON cs.conv_id = cm.conv_id
+			WHERE cm.user_id = $1
+			  AND ($2::TIMESTAMPTZ IS NULL OR (COALESCE(cs.last_active_at, c.created_at), c.id) < ($2, $3))
+			ORDER BY COALESCE(cs.last_active_at, c.created_at) DESC, c.id DESC
+			LIMIT $4
+		)
+		SELECT p.id, p.type, p.title, p.owner_id, p.created_at, p.role, p.muted, p.last_read_seq, p.current_seq,
+		       (SELECT COUNT(*) FROM messages um
+		        WHERE um.conv_id = p.id AND um.seq > p.last_read_seq
+		          AND um.sender_id <> $1 AND um.body->>'type' IS DISTINCT FROM 'system'
+		          AND um.visibility IS DISTINCT FROM 'recalled') AS unread_count,
+		       lm.msg_id, lm.seq, lm.sender_id, lm.body, lm.reply_to, lm.mentions, lm.visibility,
+		       lm.created_at, lm.edited_at, p.last_active_at
+		FROM page p
+		LEFT JOIN LATERAL (
+			SELECT msg_id, seq, sender_id, body, reply_to, mentions, visibility, created_at, edited_at
+			FROM messages
+			WHERE conv_id = p.id
+			ORDER BY seq DESC
+			LIMIT 1
+		) lm ON true
+		ORDER BY p.last_active_at DESC, p.id DESC
+	`, userID, cursorTime, cursorConvID, limit+1) // 多查一条判断是否还有更多
+
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []*ConversationSummary
+	for rows.Next() {
+		summary := &ConversationSummary{}
+		var convType, role string
+		var msgID, msgVisibility, replyTo sql.NullString
+		var msgSeq, msgSenderID sql.NullInt64
+		var msgCreatedAt, msgEditedAt sql.NullTime
+		var bodyJSON []byte
+		var mentions pq.Int64Array
+
+		err := rows.Scan(
+			&summary.ID, &convType, &summary.Title, &summary.OwnerID, &summary.CreatedAt,
+			&role, &summary.Muted, &summary.LastReadSeq, &summary.CurrentSeq, &summary.UnreadCount,
+			&msgID, &msgSeq, &msgSenderID, &bodyJSON, &replyTo, &mentions, &msgVisibility, &msgCreatedAt, &msgEditedAt,
+			&summary.LastActiveAt,
+		)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to scan conversation: %w", err)
+		}
+
+		summary.Type = types.ConversationType(convType)
+		summary.Role = types.ConversationRole(role)
+
+		if msgID.Valid {
+			msg := &Message{
+				MsgID:      msgID.String,
+				ConvID:     summary.ID,
+				Seq:        msgSeq.Int64,
+				SenderID:   msgSenderID.Int64,
+				ConvType:   summary.Type,
+				Mentions:   []int64(mentions),
+				Visibility: msgVisibility.String,
+				CreatedAt:  msgCreatedAt.Time,
+			}
+			if replyTo.Valid {
+				msg.ReplyTo = &replyTo.String
+			}
+			if msgEditedAt.Valid {
+				msg.EditedAt = &msgEditedAt.Time
+			}
+			if err := json.Unmarshal(bodyJSON, &msg.Body); err != nil {
+				return nil, false, fmt.Errorf("failed to unmarshal body: %w", err)
+			}
+			redactRecalled(msg)
+			summary.LastMessage = msg
+		}
+
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("failed to list conversations: %w", err)
+	}
+
+	hasMore := false
+	if len(summaries) > int(limit) {
+		hasMore = true
+		summaries = summaries[:limit]
+	}
+
+	return summaries, hasMore, nil
+}
+
 // GenerateMessageID 生成消息 ID
 func GenerateMessageID() string {
 	return uuid.New().String()
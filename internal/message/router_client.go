@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	gatewaypb "github.com/dollarkillerx/im-system/api/proto/gateway"
 	routerpb "github.com/dollarkillerx/im-system/api/proto/router"
 	"github.com/dollarkillerx/im-system/pkg/logger"
 	"go.uber.org/zap"
@@ -14,6 +15,7 @@ import (
 // RouterClient Router 服务客户端接口
 type RouterClient interface {
 	NotifyNewMessage(ctx context.Context, convID int64, msgID string, seq int64, senderID int64, recipientIDs []int64) (int32, error)
+	PushEvent(ctx context.Context, recipientIDs []int64, event *gatewaypb.GatewayMessage) (int32, error)
 }
 
 // routerClient Router 服务客户端实现
@@ -51,35 +53,52 @@ func (c *routerClient) NotifyNewMessage(ctx context.Context, convID int64, msgID
 	// 创建客户端
 	client := routerpb.NewRouterServiceClient(conn)
 
-	// 调用 GetRoute 获取在线用户
-	var notifiedCount int32
-	for _, recipientID := range recipientIDs {
-		resp, err := client.GetRoute(ctx, &routerpb.GetRouteRequest{
-			UserId: recipientID,
-		})
-
-		if err != nil {
-			logger.Log.Warn("Failed to get route for user",
-				zap.Int64("user_id", recipientID),
-				zap.Error(err),
-			)
-			continue
-		}
-
-		if len(resp.Routes) > 0 {
-			// 用户在线，推送通知
-			// 注意：实际推送由 Gateway 负责，这里只是获取路由信息
-			// 在完整实现中，应该调用 Gateway 的推送接口
-			notifiedCount++
-
-			logger.Log.Debug("User online, can notify",
-				zap.Int64("user_id", recipientID),
-				zap.Int("device_count", len(resp.Routes)),
-			)
-		}
+	// 由 Router 解析接收者所在的 Gateway 并推送通知
+	resp, err := client.PushMessage(ctx, &routerpb.PushMessageRequest{
+		ConvId:       convID,
+		MsgId:        msgID,
+		Seq:          seq,
+		SenderId:     senderID,
+		RecipientIds: recipientIDs,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to push message: %w", err)
 	}
 
-	return notifiedCount, nil
+	logger.Log.Debug("Router pushed message",
+		zap.Int64("conv_id", convID),
+		zap.String("msg_id", msgID),
+		zap.Int32("online_count", resp.OnlineCount),
+		zap.Int32("delivered_count", resp.DeliveredCount),
+	)
+
+	return resp.OnlineCount, nil
+}
+
+// PushEvent 推送不落库的实时事件（已读回执等）
+func (c *routerClient) PushEvent(ctx context.Context, recipientIDs []int64, event *gatewaypb.GatewayMessage) (int32, error) {
+	addr, err := c.serviceDiscovery.GetServiceAddress("router-service")
+	if err != nil {
+		return 0, fmt.Errorf("failed to discover router service: %w", err)
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to router: %w", err)
+	}
+	defer conn.Close()
+
+	client := routerpb.NewRouterServiceClient(conn)
+
+	resp, err := client.PushEvent(ctx, &routerpb.PushEventRequest{
+		RecipientIds: recipientIDs,
+		Event:        event,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to push event: %w", err)
+	}
+
+	return resp.OnlineCount, nil
 }
 
 // MockRouterClient 用于测试的 Mock 客户端
@@ -88,3 +107,7 @@ type MockRouterClient struct{}
 func (m *MockRouterClient) NotifyNewMessage(ctx context.Context, convID int64, msgID string, seq int64, senderID int64, recipientIDs []int64) (int32, error) {
 	return int32(len(recipientIDs)), nil
 }
+
+func (m *MockRouterClient) PushEvent(ctx context.Context, recipientIDs []int64, event *gatewaypb.GatewayMessage) (int32, error) {
+	return int32(len(recipientIDs)), nil
+}
@@ -2,57 +2,220 @@ package message
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	gatewaypb "github.com/dollarkillerx/im-system/api/proto/gateway"
 	"github.com/dollarkillerx/im-system/pkg/logger"
 	"github.com/dollarkillerx/im-system/pkg/types"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var (
+	// ErrConvTypeMismatch 请求的会话类型与会话实际类型不一致
+	ErrConvTypeMismatch = errors.New("conversation type mismatch")
+	// ErrMemberMuted 成员已被禁言
+	ErrMemberMuted = errors.New("member is muted")
+	// ErrSendNotAllowed 成员角色无权在该会话发言
+	ErrSendNotAllowed = errors.New("role is not allowed to send messages in this conversation")
+	// ErrManageNotAllowed 成员角色无权管理成员
+	ErrManageNotAllowed = errors.New("role is not allowed to manage members of this conversation")
+	// ErrDirectConversation 单聊会话不支持成员管理
+	ErrDirectConversation = errors.New("members of a direct conversation cannot be changed")
+	// ErrOwnerCannotLeave 所有者需先转让所有权才能退出
+	ErrOwnerCannotLeave = errors.New("owner must transfer ownership before leaving")
+	// ErrCannotModifyOwner 不能移除所有者或修改所有者角色
+	ErrCannotModifyOwner = errors.New("cannot remove or change the role of the owner")
+	// ErrInvalidRole 无效的成员角色
+	ErrInvalidRole = errors.New("invalid member role")
+	// ErrInvalidTarget 无效的目标成员
+	ErrInvalidTarget = errors.New("invalid target member")
+	// ErrInvalidDirectMembers 单聊会话必须恰好包含两个不同的用户
+	ErrInvalidDirectMembers = errors.New("direct conversation must have exactly two distinct members")
+	// ErrInvalidIdempotencyKey 幂等键过长
+	ErrInvalidIdempotencyKey = errors.New("idempotency key is too long")
+	// ErrNotMessageSender 只有发送者可以操作该消息
+	ErrNotMessageSender = errors.New("only the sender can modify this message")
+	// ErrRecallWindowExpired 超过撤回时限
+	ErrRecallWindowExpired = errors.New("recall window has expired")
+	// ErrSystemMessage 系统消息不可撤回或编辑
+	ErrSystemMessage = errors.New("system messages cannot be modified")
+	// ErrEmptyBody 消息体为空
+	ErrEmptyBody = errors.New("message body is empty")
+	// ErrInvalidAnchor 无效的定位序列号
+	ErrInvalidAnchor = errors.New("invalid anchor seq")
+	// ErrInvalidCursor 无效的分页游标
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// 系统消息事件类型
+const (
+	SystemEventMembersAdded         = "members_added"
+	SystemEventMembersRemoved       = "members_removed"
+	SystemEventMemberRoleUpdated    = "member_role_updated"
+	SystemEventMemberLeft           = "member_left"
+	SystemEventOwnershipTransferred = "ownership_transferred"
+	SystemEventMessageRecalled      = "message_recalled"
+	SystemEventMessageEdited        = "message_edited"
+)
+
+const (
+	// DefaultIdempotencyWindow 默认幂等键去重窗口
+	DefaultIdempotencyWindow = 24 * time.Hour
+	// MaxIdempotencyKeyLength 幂等键最大长度
+	MaxIdempotencyKeyLength = 128
+	// DefaultRecallWindow 默认发送者撤回时限
+	DefaultRecallWindow = 2 * time.Minute
+	// DefaultGroupReadReceiptMaxMembers 默认广播群聊已读汇总的最大成员数
+	DefaultGroupReadReceiptMaxMembers = 200
+)
+
+// GroupReadReceiptMode 群聊已读回执模式
+type GroupReadReceiptMode string
+
+const (
+	// GroupReadReceiptOff 群聊只同步自己的其他设备，不向其他成员广播
+	GroupReadReceiptOff GroupReadReceiptMode = "off"
+	// GroupReadReceiptAggregate 全员已读位置（成员已读序列号的最小值）前进时向全体成员广播
+	GroupReadReceiptAggregate GroupReadReceiptMode = "aggregate"
+)
+
+// 已读回执事件类型
+const (
+	ReadEventRead      = "read"
+	ReadEventAggregate = "read_aggregate"
 )
 
 type Service struct {
-	repo         MessageRepository
-	routerClient RouterClient
+	repo              MessageRepository
+	routerClient      RouterClient
+	idempotencyWindow time.Duration
+	recallWindow      time.Duration
+
+	groupReadReceiptMode       GroupReadReceiptMode
+	groupReadReceiptMaxMembers int
 }
 
 func NewService(repo MessageRepository, routerClient RouterClient) *Service {
 	return &Service{
-		repo:         repo,
-		routerClient: routerClient,
+		repo:                       repo,
+		routerClient:               routerClient,
+		idempotencyWindow:          DefaultIdempotencyWindow,
+		recallWindow:               DefaultRecallWindow,
+		groupReadReceiptMode:       GroupReadReceiptAggregate,
+		groupReadReceiptMaxMembers: DefaultGroupReadReceiptMaxMembers,
+	}
+}
+
+// WithGroupReadReceipts 设置群聊已读回执模式，maxMembers > 0 时超过该人数的群不广播汇总
+// mode 为空时保持默认值
+func (s *Service) WithGroupReadReceipts(mode GroupReadReceiptMode, maxMembers int) *Service {
+	if mode != "" {
+		s.groupReadReceiptMode = mode
 	}
+	if maxMembers > 0 {
+		s.groupReadReceiptMaxMembers = maxMembers
+	}
+	return s
+}
+
+// WithRecallWindow 设置发送者撤回时限，window <= 0 时保持默认值
+func (s *Service) WithRecallWindow(window time.Duration) *Service {
+	if window > 0 {
+		s.recallWindow = window
+	}
+	return s
+}
+
+// WithIdempotencyWindow 设置幂等键去重窗口，window <= 0 时保持默认值
+func (s *Service) WithIdempotencyWindow(window time.Duration) *Service {
+	if window > 0 {
+		s.idempotencyWindow = window
+	}
+	return s
 }
 
 // SendMessage 发送消息
-func (s *Service) SendMessage(ctx context.Context, convID int64, senderID int64, convType types.ConversationType, body map[string]interface{}, replyTo *string, mentions []int64) (string, int64, int64, error) {
-	// 生成消息 ID
-	msgID := GenerateMessageID()
+// idempotencyKey 非空时，同一发送者在同一会话的窗口期内重复发送会返回原消息的 msg_id 和 seq
+func (s *Service) SendMessage(ctx context.Context, convID int64, senderID int64, convType types.ConversationType, body map[string]interface{}, replyTo *string, mentions []int64, idempotencyKey string) (string, int64, int64, error) {
+	if len(idempotencyKey) > MaxIdempotencyKeyLength {
+		return "", 0, 0, ErrInvalidIdempotencyKey
+	}
 
-	// 获取下一个序列号
-	seq, err := s.repo.GetNextSeq(ctx, convID)
-	if err != nil {
-		logger.Log.Error("Failed to get next seq",
+	// 校验发送者的成员身份和发言权限，重放原消息前同样需要校验
+	if err := s.checkSendPermission(ctx, convID, senderID, convType); err != nil {
+		logger.Log.Warn("Send message rejected",
 			zap.Int64("conv_id", convID),
+			zap.Int64("sender_id", senderID),
 			zap.Error(err),
 		)
-		return "", 0, 0, fmt.Errorf("failed to get sequence: %w", err)
+		return "", 0, 0, err
 	}
 
-	// 创建消息
+	// 重试请求直接返回原消息
+	since := time.Now().Add(-s.idempotencyWindow)
+	if idempotencyKey != "" {
+		original, found, err := s.repo.GetIdempotentMessage(ctx, convID, senderID, idempotencyKey, since)
+		if err != nil {
+			logger.Log.Error("Failed to look up idempotency key",
+				zap.Int64("conv_id", convID),
+				zap.Int64("sender_id", senderID),
+				zap.Error(err),
+			)
+			return "", 0, 0, err
+		}
+		if found {
+			logger.Log.Info("Duplicate message send replayed",
+				zap.String("msg_id", original.MsgID),
+				zap.Int64("conv_id", convID),
+				zap.Int64("sender_id", senderID),
+			)
+			return original.MsgID, original.Seq, original.CreatedAt.Unix(), nil
+		}
+	}
+
+	// 生成消息 ID
+	msgID := GenerateMessageID()
+
+	// 创建消息，序列号在保存时分配
 	msg := &Message{
 		MsgID:      msgID,
 		ConvID:     convID,
-		Seq:        seq,
 		SenderID:   senderID,
 		ConvType:   convType,
 		Body:       body,
 		ReplyTo:    replyTo,
 		Mentions:   mentions,
-		Visibility: "normal",
+		Visibility: VisibilityNormal,
 		CreatedAt:  time.Now(),
 	}
 
 	// 保存消息
-	if err := s.repo.SaveMessage(ctx, msg); err != nil {
+	if idempotencyKey != "" {
+		stored, err := s.repo.SaveMessageIdempotent(ctx, msg, idempotencyKey, since)
+		if err != nil {
+			logger.Log.Error("Failed to save message",
+				zap.String("msg_id", msgID),
+				zap.Int64("conv_id", convID),
+				zap.Error(err),
+			)
+			return "", 0, 0, fmt.Errorf("failed to save message: %w", err)
+		}
+		// 并发的重试请求已先保存
+		if stored.MsgID != msgID {
+			logger.Log.Info("Duplicate message send replayed",
+				zap.String("msg_id", stored.MsgID),
+				zap.Int64("conv_id", convID),
+				zap.Int64("sender_id", senderID),
+			)
+			return stored.MsgID, stored.Seq, stored.CreatedAt.Unix(), nil
+		}
+	} else if err := s.repo.SaveMessage(ctx, msg); err != nil {
 		logger.Log.Error("Failed to save message",
 			zap.String("msg_id", msgID),
 			zap.Int64("conv_id", convID),
@@ -64,18 +227,58 @@ func (s *Service) SendMessage(ctx context.Context, convID int64, senderID int64,
 	logger.Log.Info("Message sent successfully",
 		zap.String("msg_id", msgID),
 		zap.Int64("conv_id", convID),
-		zap.Int64("seq", seq),
+		zap.Int64("seq", msg.Seq),
 		zap.Int64("sender_id", senderID),
 	)
 
 	// 异步通知 Router 推送消息
-	go s.notifyNewMessage(convID, msgID, seq, senderID)
+	go s.notifyNewMessage(convID, msgID, msg.Seq, senderID)
+
+	return msgID, msg.Seq, msg.CreatedAt.Unix(), nil
+}
+
+// checkSendPermission 检查发送者是否为会话成员且有权发言
+func (s *Service) checkSendPermission(ctx context.Context, convID int64, senderID int64, convType types.ConversationType) error {
+	conv, member, err := s.repo.GetMembership(ctx, convID, senderID)
+	if err != nil {
+		return err
+	}
+
+	if conv.Type != convType {
+		return ErrConvTypeMismatch
+	}
+
+	if member.Muted {
+		return ErrMemberMuted
+	}
 
-	return msgID, seq, msg.CreatedAt.Unix(), nil
+	if !member.Role.CanSendMessage(conv.Type) {
+		return ErrSendNotAllowed
+	}
+
+	return nil
+}
+
+// checkMembership 检查用户是否为会话成员
+func (s *Service) checkMembership(ctx context.Context, convID int64, userID int64) (*Conversation, *ConversationMember, error) {
+	conv, member, err := s.repo.GetMembership(ctx, convID, userID)
+	if err != nil {
+		logger.Log.Warn("Membership check failed",
+			zap.Int64("conv_id", convID),
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return nil, nil, err
+	}
+	return conv, member, nil
 }
 
 // PullMessages 拉取消息
-func (s *Service) PullMessages(ctx context.Context, convID int64, sinceSeq int64, limit int32) ([]*Message, bool, error) {
+func (s *Service) PullMessages(ctx context.Context, convID int64, userID int64, sinceSeq int64, limit int32) ([]*Message, bool, error) {
+	if _, _, err := s.checkMembership(ctx, convID, userID); err != nil {
+		return nil, false, err
+	}
+
 	messages, hasMore, err := s.repo.PullMessages(ctx, convID, sinceSeq, limit)
 	if err != nil {
 		logger.Log.Error("Failed to pull messages",
@@ -96,6 +299,75 @@ func (s *Service) PullMessages(ctx context.Context, convID int64, sinceSeq int64
 	return messages, hasMore, nil
 }
 
+// PullMessagesBefore 拉取 beforeSeq 之前的历史消息（向上翻页），beforeSeq 为 0 时从最新消息开始
+// 返回的消息按 seq 升序排列，bool 表示是否还有更早的消息
+func (s *Service) PullMessagesBefore(ctx context.Context, convID int64, userID int64, beforeSeq int64, limit int32) ([]*Message, bool, error) {
+	if beforeSeq < 0 {
+		return nil, false, ErrInvalidAnchor
+	}
+	if _, _, err := s.checkMembership(ctx, convID, userID); err != nil {
+		return nil, false, err
+	}
+
+	messages, hasMore, err := s.repo.PullMessagesBefore(ctx, convID, beforeSeq, limit)
+	if err != nil {
+		logger.Log.Error("Failed to pull history messages",
+			zap.Int64("conv_id", convID),
+			zap.Int64("before_seq", beforeSeq),
+			zap.Error(err),
+		)
+		return nil, false, err
+	}
+
+	logger.Log.Debug("Pulled history messages",
+		zap.Int64("conv_id", convID),
+		zap.Int64("before_seq", beforeSeq),
+		zap.Int("count", len(messages)),
+		zap.Bool("has_more", hasMore),
+	)
+
+	return messages, hasMore, nil
+}
+
+// PullMessagesAround 拉取 aroundSeq 附近的消息（用于跳转到引用的消息）
+// 约一半为 aroundSeq 之前的消息，其余为 aroundSeq 及之后的消息，结果按 seq 升序排列
+func (s *Service) PullMessagesAround(ctx context.Context, convID int64, userID int64, aroundSeq int64, limit int32) ([]*Message, bool, bool, error) {
+	if aroundSeq <= 0 {
+		return nil, false, false, ErrInvalidAnchor
+	}
+	if _, _, err := s.checkMembership(ctx, convID, userID); err != nil {
+		return nil, false, false, err
+	}
+
+	if limit <= 0 || limit > MaxPullLimit {
+		limit = MaxPullLimit
+	}
+	before := limit / 2
+
+	// before 为 0 时仍查询一条，仅用于判断是否还有更早的消息
+	older, hasMoreBefore, err := s.repo.PullMessagesBefore(ctx, convID, aroundSeq, max(before, 1))
+	if err != nil {
+		return nil, false, false, err
+	}
+	if before == 0 {
+		hasMoreBefore = len(older) > 0
+		older = nil
+	}
+
+	newer, hasMoreAfter, err := s.repo.PullMessages(ctx, convID, aroundSeq-1, limit-before)
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	logger.Log.Debug("Pulled messages around seq",
+		zap.Int64("conv_id", convID),
+		zap.Int64("around_seq", aroundSeq),
+		zap.Int("count", len(older)+len(newer)),
+	)
+
+	return append(older, newer...), hasMoreBefore, hasMoreAfter, nil
+}
+
 // CreateConversation 创建会话
 func (s *Service) CreateConversation(ctx context.Context, convType types.ConversationType, title string, ownerID int64, memberIDs []int64) (int64, error) {
 	// 验证会话类型
@@ -115,6 +387,16 @@ func (s *Service) CreateConversation(ctx context.Context, convType types.Convers
 		memberIDs = append(memberIDs, ownerID)
 	}
 
+	// 单聊会话按用户对去重
+	if convType == types.ConversationTypeDirect {
+		peerID, err := directPeer(ownerID, memberIDs)
+		if err != nil {
+			return 0, err
+		}
+		convID, _, err := s.GetOrCreateDirectConversation(ctx, ownerID, peerID)
+		return convID, err
+	}
+
 	convID, err := s.repo.CreateConversation(ctx, convType, title, ownerID, memberIDs)
 	if err != nil {
 		logger.Log.Error("Failed to create conversation",
@@ -135,8 +417,57 @@ func (s *Service) CreateConversation(ctx context.Context, convType types.Convers
 	return convID, nil
 }
 
+// GetOrCreateDirectConversation 获取与 peerID 的单聊会话，不存在时创建，返回会话ID和是否新建
+func (s *Service) GetOrCreateDirectConversation(ctx context.Context, userID int64, peerID int64) (int64, bool, error) {
+	if userID <= 0 || peerID <= 0 || userID == peerID {
+		return 0, false, ErrInvalidDirectMembers
+	}
+
+	convID, created, err := s.repo.GetOrCreateDirectConversation(ctx, userID, peerID)
+	if err != nil {
+		logger.Log.Error("Failed to get or create direct conversation",
+			zap.Int64("user_id", userID),
+			zap.Int64("peer_id", peerID),
+			zap.Error(err),
+		)
+		return 0, false, err
+	}
+
+	if created {
+		logger.Log.Info("Direct conversation created",
+			zap.Int64("conv_id", convID),
+			zap.Int64("user_id", userID),
+			zap.Int64("peer_id", peerID),
+		)
+	}
+
+	return convID, created, nil
+}
+
+// directPeer 从单聊成员列表中找出对方用户，成员必须恰好是 ownerID 和另一个用户
+func directPeer(ownerID int64, memberIDs []int64) (int64, error) {
+	var peerID int64
+	for _, id := range memberIDs {
+		if id == ownerID {
+			continue
+		}
+		if peerID != 0 && id != peerID {
+			return 0, ErrInvalidDirectMembers
+		}
+		peerID = id
+	}
+	if peerID == 0 {
+		return 0, ErrInvalidDirectMembers
+	}
+	return peerID, nil
+}
+
 // GetConversation 获取会话信息
-func (s *Service) GetConversation(ctx context.Context, convID int64) (*Conversation, []*ConversationMember, error) {
+func (s *Service) GetConversation(ctx context.Context, convID int64, userID int64) (*Conversation, []*ConversationMember, error) {
+	if _, _, err := s.checkMembership(ctx, convID, userID); err != nil {
+		return nil, nil, err
+	}
+
 	conv, members, err := s.repo.GetConversation(ctx, convID)
 	if err != nil {
 		logger.Log.Error("Failed to get conversation",
@@ -149,9 +480,15 @@ func (s *Service) GetConversation(ctx context.Context, convID int64) (*Conversat
 	return conv, members, nil
 }
 
-// UpdateReadSeq 更新已读位置
+// UpdateReadSeq 更新已读位置，前进时同步到用户的其他设备并按会话类型广播已读状态
 func (s *Service) UpdateReadSeq(ctx context.Context, convID int64, userID int64, seq int64) error {
-	err := s.repo.UpdateReadSeq(ctx, convID, userID, seq)
+	conv, member, err := s.checkMembership(ctx, convID, userID)
+	if err != nil {
+		return err
+	}
+
+	oldSeq := member.LastReadSeq
+	advanced, err := s.repo.UpdateReadSeq(ctx, convID, userID, seq)
 	if err != nil {
 		logger.Log.Error("Failed to update read seq",
 			zap.Int64("conv_id", convID),
@@ -166,13 +503,612 @@ func (s *Service) UpdateReadSeq(ctx context.Context, convID int64, userID int64,
 		zap.Int64("conv_id", convID),
 		zap.Int64("user_id", userID),
 		zap.Int64("seq", seq),
+		zap.Bool("advanced", advanced),
 	)
 
+	if advanced {
+		go s.notifyReadSeq(conv, userID, oldSeq, seq)
+	}
+
 	return nil
 }
 
-// notifyNewMessage 通知 Router 有新消息
-func (s *Service) notifyNewMessage(convID int64, msgID string, seq int64, senderID int64) {
+// notifyReadSeq 广播已读位置
+// 用户自己的所有设备总会收到，单聊同时通知对方，群聊按配置广播全员已读汇总，频道不广播
+func (s *Service) notifyReadSeq(conv *Conversation, userID int64, oldSeq int64, seq int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, members, err := s.repo.GetConversation(ctx, conv.ID)
+	if err != nil {
+		logger.Log.Error("Failed to get conversation members for read receipt",
+			zap.Int64("conv_id", conv.ID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	recipientIDs := []int64{userID}
+	if conv.Type == types.ConversationTypeDirect {
+		for _, m := range members {
+			if m.UserID != userID {
+				recipientIDs = append(recipientIDs, m.UserID)
+			}
+		}
+	}
+
+	s.pushReadEvent(ctx, recipientIDs, map[string]interface{}{
+		"type":    ReadEventRead,
+		"conv_id": conv.ID,
+		"user_id": userID,
+		"seq":     seq,
+	})
+
+	if conv.Type != types.ConversationTypeGroup || s.groupReadReceiptMode != GroupReadReceiptAggregate {
+		return
+	}
+	if len(members) > s.groupReadReceiptMaxMembers {
+		return
+	}
+
+	oldMin, newMin, ok := groupReadSeq(members, userID, oldSeq, seq)
+	if !ok || newMin <= oldMin {
+		return
+	}
+
+	memberIDs := make([]int64, 0, len(members))
+	for _, m := range members {
+		memberIDs = append(memberIDs, m.UserID)
+	}
+
+	s.pushReadEvent(ctx, memberIDs, map[string]interface{}{
+		"type":     ReadEventAggregate,
+		"conv_id":  conv.ID,
+		"read_seq": newMin,
+	})
+}
+
+// groupReadSeq 计算用户已读位置从 oldSeq 前进到 seq 前后的全员已读位置
+func groupReadSeq(members []*ConversationMember, userID int64, oldSeq int64, seq int64) (int64, int64, bool) {
+	if len(members) == 0 {
+		return 0, 0, false
+	}
+
+	var oldMin, newMin int64
+	for i, m := range members {
+		before, after := m.LastReadSeq, m.LastReadSeq
+		if m.UserID == userID {
+			before, after = oldSeq, seq
+		}
+		if i == 0 || before < oldMin {
+			oldMin = before
+		}
+		if i == 0 || after < newMin {
+			newMin = after
+		}
+	}
+
+	return oldMin, newMin, true
+}
+
+// pushReadEvent 通过 Router 推送已读回执事件
+func (s *Service) pushReadEvent(ctx context.Context, recipientIDs []int64, data map[string]interface{}) {
+	payload, err := structpb.NewStruct(data)
+	if err != nil {
+		logger.Log.Error("Failed to create read receipt payload", zap.Error(err))
+		return
+	}
+
+	event := &gatewaypb.GatewayMessage{
+		Type:      gatewaypb.MessageType_READ_RECEIPT,
+		Payload:   payload,
+		Timestamp: time.Now().Unix(),
+	}
+
+	if _, err := s.routerClient.PushEvent(ctx, recipientIDs, event); err != nil {
+		logger.Log.Warn("Failed to push read receipt",
+			zap.Any("conv_id", data["conv_id"]),
+			zap.String("type", data["type"].(string)),
+			zap.Error(err),
+		)
+	}
+}
+
+// AddMembers 添加会话成员，返回实际新增的成员和系统消息序列号
+func (s *Service) AddMembers(ctx context.Context, convID int64, operatorID int64, memberIDs []int64, role types.ConversationRole) ([]int64, int64, error) {
+	conv, operator, err := s.requireManager(ctx, convID, operatorID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if role == "" {
+		role = types.ConversationRoleMember
+	}
+	if !role.IsValid() || role == types.ConversationRoleOwner {
+		return nil, 0, ErrInvalidRole
+	}
+	// 只有所有者可以任命管理员
+	if role == types.ConversationRoleAdmin && operator.Role != types.ConversationRoleOwner {
+		return nil, 0, ErrManageNotAllowed
+	}
+
+	if len(memberIDs) == 0 {
+		return nil, 0, nil
+	}
+
+	var event *Message
+	added, err := s.repo.AddMembers(ctx, convID, memberIDs, role, func(added []int64) *Message {
+		event = newSystemMessage(conv, operatorID, SystemEventMembersAdded, map[string]interface{}{
+			"member_ids": added,
+			"role":       role.String(),
+		})
+		return event
+	})
+	if err != nil {
+		logger.Log.Error("Failed to add members",
+			zap.Int64("conv_id", convID),
+			zap.Int64("operator_id", operatorID),
+			zap.Error(err),
+		)
+		return nil, 0, err
+	}
+
+	if len(added) == 0 {
+		return nil, 0, nil
+	}
+
+	logger.Log.Info("Members added",
+		zap.Int64("conv_id", convID),
+		zap.Int64("operator_id", operatorID),
+		zap.Int64s("member_ids", added),
+	)
+
+	go s.notifyNewMessage(convID, event.MsgID, event.Seq, operatorID)
+
+	return added, event.Seq, nil
+}
+
+// RemoveMembers 移除会话成员，返回实际移除的成员和系统消息序列号
+func (s *Service) RemoveMembers(ctx context.Context, convID int64, operatorID int64, memberIDs []int64) ([]int64, int64, error) {
+	conv, operator, err := s.requireManager(ctx, convID, operatorID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	_, members, err := s.repo.GetConversation(ctx, convID)
+	if err != nil {
+		return nil, 0, err
+	}
+	roles := make(map[int64]types.ConversationRole, len(members))
+	for _, member := range members {
+		roles[member.UserID] = member.Role
+	}
+
+	var targets []int64
+	for _, userID := range memberIDs {
+		// 操作者自己应使用 LeaveConversation
+		if userID == operatorID {
+			return nil, 0, ErrInvalidTarget
+		}
+
+		role, ok := roles[userID]
+		if !ok {
+			continue
+		}
+		if role == types.ConversationRoleOwner {
+			return nil, 0, ErrCannotModifyOwner
+		}
+		// 只有所有者可以移除管理员
+		if role == types.ConversationRoleAdmin && operator.Role != types.ConversationRoleOwner {
+			return nil, 0, ErrManageNotAllowed
+		}
+		targets = append(targets, userID)
+	}
+
+	if len(targets) == 0 {
+		return nil, 0, nil
+	}
+
+	var event *Message
+	removed, err := s.repo.RemoveMembers(ctx, convID, targets, func(removed []int64) *Message {
+		event = newSystemMessage(conv, operatorID, SystemEventMembersRemoved, map[string]interface{}{
+			"member_ids": removed,
+		})
+		return event
+	})
+	if err != nil {
+		logger.Log.Error("Failed to remove members",
+			zap.Int64("conv_id", convID),
+			zap.Int64("operator_id", operatorID),
+			zap.Error(err),
+		)
+		return nil, 0, err
+	}
+
+	if len(removed) == 0 {
+		return nil, 0, nil
+	}
+
+	logger.Log.Info("Members removed",
+		zap.Int64("conv_id", convID),
+		zap.Int64("operator_id", operatorID),
+		zap.Int64s("member_ids", removed),
+	)
+
+	// 被移除的成员也需要收到通知
+	go s.notifyNewMessage(convID, event.MsgID, event.Seq, operatorID, removed...)
+
+	return removed, event.Seq, nil
+}
+
+// UpdateMemberRole 修改成员角色，返回系统消息序列号
+func (s *Service) UpdateMemberRole(ctx context.Context, convID int64, operatorID int64, userID int64, role types.ConversationRole) (int64, error) {
+	conv, operator, err := s.requireManager(ctx, convID, operatorID)
+	if err != nil {
+		return 0, err
+	}
+
+	if !role.IsValid() || role == types.ConversationRoleOwner {
+		return 0, ErrInvalidRole
+	}
+
+	_, target, err := s.repo.GetMembership(ctx, convID, userID)
+	if errors.Is(err, ErrNotMember) {
+		return 0, ErrMemberNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if target.Role == types.ConversationRoleOwner {
+		return 0, ErrCannotModifyOwner
+	}
+	// 只有所有者可以任命或撤销管理员
+	if (target.Role == types.ConversationRoleAdmin || role == types.ConversationRoleAdmin) && operator.Role != types.ConversationRoleOwner {
+		return 0, ErrManageNotAllowed
+	}
+
+	if target.Role == role {
+		return 0, nil
+	}
+
+	event := newSystemMessage(conv, operatorID, SystemEventMemberRoleUpdated, map[string]interface{}{
+		"user_id": userID,
+		"role":    role.String(),
+	})
+	if err := s.repo.UpdateMemberRole(ctx, convID, userID, role, event); err != nil {
+		logger.Log.Error("Failed to update member role",
+			zap.Int64("conv_id", convID),
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return 0, err
+	}
+
+	logger.Log.Info("Member role updated",
+		zap.Int64("conv_id", convID),
+		zap.Int64("operator_id", operatorID),
+		zap.Int64("user_id", userID),
+		zap.String("role", role.String()),
+	)
+
+	go s.notifyNewMessage(convID, event.MsgID, event.Seq, operatorID)
+
+	return event.Seq, nil
+}
+
+// LeaveConversation 退出会话，返回系统消息序列号
+func (s *Service) LeaveConversation(ctx context.Context, convID int64, userID int64) (int64, error) {
+	conv, member, err := s.checkMembership(ctx, convID, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	if conv.Type == types.ConversationTypeDirect {
+		return 0, ErrDirectConversation
+	}
+	if member.Role == types.ConversationRoleOwner {
+		return 0, ErrOwnerCannotLeave
+	}
+
+	var event *Message
+	removed, err := s.repo.RemoveMembers(ctx, convID, []int64{userID}, func([]int64) *Message {
+		event = newSystemMessage(conv, userID, SystemEventMemberLeft, map[string]interface{}{
+			"user_id": userID,
+		})
+		return event
+	})
+	if err != nil {
+		logger.Log.Error("Failed to leave conversation",
+			zap.Int64("conv_id", convID),
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return 0, err
+	}
+	// 并发退出时已被移除
+	if len(removed) == 0 {
+		return 0, ErrNotMember
+	}
+
+	logger.Log.Info("Member left conversation",
+		zap.Int64("conv_id", convID),
+		zap.Int64("user_id", userID),
+	)
+
+	// 通知退出者的其他设备
+	go s.notifyNewMessage(convID, event.MsgID, event.Seq, userID, userID)
+
+	return event.Seq, nil
+}
+
+// TransferOwnership 转让会话所有权，返回系统消息序列号
+func (s *Service) TransferOwnership(ctx context.Context, convID int64, ownerID int64, newOwnerID int64) (int64, error) {
+	conv, member, err := s.checkMembership(ctx, convID, ownerID)
+	if err != nil {
+		return 0, err
+	}
+
+	if conv.Type == types.ConversationTypeDirect {
+		return 0, ErrDirectConversation
+	}
+	if member.Role != types.ConversationRoleOwner {
+		return 0, ErrManageNotAllowed
+	}
+	if newOwnerID == ownerID {
+		return 0, ErrInvalidTarget
+	}
+
+	event := newSystemMessage(conv, ownerID, SystemEventOwnershipTransferred, map[string]interface{}{
+		"new_owner_id": newOwnerID,
+	})
+	if err := s.repo.TransferOwnership(ctx, convID, ownerID, newOwnerID, event); err != nil {
+		logger.Log.Error("Failed to transfer ownership",
+			zap.Int64("conv_id", convID),
+			zap.Int64("owner_id", ownerID),
+			zap.Int64("new_owner_id", newOwnerID),
+			zap.Error(err),
+		)
+		return 0, err
+	}
+
+	logger.Log.Info("Ownership transferred",
+		zap.Int64("conv_id", convID),
+		zap.Int64("owner_id", ownerID),
+		zap.Int64("new_owner_id", newOwnerID),
+	)
+
+	go s.notifyNewMessage(convID, event.MsgID, event.Seq, ownerID)
+
+	return event.Seq, nil
+}
+
+// RecallMessage 撤回消息，返回撤回事件的序列号
+// 发送者可在撤回时限内撤回自己的消息，群聊和频道的所有者/管理员可随时撤回任意消息
+func (s *Service) RecallMessage(ctx context.Context, convID int64, msgID string, operatorID int64) (int64, error) {
+	conv, operator, err := s.checkMembership(ctx, convID, operatorID)
+	if err != nil {
+		return 0, err
+	}
+
+	msg, err := s.repo.GetMessage(ctx, convID, msgID)
+	if err != nil {
+		return 0, err
+	}
+	if msg.Visibility == VisibilityRecalled {
+		return 0, ErrMessageRecalled
+	}
+	if isSystemMessage(msg) {
+		return 0, ErrSystemMessage
+	}
+
+	moderator := operator.Role.CanModerateMessages(conv.Type)
+	if !moderator {
+		if msg.SenderID != operatorID {
+			return 0, ErrNotMessageSender
+		}
+		if time.Since(msg.CreatedAt) > s.recallWindow {
+			return 0, ErrRecallWindowExpired
+		}
+	}
+
+	event := newSystemMessage(conv, operatorID, SystemEventMessageRecalled, map[string]interface{}{
+		"msg_id": msgID,
+		"seq":    msg.Seq,
+	})
+	if err := s.repo.RecallMessage(ctx, convID, msgID, event); err != nil {
+		logger.Log.Error("Failed to recall message",
+			zap.Int64("conv_id", convID),
+			zap.String("msg_id", msgID),
+			zap.Int64("operator_id", operatorID),
+			zap.Error(err),
+		)
+		return 0, err
+	}
+
+	logger.Log.Info("Message recalled",
+		zap.Int64("conv_id", convID),
+		zap.String("msg_id", msgID),
+		zap.Int64("operator_id", operatorID),
+		zap.Int64("seq", event.Seq),
+	)
+
+	go s.notifyNewMessage(convID, event.MsgID, event.Seq, operatorID)
+
+	return event.Seq, nil
+}
+
+// EditMessage 编辑自己发送的消息，返回编辑事件的序列号和编辑时间
+func (s *Service) EditMessage(ctx context.Context, convID int64, msgID string, operatorID int64, body map[string]interface{}) (int64, int64, error) {
+	if len(body) == 0 {
+		return 0, 0, ErrEmptyBody
+	}
+	if body["type"] == "system" {
+		return 0, 0, ErrSystemMessage
+	}
+
+	conv, member, err := s.checkMembership(ctx, convID, operatorID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if member.Muted {
+		return 0, 0, ErrMemberMuted
+	}
+	if !member.Role.CanSendMessage(conv.Type) {
+		return 0, 0, ErrSendNotAllowed
+	}
+
+	msg, err := s.repo.GetMessage(ctx, convID, msgID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if msg.Visibility == VisibilityRecalled {
+		return 0, 0, ErrMessageRecalled
+	}
+	if isSystemMessage(msg) {
+		return 0, 0, ErrSystemMessage
+	}
+	if msg.SenderID != operatorID {
+		return 0, 0, ErrNotMessageSender
+	}
+
+	event := newSystemMessage(conv, operatorID, SystemEventMessageEdited, map[string]interface{}{
+		"msg_id": msgID,
+		"seq":    msg.Seq,
+		"body":   body,
+	})
+	editedAt, err := s.repo.EditMessage(ctx, convID, msgID, operatorID, body, event)
+	if err != nil {
+		logger.Log.Error("Failed to edit message",
+			zap.Int64("conv_id", convID),
+			zap.String("msg_id", msgID),
+			zap.Int64("operator_id", operatorID),
+			zap.Error(err),
+		)
+		return 0, 0, err
+	}
+
+	logger.Log.Info("Message edited",
+		zap.Int64("conv_id", convID),
+		zap.String("msg_id", msgID),
+		zap.Int64("operator_id", operatorID),
+		zap.Int64("seq", event.Seq),
+	)
+
+	go s.notifyNewMessage(convID, event.MsgID, event.Seq, operatorID)
+
+	return event.Seq, editedAt.Unix(), nil
+}
+
+// ListConversations 获取用户的会话列表，返回会话摘要、下一页游标和是否还有更多
+func (s *Service) ListConversations(ctx context.Context, userID int64, cursor string, limit int32) ([]*ConversationSummary, string, bool, error) {
+	var after *ConversationCursor
+	if cursor != "" {
+		c, err := decodeConversationCursor(cursor)
+		if err != nil {
+			return nil, "", false, err
+		}
+		after = c
+	}
+
+	summaries, hasMore, err := s.repo.ListConversations(ctx, userID, after, limit)
+	if err != nil {
+		logger.Log.Error("Failed to list conversations",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return nil, "", false, err
+	}
+
+	var nextCursor string
+	if hasMore && len(summaries) > 0 {
+		last := summaries[len(summaries)-1]
+		nextCursor = encodeConversationCursor(&ConversationCursor{
+			LastActiveAt: last.LastActiveAt,
+			ConvID:       last.ID,
+		})
+	}
+
+	return summaries, nextCursor, hasMore, nil
+}
+
+// encodeConversationCursor 将游标编码为不透明字符串
+func encodeConversationCursor(c *ConversationCursor) string {
+	raw := fmt.Sprintf("%d:%d", c.LastActiveAt.UnixMicro(), c.ConvID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeConversationCursor 解析 encodeConversationCursor 生成的游标
+func decodeConversationCursor(cursor string) (*ConversationCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidCursor
+	}
+	micros, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	convID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &ConversationCursor{
+		LastActiveAt: time.UnixMicro(micros),
+		ConvID:       convID,
+	}, nil
+}
+
+// requireManager 检查操作者是否有权管理会话成员
+func (s *Service) requireManager(ctx context.Context, convID int64, operatorID int64) (*Conversation, *ConversationMember, error) {
+	conv, operator, err := s.checkMembership(ctx, convID, operatorID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if conv.Type == types.ConversationTypeDirect {
+		return nil, nil, ErrDirectConversation
+	}
+	if !operator.Role.CanManageMembers() {
+		return nil, nil, ErrManageNotAllowed
+	}
+
+	return conv, operator, nil
+}
+
+// newSystemMessage 构造系统消息，序列号在保存时分配
+func newSystemMessage(conv *Conversation, operatorID int64, event string, data map[string]interface{}) *Message {
+	body := map[string]interface{}{
+		"type":        "system",
+		"event":       event,
+		"operator_id": operatorID,
+	}
+	for k, v := range data {
+		body[k] = v
+	}
+
+	return &Message{
+		MsgID:      GenerateMessageID(),
+		ConvID:     conv.ID,
+		SenderID:   operatorID,
+		ConvType:   conv.Type,
+		Body:       body,
+		Visibility: VisibilityNormal,
+		CreatedAt:  time.Now(),
+	}
+}
+
+// isSystemMessage 判断是否为系统消息
+func isSystemMessage(msg *Message) bool {
+	return msg.Body["type"] == "system"
+}
+
+// notifyNewMessage 通知 Router 有新消息，extraRecipientIDs 为会话成员之外的额外接收者
+func (s *Service) notifyNewMessage(convID int64, msgID string, seq int64, senderID int64, extraRecipientIDs ...int64) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -193,6 +1129,7 @@ func (s *Service) notifyNewMessage(convID int64, msgID string, seq int64, sender
 			recipientIDs = append(recipientIDs, id)
 		}
 	}
+	recipientIDs = append(recipientIDs, extraRecipientIDs...)
 
 	if len(recipientIDs) == 0 {
 		return
@@ -215,3 +1152,21 @@ func (s *Service) notifyNewMessage(convID int64, msgID string, seq int64, sender
 		zap.Int32("notified_count", notifiedCount),
 	)
 }
+
+// FilterPeers 筛选出与用户至少共同在一个会话中的用户，在线状态等信息只对这些会话伙伴开放
+func (s *Service) FilterPeers(ctx context.Context, userID int64, userIDs []int64) ([]int64, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	peerIDs, err := s.repo.FilterPeers(ctx, userID, userIDs)
+	if err != nil {
+		logger.Log.Error("Failed to filter peers",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	return peerIDs, nil
+}
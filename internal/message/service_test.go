@@ -3,9 +3,14 @@ package message
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	gatewaypb "github.com/dollarkillerx/im-system/api/proto/gateway"
 	"github.com/dollarkillerx/im-system/pkg/logger"
 	"github.com/dollarkillerx/im-system/pkg/types"
 	"github.com/stretchr/testify/assert"
@@ -22,7 +27,8 @@ type MockMessageRepository struct {
 	conversations   map[int64]*Conversation
 	members         map[int64][]*ConversationMember
 	seqCounters     map[int64]int64
-	getNextSeqFunc  func(ctx context.Context, convID int64) (int64, error)
+	directPairs     map[[2]int64]int64
+	idempotency     map[string]*Message
 	saveMessageFunc func(ctx context.Context, msg *Message) error
 }
 
@@ -32,31 +38,71 @@ func newMockMessageRepository() *MockMessageRepository {
 		conversations: make(map[int64]*Conversation),
 		members:       make(map[int64][]*ConversationMember),
 		seqCounters:   make(map[int64]int64),
+		directPairs:   make(map[[2]int64]int64),
+		idempotency:   make(map[string]*Message),
 	}
 }
 
-func (m *MockMessageRepository) GetNextSeq(ctx context.Context, convID int64) (int64, error) {
-	if m.getNextSeqFunc != nil {
-		return m.getNextSeqFunc(ctx, convID)
-	}
-	m.seqCounters[convID]++
-	return m.seqCounters[convID], nil
-}
-
+// SaveMessage allocates the seq only when the save succeeds, like the transactional repository
 func (m *MockMessageRepository) SaveMessage(ctx context.Context, msg *Message) error {
 	if m.saveMessageFunc != nil {
 		return m.saveMessageFunc(ctx, msg)
 	}
+	m.seqCounters[msg.ConvID]++
+	msg.Seq = m.seqCounters[msg.ConvID]
 	m.messages[msg.ConvID] = append(m.messages[msg.ConvID], msg)
 	return nil
 }
 
+func (m *MockMessageRepository) SaveMessageIdempotent(ctx context.Context, msg *Message, key string, since time.Time) (*Message, error) {
+	if err := m.SaveMessage(ctx, msg); err != nil {
+		return nil, err
+	}
+	if original, found, _ := m.GetIdempotentMessage(ctx, msg.ConvID, msg.SenderID, key, since); found {
+		// roll back the save
+		m.seqCounters[msg.ConvID]--
+		m.messages[msg.ConvID] = m.messages[msg.ConvID][:len(m.messages[msg.ConvID])-1]
+		return original, nil
+	}
+	m.idempotency[idempotencyMapKey(msg.ConvID, msg.SenderID, key)] = msg
+	return msg, nil
+}
+
+func (m *MockMessageRepository) GetIdempotentMessage(ctx context.Context, convID int64, senderID int64, key string, since time.Time) (*Message, bool, error) {
+	msg, ok := m.idempotency[idempotencyMapKey(convID, senderID, key)]
+	if !ok || msg.CreatedAt.Before(since) {
+		return nil, false, nil
+	}
+	return msg, true, nil
+}
+
+func idempotencyMapKey(convID int64, senderID int64, key string) string {
+	return fmt.Sprintf("%d:%d:%s", convID, senderID, key)
+}
+
 func (m *MockMessageRepository) PullMessages(ctx context.Context, convID int64, sinceSeq int64, limit int32) ([]*Message, bool, error) {
 	msgs := m.messages[convID]
 	var result []*Message
 	for _, msg := range msgs {
 		if msg.Seq > sinceSeq {
-			result = append(result, msg)
+			result = append(result, readMessage(msg))
+		}
+	}
+
+	hasMore := int32(len(result)) > limit
+	if hasMore {
+		result = result[:limit]
+	}
+
+	return result, hasMore, nil
+}
+
+func (m *MockMessageRepository) PullMessagesBefore(ctx context.Context, convID int64, beforeSeq int64, limit int32) ([]*Message, bool, error) {
+	msgs := m.messages[convID]
+	var result []*Message
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if beforeSeq == 0 || msgs[i].Seq < beforeSeq {
+			result = append(result, readMessage(msgs[i]))
 		}
 	}
 
@@ -64,10 +110,73 @@ func (m *MockMessageRepository) PullMessages(ctx context.Context, convID int64,
 	if hasMore {
 		result = result[:limit]
 	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Seq < result[j].Seq })
 
 	return result, hasMore, nil
 }
 
+// readMessage returns a copy of a stored message as the repository would scan it
+func readMessage(stored *Message) *Message {
+	msg := *stored
+	msg.Body = make(map[string]interface{}, len(stored.Body))
+	for k, v := range stored.Body {
+		msg.Body[k] = v
+	}
+	redactRecalled(&msg)
+	return &msg
+}
+
+func (m *MockMessageRepository) findMessage(convID int64, msgID string) *Message {
+	for _, msg := range m.messages[convID] {
+		if msg.MsgID == msgID {
+			return msg
+		}
+	}
+	return nil
+}
+
+func (m *MockMessageRepository) GetMessage(ctx context.Context, convID int64, msgID string) (*Message, error) {
+	msg := m.findMessage(convID, msgID)
+	if msg == nil {
+		return nil, ErrMessageNotFound
+	}
+	return readMessage(msg), nil
+}
+
+func (m *MockMessageRepository) RecallMessage(ctx context.Context, convID int64, msgID string, event *Message) error {
+	msg := m.findMessage(convID, msgID)
+	if msg == nil {
+		return ErrMessageNotFound
+	}
+	if msg.Visibility == VisibilityRecalled {
+		return ErrMessageRecalled
+	}
+	msg.Visibility = VisibilityRecalled
+	for _, other := range m.messages[convID] {
+		if other.Body["event"] == SystemEventMessageEdited && other.Body["msg_id"] == msgID {
+			other.Visibility = VisibilityRecalled
+		}
+	}
+	return m.SaveMessage(ctx, event)
+}
+
+func (m *MockMessageRepository) EditMessage(ctx context.Context, convID int64, msgID string, editorID int64, body map[string]interface{}, event *Message) (time.Time, error) {
+	msg := m.findMessage(convID, msgID)
+	if msg == nil {
+		return time.Time{}, ErrMessageNotFound
+	}
+	if msg.Visibility == VisibilityRecalled {
+		return time.Time{}, ErrMessageRecalled
+	}
+	editedAt := time.Now()
+	msg.Body = body
+	msg.EditedAt = &editedAt
+	if err := m.SaveMessage(ctx, event); err != nil {
+		return time.Time{}, err
+	}
+	return editedAt, nil
+}
+
 func (m *MockMessageRepository) CreateConversation(ctx context.Context, convType types.ConversationType, title string, ownerID int64, memberIDs []int64) (int64, error) {
 	convID := int64(len(m.conversations) + 1)
 	m.conversations[convID] = &Conversation{
@@ -96,6 +205,23 @@ func (m *MockMessageRepository) CreateConversation(ctx context.Context, convType
 	return convID, nil
 }
 
+func (m *MockMessageRepository) GetOrCreateDirectConversation(ctx context.Context, userID int64, peerID int64) (int64, bool, error) {
+	pair := [2]int64{userID, peerID}
+	if userID > peerID {
+		pair = [2]int64{peerID, userID}
+	}
+	if convID, ok := m.directPairs[pair]; ok {
+		return convID, false, nil
+	}
+
+	convID, err := m.CreateConversation(ctx, types.ConversationTypeDirect, "", userID, []int64{userID, peerID})
+	if err != nil {
+		return 0, false, err
+	}
+	m.directPairs[pair] = convID
+	return convID, true, nil
+}
+
 func (m *MockMessageRepository) GetConversation(ctx context.Context, convID int64) (*Conversation, []*ConversationMember, error) {
 	conv, ok := m.conversations[convID]
 	if !ok {
@@ -104,15 +230,34 @@ func (m *MockMessageRepository) GetConversation(ctx context.Context, convID int6
 	return conv, m.members[convID], nil
 }
 
-func (m *MockMessageRepository) UpdateReadSeq(ctx context.Context, convID int64, userID int64, seq int64) error {
+func (m *MockMessageRepository) UpdateReadSeq(ctx context.Context, convID int64, userID int64, seq int64) (bool, error) {
 	members := m.members[convID]
 	for _, member := range members {
 		if member.UserID == userID {
+			if seq > m.seqCounters[convID] {
+				return false, ErrInvalidReadSeq
+			}
+			if member.LastReadSeq >= seq {
+				return false, nil
+			}
 			member.LastReadSeq = seq
-			return nil
+			return true, nil
+		}
+	}
+	return false, errors.New("member not found")
+}
+
+func (m *MockMessageRepository) GetMembership(ctx context.Context, convID int64, userID int64) (*Conversation, *ConversationMember, error) {
+	conv, ok := m.conversations[convID]
+	if !ok {
+		return nil, nil, ErrConversationNotFound
+	}
+	for _, member := range m.members[convID] {
+		if member.UserID == userID {
+			return conv, member, nil
 		}
 	}
-	return errors.New("member not found")
+	return conv, nil, ErrNotMember
 }
 
 func (m *MockMessageRepository) GetConversationMembers(ctx context.Context, convID int64) ([]int64, error) {
@@ -124,6 +269,175 @@ func (m *MockMessageRepository) GetConversationMembers(ctx context.Context, conv
 	return userIDs, nil
 }
 
+func (m *MockMessageRepository) FilterPeers(ctx context.Context, userID int64, userIDs []int64) ([]int64, error) {
+	peers := make(map[int64]bool)
+	for _, members := range m.members {
+		var joined bool
+		for _, member := range members {
+			if member.UserID == userID {
+				joined = true
+			}
+		}
+		if !joined {
+			continue
+		}
+		for _, member := range members {
+			peers[member.UserID] = true
+		}
+	}
+
+	var peerIDs []int64
+	for _, id := range userIDs {
+		if peers[id] {
+			peerIDs = append(peerIDs, id)
+		}
+	}
+	return peerIDs, nil
+}
+
+// AddMembers only applies the change once the event is saved, like the transactional repository
+func (m *MockMessageRepository) AddMembers(ctx context.Context, convID int64, memberIDs []int64, role types.ConversationRole, newEvent func([]int64) *Message) ([]int64, error) {
+	existing := make(map[int64]bool)
+	for _, member := range m.members[convID] {
+		existing[member.UserID] = true
+	}
+
+	var added []int64
+	var joined []*ConversationMember
+	for _, userID := range memberIDs {
+		if existing[userID] {
+			continue
+		}
+		existing[userID] = true
+		joined = append(joined, &ConversationMember{
+			ConvID:      convID,
+			UserID:      userID,
+			Role:        role,
+			LastReadSeq: m.seqCounters[convID],
+		})
+		added = append(added, userID)
+	}
+	if err := m.saveMembershipEvent(ctx, added, newEvent); err != nil {
+		return nil, err
+	}
+	m.members[convID] = append(m.members[convID], joined...)
+	return added, nil
+}
+
+func (m *MockMessageRepository) saveMembershipEvent(ctx context.Context, userIDs []int64, newEvent func([]int64) *Message) error {
+	if len(userIDs) == 0 || newEvent == nil {
+		return nil
+	}
+	return m.SaveMessage(ctx, newEvent(userIDs))
+}
+
+func (m *MockMessageRepository) RemoveMembers(ctx context.Context, convID int64, memberIDs []int64, newEvent func([]int64) *Message) ([]int64, error) {
+	targets := make(map[int64]bool)
+	for _, userID := range memberIDs {
+		targets[userID] = true
+	}
+
+	var kept []*ConversationMember
+	var removed []int64
+	for _, member := range m.members[convID] {
+		if targets[member.UserID] {
+			removed = append(removed, member.UserID)
+			continue
+		}
+		kept = append(kept, member)
+	}
+	if err := m.saveMembershipEvent(ctx, removed, newEvent); err != nil {
+		return nil, err
+	}
+	m.members[convID] = kept
+	return removed, nil
+}
+
+func (m *MockMessageRepository) UpdateMemberRole(ctx context.Context, convID int64, userID int64, role types.ConversationRole, event *Message) error {
+	for _, member := range m.members[convID] {
+		if member.UserID == userID {
+			member.Role = role
+			return m.SaveMessage(ctx, event)
+		}
+	}
+	return ErrMemberNotFound
+}
+
+func (m *MockMessageRepository) TransferOwnership(ctx context.Context, convID int64, ownerID int64, newOwnerID int64, event *Message) error {
+	var owner, newOwner *ConversationMember
+	for _, member := range m.members[convID] {
+		switch member.UserID {
+		case ownerID:
+			owner = member
+		case newOwnerID:
+			newOwner = member
+		}
+	}
+	if newOwner == nil {
+		return ErrMemberNotFound
+	}
+	newOwner.Role = types.ConversationRoleOwner
+	if owner != nil {
+		owner.Role = types.ConversationRoleAdmin
+	}
+	m.conversations[convID].OwnerID = newOwnerID
+	return m.SaveMessage(ctx, event)
+}
+
+func (m *MockMessageRepository) ListConversations(ctx context.Context, userID int64, cursor *ConversationCursor, limit int32) ([]*ConversationSummary, bool, error) {
+	var summaries []*ConversationSummary
+	for convID, conv := range m.conversations {
+		for _, member := range m.members[convID] {
+			if member.UserID != userID {
+				continue
+			}
+			summary := &ConversationSummary{
+				Conversation: *conv,
+				Role:         member.Role,
+				Muted:        member.Muted,
+				CurrentSeq:   m.seqCounters[convID],
+				LastReadSeq:  member.LastReadSeq,
+				LastActiveAt: conv.CreatedAt,
+			}
+			for _, msg := range m.messages[convID] {
+				if msg.Seq > member.LastReadSeq && msg.SenderID != userID && !isSystemMessage(msg) &&
+					msg.Visibility != VisibilityRecalled {
+					summary.UnreadCount++
+				}
+			}
+			if msgs := m.messages[convID]; len(msgs) > 0 {
+				summary.LastMessage = msgs[len(msgs)-1]
+				summary.LastActiveAt = summary.LastMessage.CreatedAt
+			}
+			summaries = append(summaries, summary)
+		}
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if !summaries[i].LastActiveAt.Equal(summaries[j].LastActiveAt) {
+			return summaries[i].LastActiveAt.After(summaries[j].LastActiveAt)
+		}
+		return summaries[i].ID > summaries[j].ID
+	})
+
+	if cursor != nil {
+		var page []*ConversationSummary
+		for _, summary := range summaries {
+			if summary.LastActiveAt.Before(cursor.LastActiveAt) ||
+				(summary.LastActiveAt.Equal(cursor.LastActiveAt) && summary.ID < cursor.ConvID) {
+				page = append(page, summary)
+			}
+		}
+		summaries = page
+	}
+
+	hasMore := int32(len(summaries)) > limit
+	if hasMore {
+		summaries = summaries[:limit]
+	}
+	return summaries, hasMore, nil
+}
+
 // Use the existing MockRouterClient from router_client.go
 
 func TestService_SendMessage(t *testing.T) {
@@ -134,7 +448,7 @@ func TestService_SendMessage(t *testing.T) {
 		convType  types.ConversationType
 		body      map[string]interface{}
 		setupMock func(*MockMessageRepository)
-		wantErr   bool
+		wantErr   error
 	}{
 		{
 			name:     "send text message",
@@ -145,11 +459,10 @@ func TestService_SendMessage(t *testing.T) {
 				"type":    "text",
 				"content": "Hello, World!",
 			},
-			wantErr: false,
 		},
 		{
 			name:     "send image message",
-			convID:   1,
+			convID:   2,
 			senderID: 100,
 			convType: types.ConversationTypeGroup,
 			body: map[string]interface{}{
@@ -158,20 +471,64 @@ func TestService_SendMessage(t *testing.T) {
 				"width":   1920,
 				"height":  1080,
 			},
-			wantErr: false,
 		},
 		{
-			name:     "failed to get next seq",
-			convID:   1,
+			name:     "channel publisher can send",
+			convID:   3,
+			senderID: 300,
+			convType: types.ConversationTypeChannel,
+			body:     map[string]interface{}{"type": "text"},
+		},
+		{
+			name:     "conversation not found",
+			convID:   999,
 			senderID: 100,
 			convType: types.ConversationTypeDirect,
 			body:     map[string]interface{}{"type": "text"},
+			wantErr:  ErrConversationNotFound,
+		},
+		{
+			name:     "sender is not a member",
+			convID:   1,
+			senderID: 999,
+			convType: types.ConversationTypeDirect,
+			body:     map[string]interface{}{"type": "text"},
+			wantErr:  ErrNotMember,
+		},
+		{
+			name:     "conversation type mismatch",
+			convID:   1,
+			senderID: 100,
+			convType: types.ConversationTypeGroup,
+			body:     map[string]interface{}{"type": "text"},
+			wantErr:  ErrConvTypeMismatch,
+		},
+		{
+			name:     "channel member cannot send",
+			convID:   3,
+			senderID: 200,
+			convType: types.ConversationTypeChannel,
+			body:     map[string]interface{}{"type": "text"},
+			wantErr:  ErrSendNotAllowed,
+		},
+		{
+			name:     "viewer cannot send",
+			convID:   2,
+			senderID: 400,
+			convType: types.ConversationTypeGroup,
+			body:     map[string]interface{}{"type": "text"},
+			wantErr:  ErrSendNotAllowed,
+		},
+		{
+			name:     "muted member cannot send",
+			convID:   2,
+			senderID: 200,
+			convType: types.ConversationTypeGroup,
+			body:     map[string]interface{}{"type": "text"},
 			setupMock: func(m *MockMessageRepository) {
-				m.getNextSeqFunc = func(ctx context.Context, convID int64) (int64, error) {
-					return 0, errors.New("database error")
-				}
+				m.members[2][1].Muted = true
 			},
-			wantErr: true,
+			wantErr: ErrMemberMuted,
 		},
 		{
 			name:     "failed to save message",
@@ -184,13 +541,14 @@ func TestService_SendMessage(t *testing.T) {
 					return errors.New("save error")
 				}
 			},
-			wantErr: true,
+			wantErr: errors.New("save error"),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := newMockMessageRepository()
+			setupSendFixtures(t, repo)
 			if tt.setupMock != nil {
 				tt.setupMock(repo)
 			}
@@ -205,10 +563,12 @@ func TestService_SendMessage(t *testing.T) {
 				tt.body,
 				nil,
 				nil,
+				"",
 			)
 
-			if tt.wantErr {
+			if tt.wantErr != nil {
 				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr.Error())
 				assert.Empty(t, msgID)
 			} else {
 				require.NoError(t, err)
@@ -220,92 +580,496 @@ func TestService_SendMessage(t *testing.T) {
 	}
 }
 
-func TestService_CreateConversation(t *testing.T) {
+func TestService_SendMessage_Idempotency(t *testing.T) {
+	ctx := context.Background()
+	body := map[string]interface{}{"type": "text", "content": "hello"}
+
+	t.Run("retry returns original message", func(t *testing.T) {
+		repo := newMockMessageRepository()
+		setupSendFixtures(t, repo)
+		service := NewService(repo, &MockRouterClient{})
+
+		msgID, seq, createdAt, err := service.SendMessage(ctx, 1, 100, types.ConversationTypeDirect, body, nil, nil, "client-1")
+		require.NoError(t, err)
+
+		retryID, retrySeq, retryCreatedAt, err := service.SendMessage(ctx, 1, 100, types.ConversationTypeDirect, body, nil, nil, "client-1")
+		require.NoError(t, err)
+		assert.Equal(t, msgID, retryID)
+		assert.Equal(t, seq, retrySeq)
+		assert.Equal(t, createdAt, retryCreatedAt)
+		assert.Len(t, repo.messages[1], 1)
+	})
+
+	t.Run("key is scoped per sender and conversation", func(t *testing.T) {
+		repo := newMockMessageRepository()
+		setupSendFixtures(t, repo)
+		service := NewService(repo, &MockRouterClient{})
+
+		first, _, _, err := service.SendMessage(ctx, 1, 100, types.ConversationTypeDirect, body, nil, nil, "client-1")
+		require.NoError(t, err)
+		otherSender, _, _, err := service.SendMessage(ctx, 1, 200, types.ConversationTypeDirect, body, nil, nil, "client-1")
+		require.NoError(t, err)
+		otherConv, _, _, err := service.SendMessage(ctx, 2, 100, types.ConversationTypeGroup, body, nil, nil, "client-1")
+		require.NoError(t, err)
+
+		assert.NotEqual(t, first, otherSender)
+		assert.NotEqual(t, first, otherConv)
+		assert.Len(t, repo.messages[1], 2)
+	})
+
+	t.Run("expired key creates new message", func(t *testing.T) {
+		repo := newMockMessageRepository()
+		setupSendFixtures(t, repo)
+		service := NewService(repo, &MockRouterClient{}).WithIdempotencyWindow(time.Minute)
+
+		msgID, _, _, err := service.SendMessage(ctx, 1, 100, types.ConversationTypeDirect, body, nil, nil, "client-1")
+		require.NoError(t, err)
+		repo.messages[1][0].CreatedAt = time.Now().Add(-2 * time.Minute)
+
+		retryID, _, _, err := service.SendMessage(ctx, 1, 100, types.ConversationTypeDirect, body, nil, nil, "client-1")
+		require.NoError(t, err)
+		assert.NotEqual(t, msgID, retryID)
+	})
+
+	t.Run("concurrent duplicate returns stored message", func(t *testing.T) {
+		repo := newMockMessageRepository()
+		setupSendFixtures(t, repo)
+		service := NewService(repo, &MockRouterClient{})
+
+		// Simulate another request saving the same key between lookup and save
+		original := &Message{MsgID: "original", ConvID: 1, Seq: 1, SenderID: 100, CreatedAt: time.Now()}
+		repo.saveMessageFunc = func(ctx context.Context, msg *Message) error {
+			repo.saveMessageFunc = nil
+			require.NoError(t, repo.SaveMessage(ctx, original))
+			repo.idempotency[idempotencyMapKey(1, 100, "client-1")] = original
+			return repo.SaveMessage(ctx, msg)
+		}
+
+		msgID, seq, _, err := service.SendMessage(ctx, 1, 100, types.ConversationTypeDirect, body, nil, nil, "client-1")
+		require.NoError(t, err)
+		assert.Equal(t, "original", msgID)
+		assert.Equal(t, int64(1), seq)
+
+		// The losing save is rolled back without consuming a seq
+		assert.Len(t, repo.messages[1], 1)
+		assert.Equal(t, int64(1), repo.seqCounters[1])
+	})
+
+	t.Run("retry after leaving is rejected", func(t *testing.T) {
+		repo := newMockMessageRepository()
+		setupSendFixtures(t, repo)
+		service := NewService(repo, &MockRouterClient{})
+
+		_, _, _, err := service.SendMessage(ctx, 2, 200, types.ConversationTypeGroup, body, nil, nil, "client-1")
+		require.NoError(t, err)
+		_, err = repo.RemoveMembers(ctx, 2, []int64{200}, nil)
+		require.NoError(t, err)
+
+		_, _, _, err = service.SendMessage(ctx, 2, 200, types.ConversationTypeGroup, body, nil, nil, "client-1")
+		assert.ErrorIs(t, err, ErrNotMember)
+	})
+
+	t.Run("key too long", func(t *testing.T) {
+		repo := newMockMessageRepository()
+		setupSendFixtures(t, repo)
+		service := NewService(repo, &MockRouterClient{})
+
+		_, _, _, err := service.SendMessage(ctx, 1, 100, types.ConversationTypeDirect, body, nil, nil, strings.Repeat("k", MaxIdempotencyKeyLength+1))
+		assert.ErrorIs(t, err, ErrInvalidIdempotencyKey)
+	})
+}
+
+func TestService_SendMessage_FailedSaveKeepsSeqContiguous(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockMessageRepository()
+	setupSendFixtures(t, repo)
+	service := NewService(repo, &MockRouterClient{})
+	body := map[string]interface{}{"type": "text"}
+
+	_, seq, _, err := service.SendMessage(ctx, 1, 100, types.ConversationTypeDirect, body, nil, nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), seq)
+
+	repo.saveMessageFunc = func(ctx context.Context, msg *Message) error {
+		return errors.New("save error")
+	}
+	_, _, _, err = service.SendMessage(ctx, 1, 100, types.ConversationTypeDirect, body, nil, nil, "")
+	require.Error(t, err)
+
+	repo.saveMessageFunc = nil
+	_, seq, _, err = service.SendMessage(ctx, 1, 100, types.ConversationTypeDirect, body, nil, nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), seq)
+}
+
+// setupSendFixtures creates a direct conversation (1), a group with a viewer (2)
+// and a channel with a publisher (3)
+// seedMessage stores a user message sent at createdAt and returns its ID
+func seedMessage(t *testing.T, repo *MockMessageRepository, convID int64, senderID int64, createdAt time.Time) string {
+	msg := &Message{
+		MsgID:      GenerateMessageID(),
+		ConvID:     convID,
+		SenderID:   senderID,
+		ConvType:   repo.conversations[convID].Type,
+		Body:       map[string]interface{}{"type": "text", "content": "secret"},
+		Mentions:   []int64{200},
+		Visibility: VisibilityNormal,
+		CreatedAt:  createdAt,
+	}
+	require.NoError(t, repo.SaveMessage(context.Background(), msg))
+	return msg.MsgID
+}
+
+func TestService_RecallMessage(t *testing.T) {
 	tests := []struct {
-		name      string
-		convType  types.ConversationType
-		title     string
-		ownerID   int64
-		memberIDs []int64
-		wantErr   bool
-		errMsg    string
+		name       string
+		convID     int64
+		senderID   int64
+		operatorID int64
+		sentAgo    time.Duration
+		wantErr    error
 	}{
 		{
-			name:      "create direct conversation",
-			convType:  types.ConversationTypeDirect,
-			title:     "",
-			ownerID:   100,
-			memberIDs: []int64{100, 200},
-			wantErr:   false,
+			name:       "sender recalls within window",
+			convID:     2,
+			senderID:   200,
+			operatorID: 200,
+			sentAgo:    time.Minute,
 		},
 		{
-			name:      "create group conversation",
-			convType:  types.ConversationTypeGroup,
-			title:     "Test Group",
-			ownerID:   100,
-			memberIDs: []int64{100, 200, 300},
-			wantErr:   false,
+			name:       "sender recall window expired",
+			convID:     2,
+			senderID:   200,
+			operatorID: 200,
+			sentAgo:    time.Hour,
+			wantErr:    ErrRecallWindowExpired,
 		},
 		{
-			name:      "create channel",
-			convType:  types.ConversationTypeChannel,
-			title:     "Test Channel",
-			ownerID:   100,
-			memberIDs: []int64{100, 200},
-			wantErr:   false,
+			name:       "owner recalls member message after window",
+			convID:     2,
+			senderID:   200,
+			operatorID: 100,
+			sentAgo:    time.Hour,
 		},
 		{
-			name:      "invalid conversation type",
-			convType:  types.ConversationType("invalid"),
-			title:     "Test",
-			ownerID:   100,
-			memberIDs: []int64{100},
-			wantErr:   true,
-			errMsg:    "invalid conversation type",
+			name:       "member cannot recall others' messages",
+			convID:     2,
+			senderID:   100,
+			operatorID: 200,
+			sentAgo:    time.Minute,
+			wantErr:    ErrNotMessageSender,
 		},
 		{
-			name:      "owner not in members - should be added",
-			convType:  types.ConversationTypeGroup,
-			title:     "Test Group",
-			ownerID:   100,
-			memberIDs: []int64{200, 300},
-			wantErr:   false,
+			name:       "direct peer cannot recall others' messages",
+			convID:     1,
+			senderID:   200,
+			operatorID: 100,
+			sentAgo:    time.Minute,
+			wantErr:    ErrNotMessageSender,
+		},
+		{
+			name:       "non-member cannot recall",
+			convID:     2,
+			senderID:   200,
+			operatorID: 999,
+			sentAgo:    time.Minute,
+			wantErr:    ErrNotMember,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := newMockMessageRepository()
-			routerClient := &MockRouterClient{}
-			service := NewService(repo, routerClient)
+			setupSendFixtures(t, repo)
+			service := NewService(repo, &MockRouterClient{})
 
-			convID, err := service.CreateConversation(
-				context.Background(),
-				tt.convType,
-				tt.title,
-				tt.ownerID,
-				tt.memberIDs,
-			)
+			msgID := seedMessage(t, repo, tt.convID, tt.senderID, time.Now().Add(-tt.sentAgo))
 
-			if tt.wantErr {
-				assert.Error(t, err)
-				if tt.errMsg != "" {
-					assert.Contains(t, err.Error(), tt.errMsg)
-				}
-				assert.Equal(t, int64(0), convID)
-			} else {
-				require.NoError(t, err)
-				assert.Greater(t, convID, int64(0))
+			seq, err := service.RecallMessage(context.Background(), tt.convID, msgID, tt.operatorID)
 
-				// Verify owner was added to members
-				conv, members, _ := repo.GetConversation(context.Background(), convID)
-				assert.NotNil(t, conv)
-				assert.NotEmpty(t, members)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Equal(t, VisibilityNormal, repo.findMessage(tt.convID, msgID).Visibility)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), seq)
 
-				ownerFound := false
-				for _, member := range members {
-					if member.UserID == tt.ownerID {
-						ownerFound = true
+			event := repo.messages[tt.convID][1]
+			assert.Equal(t, SystemEventMessageRecalled, event.Body["event"])
+			assert.Equal(t, msgID, event.Body["msg_id"])
+			assert.Equal(t, tt.operatorID, event.Body["operator_id"])
+
+			// a second recall is rejected
+			_, err = service.RecallMessage(context.Background(), tt.convID, msgID, tt.operatorID)
+			assert.ErrorIs(t, err, ErrMessageRecalled)
+		})
+	}
+}
+
+func TestService_RecallMessage_Errors(t *testing.T) {
+	repo := newMockMessageRepository()
+	setupSendFixtures(t, repo)
+	service := NewService(repo, &MockRouterClient{}).WithRecallWindow(time.Hour)
+	ctx := context.Background()
+
+	_, err := service.RecallMessage(ctx, 2, "missing", 100)
+	assert.ErrorIs(t, err, ErrMessageNotFound)
+
+	seq, err := service.UpdateMemberRole(ctx, 2, 100, 200, types.ConversationRoleAdmin)
+	require.NoError(t, err)
+	systemMsgID := repo.messages[2][seq-1].MsgID
+	_, err = service.RecallMessage(ctx, 2, systemMsgID, 100)
+	assert.ErrorIs(t, err, ErrSystemMessage)
+
+	// the configured window applies to senders
+	msgID := seedMessage(t, repo, 2, 400, time.Now().Add(-30*time.Minute))
+	repo.members[2][2].Role = types.ConversationRoleMember
+	_, err = service.RecallMessage(ctx, 2, msgID, 400)
+	assert.NoError(t, err)
+}
+
+func TestService_EditMessage(t *testing.T) {
+	newBody := map[string]interface{}{"type": "text", "content": "edited"}
+
+	tests := []struct {
+		name       string
+		senderID   int64
+		operatorID int64
+		body       map[string]interface{}
+		recalled   bool
+		wantErr    error
+	}{
+		{
+			name:       "sender edits own message",
+			senderID:   100,
+			operatorID: 100,
+			body:       newBody,
+		},
+		{
+			name:       "owner cannot edit others' messages",
+			senderID:   200,
+			operatorID: 100,
+			body:       newBody,
+			wantErr:    ErrNotMessageSender,
+		},
+		{
+			name:       "recalled message cannot be edited",
+			senderID:   100,
+			operatorID: 100,
+			body:       newBody,
+			recalled:   true,
+			wantErr:    ErrMessageRecalled,
+		},
+		{
+			name:       "empty body",
+			senderID:   100,
+			operatorID: 100,
+			body:       map[string]interface{}{},
+			wantErr:    ErrEmptyBody,
+		},
+		{
+			name:       "cannot turn a message into a system message",
+			senderID:   100,
+			operatorID: 100,
+			body:       map[string]interface{}{"type": "system", "event": "member_added"},
+			wantErr:    ErrSystemMessage,
+		},
+		{
+			name:       "viewer cannot edit",
+			senderID:   400,
+			operatorID: 400,
+			body:       newBody,
+			wantErr:    ErrSendNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockMessageRepository()
+			setupSendFixtures(t, repo)
+			service := NewService(repo, &MockRouterClient{})
+
+			msgID := seedMessage(t, repo, 2, tt.senderID, time.Now())
+			if tt.recalled {
+				repo.findMessage(2, msgID).Visibility = VisibilityRecalled
+			}
+
+			seq, editedAt, err := service.EditMessage(context.Background(), 2, msgID, tt.operatorID, tt.body)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, repo.findMessage(2, msgID).EditedAt)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), seq)
+			assert.Greater(t, editedAt, int64(0))
+
+			msg, err := repo.GetMessage(context.Background(), 2, msgID)
+			require.NoError(t, err)
+			assert.Equal(t, "edited", msg.Body["content"])
+			require.NotNil(t, msg.EditedAt)
+
+			event := repo.messages[2][1]
+			assert.Equal(t, SystemEventMessageEdited, event.Body["event"])
+			assert.Equal(t, msgID, event.Body["msg_id"])
+			assert.Equal(t, newBody, event.Body["body"])
+		})
+	}
+}
+
+func TestService_RecalledContentHidden(t *testing.T) {
+	repo := newMockMessageRepository()
+	setupSendFixtures(t, repo)
+	service := NewService(repo, &MockRouterClient{})
+	ctx := context.Background()
+
+	msgID := seedMessage(t, repo, 2, 200, time.Now())
+	_, _, err := service.EditMessage(ctx, 2, msgID, 200, map[string]interface{}{"type": "text", "content": "still secret"})
+	require.NoError(t, err)
+	_, err = service.RecallMessage(ctx, 2, msgID, 200)
+	require.NoError(t, err)
+
+	msgs, _, err := service.PullMessages(ctx, 2, 200, 0, 50)
+	require.NoError(t, err)
+	require.Len(t, msgs, 3)
+
+	assert.Equal(t, map[string]interface{}{"type": VisibilityRecalled}, msgs[0].Body)
+	assert.Nil(t, msgs[0].Mentions)
+
+	// the edit event no longer carries the edited body
+	assert.Equal(t, SystemEventMessageEdited, msgs[1].Body["event"])
+	assert.NotContains(t, msgs[1].Body, "body")
+
+	assert.Equal(t, SystemEventMessageRecalled, msgs[2].Body["event"])
+	assert.Equal(t, VisibilityNormal, msgs[2].Visibility)
+}
+
+func setupSendFixtures(t *testing.T, repo *MockMessageRepository) {
+	ctx := context.Background()
+
+	_, err := repo.CreateConversation(ctx, types.ConversationTypeDirect, "", 100, []int64{100, 200})
+	require.NoError(t, err)
+
+	_, err = repo.CreateConversation(ctx, types.ConversationTypeGroup, "Group", 100, []int64{100, 200, 400})
+	require.NoError(t, err)
+	repo.members[2][2].Role = types.ConversationRoleViewer
+
+	_, err = repo.CreateConversation(ctx, types.ConversationTypeChannel, "Channel", 100, []int64{100, 200, 300})
+	require.NoError(t, err)
+	repo.members[3][2].Role = types.ConversationRolePublisher
+}
+
+func TestService_CreateConversation(t *testing.T) {
+	tests := []struct {
+		name      string
+		convType  types.ConversationType
+		title     string
+		ownerID   int64
+		memberIDs []int64
+		wantErr   bool
+		errMsg    string
+	}{
+		{
+			name:      "create direct conversation",
+			convType:  types.ConversationTypeDirect,
+			title:     "",
+			ownerID:   100,
+			memberIDs: []int64{100, 200},
+			wantErr:   false,
+		},
+		{
+			name:      "create group conversation",
+			convType:  types.ConversationTypeGroup,
+			title:     "Test Group",
+			ownerID:   100,
+			memberIDs: []int64{100, 200, 300},
+			wantErr:   false,
+		},
+		{
+			name:      "create channel",
+			convType:  types.ConversationTypeChannel,
+			title:     "Test Channel",
+			ownerID:   100,
+			memberIDs: []int64{100, 200},
+			wantErr:   false,
+		},
+		{
+			name:      "invalid conversation type",
+			convType:  types.ConversationType("invalid"),
+			title:     "Test",
+			ownerID:   100,
+			memberIDs: []int64{100},
+			wantErr:   true,
+			errMsg:    "invalid conversation type",
+		},
+		{
+			name:      "owner not in members - should be added",
+			convType:  types.ConversationTypeGroup,
+			title:     "Test Group",
+			ownerID:   100,
+			memberIDs: []int64{200, 300},
+			wantErr:   false,
+		},
+		{
+			name:      "direct conversation with owner only added",
+			convType:  types.ConversationTypeDirect,
+			ownerID:   100,
+			memberIDs: []int64{200},
+			wantErr:   false,
+		},
+		{
+			name:      "direct conversation with three members",
+			convType:  types.ConversationTypeDirect,
+			ownerID:   100,
+			memberIDs: []int64{100, 200, 300},
+			wantErr:   true,
+			errMsg:    ErrInvalidDirectMembers.Error(),
+		},
+		{
+			name:      "direct conversation with self",
+			convType:  types.ConversationTypeDirect,
+			ownerID:   100,
+			memberIDs: []int64{100},
+			wantErr:   true,
+			errMsg:    ErrInvalidDirectMembers.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockMessageRepository()
+			routerClient := &MockRouterClient{}
+			service := NewService(repo, routerClient)
+
+			convID, err := service.CreateConversation(
+				context.Background(),
+				tt.convType,
+				tt.title,
+				tt.ownerID,
+				tt.memberIDs,
+			)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errMsg != "" {
+					assert.Contains(t, err.Error(), tt.errMsg)
+				}
+				assert.Equal(t, int64(0), convID)
+			} else {
+				require.NoError(t, err)
+				assert.Greater(t, convID, int64(0))
+
+				// Verify owner was added to members
+				conv, members, _ := repo.GetConversation(context.Background(), convID)
+				assert.NotNil(t, conv)
+				assert.NotEmpty(t, members)
+
+				ownerFound := false
+				for _, member := range members {
+					if member.UserID == tt.ownerID {
+						ownerFound = true
 						assert.Equal(t, types.ConversationRoleOwner, member.Role)
 						break
 					}
@@ -316,13 +1080,58 @@ func TestService_CreateConversation(t *testing.T) {
 	}
 }
 
+func TestService_GetOrCreateDirectConversation(t *testing.T) {
+	repo := newMockMessageRepository()
+	service := NewService(repo, &MockRouterClient{})
+	ctx := context.Background()
+
+	convID, created, err := service.GetOrCreateDirectConversation(ctx, 100, 200)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Greater(t, convID, int64(0))
+
+	t.Run("same pair returns existing conversation", func(t *testing.T) {
+		gotID, created, err := service.GetOrCreateDirectConversation(ctx, 100, 200)
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, convID, gotID)
+	})
+
+	t.Run("peer side returns existing conversation", func(t *testing.T) {
+		gotID, created, err := service.GetOrCreateDirectConversation(ctx, 200, 100)
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, convID, gotID)
+	})
+
+	t.Run("CreateConversation reuses existing conversation", func(t *testing.T) {
+		gotID, err := service.CreateConversation(ctx, types.ConversationTypeDirect, "", 200, []int64{100, 200})
+		require.NoError(t, err)
+		assert.Equal(t, convID, gotID)
+		assert.Len(t, repo.conversations, 1)
+	})
+
+	t.Run("different pair creates new conversation", func(t *testing.T) {
+		gotID, created, err := service.GetOrCreateDirectConversation(ctx, 100, 300)
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.NotEqual(t, convID, gotID)
+	})
+
+	t.Run("cannot chat with self", func(t *testing.T) {
+		_, _, err := service.GetOrCreateDirectConversation(ctx, 100, 100)
+		assert.ErrorIs(t, err, ErrInvalidDirectMembers)
+	})
+}
+
 func TestService_PullMessages(t *testing.T) {
 	repo := newMockMessageRepository()
 	routerClient := &MockRouterClient{}
 	service := NewService(repo, routerClient)
 
 	// Setup: Create some messages
-	convID := int64(1)
+	convID, err := service.CreateConversation(context.Background(), types.ConversationTypeDirect, "", 100, []int64{100, 200})
+	require.NoError(t, err)
 	for i := 1; i <= 10; i++ {
 		_, _, _, _ = service.SendMessage(
 			context.Background(),
@@ -332,44 +1141,62 @@ func TestService_PullMessages(t *testing.T) {
 			map[string]interface{}{"type": "text", "content": "test"},
 			nil,
 			nil,
+			"",
 		)
 	}
 
 	tests := []struct {
 		name          string
 		convID        int64
+		userID        int64
 		sinceSeq      int64
 		limit         int32
 		expectCount   int
 		expectHasMore bool
-		wantErr       bool
+		wantErr       error
 	}{
 		{
 			name:          "pull first batch",
 			convID:        1,
+			userID:        200,
 			sinceSeq:      0,
 			limit:         5,
 			expectCount:   5,
 			expectHasMore: true,
-			wantErr:       false,
 		},
 		{
 			name:          "pull all messages",
 			convID:        1,
+			userID:        100,
 			sinceSeq:      0,
 			limit:         20,
 			expectCount:   10,
 			expectHasMore: false,
-			wantErr:       false,
 		},
 		{
 			name:          "pull with offset",
 			convID:        1,
+			userID:        100,
 			sinceSeq:      5,
 			limit:         10,
 			expectCount:   5,
 			expectHasMore: false,
-			wantErr:       false,
+		},
+		{
+			name:     "non-member cannot pull",
+			convID:   1,
+			userID:   999,
+			sinceSeq: 0,
+			limit:    10,
+			wantErr:  ErrNotMember,
+		},
+		{
+			name:     "conversation not found",
+			convID:   999,
+			userID:   100,
+			sinceSeq: 0,
+			limit:    10,
+			wantErr:  ErrConversationNotFound,
 		},
 	}
 
@@ -378,12 +1205,13 @@ func TestService_PullMessages(t *testing.T) {
 			messages, hasMore, err := service.PullMessages(
 				context.Background(),
 				tt.convID,
+				tt.userID,
 				tt.sinceSeq,
 				tt.limit,
 			)
 
-			if tt.wantErr {
-				assert.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
 			} else {
 				require.NoError(t, err)
 				assert.Len(t, messages, tt.expectCount)
@@ -393,107 +1221,936 @@ func TestService_PullMessages(t *testing.T) {
 	}
 }
 
-func TestService_GetConversation(t *testing.T) {
+// setupHistory creates a direct conversation between 100 and 200 with messages seq 1..10
+func setupHistory(t *testing.T) *Service {
 	repo := newMockMessageRepository()
-	routerClient := &MockRouterClient{}
-	service := NewService(repo, routerClient)
+	service := NewService(repo, &MockRouterClient{})
 
-	// Create a conversation
-	convID, err := service.CreateConversation(
-		context.Background(),
-		types.ConversationTypeGroup,
-		"Test Group",
-		100,
-		[]int64{100, 200, 300},
-	)
+	convID, err := service.CreateConversation(context.Background(), types.ConversationTypeDirect, "", 100, []int64{100, 200})
 	require.NoError(t, err)
+	for i := 1; i <= 10; i++ {
+		_, _, _, err := service.SendMessage(
+			context.Background(),
+			convID,
+			100,
+			types.ConversationTypeDirect,
+			map[string]interface{}{"type": "text", "content": fmt.Sprintf("message %d", i)},
+			nil,
+			nil,
+			"",
+		)
+		require.NoError(t, err)
+	}
+
+	return service
+}
+
+func messageSeqs(messages []*Message) []int64 {
+	seqs := make([]int64, 0, len(messages))
+	for _, msg := range messages {
+		seqs = append(seqs, msg.Seq)
+	}
+	return seqs
+}
+
+func TestService_PullMessagesBefore(t *testing.T) {
+	service := setupHistory(t)
 
 	tests := []struct {
-		name    string
-		convID  int64
-		wantErr bool
+		name          string
+		userID        int64
+		beforeSeq     int64
+		limit         int32
+		expectSeqs    []int64
+		expectHasMore bool
+		wantErr       error
 	}{
 		{
-			name:    "conversation found",
-			convID:  convID,
-			wantErr: false,
+			name:          "latest page",
+			userID:        200,
+			beforeSeq:     0,
+			limit:         3,
+			expectSeqs:    []int64{8, 9, 10},
+			expectHasMore: true,
 		},
 		{
-			name:    "conversation not found",
-			convID:  999,
-			wantErr: true,
+			name:          "older page",
+			userID:        200,
+			beforeSeq:     8,
+			limit:         3,
+			expectSeqs:    []int64{5, 6, 7},
+			expectHasMore: true,
+		},
+		{
+			name:          "oldest page",
+			userID:        100,
+			beforeSeq:     4,
+			limit:         5,
+			expectSeqs:    []int64{1, 2, 3},
+			expectHasMore: false,
+		},
+		{
+			name:      "negative anchor",
+			userID:    100,
+			beforeSeq: -1,
+			limit:     5,
+			wantErr:   ErrInvalidAnchor,
+		},
+		{
+			name:      "non-member cannot pull",
+			userID:    999,
+			beforeSeq: 0,
+			limit:     5,
+			wantErr:   ErrNotMember,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			conv, members, err := service.GetConversation(context.Background(), tt.convID)
+			messages, hasMore, err := service.PullMessagesBefore(context.Background(), 1, tt.userID, tt.beforeSeq, tt.limit)
 
-			if tt.wantErr {
-				assert.Error(t, err)
-			} else {
-				require.NoError(t, err)
-				assert.NotNil(t, conv)
-				assert.Equal(t, convID, conv.ID)
-				assert.Equal(t, types.ConversationTypeGroup, conv.Type)
-				assert.Equal(t, "Test Group", conv.Title)
-				assert.Len(t, members, 3)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
 			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectSeqs, messageSeqs(messages))
+			assert.Equal(t, tt.expectHasMore, hasMore)
 		})
 	}
 }
 
-func TestService_UpdateReadSeq(t *testing.T) {
-	repo := newMockMessageRepository()
-	routerClient := &MockRouterClient{}
-	service := NewService(repo, routerClient)
-
-	// Create conversation
-	convID, _ := service.CreateConversation(
-		context.Background(),
-		types.ConversationTypeDirect,
-		"",
-		100,
-		[]int64{100, 200},
-	)
+func TestService_PullMessagesAround(t *testing.T) {
+	service := setupHistory(t)
 
 	tests := []struct {
-		name    string
-		convID  int64
-		userID  int64
-		seq     int64
-		wantErr bool
+		name                string
+		userID              int64
+		aroundSeq           int64
+		limit               int32
+		expectSeqs          []int64
+		expectHasMoreBefore bool
+		expectHasMoreAfter  bool
+		wantErr             error
 	}{
 		{
-			name:    "update read seq successfully",
-			convID:  convID,
-			userID:  100,
-			seq:     50,
-			wantErr: false,
+			name:                "middle of history",
+			userID:              200,
+			aroundSeq:           5,
+			limit:               4,
+			expectSeqs:          []int64{3, 4, 5, 6},
+			expectHasMoreBefore: true,
+			expectHasMoreAfter:  true,
 		},
 		{
-			name:    "update for non-member",
-			convID:  convID,
-			userID:  999,
-			seq:     60,
-			wantErr: true,
+			name:               "near the start",
+			userID:             200,
+			aroundSeq:          2,
+			limit:              6,
+			expectSeqs:         []int64{1, 2, 3, 4},
+			expectHasMoreAfter: true,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := service.UpdateReadSeq(
-				context.Background(),
-				tt.convID,
-				tt.userID,
-				tt.seq,
-			)
+		{
+			name:                "near the end",
+			userID:              100,
+			aroundSeq:           10,
+			limit:               4,
+			expectSeqs:          []int64{8, 9, 10},
+			expectHasMoreBefore: true,
+		},
+		{
+			name:                "single message",
+			userID:              100,
+			aroundSeq:           7,
+			limit:               1,
+			expectSeqs:          []int64{7},
+			expectHasMoreBefore: true,
+			expectHasMoreAfter:  true,
+		},
+		{
+			name:      "invalid anchor",
+			userID:    100,
+			aroundSeq: 0,
+			limit:     4,
+			wantErr:   ErrInvalidAnchor,
+		},
+		{
+			name:      "non-member cannot pull",
+			userID:    999,
+			aroundSeq: 5,
+			limit:     4,
+			wantErr:   ErrNotMember,
+		},
+	}
 
-			if tt.wantErr {
-				assert.Error(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, hasMoreBefore, hasMoreAfter, err := service.PullMessagesAround(context.Background(), 1, tt.userID, tt.aroundSeq, tt.limit)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectSeqs, messageSeqs(messages))
+			assert.Equal(t, tt.expectHasMoreBefore, hasMoreBefore)
+			assert.Equal(t, tt.expectHasMoreAfter, hasMoreAfter)
+		})
+	}
+}
+
+func TestService_GetConversation(t *testing.T) {
+	repo := newMockMessageRepository()
+	routerClient := &MockRouterClient{}
+	service := NewService(repo, routerClient)
+
+	// Create a conversation
+	convID, err := service.CreateConversation(
+		context.Background(),
+		types.ConversationTypeGroup,
+		"Test Group",
+		100,
+		[]int64{100, 200, 300},
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		convID  int64
+		userID  int64
+		wantErr error
+	}{
+		{
+			name:   "conversation found",
+			convID: convID,
+			userID: 200,
+		},
+		{
+			name:    "conversation not found",
+			convID:  999,
+			userID:  100,
+			wantErr: ErrConversationNotFound,
+		},
+		{
+			name:    "non-member cannot view conversation",
+			convID:  convID,
+			userID:  999,
+			wantErr: ErrNotMember,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, members, err := service.GetConversation(context.Background(), tt.convID, tt.userID)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, members)
 			} else {
-				assert.NoError(t, err)
+				require.NoError(t, err)
+				assert.NotNil(t, conv)
+				assert.Equal(t, convID, conv.ID)
+				assert.Equal(t, types.ConversationTypeGroup, conv.Type)
+				assert.Equal(t, "Test Group", conv.Title)
+				assert.Len(t, members, 3)
+			}
+		})
+	}
+}
+
+// recordingRouterClient records pushed events
+type recordingRouterClient struct {
+	MockRouterClient
+	mu     sync.Mutex
+	events []pushedEvent
+}
+
+type pushedEvent struct {
+	recipientIDs []int64
+	payload      map[string]interface{}
+}
+
+func (r *recordingRouterClient) PushEvent(ctx context.Context, recipientIDs []int64, event *gatewaypb.GatewayMessage) (int32, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, pushedEvent{recipientIDs: recipientIDs, payload: event.Payload.AsMap()})
+	return int32(len(recipientIDs)), nil
+}
+
+func (r *recordingRouterClient) snapshot() []pushedEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]pushedEvent(nil), r.events...)
+}
+
+func TestService_UpdateReadSeq(t *testing.T) {
+	tests := []struct {
+		name         string
+		convID       int64
+		userID       int64
+		seq          int64
+		mode         GroupReadReceiptMode
+		maxMembers   int
+		wantErr      error
+		expectEvents []pushedEvent
+	}{
+		{
+			name:   "direct read reaches own devices and peer",
+			convID: 1,
+			userID: 100,
+			seq:    5,
+			expectEvents: []pushedEvent{
+				{recipientIDs: []int64{100, 200}, payload: map[string]interface{}{"type": ReadEventRead, "conv_id": float64(1), "user_id": float64(100), "seq": float64(5)}},
+			},
+		},
+		{
+			name:   "group read with aggregate advance",
+			convID: 2,
+			userID: 100,
+			seq:    5,
+			expectEvents: []pushedEvent{
+				{recipientIDs: []int64{100}, payload: map[string]interface{}{"type": ReadEventRead, "conv_id": float64(2), "user_id": float64(100), "seq": float64(5)}},
+				{recipientIDs: []int64{100, 200, 400}, payload: map[string]interface{}{"type": ReadEventAggregate, "conv_id": float64(2), "read_seq": float64(3)}},
+			},
+		},
+		{
+			name:   "group aggregate disabled",
+			convID: 2,
+			userID: 100,
+			seq:    5,
+			mode:   GroupReadReceiptOff,
+			expectEvents: []pushedEvent{
+				{recipientIDs: []int64{100}, payload: map[string]interface{}{"type": ReadEventRead, "conv_id": float64(2), "user_id": float64(100), "seq": float64(5)}},
+			},
+		},
+		{
+			name:       "group larger than aggregate limit",
+			convID:     2,
+			userID:     100,
+			seq:        5,
+			maxMembers: 2,
+			expectEvents: []pushedEvent{
+				{recipientIDs: []int64{100}, payload: map[string]interface{}{"type": ReadEventRead, "conv_id": float64(2), "user_id": float64(100), "seq": float64(5)}},
+			},
+		},
+		{
+			name:   "reader not at the minimum does not move the aggregate",
+			convID: 2,
+			userID: 200,
+			seq:    8,
+			expectEvents: []pushedEvent{
+				{recipientIDs: []int64{200}, payload: map[string]interface{}{"type": ReadEventRead, "conv_id": float64(2), "user_id": float64(200), "seq": float64(8)}},
+			},
+		},
+		{
+			name:   "read position never moves backwards",
+			convID: 2,
+			userID: 200,
+			seq:    3,
+		},
+		{
+			name:   "channel reads only sync own devices",
+			convID: 3,
+			userID: 300,
+			seq:    5,
+			expectEvents: []pushedEvent{
+				{recipientIDs: []int64{300}, payload: map[string]interface{}{"type": ReadEventRead, "conv_id": float64(3), "user_id": float64(300), "seq": float64(5)}},
+			},
+		},
+		{
+			name:    "non-member",
+			convID:  1,
+			userID:  999,
+			seq:     5,
+			wantErr: ErrNotMember,
+		},
+		{
+			name:    "beyond the latest message",
+			convID:  1,
+			userID:  100,
+			seq:     1 << 62,
+			wantErr: ErrInvalidReadSeq,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockMessageRepository()
+			setupSendFixtures(t, repo)
+			for convID := int64(1); convID <= 3; convID++ {
+				repo.seqCounters[convID] = 10
+			}
+			// group 2: owner 100 has read up to 1, member 200 up to 4, viewer 400 up to 3
+			repo.members[2][0].LastReadSeq = 1
+			repo.members[2][1].LastReadSeq = 4
+			repo.members[2][2].LastReadSeq = 3
+			routerClient := &recordingRouterClient{}
+			service := NewService(repo, routerClient).WithGroupReadReceipts(tt.mode, tt.maxMembers)
+
+			err := service.UpdateReadSeq(context.Background(), tt.convID, tt.userID, tt.seq)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			if len(tt.expectEvents) == 0 {
+				time.Sleep(20 * time.Millisecond)
+				assert.Empty(t, routerClient.snapshot())
+				return
+			}
+			assert.Eventually(t, func() bool {
+				return len(routerClient.snapshot()) == len(tt.expectEvents)
+			}, time.Second, 5*time.Millisecond)
+			assert.Equal(t, tt.expectEvents, routerClient.snapshot())
+		})
+	}
+}
+
+// memberRoles returns the role of every member in a conversation
+func memberRoles(repo *MockMessageRepository, convID int64) map[int64]types.ConversationRole {
+	roles := make(map[int64]types.ConversationRole)
+	for _, member := range repo.members[convID] {
+		roles[member.UserID] = member.Role
+	}
+	return roles
+}
+
+func TestService_AddMembers(t *testing.T) {
+	tests := []struct {
+		name       string
+		convID     int64
+		operatorID int64
+		memberIDs  []int64
+		role       types.ConversationRole
+		setupMock  func(*MockMessageRepository)
+		wantAdded  []int64
+		wantErr    error
+	}{
+		{
+			name:       "owner adds members",
+			convID:     2,
+			operatorID: 100,
+			memberIDs:  []int64{500, 600},
+			wantAdded:  []int64{500, 600},
+		},
+		{
+			name:       "existing members are skipped",
+			convID:     2,
+			operatorID: 100,
+			memberIDs:  []int64{200, 500},
+			wantAdded:  []int64{500},
+		},
+		{
+			name:       "owner adds admin",
+			convID:     2,
+			operatorID: 100,
+			memberIDs:  []int64{500},
+			role:       types.ConversationRoleAdmin,
+			wantAdded:  []int64{500},
+		},
+		{
+			name:       "admin adds members",
+			convID:     2,
+			operatorID: 200,
+			memberIDs:  []int64{500},
+			setupMock: func(m *MockMessageRepository) {
+				m.members[2][1].Role = types.ConversationRoleAdmin
+			},
+			wantAdded: []int64{500},
+		},
+		{
+			name:       "admin cannot add admin",
+			convID:     2,
+			operatorID: 200,
+			memberIDs:  []int64{500},
+			role:       types.ConversationRoleAdmin,
+			setupMock: func(m *MockMessageRepository) {
+				m.members[2][1].Role = types.ConversationRoleAdmin
+			},
+			wantErr: ErrManageNotAllowed,
+		},
+		{
+			name:       "member cannot add members",
+			convID:     2,
+			operatorID: 200,
+			memberIDs:  []int64{500},
+			wantErr:    ErrManageNotAllowed,
+		},
+		{
+			name:       "cannot add owner",
+			convID:     2,
+			operatorID: 100,
+			memberIDs:  []int64{500},
+			role:       types.ConversationRoleOwner,
+			wantErr:    ErrInvalidRole,
+		},
+		{
+			name:       "direct conversation",
+			convID:     1,
+			operatorID: 100,
+			memberIDs:  []int64{500},
+			wantErr:    ErrDirectConversation,
+		},
+		{
+			name:       "operator is not a member",
+			convID:     2,
+			operatorID: 999,
+			memberIDs:  []int64{500},
+			wantErr:    ErrNotMember,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockMessageRepository()
+			setupSendFixtures(t, repo)
+			if tt.setupMock != nil {
+				tt.setupMock(repo)
+			}
+			service := NewService(repo, &MockRouterClient{})
+
+			added, seq, err := service.AddMembers(context.Background(), tt.convID, tt.operatorID, tt.memberIDs, tt.role)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAdded, added)
+			assert.Greater(t, seq, int64(0))
+
+			wantRole := tt.role
+			if wantRole == "" {
+				wantRole = types.ConversationRoleMember
+			}
+			roles := memberRoles(repo, tt.convID)
+			for _, userID := range tt.wantAdded {
+				assert.Equal(t, wantRole, roles[userID])
+			}
+
+			// the system message is appended to the timeline
+			msgs := repo.messages[tt.convID]
+			require.Len(t, msgs, 1)
+			assert.Equal(t, SystemEventMembersAdded, msgs[0].Body["event"])
+		})
+	}
+}
+
+func TestService_RemoveMembers(t *testing.T) {
+	tests := []struct {
+		name        string
+		operatorID  int64
+		memberIDs   []int64
+		setupMock   func(*MockMessageRepository)
+		wantRemoved []int64
+		wantErr     error
+	}{
+		{
+			name:        "owner removes member",
+			operatorID:  100,
+			memberIDs:   []int64{200},
+			wantRemoved: []int64{200},
+		},
+		{
+			name:        "non-members are ignored",
+			operatorID:  100,
+			memberIDs:   []int64{400, 999},
+			wantRemoved: []int64{400},
+		},
+		{
+			name:       "owner removes admin",
+			operatorID: 100,
+			memberIDs:  []int64{200},
+			setupMock: func(m *MockMessageRepository) {
+				m.members[2][1].Role = types.ConversationRoleAdmin
+			},
+			wantRemoved: []int64{200},
+		},
+		{
+			name:       "admin cannot remove admin",
+			operatorID: 200,
+			memberIDs:  []int64{400},
+			setupMock: func(m *MockMessageRepository) {
+				m.members[2][1].Role = types.ConversationRoleAdmin
+				m.members[2][2].Role = types.ConversationRoleAdmin
+			},
+			wantErr: ErrManageNotAllowed,
+		},
+		{
+			name:       "admin cannot remove owner",
+			operatorID: 200,
+			memberIDs:  []int64{100},
+			setupMock: func(m *MockMessageRepository) {
+				m.members[2][1].Role = types.ConversationRoleAdmin
+			},
+			wantErr: ErrCannotModifyOwner,
+		},
+		{
+			name:       "operator cannot remove self",
+			operatorID: 100,
+			memberIDs:  []int64{100},
+			wantErr:    ErrInvalidTarget,
+		},
+		{
+			name:       "member cannot remove members",
+			operatorID: 200,
+			memberIDs:  []int64{400},
+			wantErr:    ErrManageNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockMessageRepository()
+			setupSendFixtures(t, repo)
+			if tt.setupMock != nil {
+				tt.setupMock(repo)
+			}
+			service := NewService(repo, &MockRouterClient{})
+
+			removed, seq, err := service.RemoveMembers(context.Background(), 2, tt.operatorID, tt.memberIDs)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Len(t, repo.members[2], 3)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRemoved, removed)
+			assert.Greater(t, seq, int64(0))
+
+			roles := memberRoles(repo, 2)
+			for _, userID := range tt.wantRemoved {
+				assert.NotContains(t, roles, userID)
 			}
 		})
 	}
 }
+
+func TestService_MembershipChangeRollsBackWithoutEvent(t *testing.T) {
+	ctx := context.Background()
+	saveErr := errors.New("insert failed")
+
+	setup := func(t *testing.T) (*MockMessageRepository, *Service) {
+		repo := newMockMessageRepository()
+		setupSendFixtures(t, repo)
+		repo.saveMessageFunc = func(ctx context.Context, msg *Message) error {
+			return saveErr
+		}
+		return repo, NewService(repo, &MockRouterClient{})
+	}
+
+	t.Run("add members", func(t *testing.T) {
+		repo, service := setup(t)
+		_, _, err := service.AddMembers(ctx, 2, 100, []int64{500}, types.ConversationRoleMember)
+		assert.ErrorIs(t, err, saveErr)
+		assert.NotContains(t, memberRoles(repo, 2), int64(500))
+	})
+
+	t.Run("remove members", func(t *testing.T) {
+		repo, service := setup(t)
+		_, _, err := service.RemoveMembers(ctx, 2, 100, []int64{200})
+		assert.ErrorIs(t, err, saveErr)
+		assert.Contains(t, memberRoles(repo, 2), int64(200))
+	})
+
+	t.Run("leave conversation", func(t *testing.T) {
+		repo, service := setup(t)
+		_, err := service.LeaveConversation(ctx, 2, 200)
+		assert.ErrorIs(t, err, saveErr)
+		assert.Contains(t, memberRoles(repo, 2), int64(200))
+	})
+}
+
+func TestService_UpdateMemberRole(t *testing.T) {
+	tests := []struct {
+		name       string
+		operatorID int64
+		userID     int64
+		role       types.ConversationRole
+		setupMock  func(*MockMessageRepository)
+		wantErr    error
+	}{
+		{
+			name:       "owner promotes member to admin",
+			operatorID: 100,
+			userID:     200,
+			role:       types.ConversationRoleAdmin,
+		},
+		{
+			name:       "admin changes viewer to member",
+			operatorID: 200,
+			userID:     400,
+			role:       types.ConversationRoleMember,
+			setupMock: func(m *MockMessageRepository) {
+				m.members[2][1].Role = types.ConversationRoleAdmin
+			},
+		},
+		{
+			name:       "admin cannot demote admin",
+			operatorID: 200,
+			userID:     400,
+			role:       types.ConversationRoleMember,
+			setupMock: func(m *MockMessageRepository) {
+				m.members[2][1].Role = types.ConversationRoleAdmin
+				m.members[2][2].Role = types.ConversationRoleAdmin
+			},
+			wantErr: ErrManageNotAllowed,
+		},
+		{
+			name:       "cannot change owner role",
+			operatorID: 100,
+			userID:     100,
+			role:       types.ConversationRoleMember,
+			wantErr:    ErrCannotModifyOwner,
+		},
+		{
+			name:       "cannot assign owner role",
+			operatorID: 100,
+			userID:     200,
+			role:       types.ConversationRoleOwner,
+			wantErr:    ErrInvalidRole,
+		},
+		{
+			name:       "target is not a member",
+			operatorID: 100,
+			userID:     999,
+			role:       types.ConversationRoleAdmin,
+			wantErr:    ErrMemberNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockMessageRepository()
+			setupSendFixtures(t, repo)
+			if tt.setupMock != nil {
+				tt.setupMock(repo)
+			}
+			service := NewService(repo, &MockRouterClient{})
+
+			seq, err := service.UpdateMemberRole(context.Background(), 2, tt.operatorID, tt.userID, tt.role)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Greater(t, seq, int64(0))
+			assert.Equal(t, tt.role, memberRoles(repo, 2)[tt.userID])
+		})
+	}
+}
+
+func TestService_LeaveConversation(t *testing.T) {
+	tests := []struct {
+		name    string
+		convID  int64
+		userID  int64
+		wantErr error
+	}{
+		{
+			name:   "member leaves group",
+			convID: 2,
+			userID: 200,
+		},
+		{
+			name:    "owner cannot leave",
+			convID:  2,
+			userID:  100,
+			wantErr: ErrOwnerCannotLeave,
+		},
+		{
+			name:    "cannot leave direct conversation",
+			convID:  1,
+			userID:  200,
+			wantErr: ErrDirectConversation,
+		},
+		{
+			name:    "non-member",
+			convID:  2,
+			userID:  999,
+			wantErr: ErrNotMember,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockMessageRepository()
+			setupSendFixtures(t, repo)
+			service := NewService(repo, &MockRouterClient{})
+
+			seq, err := service.LeaveConversation(context.Background(), tt.convID, tt.userID)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Greater(t, seq, int64(0))
+			assert.NotContains(t, memberRoles(repo, tt.convID), tt.userID)
+		})
+	}
+}
+
+func TestService_TransferOwnership(t *testing.T) {
+	tests := []struct {
+		name       string
+		ownerID    int64
+		newOwnerID int64
+		wantErr    error
+	}{
+		{
+			name:       "owner transfers to member",
+			ownerID:    100,
+			newOwnerID: 200,
+		},
+		{
+			name:       "non-owner cannot transfer",
+			ownerID:    200,
+			newOwnerID: 400,
+			wantErr:    ErrManageNotAllowed,
+		},
+		{
+			name:       "cannot transfer to self",
+			ownerID:    100,
+			newOwnerID: 100,
+			wantErr:    ErrInvalidTarget,
+		},
+		{
+			name:       "new owner is not a member",
+			ownerID:    100,
+			newOwnerID: 999,
+			wantErr:    ErrMemberNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockMessageRepository()
+			setupSendFixtures(t, repo)
+			service := NewService(repo, &MockRouterClient{})
+
+			seq, err := service.TransferOwnership(context.Background(), 2, tt.ownerID, tt.newOwnerID)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Greater(t, seq, int64(0))
+
+			roles := memberRoles(repo, 2)
+			assert.Equal(t, types.ConversationRoleOwner, roles[tt.newOwnerID])
+			assert.Equal(t, types.ConversationRoleAdmin, roles[tt.ownerID])
+			assert.Equal(t, tt.newOwnerID, repo.conversations[2].OwnerID)
+		})
+	}
+}
+
+func TestService_ListConversations(t *testing.T) {
+	repo := newMockMessageRepository()
+	setupSendFixtures(t, repo)
+	service := NewService(repo, &MockRouterClient{})
+	ctx := context.Background()
+
+	base := time.Now().Truncate(time.Microsecond)
+	for _, conv := range repo.conversations {
+		conv.CreatedAt = base.Add(-time.Hour)
+	}
+
+	// Activity order: conv 1 (newest message), conv 3, conv 2 (no messages)
+	repo.seqCounters[3] = 4
+	repo.messages[3] = []*Message{{MsgID: "m3", ConvID: 3, Seq: 4, SenderID: 300, CreatedAt: base.Add(-time.Minute)}}
+	// Own, system and recalled messages are not unread
+	repo.seqCounters[1] = 10
+	repo.messages[1] = []*Message{
+		{MsgID: "m0", ConvID: 1, Seq: 6, SenderID: 200, CreatedAt: base.Add(-4 * time.Second)},
+		{MsgID: "own", ConvID: 1, Seq: 7, SenderID: 100, CreatedAt: base.Add(-3 * time.Second)},
+		{MsgID: "sys", ConvID: 1, Seq: 8, SenderID: 200, Body: map[string]interface{}{"type": "system"}, CreatedAt: base.Add(-2 * time.Second)},
+		{MsgID: "gone", ConvID: 1, Seq: 9, SenderID: 200, Visibility: VisibilityRecalled, CreatedAt: base.Add(-time.Second)},
+		{MsgID: "m1", ConvID: 1, Seq: 10, SenderID: 200, CreatedAt: base},
+	}
+	_, err := repo.UpdateReadSeq(ctx, 1, 100, 5)
+	require.NoError(t, err)
+
+	t.Run("ordered by last activity", func(t *testing.T) {
+		summaries, nextCursor, hasMore, err := service.ListConversations(ctx, 100, "", 10)
+		require.NoError(t, err)
+		assert.False(t, hasMore)
+		assert.Empty(t, nextCursor)
+		require.Len(t, summaries, 3)
+
+		assert.Equal(t, int64(1), summaries[0].ID)
+		assert.Equal(t, int64(3), summaries[1].ID)
+		assert.Equal(t, int64(2), summaries[2].ID)
+
+		assert.Equal(t, "m1", summaries[0].LastMessage.MsgID)
+		assert.Equal(t, int64(2), summaries[0].UnreadCount)
+		assert.Equal(t, int64(1), summaries[1].UnreadCount)
+		assert.Nil(t, summaries[2].LastMessage)
+		assert.Equal(t, int64(0), summaries[2].UnreadCount)
+	})
+
+	t.Run("paginates with cursor", func(t *testing.T) {
+		first, cursor, hasMore, err := service.ListConversations(ctx, 100, "", 2)
+		require.NoError(t, err)
+		assert.True(t, hasMore)
+		require.NotEmpty(t, cursor)
+		require.Len(t, first, 2)
+
+		second, cursor, hasMore, err := service.ListConversations(ctx, 100, cursor, 2)
+		require.NoError(t, err)
+		assert.False(t, hasMore)
+		assert.Empty(t, cursor)
+		require.Len(t, second, 1)
+		assert.Equal(t, int64(2), second[0].ID)
+	})
+
+	t.Run("only lists own conversations", func(t *testing.T) {
+		summaries, _, _, err := service.ListConversations(ctx, 300, "", 10)
+		require.NoError(t, err)
+		require.Len(t, summaries, 1)
+		assert.Equal(t, int64(3), summaries[0].ID)
+		assert.Equal(t, types.ConversationRolePublisher, summaries[0].Role)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		_, _, _, err := service.ListConversations(ctx, 100, "not-a-cursor", 10)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+}
+
+func TestService_FilterPeers(t *testing.T) {
+	repo := newMockMessageRepository()
+	setupSendFixtures(t, repo)
+	service := NewService(repo, &MockRouterClient{})
+	ctx := context.Background()
+
+	// User 300 only shares the channel with 100 and 200
+	peers, err := service.FilterPeers(ctx, 300, []int64{100, 200, 400, 999})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int64{100, 200}, peers)
+
+	peers, err = service.FilterPeers(ctx, 400, []int64{300})
+	require.NoError(t, err)
+	assert.Empty(t, peers)
+
+	peers, err = service.FilterPeers(ctx, 100, nil)
+	require.NoError(t, err)
+	assert.Empty(t, peers)
+}
+
+func TestConversationCursor_RoundTrip(t *testing.T) {
+	cursor := &ConversationCursor{
+		LastActiveAt: time.Now().Truncate(time.Microsecond),
+		ConvID:       42,
+	}
+
+	decoded, err := decodeConversationCursor(encodeConversationCursor(cursor))
+	require.NoError(t, err)
+	assert.True(t, cursor.LastActiveAt.Equal(decoded.LastActiveAt))
+	assert.Equal(t, cursor.ConvID, decoded.ConvID)
+}
@@ -0,0 +1,195 @@
+package router
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/dollarkillerx/im-system/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// KickReasonKicked is sent to a device that was kicked by the user
+	KickReasonKicked = "kicked"
+	// KickReasonDeviceLimit is sent to a device evicted by the device policy
+	KickReasonDeviceLimit = "device_limit"
+	// PlatformUnknown is the platform class of devices that did not declare one
+	PlatformUnknown = "unknown"
+)
+
+// DevicePolicy limits how many devices a user may have connected at once.
+// When a new device connects beyond a limit, the devices that connected
+// earliest are kicked.
+type DevicePolicy struct {
+	// MaxDevices caps the devices of a user across platforms; 0 means unlimited
+	MaxDevices int
+	// PlatformLimits caps the devices per platform class, e.g. one mobile and
+	// one desktop; platforms without a positive limit are only bound by MaxDevices.
+	// Devices that did not declare a platform count as PlatformUnknown
+	PlatformLimits map[string]int
+}
+
+// evictions returns the routes that exceed the policy. The newest connection
+// always wins, so concurrent registrations agree on which devices to keep.
+func (p DevicePolicy) evictions(routes []*DeviceRoute) []*DeviceRoute {
+	if p.MaxDevices <= 0 && len(p.PlatformLimits) == 0 {
+		return nil
+	}
+
+	sorted := make([]*DeviceRoute, len(routes))
+	copy(sorted, routes)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].ConnectedAt != sorted[j].ConnectedAt {
+			return sorted[i].ConnectedAt > sorted[j].ConnectedAt
+		}
+		return sorted[i].DeviceID > sorted[j].DeviceID
+	})
+
+	var evicted []*DeviceRoute
+	kept := 0
+	perPlatform := make(map[string]int)
+	for _, route := range sorted {
+		platform := normalizePlatform(route.Platform)
+		limit := p.PlatformLimits[platform]
+		if (p.MaxDevices > 0 && kept >= p.MaxDevices) ||
+			(limit > 0 && perPlatform[platform] >= limit) {
+			evicted = append(evicted, route)
+			continue
+		}
+		kept++
+		perPlatform[platform]++
+	}
+
+	return evicted
+}
+
+// WithDevicePolicy sets the multi-device login policy
+func (s *Service) WithDevicePolicy(policy DevicePolicy) *Service {
+	s.devicePolicy = policy
+	return s
+}
+
+// WithTokenRevoker sets the revoker used to invalidate the tokens of kicked devices
+func (s *Service) WithTokenRevoker(revoker TokenRevoker) *Service {
+	s.revoker = revoker
+	return s
+}
+
+// KickDevice disconnects a device, revokes its token and removes its route.
+// The token is revoked even if the device is not connected; the result
+// reports whether a connection was kicked.
+func (s *Service) KickDevice(ctx context.Context, userID int64, deviceID, reason string) (bool, error) {
+	if reason == "" {
+		reason = KickReasonKicked
+	}
+
+	if s.revoker != nil {
+		if err := s.revoker.RevokeDevice(ctx, userID, deviceID); err != nil {
+			return false, err
+		}
+	}
+
+	routes, err := s.GetRoute(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, route := range routes {
+		if route.DeviceID == deviceID {
+			s.disconnectDevices(ctx, userID, []*DeviceRoute{route}, reason)
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// enforceDevicePolicy kicks the devices of a user that exceed the device
+// policy and returns them
+func (s *Service) enforceDevicePolicy(ctx context.Context, userID int64) ([]*DeviceRoute, error) {
+	if s.devicePolicy.MaxDevices <= 0 && len(s.devicePolicy.PlatformLimits) == 0 {
+		return nil, nil
+	}
+
+	routes, err := s.GetRoute(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	evicted := s.devicePolicy.evictions(routes)
+	if len(evicted) == 0 {
+		return nil, nil
+	}
+
+	// Revoke first so an evicted device cannot reconnect and evict the new one
+	if s.revoker != nil {
+		for _, route := range evicted {
+			if err := s.revoker.RevokeDevice(ctx, userID, route.DeviceID); err != nil {
+				logger.Log.Warn("Failed to revoke evicted device",
+					zap.Int64("user_id", userID),
+					zap.String("device_id", route.DeviceID),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+
+	s.disconnectDevices(ctx, userID, evicted, KickReasonDeviceLimit)
+	return evicted, nil
+}
+
+// disconnectDevices asks each device's gateway to send KICKED and close the
+// stream, then removes the routes. The gateways are told first so a closing
+// connection does not re-register its route.
+func (s *Service) disconnectDevices(ctx context.Context, userID int64, routes []*DeviceRoute, reason string) {
+	byGateway := make(map[string][]string)
+	for _, route := range routes {
+		byGateway[route.GatewayAddr] = append(byGateway[route.GatewayAddr], route.DeviceID)
+	}
+
+	for gatewayAddr, deviceIDs := range byGateway {
+		if _, err := s.pusher.KickDevices(ctx, gatewayAddr, userID, deviceIDs, reason); err != nil {
+			logger.Log.Warn("Failed to kick devices on gateway",
+				zap.String("gateway_addr", gatewayAddr),
+				zap.Int64("user_id", userID),
+				zap.Strings("device_ids", deviceIDs),
+				zap.Error(err),
+			)
+		}
+	}
+
+	for _, route := range routes {
+		if err := s.UnregisterRoute(ctx, userID, route.DeviceID, route.GatewayAddr); err != nil {
+			logger.Log.Warn("Failed to remove kicked device route",
+				zap.Int64("user_id", userID),
+				zap.String("device_id", route.DeviceID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	logger.Log.Info("Devices kicked",
+		zap.Int64("user_id", userID),
+		zap.Int("device_count", len(routes)),
+		zap.String("reason", reason),
+	)
+}
+
+// normalizePlatform folds a client-supplied platform name to its policy key.
+// A missing platform maps to PlatformUnknown so it cannot bypass the limits.
+func normalizePlatform(platform string) string {
+	platform = strings.ToLower(strings.TrimSpace(platform))
+	if platform == "" {
+		return PlatformUnknown
+	}
+	return platform
+}
+
+func routeDeviceIDs(routes []*DeviceRoute) []string {
+	ids := make([]string, 0, len(routes))
+	for _, route := range routes {
+		ids = append(ids, route.DeviceID)
+	}
+	return ids
+}
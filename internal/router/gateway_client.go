@@ -0,0 +1,67 @@
+package router
+
+import (
+	"context"
+	"fmt"
+
+	gatewaypb "github.com/dollarkillerx/im-system/api/proto/gateway"
+	"github.com/dollarkillerx/im-system/pkg/interceptor"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// gatewayClient Gateway 推送客户端实现
+type gatewayClient struct {
+	internalToken string
+}
+
+// NewGatewayClient 创建 Gateway 推送客户端
+func NewGatewayClient(internalToken string) GatewayPusher {
+	return &gatewayClient{
+		internalToken: internalToken,
+	}
+}
+
+// PushToUsers 推送消息到指定 Gateway 实例上的用户连接
+func (c *gatewayClient) PushToUsers(ctx context.Context, gatewayAddr string, userIDs []int64, msg *gatewaypb.GatewayMessage) (int32, error) {
+	// 直连路由表中记录的 Gateway 地址
+	conn, err := grpc.Dial(gatewayAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to gateway %s: %w", gatewayAddr, err)
+	}
+	defer conn.Close()
+
+	client := gatewaypb.NewGatewayServiceClient(conn)
+
+	resp, err := client.PushToUsers(interceptor.WithInternalToken(ctx, c.internalToken), &gatewaypb.PushToUsersRequest{
+		UserIds: userIDs,
+		Message: msg,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.DeliveredCount, nil
+}
+
+// KickDevices 通知指定 Gateway 实例将用户的设备踢下线
+func (c *gatewayClient) KickDevices(ctx context.Context, gatewayAddr string, userID int64, deviceIDs []string, reason string) (int32, error) {
+	conn, err := grpc.Dial(gatewayAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to gateway %s: %w", gatewayAddr, err)
+	}
+	defer conn.Close()
+
+	client := gatewaypb.NewGatewayServiceClient(conn)
+
+	resp, err := client.KickDevices(interceptor.WithInternalToken(ctx, c.internalToken), &gatewaypb.KickDevicesRequest{
+		UserId:    userID,
+		DeviceIds: deviceIDs,
+		Reason:    reason,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.KickedCount, nil
+}
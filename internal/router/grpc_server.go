@@ -2,6 +2,8 @@ package router
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	routerpb "github.com/dollarkillerx/im-system/api/proto/router"
 	"google.golang.org/grpc/codes"
@@ -18,14 +20,15 @@ func NewGRPCServer(service *Service) *GRPCServer {
 }
 
 func (s *GRPCServer) RegisterRoute(ctx context.Context, req *routerpb.RegisterRouteRequest) (*routerpb.RegisterRouteResponse, error) {
-	err := s.service.RegisterRoute(ctx, req.UserId, req.DeviceId, req.GatewayAddr)
+	evicted, err := s.service.RegisterDeviceRoute(ctx, req.UserId, req.DeviceId, req.Platform, req.GatewayAddr)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to register route: %v", err)
 	}
 
 	return &routerpb.RegisterRouteResponse{
-		Success: true,
-		Message: "Route registered successfully",
+		Success:          true,
+		Message:          "Route registered successfully",
+		EvictedDeviceIds: routeDeviceIDs(evicted),
 	}, nil
 }
 
@@ -46,18 +49,14 @@ func (s *GRPCServer) GetRoute(ctx context.Context, req *routerpb.GetRouteRequest
 
 	var pbRoutes []*routerpb.DeviceRoute
 	for _, route := range routes {
-		pbRoutes = append(pbRoutes, &routerpb.DeviceRoute{
-			DeviceId:    route.DeviceID,
-			GatewayAddr: route.GatewayAddr,
-			LastActive:  route.LastActive,
-		})
+		pbRoutes = append(pbRoutes, toPBRoute(route))
 	}
 
 	return &routerpb.GetRouteResponse{Routes: pbRoutes}, nil
 }
 
 func (s *GRPCServer) UnregisterRoute(ctx context.Context, req *routerpb.UnregisterRouteRequest) (*routerpb.UnregisterRouteResponse, error) {
-	err := s.service.UnregisterRoute(ctx, req.UserId, req.DeviceId)
+	err := s.service.UnregisterRoute(ctx, req.UserId, req.DeviceId, req.GatewayAddr)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to unregister route: %v", err)
 	}
@@ -66,13 +65,164 @@ func (s *GRPCServer) UnregisterRoute(ctx context.Context, req *routerpb.Unregist
 }
 
 func (s *GRPCServer) GetOnlineStatus(ctx context.Context, req *routerpb.GetOnlineStatusRequest) (*routerpb.GetOnlineStatusResponse, error) {
-	online, deviceIDs, err := s.service.GetOnlineStatus(ctx, req.UserId)
+	p, err := s.service.GetPresence(ctx, req.ViewerId, req.UserId)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to get online status: %v", err)
 	}
 
 	return &routerpb.GetOnlineStatusResponse{
-		Online:    online,
-		DeviceIds: deviceIDs,
+		Online:                p.Online,
+		DeviceIds:             p.DeviceIDs,
+		Status:                p.Status,
+		CustomStatus:          p.CustomStatus,
+		CustomStatusExpiresAt: p.CustomStatusExpiresAt,
+		LastSeen:              p.LastSeen,
+	}, nil
+}
+
+func (s *GRPCServer) SetPresence(ctx context.Context, req *routerpb.SetPresenceRequest) (*routerpb.SetPresenceResponse, error) {
+	p, err := s.service.SetPresence(ctx, req.UserId, req.Status, req.CustomStatus, time.Duration(req.CustomStatusTtl)*time.Second)
+	if errors.Is(err, ErrInvalidPresenceStatus) || errors.Is(err, ErrCustomStatusTooLong) {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to set presence: %v", err)
+	}
+
+	return &routerpb.SetPresenceResponse{Presence: toPBPresence(p)}, nil
+}
+
+func (s *GRPCServer) BatchGetRoutes(ctx context.Context, req *routerpb.BatchGetRoutesRequest) (*routerpb.BatchGetRoutesResponse, error) {
+	userRoutes, err := s.service.BatchGetRoutes(ctx, req.UserIds)
+	if errors.Is(err, ErrTooManyUsers) {
+		return nil, status.Errorf(codes.InvalidArgument, "%v: at most %d users per request", err, MaxBatchLookupUsers)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get routes: %v", err)
+	}
+
+	results := make([]*routerpb.UserRoutes, 0, len(userRoutes))
+	for _, ur := range userRoutes {
+		pbRoutes := make([]*routerpb.DeviceRoute, 0, len(ur.Routes))
+		for _, route := range ur.Routes {
+			pbRoutes = append(pbRoutes, toPBRoute(route))
+		}
+		results = append(results, &routerpb.UserRoutes{
+			UserId: ur.UserID,
+			Routes: pbRoutes,
+		})
+	}
+
+	return &routerpb.BatchGetRoutesResponse{Results: results}, nil
+}
+
+func (s *GRPCServer) BatchGetOnlineStatus(ctx context.Context, req *routerpb.BatchGetOnlineStatusRequest) (*routerpb.BatchGetOnlineStatusResponse, error) {
+	presences, err := s.service.BatchGetPresence(ctx, req.ViewerId, req.UserIds)
+	if errors.Is(err, ErrTooManyUsers) {
+		return nil, status.Errorf(codes.InvalidArgument, "%v: at most %d users per request", err, MaxBatchLookupUsers)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get online status: %v", err)
+	}
+
+	results := make([]*routerpb.UserOnlineStatus, 0, len(presences))
+	for _, p := range presences {
+		results = append(results, &routerpb.UserOnlineStatus{
+			UserId:                p.UserID,
+			Online:                p.Online,
+			DeviceIds:             p.DeviceIDs,
+			Status:                p.Status,
+			CustomStatus:          p.CustomStatus,
+			CustomStatusExpiresAt: p.CustomStatusExpiresAt,
+			LastSeen:              p.LastSeen,
+		})
+	}
+
+	return &routerpb.BatchGetOnlineStatusResponse{Results: results}, nil
+}
+
+func (s *GRPCServer) PushMessage(ctx context.Context, req *routerpb.PushMessageRequest) (*routerpb.PushMessageResponse, error) {
+	onlineCount, deliveredCount, err := s.service.PushMessage(ctx, req.ConvId, req.MsgId, req.Seq, req.SenderId, req.RecipientIds)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to push message: %v", err)
+	}
+
+	return &routerpb.PushMessageResponse{
+		OnlineCount:    onlineCount,
+		DeliveredCount: deliveredCount,
 	}, nil
 }
+
+func (s *GRPCServer) PushEvent(ctx context.Context, req *routerpb.PushEventRequest) (*routerpb.PushEventResponse, error) {
+	onlineCount, deliveredCount, err := s.service.PushEvent(ctx, req.RecipientIds, req.Event)
+	if errors.Is(err, ErrEmptyEvent) {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to push event: %v", err)
+	}
+
+	return &routerpb.PushEventResponse{
+		OnlineCount:    onlineCount,
+		DeliveredCount: deliveredCount,
+	}, nil
+}
+
+func (s *GRPCServer) SubscribePresence(ctx context.Context, req *routerpb.SubscribePresenceRequest) (*routerpb.SubscribePresenceResponse, error) {
+	presences, err := s.service.SubscribePresence(ctx, req.SubscriberId, req.UserIds)
+	if errors.Is(err, ErrTooManyPresenceTargets) {
+		return nil, status.Errorf(codes.InvalidArgument, "%v: at most %d users per request", err, MaxPresenceTargets)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to subscribe presence: %v", err)
+	}
+
+	pbStatuses := make([]*routerpb.PresenceStatus, 0, len(presences))
+	for _, p := range presences {
+		pbStatuses = append(pbStatuses, toPBPresence(p))
+	}
+
+	return &routerpb.SubscribePresenceResponse{Statuses: pbStatuses}, nil
+}
+
+func (s *GRPCServer) UnsubscribePresence(ctx context.Context, req *routerpb.UnsubscribePresenceRequest) (*routerpb.UnsubscribePresenceResponse, error) {
+	if err := s.service.UnsubscribePresence(ctx, req.SubscriberId, req.UserIds); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to unsubscribe presence: %v", err)
+	}
+
+	return &routerpb.UnsubscribePresenceResponse{Success: true}, nil
+}
+
+func (s *GRPCServer) KickDevice(ctx context.Context, req *routerpb.KickDeviceRequest) (*routerpb.KickDeviceResponse, error) {
+	if req.DeviceId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "device_id is required")
+	}
+
+	kicked, err := s.service.KickDevice(ctx, req.UserId, req.DeviceId, req.Reason)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to kick device: %v", err)
+	}
+
+	return &routerpb.KickDeviceResponse{Kicked: kicked}, nil
+}
+
+func toPBRoute(route *DeviceRoute) *routerpb.DeviceRoute {
+	return &routerpb.DeviceRoute{
+		DeviceId:    route.DeviceID,
+		GatewayAddr: route.GatewayAddr,
+		LastActive:  route.LastActive,
+		Platform:    route.Platform,
+		ConnectedAt: route.ConnectedAt / 1000,
+	}
+}
+
+func toPBPresence(p *Presence) *routerpb.PresenceStatus {
+	return &routerpb.PresenceStatus{
+		UserId:                p.UserID,
+		Online:                p.Online,
+		Status:                p.Status,
+		CustomStatus:          p.CustomStatus,
+		CustomStatusExpiresAt: p.CustomStatusExpiresAt,
+		LastSeen:              p.LastSeen,
+	}
+}
@@ -2,6 +2,8 @@ package router
 
 import (
 	"context"
+
+	gatewaypb "github.com/dollarkillerx/im-system/api/proto/gateway"
 )
 
 // RouteStorage defines the interface for managing user device routes
@@ -9,8 +11,8 @@ type RouteStorage interface {
 	// RegisterRoute registers a device route for a user
 	RegisterRoute(ctx context.Context, userID int64, deviceID, gatewayAddr string) error
 
-	// UnregisterRoute removes a device route for a user
-	UnregisterRoute(ctx context.Context, userID int64, deviceID string) error
+	// UnregisterRoute removes a device route for a user if it still belongs to gatewayAddr
+	UnregisterRoute(ctx context.Context, userID int64, deviceID, gatewayAddr string) error
 
 	// GetRoute retrieves all device routes for a user
 	GetRoute(ctx context.Context, userID int64) ([]*DeviceRoute, error)
@@ -21,3 +23,18 @@ type RouteStorage interface {
 	// GetOnlineStatus checks if a user is online and returns online device IDs
 	GetOnlineStatus(ctx context.Context, userID int64) (bool, []string, error)
 }
+
+// GatewayPusher defines the interface for pushing messages to a specific gateway instance
+type GatewayPusher interface {
+	// PushToUsers pushes a message to all connections of the given users on the gateway at gatewayAddr
+	PushToUsers(ctx context.Context, gatewayAddr string, userIDs []int64, msg *gatewaypb.GatewayMessage) (int32, error)
+
+	// KickDevices sends KICKED to the given devices of a user on the gateway at gatewayAddr and closes their streams
+	KickDevices(ctx context.Context, gatewayAddr string, userID int64, deviceIDs []string, reason string) (int32, error)
+}
+
+// TokenRevoker invalidates the tokens issued to a device
+type TokenRevoker interface {
+	// RevokeDevice revokes every token issued to the device so far
+	RevokeDevice(ctx context.Context, userID int64, deviceID string) error
+}
@@ -0,0 +1,549 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	gatewaypb "github.com/dollarkillerx/im-system/api/proto/gateway"
+	"github.com/dollarkillerx/im-system/pkg/logger"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// presenceActiveKey is a sorted set of online users scored by their last
+	// activity, used to detect routes that expired without an unregister
+	presenceActiveKey = "presence_active"
+	// presencePendingKey is a sorted set of users that went offline scored by
+	// the time they were last seen; the offline event is only published once
+	// the debounce window has passed without a reconnect
+	presencePendingKey = "presence_pending_offline"
+
+	presenceSubscribersPrefix = "presence_subscribers:" // target -> subscriber IDs
+	presenceWatchingPrefix    = "presence_watching:"    // subscriber -> target IDs
+	// presenceStatePrefix holds the manual status, custom status and last
+	// seen time of a user; it outlives the user's routes
+	presenceStatePrefix = "presence_state:"
+
+	// presenceSubscriptionTTL bounds how long subscription sets survive if the
+	// subscriber's offline cleanup never runs
+	presenceSubscriptionTTL = 24 * time.Hour
+
+	// DefaultPresenceDebounce is how long a user must stay offline before
+	// subscribers are told, so that quick reconnects do not flap
+	DefaultPresenceDebounce = 5 * time.Second
+	// DefaultPresenceSweepInterval is how often expired routes and pending
+	// offline events are processed
+	DefaultPresenceSweepInterval = time.Second
+
+	// MaxPresenceTargets caps the number of users in one subscribe call
+	MaxPresenceTargets = 500
+	// MaxCustomStatusLength caps the custom status text in characters
+	MaxCustomStatusLength = 100
+
+	PresenceOnline  = "online"
+	PresenceOffline = "offline"
+	// PresenceAway, PresenceBusy and PresenceInvisible are manual statuses
+	// that only apply while the user has at least one route
+	PresenceAway      = "away"
+	PresenceBusy      = "busy"
+	PresenceInvisible = "invisible"
+)
+
+var (
+	// ErrTooManyPresenceTargets is returned when a subscription request exceeds MaxPresenceTargets
+	ErrTooManyPresenceTargets = errors.New("too many presence targets")
+	// ErrInvalidPresenceStatus is returned when SetPresence receives an unknown status
+	ErrInvalidPresenceStatus = errors.New("invalid presence status")
+	// ErrCustomStatusTooLong is returned when the custom status exceeds MaxCustomStatusLength
+	ErrCustomStatusTooLong = errors.New("custom status too long")
+)
+
+// Presence is a user's presence as seen by a particular viewer. Invisible
+// users appear offline to everyone but themselves.
+type Presence struct {
+	UserID                int64
+	Status                string
+	Online                bool
+	DeviceIDs             []string
+	CustomStatus          string
+	CustomStatusExpiresAt int64
+	LastSeen              int64
+}
+
+// presenceState is the stored part of a user's presence
+type presenceState struct {
+	status                string
+	customStatus          string
+	customStatusExpiresAt int64
+	lastSeen              int64
+}
+
+// WithPresenceDebounce overrides the offline debounce window; d <= 0 keeps the default
+func (s *Service) WithPresenceDebounce(d time.Duration) *Service {
+	if d > 0 {
+		s.presenceDebounce = d
+	}
+	return s
+}
+
+// SubscribePresence subscribes subscriberID to presence changes of targetIDs
+// and returns their current status. Subscriptions are dropped when the
+// subscriber goes offline, so clients re-subscribe after reconnecting.
+func (s *Service) SubscribePresence(ctx context.Context, subscriberID int64, targetIDs []int64) ([]*Presence, error) {
+	if len(targetIDs) > MaxPresenceTargets {
+		return nil, ErrTooManyPresenceTargets
+	}
+	if len(targetIDs) == 0 {
+		return nil, nil
+	}
+
+	watchingKey := presenceWatchingKey(subscriberID)
+	pipe := s.redis.TxPipeline()
+	for _, targetID := range targetIDs {
+		subscribersKey := presenceSubscribersKey(targetID)
+		pipe.SAdd(ctx, subscribersKey, subscriberID)
+		pipe.Expire(ctx, subscribersKey, presenceSubscriptionTTL)
+		pipe.SAdd(ctx, watchingKey, targetID)
+	}
+	pipe.Expire(ctx, watchingKey, presenceSubscriptionTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to store presence subscriptions: %w", err)
+	}
+
+	return s.BatchGetPresence(ctx, subscriberID, targetIDs)
+}
+
+// UnsubscribePresence removes subscriberID's subscriptions to targetIDs
+func (s *Service) UnsubscribePresence(ctx context.Context, subscriberID int64, targetIDs []int64) error {
+	if len(targetIDs) == 0 {
+		return nil
+	}
+
+	watchingKey := presenceWatchingKey(subscriberID)
+	pipe := s.redis.TxPipeline()
+	for _, targetID := range targetIDs {
+		pipe.SRem(ctx, presenceSubscribersKey(targetID), subscriberID)
+		pipe.SRem(ctx, watchingKey, targetID)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to remove presence subscriptions: %w", err)
+	}
+
+	return nil
+}
+
+// SetPresence sets userID's manual status and custom status text. A zero
+// customStatusTTL keeps the custom status until it is changed. Subscribers
+// are notified when the status visible to them changes; switching to
+// invisible looks like going offline.
+func (s *Service) SetPresence(ctx context.Context, userID int64, status, customStatus string, customStatusTTL time.Duration) (*Presence, error) {
+	switch status {
+	case PresenceOnline, PresenceAway, PresenceBusy, PresenceInvisible:
+	default:
+		return nil, ErrInvalidPresenceStatus
+	}
+	if utf8.RuneCountInString(customStatus) > MaxCustomStatusLength {
+		return nil, ErrCustomStatusTooLong
+	}
+
+	before, err := s.GetPresence(ctx, 0, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var expiresAt int64
+	if customStatus != "" && customStatusTTL > 0 {
+		expiresAt = time.Now().Add(customStatusTTL).Unix()
+	}
+
+	fields := map[string]interface{}{
+		"status":                   status,
+		"custom_status":            customStatus,
+		"custom_status_expires_at": expiresAt,
+	}
+	// Others see an invisible user as offline from now on
+	if status == PresenceInvisible && before.Online {
+		fields["last_seen"] = time.Now().Unix()
+	}
+
+	if err := s.redis.HSet(ctx, presenceStateKey(userID), fields).Err(); err != nil {
+		return nil, fmt.Errorf("failed to set presence: %w", err)
+	}
+
+	after, err := s.GetPresence(ctx, 0, userID)
+	if err != nil {
+		return nil, err
+	}
+	if after.Status != before.Status ||
+		after.CustomStatus != before.CustomStatus ||
+		after.CustomStatusExpiresAt != before.CustomStatusExpiresAt {
+		s.publishPresence(ctx, after)
+	}
+
+	return s.GetPresence(ctx, userID, userID)
+}
+
+// GetPresence returns userID's presence as seen by viewerID
+func (s *Service) GetPresence(ctx context.Context, viewerID, userID int64) (*Presence, error) {
+	presences, err := s.BatchGetPresence(ctx, viewerID, []int64{userID})
+	if err != nil {
+		return nil, err
+	}
+	return presences[0], nil
+}
+
+// BatchGetPresence returns the presence of many users as seen by viewerID
+// with pipelined Redis calls. Results follow the order of userIDs.
+func (s *Service) BatchGetPresence(ctx context.Context, viewerID int64, userIDs []int64) ([]*Presence, error) {
+	onlineStatuses, err := s.BatchGetOnlineStatus(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	states, err := s.batchGetPresenceState(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	presences := make([]*Presence, 0, len(onlineStatuses))
+	for i, st := range onlineStatuses {
+		state := states[i]
+		p := &Presence{
+			UserID: st.UserID,
+			Status: PresenceOffline,
+		}
+
+		if state.customStatus != "" && (state.customStatusExpiresAt == 0 || state.customStatusExpiresAt > now) {
+			p.CustomStatus = state.customStatus
+			p.CustomStatusExpiresAt = state.customStatusExpiresAt
+		}
+
+		visible := st.Online && (state.status != PresenceInvisible || viewerID == st.UserID)
+		if visible {
+			p.Online = true
+			p.DeviceIDs = st.DeviceIDs
+			p.Status = state.status
+		} else {
+			p.LastSeen = state.lastSeen
+		}
+
+		presences = append(presences, p)
+	}
+
+	return presences, nil
+}
+
+// SweepPresence expires users whose routes timed out without an unregister
+// and publishes offline events whose debounce window has passed
+func (s *Service) SweepPresence(ctx context.Context, now time.Time) error {
+	// Routes that expired through TTL never reach UnregisterRoute
+	expired, err := s.redis.ZRangeByScoreWithScores(ctx, presenceActiveKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Add(-defaultTTL).Unix(), 10),
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("failed to scan active users: %w", err)
+	}
+
+	for _, z := range expired {
+		userID, err := strconv.ParseInt(z.Member.(string), 10, 64)
+		if err != nil {
+			s.redis.ZRem(ctx, presenceActiveKey, z.Member)
+			continue
+		}
+
+		online, _, err := s.GetOnlineStatus(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if online {
+			continue
+		}
+
+		if err := s.markOffline(ctx, userID, int64(z.Score)); err != nil {
+			return err
+		}
+	}
+
+	due, err := s.redis.ZRangeByScoreWithScores(ctx, presencePendingKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Add(-s.presenceDebounce).Unix(), 10),
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("failed to scan pending offline users: %w", err)
+	}
+
+	for _, z := range due {
+		// Only the instance that removes the entry publishes the event
+		removed, err := s.redis.ZRem(ctx, presencePendingKey, z.Member).Result()
+		if err != nil {
+			return fmt.Errorf("failed to claim pending offline user: %w", err)
+		}
+		if removed == 0 {
+			continue
+		}
+
+		userID, err := strconv.ParseInt(z.Member.(string), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		online, _, err := s.GetOnlineStatus(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if online {
+			continue
+		}
+
+		// Invisible users already look offline to their subscribers
+		state, err := s.getPresenceState(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if state.status != PresenceInvisible {
+			p, err := s.GetPresence(ctx, 0, userID)
+			if err != nil {
+				return err
+			}
+			s.publishPresence(ctx, p)
+		}
+		s.clearPresenceSubscriptions(ctx, userID)
+	}
+
+	return nil
+}
+
+// RunPresenceSweeper calls SweepPresence every interval until ctx is done
+func (s *Service) RunPresenceSweeper(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultPresenceSweepInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.SweepPresence(ctx, now); err != nil {
+				logger.Log.Warn("Failed to sweep presence", zap.Error(err))
+			}
+		}
+	}
+}
+
+// touchPresence records activity for userID and publishes an online event if
+// the user was previously offline. A reconnect within the debounce window
+// cancels the pending offline event instead, so subscribers see nothing.
+func (s *Service) touchPresence(ctx context.Context, userID int64) error {
+	added, err := s.redis.ZAdd(ctx, presenceActiveKey, redis.Z{
+		Score:  float64(time.Now().Unix()),
+		Member: userID,
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("failed to update presence: %w", err)
+	}
+	if added == 0 {
+		return nil
+	}
+
+	removed, err := s.redis.ZRem(ctx, presencePendingKey, userID).Result()
+	if err != nil {
+		return fmt.Errorf("failed to cancel pending offline: %w", err)
+	}
+	if removed > 0 {
+		return nil
+	}
+
+	p, err := s.GetPresence(ctx, 0, userID)
+	if err != nil {
+		return err
+	}
+	// Invisible users stay offline to their subscribers
+	if p.Online {
+		s.publishPresence(ctx, p)
+	}
+	return nil
+}
+
+// markOffline schedules an offline event for userID after the debounce window
+func (s *Service) markOffline(ctx context.Context, userID int64, lastSeen int64) error {
+	removed, err := s.redis.ZRem(ctx, presenceActiveKey, userID).Result()
+	if err != nil {
+		return fmt.Errorf("failed to update presence: %w", err)
+	}
+	if removed == 0 {
+		return nil
+	}
+
+	// An invisible user's last seen time was frozen when they went invisible
+	status, err := s.redis.HGet(ctx, presenceStateKey(userID), "status").Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("failed to get presence state: %w", err)
+	}
+	if status != PresenceInvisible {
+		if err := s.redis.HSet(ctx, presenceStateKey(userID), "last_seen", lastSeen).Err(); err != nil {
+			return fmt.Errorf("failed to record last seen: %w", err)
+		}
+	}
+
+	err = s.redis.ZAdd(ctx, presencePendingKey, redis.Z{
+		Score:  float64(lastSeen),
+		Member: userID,
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to schedule offline presence: %w", err)
+	}
+
+	return nil
+}
+
+// publishPresence pushes a PRESENCE event with p to the online subscribers of p.UserID
+func (s *Service) publishPresence(ctx context.Context, p *Presence) {
+	members, err := s.redis.SMembers(ctx, presenceSubscribersKey(p.UserID)).Result()
+	if err != nil {
+		logger.Log.Warn("Failed to get presence subscribers",
+			zap.Int64("user_id", p.UserID),
+			zap.Error(err),
+		)
+		return
+	}
+	if len(members) == 0 {
+		return
+	}
+
+	subscriberIDs := make([]int64, 0, len(members))
+	for _, member := range members {
+		subscriberID, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		subscriberIDs = append(subscriberIDs, subscriberID)
+	}
+
+	data := map[string]interface{}{
+		"user_id": p.UserID,
+		"status":  p.Status,
+	}
+	if p.LastSeen > 0 {
+		data["last_seen"] = p.LastSeen
+	}
+	if p.CustomStatus != "" {
+		data["custom_status"] = p.CustomStatus
+	}
+	if p.CustomStatusExpiresAt > 0 {
+		data["custom_status_expires_at"] = p.CustomStatusExpiresAt
+	}
+
+	payload, err := structpb.NewStruct(data)
+	if err != nil {
+		logger.Log.Error("Failed to create presence payload", zap.Error(err))
+		return
+	}
+
+	event := &gatewaypb.GatewayMessage{
+		Type:      gatewaypb.MessageType_PRESENCE,
+		Payload:   payload,
+		Timestamp: time.Now().Unix(),
+	}
+
+	onlineCount, deliveredCount := s.pushToUsers(ctx, subscriberIDs, event)
+
+	logger.Log.Debug("Presence pushed",
+		zap.Int64("user_id", p.UserID),
+		zap.String("status", p.Status),
+		zap.Int32("online_count", onlineCount),
+		zap.Int32("delivered_count", deliveredCount),
+	)
+}
+
+// clearPresenceSubscriptions drops everything subscriberID was watching
+func (s *Service) clearPresenceSubscriptions(ctx context.Context, subscriberID int64) {
+	watchingKey := presenceWatchingKey(subscriberID)
+
+	targets, err := s.redis.SMembers(ctx, watchingKey).Result()
+	if err != nil {
+		logger.Log.Warn("Failed to get presence subscriptions",
+			zap.Int64("user_id", subscriberID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	pipe := s.redis.TxPipeline()
+	for _, target := range targets {
+		pipe.SRem(ctx, presenceSubscribersPrefix+target, subscriberID)
+	}
+	pipe.Del(ctx, watchingKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Log.Warn("Failed to clear presence subscriptions",
+			zap.Int64("user_id", subscriberID),
+			zap.Error(err),
+		)
+	}
+}
+
+// getPresenceState loads the stored presence state of userID
+func (s *Service) getPresenceState(ctx context.Context, userID int64) (*presenceState, error) {
+	states, err := s.batchGetPresenceState(ctx, []int64{userID})
+	if err != nil {
+		return nil, err
+	}
+	return states[0], nil
+}
+
+// batchGetPresenceState loads the stored presence state of many users with
+// pipelined Redis calls. Results follow the order of userIDs.
+func (s *Service) batchGetPresenceState(ctx context.Context, userIDs []int64) ([]*presenceState, error) {
+	states := make([]*presenceState, 0, len(userIDs))
+	for start := 0; start < len(userIDs); start += batchPipelineSize {
+		chunk := userIDs[start:min(start+batchPipelineSize, len(userIDs))]
+
+		pipe := s.redis.Pipeline()
+		cmds := make([]*redis.MapStringStringCmd, len(chunk))
+		for i, userID := range chunk {
+			cmds[i] = pipe.HGetAll(ctx, presenceStateKey(userID))
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get presence state: %w", err)
+		}
+
+		for _, cmd := range cmds {
+			fields := cmd.Val()
+			state := &presenceState{
+				status:       fields["status"],
+				customStatus: fields["custom_status"],
+			}
+			if state.status == "" {
+				state.status = PresenceOnline
+			}
+			state.customStatusExpiresAt, _ = strconv.ParseInt(fields["custom_status_expires_at"], 10, 64)
+			state.lastSeen, _ = strconv.ParseInt(fields["last_seen"], 10, 64)
+			states = append(states, state)
+		}
+	}
+
+	return states, nil
+}
+
+func presenceStateKey(userID int64) string {
+	return fmt.Sprintf("%s%d", presenceStatePrefix, userID)
+}
+
+func presenceSubscribersKey(userID int64) string {
+	return fmt.Sprintf("%s%d", presenceSubscribersPrefix, userID)
+}
+
+func presenceWatchingKey(userID int64) string {
+	return fmt.Sprintf("%s%d", presenceWatchingPrefix, userID)
+}
@@ -3,56 +3,142 @@ package router
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
+	gatewaypb "github.com/dollarkillerx/im-system/api/proto/gateway"
 	"github.com/dollarkillerx/im-system/pkg/logger"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 const (
 	routeKeyPrefix    = "route:"
 	presenceKeyPrefix = "presence:"
 	defaultTTL        = 60 * time.Second
+
+	// keepAliveAttempts bounds the compare-and-swap retries in KeepAlive
+	keepAliveAttempts = 3
+	// unregisterAttempts bounds the compare-and-delete retries in UnregisterRoute
+	unregisterAttempts = 3
+
+	// MaxBatchLookupUsers caps the number of users in one batch lookup
+	MaxBatchLookupUsers = 10000
+	// batchPipelineSize is the number of commands sent per Redis pipeline
+	batchPipelineSize = 1000
+)
+
+var (
+	// ErrEmptyEvent is returned when PushEvent is called without an event
+	ErrEmptyEvent = errors.New("event is required")
+	// ErrTooManyUsers is returned when a batch lookup exceeds MaxBatchLookupUsers
+	ErrTooManyUsers = errors.New("too many users")
+	// ErrRouteNotFound is returned by KeepAlive when the device has no live route
+	ErrRouteNotFound = errors.New("route not found")
+)
+
+// Each device in a route hash expires on its own: an entry whose LastActive
+// is older than defaultTTL is stale even if other devices keep the hash key
+// alive. Updates and pruning are compare-and-swap on the stored value so a
+// concurrent KeepAlive or re-registration is never overwritten or deleted.
+var (
+	// routeCASScript replaces a device entry only if it still holds the
+	// value that was read, and refreshes the hash TTL
+	routeCASScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], ARGV[1]) ~= ARGV[2] then
+  return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[3])
+redis.call('EXPIRE', KEYS[1], ARGV[4])
+return 1
+`)
+
+	// routePruneScript deletes device entries that still hold the stale
+	// value that was read; ARGV is a list of field/value pairs
+	routePruneScript = redis.NewScript(`
+local removed = 0
+for i = 1, #ARGV, 2 do
+  if redis.call('HGET', KEYS[1], ARGV[i]) == ARGV[i + 1] then
+    redis.call('HDEL', KEYS[1], ARGV[i])
+    removed = removed + 1
+  end
+end
+return removed
+`)
 )
 
 type DeviceRoute struct {
 	DeviceID    string `json:"device_id"`
 	GatewayAddr string `json:"gateway_addr"`
 	LastActive  int64  `json:"last_active"`
+	Platform    string `json:"platform,omitempty"`
+	// ConnectedAt is in milliseconds so the device policy can tell apart
+	// connections made within the same second
+	ConnectedAt int64 `json:"connected_at,omitempty"`
+}
+
+// UserRoutes holds the device routes of one user in a batch lookup
+type UserRoutes struct {
+	UserID int64
+	Routes []*DeviceRoute
+}
+
+// UserOnlineStatus holds the online status of one user in a batch lookup
+type UserOnlineStatus struct {
+	UserID    int64
+	Online    bool
+	DeviceIDs []string
 }
 
 type Service struct {
-	redis *redis.Client
+	redis            *redis.Client
+	pusher           GatewayPusher
+	presenceDebounce time.Duration
+	devicePolicy     DevicePolicy
+	revoker          TokenRevoker
 }
 
-func NewService(redisClient *redis.Client) *Service {
+func NewService(redisClient *redis.Client, pusher GatewayPusher) *Service {
 	return &Service{
-		redis: redisClient,
+		redis:            redisClient,
+		pusher:           pusher,
+		presenceDebounce: DefaultPresenceDebounce,
 	}
 }
 
 // RegisterRoute registers a user's device route
 func (s *Service) RegisterRoute(ctx context.Context, userID int64, deviceID, gatewayAddr string) error {
+	_, err := s.RegisterDeviceRoute(ctx, userID, deviceID, "", gatewayAddr)
+	return err
+}
+
+// RegisterDeviceRoute registers a device route with its platform class and
+// enforces the device policy. It returns the devices that were kicked to
+// make room for the new one.
+func (s *Service) RegisterDeviceRoute(ctx context.Context, userID int64, deviceID, platform, gatewayAddr string) ([]*DeviceRoute, error) {
 	routeKey := fmt.Sprintf("%s%d", routeKeyPrefix, userID)
 	presenceKey := fmt.Sprintf("%s%d", presenceKeyPrefix, userID)
 
+	now := time.Now()
 	route := &DeviceRoute{
 		DeviceID:    deviceID,
 		GatewayAddr: gatewayAddr,
-		LastActive:  time.Now().Unix(),
+		LastActive:  now.Unix(),
+		Platform:    normalizePlatform(platform),
+		ConnectedAt: now.UnixMilli(),
 	}
 
 	routeData, err := json.Marshal(route)
 	if err != nil {
-		return fmt.Errorf("failed to marshal route: %w", err)
+		return nil, fmt.Errorf("failed to marshal route: %w", err)
 	}
 
 	// Store route with TTL
 	err = s.redis.HSet(ctx, routeKey, deviceID, routeData).Err()
 	if err != nil {
-		return fmt.Errorf("failed to set route: %w", err)
+		return nil, fmt.Errorf("failed to set route: %w", err)
 	}
 
 	// Set TTL on route key
@@ -61,48 +147,83 @@ func (s *Service) RegisterRoute(ctx context.Context, userID int64, deviceID, gat
 	// Set presence to online
 	s.redis.Set(ctx, presenceKey, "online", defaultTTL)
 
+	if err := s.touchPresence(ctx, userID); err != nil {
+		logger.Log.Warn("Failed to publish online presence",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+	}
+
 	logger.Log.Debug("Route registered",
 		zap.Int64("user_id", userID),
 		zap.String("device_id", deviceID),
+		zap.String("platform", route.Platform),
 		zap.String("gateway_addr", gatewayAddr),
 	)
 
-	return nil
+	// The route is stored before the policy runs so concurrent registrations
+	// see each other and agree on the devices to evict
+	evicted, err := s.enforceDevicePolicy(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enforce device policy: %w", err)
+	}
+
+	return evicted, nil
 }
 
-// KeepAlive updates the TTL for a user's route
+// KeepAlive refreshes LastActive of a device route. A device whose route
+// has already gone stale is not revived; it must register again.
 func (s *Service) KeepAlive(ctx context.Context, userID int64, deviceID string) error {
 	routeKey := fmt.Sprintf("%s%d", routeKeyPrefix, userID)
 	presenceKey := fmt.Sprintf("%s%d", presenceKeyPrefix, userID)
 
-	// Check if device route exists
-	exists, err := s.redis.HExists(ctx, routeKey, deviceID).Result()
-	if err != nil {
-		return fmt.Errorf("failed to check route existence: %w", err)
-	}
+	refreshed := false
+	for attempt := 0; attempt < keepAliveAttempts && !refreshed; attempt++ {
+		routeData, err := s.redis.HGet(ctx, routeKey, deviceID).Result()
+		if errors.Is(err, redis.Nil) {
+			return fmt.Errorf("%w for device: %s", ErrRouteNotFound, deviceID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get route: %w", err)
+		}
 
-	if !exists {
-		return fmt.Errorf("route not found for device: %s", deviceID)
-	}
+		var route DeviceRoute
+		if err := json.Unmarshal([]byte(routeData), &route); err != nil {
+			return fmt.Errorf("failed to unmarshal route: %w", err)
+		}
 
-	// Update last active time
-	routeData, err := s.redis.HGet(ctx, routeKey, deviceID).Result()
-	if err != nil {
-		return fmt.Errorf("failed to get route: %w", err)
-	}
+		now := time.Now()
+		if isStaleRoute(&route, now) {
+			s.pruneRoutes(ctx, routeKey, map[string]string{deviceID: routeData})
+			return fmt.Errorf("%w for device: %s", ErrRouteNotFound, deviceID)
+		}
 
-	var route DeviceRoute
-	if err := json.Unmarshal([]byte(routeData), &route); err != nil {
-		return fmt.Errorf("failed to unmarshal route: %w", err)
-	}
+		route.LastActive = now.Unix()
+		updatedData, err := json.Marshal(route)
+		if err != nil {
+			return fmt.Errorf("failed to marshal route: %w", err)
+		}
 
-	route.LastActive = time.Now().Unix()
-	updatedData, _ := json.Marshal(route)
+		swapped, err := routeCASScript.Run(ctx, s.redis, []string{routeKey},
+			deviceID, routeData, updatedData, int64(defaultTTL/time.Second)).Int()
+		if err != nil {
+			return fmt.Errorf("failed to refresh route: %w", err)
+		}
+		refreshed = swapped == 1
+	}
+	if !refreshed {
+		return fmt.Errorf("failed to refresh route: device %s updated concurrently", deviceID)
+	}
 
-	s.redis.HSet(ctx, routeKey, deviceID, updatedData)
-	s.redis.Expire(ctx, routeKey, defaultTTL)
 	s.redis.Expire(ctx, presenceKey, defaultTTL)
 
+	if err := s.touchPresence(ctx, userID); err != nil {
+		logger.Log.Warn("Failed to refresh presence",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+	}
+
 	return nil
 }
 
@@ -115,40 +236,101 @@ func (s *Service) GetRoute(ctx context.Context, userID int64) ([]*DeviceRoute, e
 		return nil, fmt.Errorf("failed to get routes: %w", err)
 	}
 
-	var deviceRoutes []*DeviceRoute
-	for _, routeData := range routes {
-		var route DeviceRoute
-		if err := json.Unmarshal([]byte(routeData), &route); err != nil {
-			logger.Log.Warn("Failed to unmarshal route", zap.Error(err))
-			continue
+	return s.liveRoutes(ctx, routeKey, routes), nil
+}
+
+// BatchGetRoutes retrieves the routes of many users with pipelined Redis
+// calls. Results follow the order of userIDs.
+func (s *Service) BatchGetRoutes(ctx context.Context, userIDs []int64) ([]*UserRoutes, error) {
+	if len(userIDs) > MaxBatchLookupUsers {
+		return nil, ErrTooManyUsers
+	}
+
+	results := make([]*UserRoutes, 0, len(userIDs))
+	for start := 0; start < len(userIDs); start += batchPipelineSize {
+		chunk := userIDs[start:min(start+batchPipelineSize, len(userIDs))]
+
+		pipe := s.redis.Pipeline()
+		cmds := make([]*redis.MapStringStringCmd, len(chunk))
+		for i, userID := range chunk {
+			cmds[i] = pipe.HGetAll(ctx, fmt.Sprintf("%s%d", routeKeyPrefix, userID))
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get routes: %w", err)
+		}
+
+		for i, userID := range chunk {
+			results = append(results, &UserRoutes{
+				UserID: userID,
+				Routes: s.liveRoutes(ctx, fmt.Sprintf("%s%d", routeKeyPrefix, userID), cmds[i].Val()),
+			})
 		}
-		deviceRoutes = append(deviceRoutes, &route)
 	}
 
-	return deviceRoutes, nil
+	return results, nil
 }
 
-// UnregisterRoute removes a user's device route
-func (s *Service) UnregisterRoute(ctx context.Context, userID int64, deviceID string) error {
+// UnregisterRoute removes a user's device route if it still belongs to
+// gatewayAddr. A device that has reconnected through another gateway keeps
+// its new route when the old gateway reports the disconnect late.
+func (s *Service) UnregisterRoute(ctx context.Context, userID int64, deviceID, gatewayAddr string) error {
 	routeKey := fmt.Sprintf("%s%d", routeKeyPrefix, userID)
 	presenceKey := fmt.Sprintf("%s%d", presenceKeyPrefix, userID)
 
-	// Remove device route
-	err := s.redis.HDel(ctx, routeKey, deviceID).Err()
-	if err != nil {
-		return fmt.Errorf("failed to delete route: %w", err)
+	// Remove device route, compare-and-delete on the value that was read so a
+	// concurrent re-registration is never deleted
+	removed := false
+	for attempt := 0; attempt < unregisterAttempts && !removed; attempt++ {
+		routeData, err := s.redis.HGet(ctx, routeKey, deviceID).Result()
+		if errors.Is(err, redis.Nil) {
+			removed = true
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get route: %w", err)
+		}
+
+		var route DeviceRoute
+		if err := json.Unmarshal([]byte(routeData), &route); err != nil {
+			return fmt.Errorf("failed to unmarshal route: %w", err)
+		}
+		if route.GatewayAddr != gatewayAddr {
+			logger.Log.Debug("Route owned by another gateway, not unregistered",
+				zap.Int64("user_id", userID),
+				zap.String("device_id", deviceID),
+				zap.String("gateway_addr", gatewayAddr),
+				zap.String("owner_gateway_addr", route.GatewayAddr),
+			)
+			return nil
+		}
+
+		deleted, err := routePruneScript.Run(ctx, s.redis, []string{routeKey}, deviceID, routeData).Int()
+		if err != nil {
+			return fmt.Errorf("failed to delete route: %w", err)
+		}
+		removed = deleted == 1
+	}
+	if !removed {
+		return fmt.Errorf("failed to delete route: device %s updated concurrently", deviceID)
 	}
 
-	// Check if any devices remain
-	count, err := s.redis.HLen(ctx, routeKey).Result()
+	// Check if any live devices remain; stale ones are pruned on the way
+	routes, err := s.GetRoute(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("failed to check route count: %w", err)
 	}
 
-	// If no devices remain, set presence to offline
-	if count == 0 {
-		s.redis.Del(ctx, routeKey)
+	// If no devices remain, set presence to offline; subscribers are told
+	// once the debounce window passes without a reconnect
+	if len(routes) == 0 {
 		s.redis.Set(ctx, presenceKey, "offline", defaultTTL)
+
+		if err := s.markOffline(ctx, userID, time.Now().Unix()); err != nil {
+			logger.Log.Warn("Failed to schedule offline presence",
+				zap.Int64("user_id", userID),
+				zap.Error(err),
+			)
+		}
 	}
 
 	logger.Log.Debug("Route unregistered",
@@ -161,13 +343,200 @@ func (s *Service) UnregisterRoute(ctx context.Context, userID int64, deviceID st
 
 // GetOnlineStatus checks if a user is online
 func (s *Service) GetOnlineStatus(ctx context.Context, userID int64) (bool, []string, error) {
-	routeKey := fmt.Sprintf("%s%d", routeKeyPrefix, userID)
-
-	routes, err := s.redis.HKeys(ctx, routeKey).Result()
+	routes, err := s.GetRoute(ctx, userID)
 	if err != nil {
 		return false, nil, fmt.Errorf("failed to get device IDs: %w", err)
 	}
 
-	online := len(routes) > 0
-	return online, routes, nil
+	deviceIDs := make([]string, 0, len(routes))
+	for _, route := range routes {
+		deviceIDs = append(deviceIDs, route.DeviceID)
+	}
+
+	online := len(deviceIDs) > 0
+	return online, deviceIDs, nil
+}
+
+// BatchGetOnlineStatus checks whether many users are online with pipelined
+// Redis calls. Results follow the order of userIDs.
+func (s *Service) BatchGetOnlineStatus(ctx context.Context, userIDs []int64) ([]*UserOnlineStatus, error) {
+	if len(userIDs) > MaxBatchLookupUsers {
+		return nil, ErrTooManyUsers
+	}
+
+	userRoutes, err := s.BatchGetRoutes(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device IDs: %w", err)
+	}
+
+	results := make([]*UserOnlineStatus, 0, len(userRoutes))
+	for _, ur := range userRoutes {
+		deviceIDs := make([]string, 0, len(ur.Routes))
+		for _, route := range ur.Routes {
+			deviceIDs = append(deviceIDs, route.DeviceID)
+		}
+		results = append(results, &UserOnlineStatus{
+			UserID:    ur.UserID,
+			Online:    len(deviceIDs) > 0,
+			DeviceIDs: deviceIDs,
+		})
+	}
+
+	return results, nil
+}
+
+// PushMessage resolves the gateways of all online recipients and pushes a
+// new message notification to each of them
+func (s *Service) PushMessage(ctx context.Context, convID int64, msgID string, seq int64, senderID int64, recipientIDs []int64) (int32, int32, error) {
+	payload, err := structpb.NewStruct(map[string]interface{}{
+		"type":      "new_message",
+		"conv_id":   convID,
+		"msg_id":    msgID,
+		"seq":       seq,
+		"sender_id": senderID,
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create notification payload: %w", err)
+	}
+
+	notification := &gatewaypb.GatewayMessage{
+		Type:      gatewaypb.MessageType_NOTIFICATION,
+		Payload:   payload,
+		Timestamp: time.Now().Unix(),
+		MsgId:     &msgID,
+	}
+
+	onlineCount, deliveredCount := s.pushToUsers(ctx, recipientIDs, notification)
+
+	logger.Log.Debug("Message pushed",
+		zap.Int64("conv_id", convID),
+		zap.String("msg_id", msgID),
+		zap.Int32("online_count", onlineCount),
+		zap.Int32("delivered_count", deliveredCount),
+	)
+
+	return onlineCount, deliveredCount, nil
+}
+
+// PushEvent pushes a transient event such as a typing indicator to all
+// online recipients; nothing is persisted
+func (s *Service) PushEvent(ctx context.Context, recipientIDs []int64, event *gatewaypb.GatewayMessage) (int32, int32, error) {
+	if event == nil {
+		return 0, 0, ErrEmptyEvent
+	}
+	if event.Timestamp == 0 {
+		event.Timestamp = time.Now().Unix()
+	}
+
+	onlineCount, deliveredCount := s.pushToUsers(ctx, recipientIDs, event)
+
+	logger.Log.Debug("Event pushed",
+		zap.String("type", event.Type.String()),
+		zap.Int32("online_count", onlineCount),
+		zap.Int32("delivered_count", deliveredCount),
+	)
+
+	return onlineCount, deliveredCount, nil
+}
+
+// pushToUsers groups the online recipients by the gateway instance they are
+// connected to and pushes msg to each gateway once
+func (s *Service) pushToUsers(ctx context.Context, recipientIDs []int64, msg *gatewaypb.GatewayMessage) (int32, int32) {
+	gatewayUsers := make(map[string][]int64)
+	var onlineCount int32
+	for start := 0; start < len(recipientIDs); start += MaxBatchLookupUsers {
+		chunk := recipientIDs[start:min(start+MaxBatchLookupUsers, len(recipientIDs))]
+
+		userRoutes, err := s.BatchGetRoutes(ctx, chunk)
+		if err != nil {
+			logger.Log.Warn("Failed to get routes for recipients",
+				zap.Int("user_count", len(chunk)),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		for _, ur := range userRoutes {
+			if len(ur.Routes) == 0 {
+				continue
+			}
+			onlineCount++
+
+			seen := make(map[string]bool)
+			for _, route := range ur.Routes {
+				if seen[route.GatewayAddr] {
+					continue
+				}
+				seen[route.GatewayAddr] = true
+				gatewayUsers[route.GatewayAddr] = append(gatewayUsers[route.GatewayAddr], ur.UserID)
+			}
+		}
+	}
+
+	var deliveredCount int32
+	for gatewayAddr, userIDs := range gatewayUsers {
+		count, err := s.pusher.PushToUsers(ctx, gatewayAddr, userIDs, msg)
+		if err != nil {
+			logger.Log.Warn("Failed to push to gateway",
+				zap.String("gateway_addr", gatewayAddr),
+				zap.String("type", msg.Type.String()),
+				zap.Int("user_count", len(userIDs)),
+				zap.Error(err),
+			)
+			continue
+		}
+		deliveredCount += count
+	}
+
+	return onlineCount, deliveredCount
+}
+
+// liveRoutes unmarshals the device routes stored in a route hash and prunes
+// the entries that have gone stale
+func (s *Service) liveRoutes(ctx context.Context, routeKey string, routes map[string]string) []*DeviceRoute {
+	now := time.Now()
+	var deviceRoutes []*DeviceRoute
+	var stale map[string]string
+	for deviceID, routeData := range routes {
+		var route DeviceRoute
+		if err := json.Unmarshal([]byte(routeData), &route); err != nil {
+			logger.Log.Warn("Failed to unmarshal route", zap.Error(err))
+			continue
+		}
+		if isStaleRoute(&route, now) {
+			if stale == nil {
+				stale = make(map[string]string)
+			}
+			stale[deviceID] = routeData
+			continue
+		}
+		deviceRoutes = append(deviceRoutes, &route)
+	}
+
+	if len(stale) > 0 {
+		s.pruneRoutes(ctx, routeKey, stale)
+	}
+
+	return deviceRoutes
+}
+
+// pruneRoutes deletes stale device entries unless they changed since they were read
+func (s *Service) pruneRoutes(ctx context.Context, routeKey string, stale map[string]string) {
+	args := make([]interface{}, 0, 2*len(stale))
+	for deviceID, routeData := range stale {
+		args = append(args, deviceID, routeData)
+	}
+
+	if err := routePruneScript.Run(ctx, s.redis, []string{routeKey}, args...).Err(); err != nil {
+		logger.Log.Warn("Failed to prune stale routes",
+			zap.String("route_key", routeKey),
+			zap.Int("device_count", len(stale)),
+			zap.Error(err),
+		)
+	}
+}
+
+// isStaleRoute reports whether a device has missed its keep-alives for longer than defaultTTL
+func isStaleRoute(route *DeviceRoute, now time.Time) bool {
+	return now.Unix()-route.LastActive > int64(defaultTTL/time.Second)
 }
@@ -4,10 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
+	gatewaypb "github.com/dollarkillerx/im-system/api/proto/gateway"
 	"github.com/dollarkillerx/im-system/pkg/logger"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
@@ -45,7 +48,7 @@ func setupTestService(t *testing.T) (*Service, *redis.Client, func()) {
 		Addr: mr.Addr(),
 	})
 
-	service := NewService(client)
+	service := NewService(client, newMockGatewayPusher())
 
 	cleanup := func() {
 		client.Close()
@@ -55,6 +58,61 @@ func setupTestService(t *testing.T) (*Service, *redis.Client, func()) {
 	return service, client, cleanup
 }
 
+// MockGatewayPusher records notifications pushed to each gateway
+type MockGatewayPusher struct {
+	mu          sync.Mutex
+	pushes      map[string][]int64 // gatewayAddr -> user IDs
+	messages    []*gatewaypb.GatewayMessage
+	kicks       map[string][]string // gatewayAddr -> device IDs
+	kickReasons []string
+	err         error
+}
+
+func newMockGatewayPusher() *MockGatewayPusher {
+	return &MockGatewayPusher{
+		pushes: make(map[string][]int64),
+		kicks:  make(map[string][]string),
+	}
+}
+
+func (m *MockGatewayPusher) KickDevices(ctx context.Context, gatewayAddr string, userID int64, deviceIDs []string, reason string) (int32, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return 0, m.err
+	}
+	m.kicks[gatewayAddr] = append(m.kicks[gatewayAddr], deviceIDs...)
+	m.kickReasons = append(m.kickReasons, reason)
+	return int32(len(deviceIDs)), nil
+}
+
+// mockTokenRevoker records revoked devices
+type mockTokenRevoker struct {
+	mu      sync.Mutex
+	revoked []string
+}
+
+func (m *mockTokenRevoker) RevokeDevice(ctx context.Context, userID int64, deviceID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.revoked = append(m.revoked, deviceID)
+	return nil
+}
+
+func (m *MockGatewayPusher) PushToUsers(ctx context.Context, gatewayAddr string, userIDs []int64, msg *gatewaypb.GatewayMessage) (int32, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return 0, m.err
+	}
+	m.pushes[gatewayAddr] = append(m.pushes[gatewayAddr], userIDs...)
+	m.messages = append(m.messages, msg)
+	return int32(len(userIDs)), nil
+}
+
 func TestService_RegisterRoute(t *testing.T) {
 	service, _, cleanup := setupTestService(t)
 	defer cleanup()
@@ -205,7 +263,7 @@ func TestService_KeepAlive(t *testing.T) {
 			err := service.KeepAlive(ctx, tt.userID, tt.deviceID)
 
 			if tt.wantErr {
-				assert.Error(t, err)
+				assert.ErrorIs(t, err, ErrRouteNotFound)
 				if tt.errMsg != "" {
 					assert.Contains(t, err.Error(), tt.errMsg)
 				}
@@ -222,6 +280,84 @@ func TestService_KeepAlive(t *testing.T) {
 	}
 }
 
+func TestService_StaleDeviceRoutes(t *testing.T) {
+	service, client, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	userID := int64(350)
+	routeKey := "route:350"
+
+	// seedStale writes a device entry whose keep-alives stopped long ago
+	seedStale := func(deviceID string) string {
+		data, err := json.Marshal(&DeviceRoute{
+			DeviceID:    deviceID,
+			GatewayAddr: "gateway-dead:8080",
+			LastActive:  time.Now().Add(-2 * defaultTTL).Unix(),
+		})
+		require.NoError(t, err)
+		require.NoError(t, client.HSet(ctx, routeKey, deviceID, data).Err())
+		return string(data)
+	}
+
+	require.NoError(t, service.RegisterRoute(ctx, userID, "device-live", "gateway-1:8080"))
+
+	t.Run("stale entries are hidden and pruned on read", func(t *testing.T) {
+		seedStale("device-stale")
+
+		routes, err := service.GetRoute(ctx, userID)
+		require.NoError(t, err)
+		require.Len(t, routes, 1)
+		assert.Equal(t, "device-live", routes[0].DeviceID)
+
+		exists, err := client.HExists(ctx, routeKey, "device-stale").Result()
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		seedStale("device-stale")
+		online, devices, err := service.GetOnlineStatus(ctx, userID)
+		require.NoError(t, err)
+		assert.True(t, online)
+		assert.Equal(t, []string{"device-live"}, devices)
+	})
+
+	t.Run("keep alive does not revive a stale device", func(t *testing.T) {
+		seedStale("device-stale")
+
+		err := service.KeepAlive(ctx, userID, "device-stale")
+		assert.ErrorIs(t, err, ErrRouteNotFound)
+
+		exists, err := client.HExists(ctx, routeKey, "device-stale").Result()
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("prune keeps entries refreshed after the read", func(t *testing.T) {
+		staleData := seedStale("device-stale")
+		require.NoError(t, service.RegisterRoute(ctx, userID, "device-stale", "gateway-2:8080"))
+
+		service.pruneRoutes(ctx, routeKey, map[string]string{"device-stale": staleData})
+
+		routes, err := service.GetRoute(ctx, userID)
+		require.NoError(t, err)
+		assert.Len(t, routes, 2)
+		require.NoError(t, service.UnregisterRoute(ctx, userID, "device-stale", "gateway-2:8080"))
+	})
+
+	t.Run("last live device leaving goes offline despite stale entries", func(t *testing.T) {
+		seedStale("device-stale")
+
+		require.NoError(t, service.UnregisterRoute(ctx, userID, "device-live", "gateway-1:8080"))
+
+		_, err := client.ZScore(ctx, presencePendingKey, "350").Result()
+		assert.NoError(t, err, "offline presence should be scheduled")
+
+		exists, err := client.Exists(ctx, routeKey).Result()
+		require.NoError(t, err)
+		assert.Zero(t, exists)
+	})
+}
+
 func TestService_UnregisterRoute(t *testing.T) {
 	service, _, cleanup := setupTestService(t)
 	defer cleanup()
@@ -237,6 +373,7 @@ func TestService_UnregisterRoute(t *testing.T) {
 		name            string
 		userID          int64
 		deviceID        string
+		gatewayAddr     string
 		expectRemaining int
 		wantErr         bool
 	}{
@@ -244,6 +381,15 @@ func TestService_UnregisterRoute(t *testing.T) {
 			name:            "unregister first device",
 			userID:          userID,
 			deviceID:        "device-001",
+			gatewayAddr:     "gateway-1:8080",
+			expectRemaining: 1,
+			wantErr:         false,
+		},
+		{
+			name:            "another gateway cannot unregister the device",
+			userID:          userID,
+			deviceID:        "device-002",
+			gatewayAddr:     "gateway-1:8080",
 			expectRemaining: 1,
 			wantErr:         false,
 		},
@@ -251,6 +397,7 @@ func TestService_UnregisterRoute(t *testing.T) {
 			name:            "unregister last device",
 			userID:          userID,
 			deviceID:        "device-002",
+			gatewayAddr:     "gateway-2:8080",
 			expectRemaining: 0,
 			wantErr:         false,
 		},
@@ -258,7 +405,7 @@ func TestService_UnregisterRoute(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := service.UnregisterRoute(ctx, tt.userID, tt.deviceID)
+			err := service.UnregisterRoute(ctx, tt.userID, tt.deviceID, tt.gatewayAddr)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -270,15 +417,39 @@ func TestService_UnregisterRoute(t *testing.T) {
 				require.NoError(t, err)
 				assert.Len(t, routes, tt.expectRemaining)
 
-				// Verify the device is not in the list
+				// Verify the device is only kept by the gateway that owns it
 				for _, route := range routes {
-					assert.NotEqual(t, tt.deviceID, route.DeviceID)
+					if route.DeviceID == tt.deviceID {
+						assert.NotEqual(t, tt.gatewayAddr, route.GatewayAddr)
+					}
 				}
 			}
 		})
 	}
 }
 
+func TestService_UnregisterRoute_LateDisconnect(t *testing.T) {
+	service, _, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	userID := int64(410)
+
+	// the device reconnects through another gateway before the old one reports the disconnect
+	require.NoError(t, service.RegisterRoute(ctx, userID, "device-001", "gateway-1:8080"))
+	require.NoError(t, service.RegisterRoute(ctx, userID, "device-001", "gateway-2:8080"))
+	require.NoError(t, service.UnregisterRoute(ctx, userID, "device-001", "gateway-1:8080"))
+
+	routes, err := service.GetRoute(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, routes, 1)
+	assert.Equal(t, "gateway-2:8080", routes[0].GatewayAddr)
+
+	online, _, err := service.GetOnlineStatus(ctx, userID)
+	require.NoError(t, err)
+	assert.True(t, online)
+}
+
 func TestService_GetOnlineStatus(t *testing.T) {
 	service, _, cleanup := setupTestService(t)
 	defer cleanup()
@@ -317,8 +488,8 @@ func TestService_GetOnlineStatus(t *testing.T) {
 			userID:       userID,
 			expectOnline: false,
 			setupFunc: func() {
-				service.UnregisterRoute(ctx, userID, "device-001")
-				service.UnregisterRoute(ctx, userID, "device-002")
+				service.UnregisterRoute(ctx, userID, "device-001", "gateway-1:8080")
+				service.UnregisterRoute(ctx, userID, "device-002", "gateway-2:8080")
 			},
 			expectDevices: 0,
 			wantErr:       false,
@@ -344,6 +515,65 @@ func TestService_GetOnlineStatus(t *testing.T) {
 	}
 }
 
+func TestService_BatchGetRoutes(t *testing.T) {
+	service, _, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	require.NoError(t, service.RegisterRoute(ctx, 100, "device-1", "gateway-1:50051"))
+	require.NoError(t, service.RegisterRoute(ctx, 100, "device-2", "gateway-2:50051"))
+	require.NoError(t, service.RegisterRoute(ctx, 300, "device-1", "gateway-1:50051"))
+
+	results, err := service.BatchGetRoutes(ctx, []int64{300, 200, 100})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, int64(300), results[0].UserID)
+	require.Len(t, results[0].Routes, 1)
+	assert.Equal(t, "gateway-1:50051", results[0].Routes[0].GatewayAddr)
+
+	assert.Equal(t, int64(200), results[1].UserID)
+	assert.Empty(t, results[1].Routes)
+
+	assert.Equal(t, int64(100), results[2].UserID)
+	assert.Len(t, results[2].Routes, 2)
+
+	_, err = service.BatchGetRoutes(ctx, make([]int64, MaxBatchLookupUsers+1))
+	assert.ErrorIs(t, err, ErrTooManyUsers)
+}
+
+func TestService_BatchGetOnlineStatus(t *testing.T) {
+	service, _, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// span more than one pipeline chunk
+	userIDs := make([]int64, batchPipelineSize+10)
+	for i := range userIDs {
+		userIDs[i] = int64(i + 1)
+	}
+	online := map[int64]bool{1: true, int64(batchPipelineSize + 5): true}
+	for userID := range online {
+		require.NoError(t, service.RegisterRoute(ctx, userID, "device-1", "gateway-1:50051"))
+	}
+
+	results, err := service.BatchGetOnlineStatus(ctx, userIDs)
+	require.NoError(t, err)
+	require.Len(t, results, len(userIDs))
+
+	for i, st := range results {
+		assert.Equal(t, userIDs[i], st.UserID)
+		assert.Equal(t, online[st.UserID], st.Online, "user %d", st.UserID)
+		if st.Online {
+			assert.Equal(t, []string{"device-1"}, st.DeviceIDs)
+		}
+	}
+
+	_, err = service.BatchGetOnlineStatus(ctx, make([]int64, MaxBatchLookupUsers+1))
+	assert.ErrorIs(t, err, ErrTooManyUsers)
+}
+
 func TestDeviceRoute_JSONMarshaling(t *testing.T) {
 	route := &DeviceRoute{
 		DeviceID:    "device-123",
@@ -425,8 +655,8 @@ func (m *MockRouteStorage) RegisterRoute(ctx context.Context, userID int64, devi
 	return nil
 }
 
-func (m *MockRouteStorage) UnregisterRoute(ctx context.Context, userID int64, deviceID string) error {
-	if m.routes[userID] != nil {
+func (m *MockRouteStorage) UnregisterRoute(ctx context.Context, userID int64, deviceID, gatewayAddr string) error {
+	if route := m.routes[userID][deviceID]; route != nil && route.GatewayAddr == gatewayAddr {
 		delete(m.routes[userID], deviceID)
 	}
 	return nil
@@ -484,7 +714,7 @@ func TestMockRouteStorage(t *testing.T) {
 	assert.Len(t, devices, 1)
 
 	// Test unregistration
-	err = storage.UnregisterRoute(ctx, 100, "device-1")
+	err = storage.UnregisterRoute(ctx, 100, "device-1", "gateway-1:8080")
 	assert.NoError(t, err)
 
 	online, devices, err = storage.GetOnlineStatus(ctx, 100)
@@ -492,3 +722,512 @@ func TestMockRouteStorage(t *testing.T) {
 	assert.False(t, online)
 	assert.Len(t, devices, 0)
 }
+
+func TestService_PushMessage(t *testing.T) {
+	service, _, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// User 200 has two devices on different gateways, user 300 has two devices on the same gateway
+	require.NoError(t, service.RegisterRoute(ctx, 200, "device-1", "gateway-1:50051"))
+	require.NoError(t, service.RegisterRoute(ctx, 200, "device-2", "gateway-2:50051"))
+	require.NoError(t, service.RegisterRoute(ctx, 300, "device-1", "gateway-1:50051"))
+	require.NoError(t, service.RegisterRoute(ctx, 300, "device-2", "gateway-1:50051"))
+
+	t.Run("fan out to owning gateways", func(t *testing.T) {
+		pusher := newMockGatewayPusher()
+		service.pusher = pusher
+
+		onlineCount, deliveredCount, err := service.PushMessage(ctx, 1, "msg-1", 10, 100, []int64{200, 300, 400})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), onlineCount)
+		assert.Equal(t, int32(3), deliveredCount)
+		assert.ElementsMatch(t, []int64{200, 300}, pusher.pushes["gateway-1:50051"])
+		assert.ElementsMatch(t, []int64{200}, pusher.pushes["gateway-2:50051"])
+	})
+
+	t.Run("no online recipients", func(t *testing.T) {
+		pusher := newMockGatewayPusher()
+		service.pusher = pusher
+
+		onlineCount, deliveredCount, err := service.PushMessage(ctx, 1, "msg-2", 11, 100, []int64{400, 500})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), onlineCount)
+		assert.Equal(t, int32(0), deliveredCount)
+		assert.Empty(t, pusher.pushes)
+	})
+
+	t.Run("gateway push failure", func(t *testing.T) {
+		pusher := newMockGatewayPusher()
+		pusher.err = errors.New("gateway unavailable")
+		service.pusher = pusher
+
+		onlineCount, deliveredCount, err := service.PushMessage(ctx, 1, "msg-3", 12, 100, []int64{200})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), onlineCount)
+		assert.Equal(t, int32(0), deliveredCount)
+	})
+}
+
+func TestService_PushEvent(t *testing.T) {
+	service, _, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	require.NoError(t, service.RegisterRoute(ctx, 200, "device-1", "gateway-1:50051"))
+	require.NoError(t, service.RegisterRoute(ctx, 300, "device-1", "gateway-2:50051"))
+
+	t.Run("fan out event", func(t *testing.T) {
+		pusher := newMockGatewayPusher()
+		service.pusher = pusher
+
+		event := &gatewaypb.GatewayMessage{Type: gatewaypb.MessageType_TYPING}
+		onlineCount, deliveredCount, err := service.PushEvent(ctx, []int64{200, 300, 400}, event)
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), onlineCount)
+		assert.Equal(t, int32(2), deliveredCount)
+		assert.ElementsMatch(t, []int64{200}, pusher.pushes["gateway-1:50051"])
+		assert.ElementsMatch(t, []int64{300}, pusher.pushes["gateway-2:50051"])
+
+		require.Len(t, pusher.messages, 2)
+		assert.Equal(t, gatewaypb.MessageType_TYPING, pusher.messages[0].Type)
+		assert.NotZero(t, pusher.messages[0].Timestamp)
+	})
+
+	t.Run("missing event", func(t *testing.T) {
+		_, _, err := service.PushEvent(ctx, []int64{200}, nil)
+		assert.ErrorIs(t, err, ErrEmptyEvent)
+	})
+}
+
+// presenceEvents returns the PRESENCE payloads pushed so far
+func (m *MockGatewayPusher) presenceEvents() []map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var events []map[string]interface{}
+	for _, msg := range m.messages {
+		if msg.Type == gatewaypb.MessageType_PRESENCE {
+			events = append(events, msg.Payload.AsMap())
+		}
+	}
+	return events
+}
+
+func TestService_SubscribePresence(t *testing.T) {
+	service, client, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	require.NoError(t, service.RegisterRoute(ctx, 200, "device-1", "gateway-1:50051"))
+
+	statuses, err := service.SubscribePresence(ctx, 100, []int64{200, 300})
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.Equal(t, int64(200), statuses[0].UserID)
+	assert.True(t, statuses[0].Online)
+	assert.Equal(t, PresenceOnline, statuses[0].Status)
+	assert.Equal(t, int64(300), statuses[1].UserID)
+	assert.False(t, statuses[1].Online)
+	assert.Equal(t, PresenceOffline, statuses[1].Status)
+
+	subscribers, err := client.SMembers(ctx, presenceSubscribersKey(200)).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"100"}, subscribers)
+
+	require.NoError(t, service.UnsubscribePresence(ctx, 100, []int64{200}))
+	subscribers, err = client.SMembers(ctx, presenceSubscribersKey(200)).Result()
+	require.NoError(t, err)
+	assert.Empty(t, subscribers)
+
+	watching, err := client.SMembers(ctx, presenceWatchingKey(100)).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"300"}, watching)
+
+	_, err = service.SubscribePresence(ctx, 100, make([]int64, MaxPresenceTargets+1))
+	assert.ErrorIs(t, err, ErrTooManyPresenceTargets)
+}
+
+func TestService_PresenceTransitions(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*Service, *MockGatewayPusher, *redis.Client, func()) {
+		service, client, cleanup := setupTestService(t)
+		pusher := newMockGatewayPusher()
+		service.pusher = pusher
+
+		require.NoError(t, service.RegisterRoute(ctx, 100, "device-1", "gateway-1:50051"))
+		_, err := service.SubscribePresence(ctx, 100, []int64{200})
+		require.NoError(t, err)
+		return service, pusher, client, cleanup
+	}
+
+	t.Run("online is pushed once per transition", func(t *testing.T) {
+		service, pusher, _, cleanup := setup(t)
+		defer cleanup()
+
+		require.NoError(t, service.RegisterRoute(ctx, 200, "device-1", "gateway-2:50051"))
+		require.NoError(t, service.RegisterRoute(ctx, 200, "device-2", "gateway-2:50051"))
+		require.NoError(t, service.KeepAlive(ctx, 200, "device-1"))
+
+		events := pusher.presenceEvents()
+		require.Len(t, events, 1)
+		assert.Equal(t, float64(200), events[0]["user_id"])
+		assert.Equal(t, PresenceOnline, events[0]["status"])
+		assert.ElementsMatch(t, []int64{100}, pusher.pushes["gateway-1:50051"])
+	})
+
+	t.Run("offline is debounced", func(t *testing.T) {
+		service, pusher, _, cleanup := setup(t)
+		defer cleanup()
+
+		require.NoError(t, service.RegisterRoute(ctx, 200, "device-1", "gateway-2:50051"))
+		require.NoError(t, service.UnregisterRoute(ctx, 200, "device-1", "gateway-2:50051"))
+
+		now := time.Now()
+		require.NoError(t, service.SweepPresence(ctx, now))
+		assert.Len(t, pusher.presenceEvents(), 1)
+
+		require.NoError(t, service.SweepPresence(ctx, now.Add(DefaultPresenceDebounce+time.Second)))
+		events := pusher.presenceEvents()
+		require.Len(t, events, 2)
+		assert.Equal(t, PresenceOffline, events[1]["status"])
+		assert.InDelta(t, float64(now.Unix()), events[1]["last_seen"], 1)
+
+		// the event is published only once
+		require.NoError(t, service.SweepPresence(ctx, now.Add(2*DefaultPresenceDebounce)))
+		assert.Len(t, pusher.presenceEvents(), 2)
+	})
+
+	t.Run("reconnect within debounce is silent", func(t *testing.T) {
+		service, pusher, _, cleanup := setup(t)
+		defer cleanup()
+
+		require.NoError(t, service.RegisterRoute(ctx, 200, "device-1", "gateway-2:50051"))
+		require.NoError(t, service.UnregisterRoute(ctx, 200, "device-1", "gateway-2:50051"))
+		require.NoError(t, service.RegisterRoute(ctx, 200, "device-1", "gateway-3:50051"))
+
+		require.NoError(t, service.SweepPresence(ctx, time.Now().Add(DefaultPresenceDebounce+time.Second)))
+		assert.Len(t, pusher.presenceEvents(), 1)
+	})
+
+	t.Run("route expiry is detected", func(t *testing.T) {
+		service, pusher, client, cleanup := setup(t)
+		defer cleanup()
+
+		require.NoError(t, service.RegisterRoute(ctx, 200, "device-1", "gateway-2:50051"))
+		// simulate the route key expiring without an unregister
+		require.NoError(t, client.Del(ctx, "route:200").Err())
+
+		require.NoError(t, service.SweepPresence(ctx, time.Now()))
+		assert.Len(t, pusher.presenceEvents(), 1)
+
+		// user 100 keeps its route alive across the sweep
+		require.NoError(t, service.RegisterRoute(ctx, 100, "device-1", "gateway-1:50051"))
+
+		require.NoError(t, service.SweepPresence(ctx, time.Now().Add(defaultTTL+time.Second)))
+		events := pusher.presenceEvents()
+		require.Len(t, events, 2)
+		assert.Equal(t, float64(200), events[1]["user_id"])
+		assert.Equal(t, PresenceOffline, events[1]["status"])
+	})
+
+	t.Run("subscriptions end when the subscriber goes offline", func(t *testing.T) {
+		service, pusher, client, cleanup := setup(t)
+		defer cleanup()
+
+		require.NoError(t, service.UnregisterRoute(ctx, 100, "device-1", "gateway-1:50051"))
+		require.NoError(t, service.SweepPresence(ctx, time.Now().Add(DefaultPresenceDebounce+time.Second)))
+
+		subscribers, err := client.SMembers(ctx, presenceSubscribersKey(200)).Result()
+		require.NoError(t, err)
+		assert.Empty(t, subscribers)
+
+		exists, err := client.Exists(ctx, presenceWatchingKey(100)).Result()
+		require.NoError(t, err)
+		assert.Zero(t, exists)
+
+		require.NoError(t, service.RegisterRoute(ctx, 200, "device-1", "gateway-2:50051"))
+		assert.Empty(t, pusher.presenceEvents())
+	})
+}
+
+func TestService_SetPresence(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*Service, *MockGatewayPusher, func()) {
+		service, _, cleanup := setupTestService(t)
+		pusher := newMockGatewayPusher()
+		service.pusher = pusher
+
+		require.NoError(t, service.RegisterRoute(ctx, 100, "device-1", "gateway-1:50051"))
+		require.NoError(t, service.RegisterRoute(ctx, 200, "device-1", "gateway-2:50051"))
+		_, err := service.SubscribePresence(ctx, 100, []int64{200})
+		require.NoError(t, err)
+		return service, pusher, cleanup
+	}
+
+	t.Run("manual status and custom text", func(t *testing.T) {
+		service, pusher, cleanup := setup(t)
+		defer cleanup()
+
+		p, err := service.SetPresence(ctx, 200, PresenceBusy, "In a meeting", time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, PresenceBusy, p.Status)
+		assert.Equal(t, "In a meeting", p.CustomStatus)
+		assert.Greater(t, p.CustomStatusExpiresAt, time.Now().Unix())
+
+		events := pusher.presenceEvents()
+		require.Len(t, events, 1)
+		assert.Equal(t, PresenceBusy, events[0]["status"])
+		assert.Equal(t, "In a meeting", events[0]["custom_status"])
+
+		seen, err := service.GetPresence(ctx, 100, 200)
+		require.NoError(t, err)
+		assert.True(t, seen.Online)
+		assert.Equal(t, PresenceBusy, seen.Status)
+
+		// dropping the expiry is a visible change, repeating it is not
+		_, err = service.SetPresence(ctx, 200, PresenceBusy, "In a meeting", 0)
+		require.NoError(t, err)
+		assert.Len(t, pusher.presenceEvents(), 2)
+		_, err = service.SetPresence(ctx, 200, PresenceBusy, "In a meeting", 0)
+		require.NoError(t, err)
+		assert.Len(t, pusher.presenceEvents(), 2)
+	})
+
+	t.Run("custom status expires", func(t *testing.T) {
+		service, _, cleanup := setup(t)
+		defer cleanup()
+
+		_, err := service.SetPresence(ctx, 200, PresenceAway, "Lunch", time.Second)
+		require.NoError(t, err)
+		require.NoError(t, service.redis.HSet(ctx, presenceStateKey(200), "custom_status_expires_at", time.Now().Add(-time.Second).Unix()).Err())
+
+		p, err := service.GetPresence(ctx, 100, 200)
+		require.NoError(t, err)
+		assert.Equal(t, PresenceAway, p.Status)
+		assert.Empty(t, p.CustomStatus)
+		assert.Zero(t, p.CustomStatusExpiresAt)
+	})
+
+	t.Run("invisible users look offline but stay routable", func(t *testing.T) {
+		service, pusher, cleanup := setup(t)
+		defer cleanup()
+
+		_, err := service.SetPresence(ctx, 200, PresenceInvisible, "", 0)
+		require.NoError(t, err)
+
+		events := pusher.presenceEvents()
+		require.Len(t, events, 1)
+		assert.Equal(t, PresenceOffline, events[0]["status"])
+		assert.NotNil(t, events[0]["last_seen"])
+
+		other, err := service.GetPresence(ctx, 100, 200)
+		require.NoError(t, err)
+		assert.False(t, other.Online)
+		assert.Equal(t, PresenceOffline, other.Status)
+		assert.Empty(t, other.DeviceIDs)
+		assert.Greater(t, other.LastSeen, int64(0))
+
+		self, err := service.GetPresence(ctx, 200, 200)
+		require.NoError(t, err)
+		assert.True(t, self.Online)
+		assert.Equal(t, PresenceInvisible, self.Status)
+
+		routes, err := service.GetRoute(ctx, 200)
+		require.NoError(t, err)
+		assert.Len(t, routes, 1)
+
+		// disconnecting and reconnecting while invisible publishes nothing
+		require.NoError(t, service.UnregisterRoute(ctx, 200, "device-1", "gateway-2:50051"))
+		require.NoError(t, service.SweepPresence(ctx, time.Now().Add(DefaultPresenceDebounce+time.Second)))
+		require.NoError(t, service.RegisterRoute(ctx, 200, "device-1", "gateway-2:50051"))
+		assert.Len(t, pusher.presenceEvents(), 1)
+
+		after, err := service.GetPresence(ctx, 100, 200)
+		require.NoError(t, err)
+		assert.Equal(t, other.LastSeen, after.LastSeen)
+
+		// becoming visible again looks like coming online
+		_, err = service.SetPresence(ctx, 200, PresenceOnline, "", 0)
+		require.NoError(t, err)
+		events = pusher.presenceEvents()
+		require.Len(t, events, 2)
+		assert.Equal(t, PresenceOnline, events[1]["status"])
+	})
+
+	t.Run("last seen after going offline", func(t *testing.T) {
+		service, _, cleanup := setup(t)
+		defer cleanup()
+
+		require.NoError(t, service.UnregisterRoute(ctx, 200, "device-1", "gateway-2:50051"))
+
+		p, err := service.GetPresence(ctx, 100, 200)
+		require.NoError(t, err)
+		assert.Equal(t, PresenceOffline, p.Status)
+		assert.InDelta(t, time.Now().Unix(), p.LastSeen, 1)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		service, _, cleanup := setup(t)
+		defer cleanup()
+
+		_, err := service.SetPresence(ctx, 200, "sleeping", "", 0)
+		assert.ErrorIs(t, err, ErrInvalidPresenceStatus)
+
+		_, err = service.SetPresence(ctx, 200, PresenceAway, strings.Repeat("字", MaxCustomStatusLength+1), 0)
+		assert.ErrorIs(t, err, ErrCustomStatusTooLong)
+	})
+}
+
+func TestDevicePolicy_Evictions(t *testing.T) {
+	route := func(deviceID, platform string, connectedAt int64) *DeviceRoute {
+		return &DeviceRoute{DeviceID: deviceID, Platform: platform, ConnectedAt: connectedAt}
+	}
+
+	routes := []*DeviceRoute{
+		route("phone-old", "mobile", 1000),
+		route("laptop", "desktop", 2000),
+		route("phone-new", "mobile", 3000),
+		route("browser", "web", 4000),
+		route("unknown", "", 5000),
+	}
+
+	t.Run("no limits", func(t *testing.T) {
+		assert.Empty(t, DevicePolicy{}.evictions(routes))
+	})
+
+	t.Run("platform limit keeps the newest device", func(t *testing.T) {
+		policy := DevicePolicy{PlatformLimits: map[string]int{"mobile": 1, "desktop": 1}}
+		assert.Equal(t, []string{"phone-old"}, routeDeviceIDs(policy.evictions(routes)))
+	})
+
+	t.Run("max devices evicts the oldest", func(t *testing.T) {
+		policy := DevicePolicy{MaxDevices: 3}
+		assert.ElementsMatch(t, []string{"phone-old", "laptop"}, routeDeviceIDs(policy.evictions(routes)))
+	})
+
+	t.Run("both limits", func(t *testing.T) {
+		policy := DevicePolicy{MaxDevices: 3, PlatformLimits: map[string]int{"mobile": 1}}
+		// phone-old goes for the mobile limit, laptop for the overall limit
+		assert.ElementsMatch(t, []string{"phone-old", "laptop"}, routeDeviceIDs(policy.evictions(routes)))
+	})
+
+	t.Run("undeclared platform counts as unknown", func(t *testing.T) {
+		policy := DevicePolicy{PlatformLimits: map[string]int{PlatformUnknown: 1}}
+		undeclared := []*DeviceRoute{route("old", "", 1000), route("new", " ", 2000), route("phone", "mobile", 3000)}
+		assert.Equal(t, []string{"old"}, routeDeviceIDs(policy.evictions(undeclared)))
+	})
+
+	t.Run("ties are broken by device ID", func(t *testing.T) {
+		policy := DevicePolicy{PlatformLimits: map[string]int{"mobile": 1}}
+		tied := []*DeviceRoute{route("phone-a", "mobile", 1000), route("phone-b", "mobile", 1000)}
+		assert.Equal(t, []string{"phone-a"}, routeDeviceIDs(policy.evictions(tied)))
+	})
+}
+
+func TestService_RegisterDeviceRoute_DevicePolicy(t *testing.T) {
+	service, _, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	pusher := newMockGatewayPusher()
+	revoker := &mockTokenRevoker{}
+	service.pusher = pusher
+	service.WithTokenRevoker(revoker).WithDevicePolicy(DevicePolicy{
+		MaxDevices:     3,
+		PlatformLimits: map[string]int{"mobile": 1},
+	})
+
+	evicted, err := service.RegisterDeviceRoute(ctx, 100, "phone-1", "Mobile", "gateway-1:50051")
+	require.NoError(t, err)
+	assert.Empty(t, evicted)
+	time.Sleep(2 * time.Millisecond)
+
+	evicted, err = service.RegisterDeviceRoute(ctx, 100, "laptop", "desktop", "gateway-1:50051")
+	require.NoError(t, err)
+	assert.Empty(t, evicted)
+	time.Sleep(2 * time.Millisecond)
+
+	// A second mobile device replaces the first one
+	evicted, err = service.RegisterDeviceRoute(ctx, 100, "phone-2", "mobile", "gateway-2:50051")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"phone-1"}, routeDeviceIDs(evicted))
+	assert.Equal(t, []string{"phone-1"}, pusher.kicks["gateway-1:50051"])
+	assert.Equal(t, []string{KickReasonDeviceLimit}, pusher.kickReasons)
+	assert.Equal(t, []string{"phone-1"}, revoker.revoked)
+
+	routes, err := service.GetRoute(ctx, 100)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"laptop", "phone-2"}, routeDeviceIDs(routes))
+	for _, route := range routes {
+		if route.DeviceID == "phone-2" {
+			assert.Equal(t, "mobile", route.Platform)
+			assert.NotZero(t, route.ConnectedAt)
+		}
+	}
+
+	// Re-registering the same device is not an eviction
+	evicted, err = service.RegisterDeviceRoute(ctx, 100, "phone-2", "mobile", "gateway-1:50051")
+	require.NoError(t, err)
+	assert.Empty(t, evicted)
+	time.Sleep(2 * time.Millisecond)
+
+	// Devices without a platform are only bound by the overall limit
+	_, err = service.RegisterDeviceRoute(ctx, 100, "tablet", "", "gateway-1:50051")
+	require.NoError(t, err)
+	time.Sleep(2 * time.Millisecond)
+	evicted, err = service.RegisterDeviceRoute(ctx, 100, "browser", "web", "gateway-1:50051")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"laptop"}, routeDeviceIDs(evicted))
+
+	online, deviceIDs, err := service.GetOnlineStatus(ctx, 100)
+	require.NoError(t, err)
+	assert.True(t, online)
+	assert.ElementsMatch(t, []string{"phone-2", "tablet", "browser"}, deviceIDs)
+}
+
+func TestService_KickDevice(t *testing.T) {
+	service, _, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	pusher := newMockGatewayPusher()
+	revoker := &mockTokenRevoker{}
+	service.pusher = pusher
+	service.WithTokenRevoker(revoker)
+
+	require.NoError(t, service.RegisterRoute(ctx, 100, "device-001", "gateway-1:50051"))
+	require.NoError(t, service.RegisterRoute(ctx, 100, "device-002", "gateway-2:50051"))
+
+	kicked, err := service.KickDevice(ctx, 100, "device-002", "")
+	require.NoError(t, err)
+	assert.True(t, kicked)
+	assert.Equal(t, []string{"device-002"}, pusher.kicks["gateway-2:50051"])
+	assert.Equal(t, []string{KickReasonKicked}, pusher.kickReasons)
+	assert.Equal(t, []string{"device-002"}, revoker.revoked)
+
+	online, deviceIDs, err := service.GetOnlineStatus(ctx, 100)
+	require.NoError(t, err)
+	assert.True(t, online)
+	assert.Equal(t, []string{"device-001"}, deviceIDs)
+
+	// An offline device still has its token revoked
+	kicked, err = service.KickDevice(ctx, 100, "device-003", "")
+	require.NoError(t, err)
+	assert.False(t, kicked)
+	assert.Equal(t, []string{"device-002", "device-003"}, revoker.revoked)
+	assert.Len(t, pusher.kickReasons, 1)
+
+	// Kicking the last device takes the user offline
+	kicked, err = service.KickDevice(ctx, 100, "device-001", "")
+	require.NoError(t, err)
+	assert.True(t, kicked)
+
+	online, _, err = service.GetOnlineStatus(ctx, 100)
+	require.NoError(t, err)
+	assert.False(t, online)
+}
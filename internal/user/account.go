@@ -0,0 +1,202 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dollarkillerx/im-system/pkg/logger"
+	"github.com/dollarkillerx/im-system/pkg/mailer"
+	"go.uber.org/zap"
+)
+
+const accountTokenBytes = 32
+
+// defaultEmailVerifyResendInterval is used when no resend interval is configured
+const defaultEmailVerifyResendInterval = time.Minute
+
+var (
+	// ErrMailDisabled is returned by the email flows when no mailer is configured
+	ErrMailDisabled = errors.New("email delivery is not enabled")
+	// ErrInvalidAccountToken is returned for unknown, expired or already used
+	// password reset and email verification tokens
+	ErrInvalidAccountToken = errors.New("invalid or expired token")
+	// ErrEmailAlreadyVerified is returned when verifying a verified address
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+	// ErrVerificationEmailTooSoon is returned when a verification email is
+	// requested again before the resend interval has passed
+	ErrVerificationEmailTooSoon = errors.New("verification email sent recently")
+)
+
+// AccountEmails configures the password reset and email verification
+// emails. The URLs are templates whose %s is replaced by the token.
+type AccountEmails struct {
+	PasswordResetTTL time.Duration
+	PasswordResetURL string
+	EmailVerifyTTL   time.Duration
+	EmailVerifyURL   string
+	// EmailVerifyResendInterval is the minimum time between verification emails
+	EmailVerifyResendInterval time.Duration
+}
+
+// WithMailer enables password reset and email verification
+func (s *Service) WithMailer(m mailer.Mailer, emails AccountEmails) *Service {
+	s.mailer = m
+	s.emails = emails
+	return s
+}
+
+// RequestPasswordReset emails a password reset link. Unknown addresses are
+// not reported, so the call cannot be used to find registered emails.
+func (s *Service) RequestPasswordReset(ctx context.Context, email string) error {
+	if s.mailer == nil {
+		return ErrMailDisabled
+	}
+
+	user, err := s.repo.GetUserByEmail(ctx, strings.TrimSpace(email))
+	if errors.Is(err, ErrUserNotFound) {
+		logger.Log.Info("Password reset requested for unknown email")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	link, err := s.issueAccountToken(ctx, user, TokenPurposePasswordReset, s.emails.PasswordResetTTL, s.emails.PasswordResetURL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %s and can be used once.\n\n%s\n\nIf you did not ask for a password reset, you can ignore this email.",
+			user.Username, s.emails.PasswordResetTTL, link),
+	})
+}
+
+// ResetPassword sets a new password with a password reset token and signs
+// out every device of the user. The link was delivered to the account's
+// address, so the address is marked verified as well.
+func (s *Service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if err := s.checkPassword(newPassword); err != nil {
+		return err
+	}
+
+	consumed, err := s.repo.ConsumeAccountToken(ctx, TokenPurposePasswordReset, hashAccountToken(token))
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdatePassword(ctx, consumed.UserID, newPassword); err != nil {
+		return err
+	}
+
+	if _, err := s.repo.MarkEmailVerified(ctx, consumed.UserID, consumed.Email); err != nil {
+		logger.Log.Warn("Failed to mark email verified after password reset",
+			zap.Int64("user_id", consumed.UserID),
+			zap.Error(err),
+		)
+	}
+
+	logger.Log.Info("Password reset",
+		zap.Int64("user_id", consumed.UserID),
+	)
+
+	return s.endSessions(ctx, consumed.UserID, "")
+}
+
+// SendVerificationEmail emails a verification link to the address of the
+// token's user. Resends are limited to one per resend interval.
+func (s *Service) SendVerificationEmail(ctx context.Context, token string) error {
+	if s.mailer == nil {
+		return ErrMailDisabled
+	}
+
+	claims, err := s.validateClaims(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+
+	interval := s.emails.EmailVerifyResendInterval
+	if interval <= 0 {
+		interval = defaultEmailVerifyResendInterval
+	}
+	lastSent, err := s.repo.LastAccountTokenAt(ctx, user.ID, TokenPurposeEmailVerify)
+	if err != nil {
+		return err
+	}
+	if wait := interval - time.Since(lastSent); !lastSent.IsZero() && wait > 0 {
+		return fmt.Errorf("%w, retry after %s", ErrVerificationEmailTooSoon, wait.Round(time.Second))
+	}
+
+	link, err := s.issueAccountToken(ctx, user, TokenPurposeEmailVerify, s.emails.EmailVerifyTTL, s.emails.EmailVerifyURL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to confirm your email address. It expires in %s.\n\n%s",
+			user.Username, s.emails.EmailVerifyTTL, link),
+	})
+}
+
+// VerifyEmail confirms the address a verification token was sent to. The
+// token fails if the user has changed their email since.
+func (s *Service) VerifyEmail(ctx context.Context, token string) (int64, error) {
+	consumed, err := s.repo.ConsumeAccountToken(ctx, TokenPurposeEmailVerify, hashAccountToken(token))
+	if err != nil {
+		return 0, err
+	}
+
+	verified, err := s.repo.MarkEmailVerified(ctx, consumed.UserID, consumed.Email)
+	if err != nil {
+		return 0, err
+	}
+	if !verified {
+		return 0, ErrInvalidAccountToken
+	}
+
+	logger.Log.Info("Email verified",
+		zap.Int64("user_id", consumed.UserID),
+	)
+
+	return consumed.UserID, nil
+}
+
+// issueAccountToken stores a new single-use token and returns the link
+// carrying it
+func (s *Service) issueAccountToken(ctx context.Context, user *User, purpose string, ttl time.Duration, urlTemplate string) (string, error) {
+	buf := make([]byte, accountTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+
+	if err := s.repo.CreateAccountToken(ctx, user.ID, purpose, hashAccountToken(token), user.Email, time.Now().Add(ttl)); err != nil {
+		return "", err
+	}
+
+	return strings.Replace(urlTemplate, "%s", token, 1), nil
+}
+
+func hashAccountToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,178 @@
+package user
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/dollarkillerx/im-system/pkg/auth"
+	"github.com/dollarkillerx/im-system/pkg/mailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var tokenLinkPattern = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`)
+
+// recordingMailer keeps sent emails in memory
+type recordingMailer struct {
+	sent []*mailer.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg *mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// lastToken extracts the token from the link in the last email
+func (m *recordingMailer) lastToken(t *testing.T) string {
+	require.NotEmpty(t, m.sent)
+	match := tokenLinkPattern.FindStringSubmatch(m.sent[len(m.sent)-1].Body)
+	require.Len(t, match, 2)
+	return match[1]
+}
+
+func setupAccountService(t *testing.T) (*Service, *MockUserRepository, *recordingMailer) {
+	repo := newMockUserRepository()
+	repo.users["alice"] = &User{
+		ID:           1,
+		Username:     "alice",
+		PasswordHash: "hashed_old-password",
+		Email:        "alice@example.com",
+	}
+
+	mail := &recordingMailer{}
+	service := NewService(repo, auth.NewJWTManager("test-secret", time.Hour)).
+		WithMailer(mail, AccountEmails{
+			PasswordResetTTL: 30 * time.Minute,
+			PasswordResetURL: "https://im.example.com/reset-password?token=%s",
+			EmailVerifyTTL:   24 * time.Hour,
+			EmailVerifyURL:   "https://im.example.com/verify-email?token=%s",
+		})
+	return service, repo, mail
+}
+
+// accessToken issues an access token for a user
+func accessToken(t *testing.T, service *Service, userID int64) string {
+	token, _, err := service.jwtManager.Generate(userID, "device-001")
+	require.NoError(t, err)
+	return token
+}
+
+func TestService_PasswordReset(t *testing.T) {
+	service, repo, mail := setupAccountService(t)
+	ctx := context.Background()
+
+	require.NoError(t, service.RequestPasswordReset(ctx, "alice@example.com"))
+	require.Len(t, mail.sent, 1)
+	assert.Equal(t, "alice@example.com", mail.sent[0].To)
+	token := mail.lastToken(t)
+
+	// only the hash is stored
+	assert.NotContains(t, repo.accountTokens, token)
+	assert.Contains(t, repo.accountTokens, hashAccountToken(token))
+
+	assert.ErrorIs(t, service.ResetPassword(ctx, token, ""), ErrPasswordRequired)
+	require.NoError(t, service.ResetPassword(ctx, token, "new-password"))
+
+	_, _, _, err := service.Login(ctx, "alice", "new-password", "device-001", "")
+	assert.NoError(t, err)
+	assert.True(t, repo.users["alice"].EmailVerified)
+
+	// tokens are single-use
+	assert.ErrorIs(t, service.ResetPassword(ctx, token, "another-password"), ErrInvalidAccountToken)
+}
+
+func TestService_PasswordResetUnknownEmail(t *testing.T) {
+	service, _, mail := setupAccountService(t)
+
+	require.NoError(t, service.RequestPasswordReset(context.Background(), "nobody@example.com"))
+	assert.Empty(t, mail.sent)
+}
+
+func TestService_PasswordResetNewTokenReplacesOld(t *testing.T) {
+	service, _, mail := setupAccountService(t)
+	ctx := context.Background()
+
+	require.NoError(t, service.RequestPasswordReset(ctx, "alice@example.com"))
+	first := mail.lastToken(t)
+	require.NoError(t, service.RequestPasswordReset(ctx, "alice@example.com"))
+	second := mail.lastToken(t)
+
+	assert.ErrorIs(t, service.ResetPassword(ctx, first, "new-password"), ErrInvalidAccountToken)
+	assert.NoError(t, service.ResetPassword(ctx, second, "new-password"))
+}
+
+func TestService_PasswordResetExpired(t *testing.T) {
+	service, repo, mail := setupAccountService(t)
+	ctx := context.Background()
+
+	require.NoError(t, service.RequestPasswordReset(ctx, "alice@example.com"))
+	token := mail.lastToken(t)
+	repo.accountTokens[hashAccountToken(token)].expiresAt = time.Now().Add(-time.Second)
+
+	assert.ErrorIs(t, service.ResetPassword(ctx, token, "new-password"), ErrInvalidAccountToken)
+}
+
+func TestService_VerifyEmail(t *testing.T) {
+	service, repo, mail := setupAccountService(t)
+	ctx := context.Background()
+
+	require.NoError(t, service.SendVerificationEmail(ctx, accessToken(t, service, 1)))
+	token := mail.lastToken(t)
+
+	// a verification token cannot reset the password
+	assert.ErrorIs(t, service.ResetPassword(ctx, token, "new-password"), ErrInvalidAccountToken)
+
+	userID, err := service.VerifyEmail(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), userID)
+	assert.True(t, repo.users["alice"].EmailVerified)
+
+	_, err = service.VerifyEmail(ctx, token)
+	assert.ErrorIs(t, err, ErrInvalidAccountToken)
+
+	assert.ErrorIs(t, service.SendVerificationEmail(ctx, accessToken(t, service, 1)), ErrEmailAlreadyVerified)
+}
+
+func TestService_SendVerificationEmailRateLimit(t *testing.T) {
+	service, repo, mail := setupAccountService(t)
+	ctx := context.Background()
+	token := accessToken(t, service, 1)
+
+	assert.ErrorIs(t, service.SendVerificationEmail(ctx, "not-a-token"), ErrInvalidToken)
+	assert.Empty(t, mail.sent)
+
+	require.NoError(t, service.SendVerificationEmail(ctx, token))
+	assert.ErrorIs(t, service.SendVerificationEmail(ctx, token), ErrVerificationEmailTooSoon)
+	assert.Len(t, mail.sent, 1)
+
+	// a resend is allowed once the interval has passed
+	for _, sent := range repo.accountTokens {
+		sent.createdAt = time.Now().Add(-time.Minute)
+	}
+	require.NoError(t, service.SendVerificationEmail(ctx, token))
+	assert.Len(t, mail.sent, 2)
+}
+
+func TestService_VerifyEmailAfterAddressChange(t *testing.T) {
+	service, repo, mail := setupAccountService(t)
+	ctx := context.Background()
+
+	require.NoError(t, service.SendVerificationEmail(ctx, accessToken(t, service, 1)))
+	token := mail.lastToken(t)
+
+	repo.users["alice"].Email = "alice@new.example.com"
+
+	_, err := service.VerifyEmail(ctx, token)
+	assert.ErrorIs(t, err, ErrInvalidAccountToken)
+	assert.False(t, repo.users["alice"].EmailVerified)
+}
+
+func TestService_MailDisabled(t *testing.T) {
+	service := NewService(newMockUserRepository(), auth.NewJWTManager("test-secret", time.Hour))
+	ctx := context.Background()
+
+	assert.ErrorIs(t, service.RequestPasswordReset(ctx, "alice@example.com"), ErrMailDisabled)
+	assert.ErrorIs(t, service.SendVerificationEmail(ctx, "token"), ErrMailDisabled)
+}
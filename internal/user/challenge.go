@@ -0,0 +1,133 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// loginChallengeKeyPrefix maps a challenge token hash to a hash with the
+	// user, device and failed attempts of a login waiting for its second factor
+	loginChallengeKeyPrefix = "login_challenge:"
+
+	challengeTokenBytes = 32
+
+	defaultChallengeTTL         = 5 * time.Minute
+	defaultChallengeMaxAttempts = 5
+)
+
+// ErrInvalidChallenge is returned for unknown, expired or used challenge tokens
+var ErrInvalidChallenge = errors.New("invalid or expired login challenge")
+
+// challengeFailScript counts a failed code and deletes the challenge once
+// it reaches the attempt limit. Returns the remaining attempts, or -1 if
+// the challenge no longer exists.
+var challengeFailScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+  return -1
+end
+local attempts = redis.call('HINCRBY', KEYS[1], 'attempts', 1)
+local remaining = tonumber(ARGV[1]) - attempts
+if remaining <= 0 then
+  redis.call('DEL', KEYS[1])
+  return 0
+end
+return remaining
+`)
+
+// ChallengeStore keeps the short-lived challenges of logins that passed the
+// password check and wait for a second factor. Only token hashes are stored.
+type ChallengeStore struct {
+	redis       *redis.Client
+	ttl         time.Duration
+	maxAttempts int
+}
+
+// NewChallengeStore creates a challenge store. A challenge expires after ttl
+// or after maxAttempts wrong codes, whichever comes first.
+func NewChallengeStore(redisClient *redis.Client, ttl time.Duration, maxAttempts int) *ChallengeStore {
+	if ttl <= 0 {
+		ttl = defaultChallengeTTL
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = defaultChallengeMaxAttempts
+	}
+	return &ChallengeStore{
+		redis:       redisClient,
+		ttl:         ttl,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Issue creates a challenge for a device's login
+func (s *ChallengeStore) Issue(ctx context.Context, userID int64, deviceID string) (string, time.Time, error) {
+	buf := make([]byte, challengeTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate challenge token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	key := loginChallengeKeyPrefix + hashAccountToken(token)
+
+	pipe := s.redis.TxPipeline()
+	pipe.HSet(ctx, key, "user_id", userID, "device_id", deviceID, "attempts", 0)
+	pipe.Expire(ctx, key, s.ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to store challenge: %w", err)
+	}
+
+	return token, time.Now().Add(s.ttl), nil
+}
+
+// Get returns the user and device a challenge was issued to
+func (s *ChallengeStore) Get(ctx context.Context, token string) (int64, string, error) {
+	fields, err := s.redis.HMGet(ctx, loginChallengeKeyPrefix+hashAccountToken(token), "user_id", "device_id").Result()
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get challenge: %w", err)
+	}
+
+	userIDVal, _ := fields[0].(string)
+	deviceID, _ := fields[1].(string)
+	if userIDVal == "" {
+		return 0, "", ErrInvalidChallenge
+	}
+
+	userID, err := strconv.ParseInt(userIDVal, 10, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid challenge: %w", err)
+	}
+
+	return userID, deviceID, nil
+}
+
+// Fail counts a wrong code and returns how many attempts are left
+func (s *ChallengeStore) Fail(ctx context.Context, token string) (int, error) {
+	remaining, err := challengeFailScript.Run(ctx, s.redis,
+		[]string{loginChallengeKeyPrefix + hashAccountToken(token)}, s.maxAttempts).Int()
+	if err != nil {
+		return 0, fmt.Errorf("failed to record challenge attempt: %w", err)
+	}
+	if remaining < 0 {
+		return 0, ErrInvalidChallenge
+	}
+	return remaining, nil
+}
+
+// Consume deletes a challenge, returning ErrInvalidChallenge if it was
+// already used, so that one challenge completes at most one login
+func (s *ChallengeStore) Consume(ctx context.Context, token string) error {
+	deleted, err := s.redis.Del(ctx, loginChallengeKeyPrefix+hashAccountToken(token)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to consume challenge: %w", err)
+	}
+	if deleted == 0 {
+		return ErrInvalidChallenge
+	}
+	return nil
+}
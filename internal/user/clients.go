@@ -0,0 +1,70 @@
+package user
+
+import (
+	"context"
+	"fmt"
+
+	routerpb "github.com/dollarkillerx/im-system/api/proto/router"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// ServiceDiscovery resolves the address of a service
+type ServiceDiscovery interface {
+	GetServiceAddress(serviceName string) (string, error)
+}
+
+// RouterClient kicks devices through the Router service, which tells their
+// gateways to send KICKED and close the stream
+type RouterClient struct {
+	discovery ServiceDiscovery
+}
+
+// NewRouterClient creates a Router client
+func NewRouterClient(discovery ServiceDiscovery) *RouterClient {
+	return &RouterClient{discovery: discovery}
+}
+
+// KickDevices kicks every connected device of a user except exceptDeviceID
+func (c *RouterClient) KickDevices(ctx context.Context, userID int64, exceptDeviceID, reason string) (int, error) {
+	addr, err := c.discovery.GetServiceAddress("router-service")
+	if err != nil {
+		return 0, fmt.Errorf("failed to discover router service: %w", err)
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to router service: %w", err)
+	}
+	defer conn.Close()
+
+	client := routerpb.NewRouterServiceClient(conn)
+
+	resp, err := client.GetRoute(ctx, &routerpb.GetRouteRequest{
+		UserId: userID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get routes: %w", err)
+	}
+
+	kicked := 0
+	for _, route := range resp.Routes {
+		if route.DeviceId == exceptDeviceID {
+			continue
+		}
+
+		kickResp, err := client.KickDevice(ctx, &routerpb.KickDeviceRequest{
+			UserId:   userID,
+			DeviceId: route.DeviceId,
+			Reason:   reason,
+		})
+		if err != nil {
+			return kicked, fmt.Errorf("failed to kick device %s: %w", route.DeviceId, err)
+		}
+		if kickResp.Kicked {
+			kicked++
+		}
+	}
+
+	return kicked, nil
+}
@@ -2,23 +2,53 @@ package user
 
 import (
 	"context"
+	"errors"
+	"net"
+	"strings"
 
 	userpb "github.com/dollarkillerx/im-system/api/proto/user"
+	"github.com/dollarkillerx/im-system/pkg/auth"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// forwardedForHeader carries the client IP when the service sits behind a proxy
+const forwardedForHeader = "x-forwarded-for"
+
+// ErrorInfo reasons that tell a locked login apart from a delayed one
+const (
+	errorDomain               = "im-system"
+	errorReasonLoginThrottled = "LOGIN_THROTTLED"
+	errorReasonLoginLocked    = "LOGIN_LOCKED"
 )
 
 type GRPCServer struct {
 	userpb.UnimplementedUserServiceServer
-	service *Service
+	service           *Service
+	trustForwardedFor bool
 }
 
 func NewGRPCServer(service *Service) *GRPCServer {
 	return &GRPCServer{service: service}
 }
 
+// WithTrustForwardedFor takes the client IP from the x-forwarded-for header.
+// Only enable it behind a proxy that sets the header, otherwise clients can
+// pick their own IP and evade per-IP login throttling.
+func (s *GRPCServer) WithTrustForwardedFor(trust bool) *GRPCServer {
+	s.trustForwardedFor = trust
+	return s
+}
+
 func (s *GRPCServer) Register(ctx context.Context, req *userpb.RegisterRequest) (*userpb.RegisterResponse, error) {
 	userID, err := s.service.Register(ctx, req.Username, req.Password, req.Email, req.Nickname)
+	if errors.Is(err, ErrPasswordRequired) || errors.Is(err, ErrWeakPassword) {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to register: %v", err)
 	}
@@ -30,25 +60,43 @@ func (s *GRPCServer) Register(ctx context.Context, req *userpb.RegisterRequest)
 }
 
 func (s *GRPCServer) Login(ctx context.Context, req *userpb.LoginRequest) (*userpb.LoginResponse, error) {
-	userID, token, expiresAt, user, err := s.service.Login(ctx, req.Username, req.Password, req.DeviceId)
+	userID, session, user, err := s.service.Login(ctx, req.Username, req.Password, req.DeviceId, s.clientIP(ctx))
+	var challenge *TwoFactorChallengeError
+	if errors.As(err, &challenge) {
+		return &userpb.LoginResponse{
+			TwoFactorRequired:  true,
+			ChallengeToken:     challenge.Challenge,
+			ChallengeExpiresAt: challenge.ExpiresAt.Unix(),
+		}, nil
+	}
+	var throttled *LoginThrottledError
+	if errors.As(err, &throttled) {
+		return nil, loginThrottledStatus(throttled)
+	}
+	if errors.Is(err, ErrTwoFactorUnavailable) {
+		return nil, status.Errorf(codes.Unavailable, "login failed: %v", err)
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Unauthenticated, "login failed: %v", err)
 	}
 
-	return &userpb.LoginResponse{
-		UserId:    userID,
-		Token:     token,
-		ExpiresAt: expiresAt,
-		UserInfo: &userpb.UserInfo{
-			UserId:    user.ID,
-			Username:  user.Username,
-			Nickname:  user.Nickname,
-			Email:     user.Email,
-			Avatar:    user.Avatar,
-			Bio:       user.Bio,
-			CreatedAt: user.CreatedAt.Unix(),
-		},
-	}, nil
+	return loginResponse(userID, session, user), nil
+}
+
+func (s *GRPCServer) LoginTwoFactor(ctx context.Context, req *userpb.LoginTwoFactorRequest) (*userpb.LoginResponse, error) {
+	userID, session, user, err := s.service.LoginTwoFactor(ctx, req.ChallengeToken, req.Code, s.clientIP(ctx))
+	var throttled *LoginThrottledError
+	if errors.As(err, &throttled) {
+		return nil, loginThrottledStatus(throttled)
+	}
+	if errors.Is(err, ErrInvalidChallenge) || errors.Is(err, ErrInvalidTwoFactorCode) {
+		return nil, status.Errorf(codes.Unauthenticated, "login failed: %v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "login failed: %v", err)
+	}
+
+	return loginResponse(userID, session, user), nil
 }
 
 func (s *GRPCServer) GetUserInfo(ctx context.Context, req *userpb.GetUserInfoRequest) (*userpb.GetUserInfoResponse, error) {
@@ -59,13 +107,15 @@ func (s *GRPCServer) GetUserInfo(ctx context.Context, req *userpb.GetUserInfoReq
 
 	return &userpb.GetUserInfoResponse{
 		UserInfo: &userpb.UserInfo{
-			UserId:    user.ID,
-			Username:  user.Username,
-			Nickname:  user.Nickname,
-			Email:     user.Email,
-			Avatar:    user.Avatar,
-			Bio:       user.Bio,
-			CreatedAt: user.CreatedAt.Unix(),
+			UserId:           user.ID,
+			Username:         user.Username,
+			Nickname:         user.Nickname,
+			Email:            user.Email,
+			EmailVerified:    user.EmailVerified,
+			TwoFactorEnabled: user.TwoFactorEnabled,
+			Avatar:           user.Avatar,
+			Bio:              user.Bio,
+			CreatedAt:        user.CreatedAt.Unix(),
 		},
 	}, nil
 }
@@ -108,3 +158,234 @@ func (s *GRPCServer) ValidateToken(ctx context.Context, req *userpb.ValidateToke
 		DeviceId: deviceID,
 	}, nil
 }
+
+func (s *GRPCServer) RefreshToken(ctx context.Context, req *userpb.RefreshTokenRequest) (*userpb.RefreshTokenResponse, error) {
+	session, err := s.service.RefreshToken(ctx, req.RefreshToken)
+	if errors.Is(err, ErrRefreshDisabled) {
+		return nil, status.Errorf(codes.Unimplemented, "%v", err)
+	}
+	if errors.Is(err, auth.ErrInvalidRefreshToken) || errors.Is(err, auth.ErrRefreshTokenReused) {
+		return nil, status.Errorf(codes.Unauthenticated, "%v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to refresh token: %v", err)
+	}
+
+	return &userpb.RefreshTokenResponse{
+		Token:            session.AccessToken,
+		ExpiresAt:        session.ExpiresAt,
+		RefreshToken:     session.RefreshToken,
+		RefreshExpiresAt: session.RefreshExpiresAt,
+	}, nil
+}
+
+func (s *GRPCServer) Logout(ctx context.Context, req *userpb.LogoutRequest) (*userpb.LogoutResponse, error) {
+	err := s.service.Logout(ctx, req.Token)
+	if errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrTokenRevoked) {
+		return nil, status.Errorf(codes.Unauthenticated, "logout failed: %v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "logout failed: %v", err)
+	}
+
+	return &userpb.LogoutResponse{Success: true}, nil
+}
+
+func (s *GRPCServer) RequestPasswordReset(ctx context.Context, req *userpb.RequestPasswordResetRequest) (*userpb.RequestPasswordResetResponse, error) {
+	err := s.service.RequestPasswordReset(ctx, req.Email)
+	if errors.Is(err, ErrMailDisabled) {
+		return nil, status.Errorf(codes.Unimplemented, "%v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to request password reset: %v", err)
+	}
+
+	return &userpb.RequestPasswordResetResponse{Success: true}, nil
+}
+
+func (s *GRPCServer) ResetPassword(ctx context.Context, req *userpb.ResetPasswordRequest) (*userpb.ResetPasswordResponse, error) {
+	err := s.service.ResetPassword(ctx, req.Token, req.NewPassword)
+	if errors.Is(err, ErrPasswordRequired) || errors.Is(err, ErrWeakPassword) {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	if errors.Is(err, ErrInvalidAccountToken) {
+		return nil, status.Errorf(codes.Unauthenticated, "%v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to reset password: %v", err)
+	}
+
+	return &userpb.ResetPasswordResponse{Success: true}, nil
+}
+
+func (s *GRPCServer) SendVerificationEmail(ctx context.Context, req *userpb.SendVerificationEmailRequest) (*userpb.SendVerificationEmailResponse, error) {
+	err := s.service.SendVerificationEmail(ctx, req.Token)
+	if errors.Is(err, ErrMailDisabled) {
+		return nil, status.Errorf(codes.Unimplemented, "%v", err)
+	}
+	if errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrTokenRevoked) {
+		return nil, status.Errorf(codes.Unauthenticated, "%v", err)
+	}
+	if errors.Is(err, ErrVerificationEmailTooSoon) {
+		return nil, status.Errorf(codes.ResourceExhausted, "%v", err)
+	}
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, status.Errorf(codes.NotFound, "%v", err)
+	}
+	if errors.Is(err, ErrEmailAlreadyVerified) {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to send verification email: %v", err)
+	}
+
+	return &userpb.SendVerificationEmailResponse{Success: true}, nil
+}
+
+func (s *GRPCServer) VerifyEmail(ctx context.Context, req *userpb.VerifyEmailRequest) (*userpb.VerifyEmailResponse, error) {
+	userID, err := s.service.VerifyEmail(ctx, req.Token)
+	if errors.Is(err, ErrInvalidAccountToken) {
+		return nil, status.Errorf(codes.Unauthenticated, "%v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to verify email: %v", err)
+	}
+
+	return &userpb.VerifyEmailResponse{Success: true, UserId: userID}, nil
+}
+
+func (s *GRPCServer) ChangePassword(ctx context.Context, req *userpb.ChangePasswordRequest) (*userpb.ChangePasswordResponse, error) {
+	err := s.service.ChangePassword(ctx, req.Token, req.OldPassword, req.NewPassword)
+	if errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrTokenRevoked) {
+		return nil, status.Errorf(codes.Unauthenticated, "%v", err)
+	}
+	if errors.Is(err, ErrInvalidCredentials) {
+		return nil, status.Errorf(codes.PermissionDenied, "%v", err)
+	}
+	if errors.Is(err, ErrPasswordRequired) || errors.Is(err, ErrWeakPassword) {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to change password: %v", err)
+	}
+
+	return &userpb.ChangePasswordResponse{Success: true}, nil
+}
+
+func (s *GRPCServer) EnrollTOTP(ctx context.Context, req *userpb.EnrollTOTPRequest) (*userpb.EnrollTOTPResponse, error) {
+	secret, uri, err := s.service.EnrollTOTP(ctx, req.Token)
+	if err != nil {
+		return nil, twoFactorStatus("failed to enroll authenticator", err)
+	}
+
+	return &userpb.EnrollTOTPResponse{
+		Secret:     secret,
+		OtpauthUri: uri,
+	}, nil
+}
+
+func (s *GRPCServer) ConfirmTOTP(ctx context.Context, req *userpb.ConfirmTOTPRequest) (*userpb.ConfirmTOTPResponse, error) {
+	recoveryCodes, err := s.service.ConfirmTOTP(ctx, req.Token, req.Code)
+	if err != nil {
+		return nil, twoFactorStatus("failed to confirm authenticator", err)
+	}
+
+	return &userpb.ConfirmTOTPResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (s *GRPCServer) DisableTOTP(ctx context.Context, req *userpb.DisableTOTPRequest) (*userpb.DisableTOTPResponse, error) {
+	if err := s.service.DisableTOTP(ctx, req.Token, req.Code); err != nil {
+		return nil, twoFactorStatus("failed to disable two-factor authentication", err)
+	}
+
+	return &userpb.DisableTOTPResponse{Success: true}, nil
+}
+
+func (s *GRPCServer) RegenerateRecoveryCodes(ctx context.Context, req *userpb.RegenerateRecoveryCodesRequest) (*userpb.RegenerateRecoveryCodesResponse, error) {
+	recoveryCodes, err := s.service.RegenerateRecoveryCodes(ctx, req.Token, req.Code)
+	if err != nil {
+		return nil, twoFactorStatus("failed to regenerate recovery codes", err)
+	}
+
+	return &userpb.RegenerateRecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// loginResponse builds the response of a completed login
+func loginResponse(userID int64, session *Session, user *User) *userpb.LoginResponse {
+	return &userpb.LoginResponse{
+		UserId:           userID,
+		Token:            session.AccessToken,
+		ExpiresAt:        session.ExpiresAt,
+		RefreshToken:     session.RefreshToken,
+		RefreshExpiresAt: session.RefreshExpiresAt,
+		UserInfo: &userpb.UserInfo{
+			UserId:           user.ID,
+			Username:         user.Username,
+			Nickname:         user.Nickname,
+			Email:            user.Email,
+			EmailVerified:    user.EmailVerified,
+			TwoFactorEnabled: user.TwoFactorEnabled,
+			Avatar:           user.Avatar,
+			Bio:              user.Bio,
+			CreatedAt:        user.CreatedAt.Unix(),
+		},
+	}
+}
+
+// twoFactorStatus maps errors of the 2FA management calls to gRPC statuses
+func twoFactorStatus(msg string, err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrTokenRevoked):
+		return status.Errorf(codes.Unauthenticated, "%v", err)
+	case errors.Is(err, ErrInvalidTwoFactorCode):
+		return status.Errorf(codes.PermissionDenied, "%v", err)
+	case errors.Is(err, ErrTwoFactorEnabled) || errors.Is(err, ErrTwoFactorNotEnabled) || errors.Is(err, ErrTOTPNotEnrolled):
+		return status.Errorf(codes.FailedPrecondition, "%v", err)
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", msg, err)
+	}
+}
+
+// loginThrottledStatus reports a throttled login as ResourceExhausted with a
+// RetryInfo detail telling the client when to try again, and an ErrorInfo
+// whose reason is LOGIN_LOCKED once the lockout limit is reached
+func loginThrottledStatus(err *LoginThrottledError) error {
+	reason := errorReasonLoginThrottled
+	if err.Locked {
+		reason = errorReasonLoginLocked
+	}
+
+	st := status.New(codes.ResourceExhausted, err.Error())
+	detailed, detailErr := st.WithDetails(
+		&errdetails.ErrorInfo{Reason: reason, Domain: errorDomain},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(err.RetryAfter)},
+	)
+	if detailErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// clientIP returns the IP of the calling client, or "" if unknown
+func (s *GRPCServer) clientIP(ctx context.Context) string {
+	if s.trustForwardedFor {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(forwardedForHeader); len(values) > 0 {
+				first, _, _ := strings.Cut(values[0], ",")
+				if ip := strings.TrimSpace(first); ip != "" {
+					return ip
+				}
+			}
+		}
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
@@ -2,6 +2,7 @@ package user
 
 import (
 	"context"
+	"time"
 )
 
 // UserRepository defines the interface for user data persistence
@@ -20,4 +21,50 @@ type UserRepository interface {
 
 	// VerifyPassword verifies if the provided password matches the hashed password
 	VerifyPassword(hashedPassword, password string) error
+
+	// GetUserByEmail retrieves a user by email (excludes password hash)
+	GetUserByEmail(ctx context.Context, email string) (*User, error)
+
+	// UpdatePassword hashes and stores a new password
+	UpdatePassword(ctx context.Context, userID int64, password string) error
+
+	// MarkEmailVerified marks the email as verified if the user still has that address
+	MarkEmailVerified(ctx context.Context, userID int64, email string) (bool, error)
+
+	// CreateAccountToken stores a hashed single-use token, replacing unused tokens of the same purpose
+	CreateAccountToken(ctx context.Context, userID int64, purpose, tokenHash, email string, expiresAt time.Time) error
+
+	// LastAccountTokenAt returns when the latest token of a purpose was created, or the zero time
+	LastAccountTokenAt(ctx context.Context, userID int64, purpose string) (time.Time, error)
+
+	// ConsumeAccountToken marks a valid token as used, returning ErrInvalidAccountToken otherwise
+	ConsumeAccountToken(ctx context.Context, purpose, tokenHash string) (*AccountToken, error)
+
+	// GetTOTP retrieves the user's TOTP secret, whether 2FA is enabled and the last used time step
+	GetTOTP(ctx context.Context, userID int64) (*TOTPState, error)
+
+	// SetTOTPSecret stores a pending TOTP secret, returning false if 2FA is already enabled
+	SetTOTPSecret(ctx context.Context, userID int64, secret string) (bool, error)
+
+	// EnableTOTP activates the pending secret with the given recovery code hashes, returning false if none is pending
+	EnableTOTP(ctx context.Context, userID, step int64, codeHashes []string) (bool, error)
+
+	// DisableTOTP removes the TOTP secret and recovery codes
+	DisableTOTP(ctx context.Context, userID int64) error
+
+	// UseTOTPStep records a time step as used, returning false if it is not newer than the last one
+	UseTOTPStep(ctx context.Context, userID, step int64) (bool, error)
+
+	// ReplaceRecoveryCodes replaces all recovery codes with the given hashes
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error
+
+	// ConsumeRecoveryCode marks an unused recovery code as used, returning false if there is none
+	ConsumeRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error)
+}
+
+// DeviceKicker disconnects the live gateway streams of a user's devices
+type DeviceKicker interface {
+	// KickDevices kicks every connected device of the user except
+	// exceptDeviceID and returns how many were kicked
+	KickDevices(ctx context.Context, userID int64, exceptDeviceID, reason string) (int, error)
 }
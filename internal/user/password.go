@@ -0,0 +1,165 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dollarkillerx/im-system/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// KickReasonPasswordChanged is sent to devices signed out by a password change
+const KickReasonPasswordChanged = "password_changed"
+
+// maxPasswordBytes is the longest password bcrypt accepts
+const maxPasswordBytes = 72
+
+var (
+	// ErrInvalidCredentials is returned for a wrong username or password
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrPasswordRequired is returned when a new password is empty
+	ErrPasswordRequired = errors.New("password is required")
+	// ErrWeakPassword is returned when a new password violates the password policy
+	ErrWeakPassword = errors.New("password does not meet the password policy")
+)
+
+// PasswordPolicy is the set of rules new passwords must satisfy. Lengths
+// count characters; zero values disable a rule. Independent of the policy,
+// passwords longer than 72 bytes are rejected because bcrypt cannot hash
+// them, which matters for multi-byte characters.
+type PasswordPolicy struct {
+	MinLength     int
+	MaxLength     int
+	RequireLetter bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// Validate checks a password against the policy
+func (p PasswordPolicy) Validate(password string) error {
+	length := utf8.RuneCountInString(password)
+	if p.MinLength > 0 && length < p.MinLength {
+		return fmt.Errorf("%w: must be at least %d characters", ErrWeakPassword, p.MinLength)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		return fmt.Errorf("%w: must be at most %d characters", ErrWeakPassword, p.MaxLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return fmt.Errorf("%w: must be at most %d bytes", ErrWeakPassword, maxPasswordBytes)
+	}
+
+	var hasLetter, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireLetter && !hasLetter {
+		return fmt.Errorf("%w: must contain a letter", ErrWeakPassword)
+	}
+	if p.RequireDigit && !hasDigit {
+		return fmt.Errorf("%w: must contain a digit", ErrWeakPassword)
+	}
+	if p.RequireSymbol && !hasSymbol {
+		return fmt.Errorf("%w: must contain a symbol", ErrWeakPassword)
+	}
+
+	return nil
+}
+
+// WithPasswordPolicy sets the rules for new passwords
+func (s *Service) WithPasswordPolicy(policy PasswordPolicy) *Service {
+	s.passwordPolicy = policy
+	return s
+}
+
+// WithDeviceKicker sets the kicker used to disconnect signed out devices
+func (s *Service) WithDeviceKicker(kicker DeviceKicker) *Service {
+	s.kicker = kicker
+	return s
+}
+
+// ChangePassword replaces the password of the token's user after checking
+// the old one. Every other device of the user is signed out: its tokens and
+// refresh session are revoked and its live gateway streams are closed. The
+// device making the change stays signed in.
+func (s *Service) ChangePassword(ctx context.Context, token, oldPassword, newPassword string) error {
+	claims, err := s.validateClaims(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	// GetUserByID leaves out the password hash
+	credentials, err := s.repo.GetUserByUsername(ctx, user.Username)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.VerifyPassword(credentials.PasswordHash, oldPassword); err != nil {
+		return ErrInvalidCredentials
+	}
+
+	if err := s.checkPassword(newPassword); err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdatePassword(ctx, user.ID, newPassword); err != nil {
+		return err
+	}
+
+	logger.Log.Info("Password changed",
+		zap.Int64("user_id", user.ID),
+		zap.String("device_id", claims.DeviceID),
+	)
+
+	return s.endSessions(ctx, user.ID, claims.DeviceID)
+}
+
+// checkPassword validates a new password against the policy
+func (s *Service) checkPassword(password string) error {
+	if password == "" {
+		return ErrPasswordRequired
+	}
+	return s.passwordPolicy.Validate(password)
+}
+
+// endSessions revokes the tokens and refresh sessions of all of a user's
+// devices except keepDeviceID and disconnects them. Disconnecting is best
+// effort: revoked devices cannot reconnect or call services anyway.
+func (s *Service) endSessions(ctx context.Context, userID int64, keepDeviceID string) error {
+	if s.revocation != nil {
+		if err := s.revocation.RevokeUser(ctx, userID, keepDeviceID); err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+	}
+
+	if s.kicker != nil {
+		kicked, err := s.kicker.KickDevices(ctx, userID, keepDeviceID, KickReasonPasswordChanged)
+		if err != nil {
+			logger.Log.Warn("Failed to disconnect signed out devices",
+				zap.Int64("user_id", userID),
+				zap.Error(err),
+			)
+		} else if kicked > 0 {
+			logger.Log.Info("Signed out devices disconnected",
+				zap.Int64("user_id", userID),
+				zap.Int("device_count", kicked),
+			)
+		}
+	}
+
+	return nil
+}
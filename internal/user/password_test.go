@@ -0,0 +1,136 @@
+package user
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dollarkillerx/im-system/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingKicker records kick requests
+type recordingKicker struct {
+	userID         int64
+	exceptDeviceID string
+	reason         string
+	calls          int
+}
+
+func (k *recordingKicker) KickDevices(ctx context.Context, userID int64, exceptDeviceID, reason string) (int, error) {
+	k.userID = userID
+	k.exceptDeviceID = exceptDeviceID
+	k.reason = reason
+	k.calls++
+	return 1, nil
+}
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	policy := PasswordPolicy{
+		MinLength:     8,
+		MaxLength:     16,
+		RequireLetter: true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{name: "valid", password: "abc123!x", wantErr: false},
+		{name: "too short", password: "ab1!", wantErr: true},
+		{name: "too long", password: "abcdefgh12345678!", wantErr: true},
+		{name: "no letter", password: "12345678!", wantErr: true},
+		{name: "no digit", password: "abcdefgh!", wantErr: true},
+		{name: "no symbol", password: "abcdefgh1", wantErr: true},
+		{name: "multibyte characters count once", password: "密码密码密码1!", wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrWeakPassword)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	// the zero policy accepts anything bcrypt can hash
+	assert.NoError(t, PasswordPolicy{}.Validate("x"))
+	assert.NoError(t, PasswordPolicy{}.Validate(strings.Repeat("a", 72)))
+	assert.ErrorIs(t, PasswordPolicy{}.Validate(strings.Repeat("a", 73)), ErrWeakPassword)
+
+	// 30 characters fit a 64 character limit, but as CJK they take 88 bytes
+	cjk := strings.Repeat("密", 29) + "1"
+	assert.ErrorIs(t, PasswordPolicy{MaxLength: 64}.Validate(cjk), ErrWeakPassword)
+}
+
+func TestService_ChangePassword(t *testing.T) {
+	kicker := &recordingKicker{}
+	service := setupTokenService(t).
+		WithPasswordPolicy(PasswordPolicy{MinLength: 8}).
+		WithDeviceKicker(kicker)
+	ctx := context.Background()
+
+	_, current, _, err := service.Login(ctx, "testuser", "password123", "device-001", "")
+	require.NoError(t, err)
+	_, other, _, err := service.Login(ctx, "testuser", "password123", "device-002", "")
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, service.ChangePassword(ctx, current.AccessToken, "wrong-password", "new-password"), ErrInvalidCredentials)
+	assert.ErrorIs(t, service.ChangePassword(ctx, current.AccessToken, "password123", "short"), ErrWeakPassword)
+	assert.Equal(t, 0, kicker.calls)
+
+	require.NoError(t, service.ChangePassword(ctx, current.AccessToken, "password123", "new-password"))
+
+	// the new password is in effect
+	_, _, _, err = service.Login(ctx, "testuser", "password123", "device-003", "")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	// the device that made the change stays signed in
+	_, _, err = service.ValidateToken(ctx, current.AccessToken)
+	assert.NoError(t, err)
+	_, err = service.RefreshToken(ctx, current.RefreshToken)
+	assert.NoError(t, err)
+
+	// every other device is signed out and disconnected
+	_, _, err = service.ValidateToken(ctx, other.AccessToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+	_, err = service.RefreshToken(ctx, other.RefreshToken)
+	assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+
+	assert.Equal(t, 1, kicker.calls)
+	assert.Equal(t, int64(100), kicker.userID)
+	assert.Equal(t, "device-001", kicker.exceptDeviceID)
+	assert.Equal(t, KickReasonPasswordChanged, kicker.reason)
+}
+
+func TestService_ResetPasswordSignsOutAllDevices(t *testing.T) {
+	kicker := &recordingKicker{}
+	mail := &recordingMailer{}
+	service := setupTokenService(t).
+		WithDeviceKicker(kicker).
+		WithMailer(mail, AccountEmails{
+			PasswordResetTTL: 30 * time.Minute,
+			PasswordResetURL: "https://im.example.com/reset-password?token=%s",
+		})
+	service.repo.(*MockUserRepository).users["testuser"].Email = "test@example.com"
+	ctx := context.Background()
+
+	_, session, _, err := service.Login(ctx, "testuser", "password123", "device-001", "")
+	require.NoError(t, err)
+
+	require.NoError(t, service.RequestPasswordReset(ctx, "test@example.com"))
+	require.NoError(t, service.ResetPassword(ctx, mail.lastToken(t), "new-password"))
+
+	_, _, err = service.ValidateToken(ctx, session.AccessToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+	assert.Equal(t, "", kicker.exceptDeviceID)
+	assert.Equal(t, 1, kicker.calls)
+}
@@ -10,15 +10,36 @@ import (
 )
 
 type User struct {
-	ID           int64
-	Username     string
-	PasswordHash string
-	Email        string
-	Nickname     string
-	Avatar       string
-	Bio          string
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	ID               int64
+	Username         string
+	PasswordHash     string
+	Email            string
+	EmailVerified    bool
+	TwoFactorEnabled bool
+	Nickname         string
+	Avatar           string
+	Bio              string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// Account token purposes
+const (
+	TokenPurposePasswordReset = "password_reset"
+	TokenPurposeEmailVerify   = "email_verify"
+)
+
+// AccountToken is a consumed single-use account token
+type AccountToken struct {
+	UserID int64
+	Email  string
+}
+
+// TOTPState is a user's TOTP enrollment
+type TOTPState struct {
+	Secret   string
+	Enabled  bool
+	LastStep int64
 }
 
 type Repository struct {
@@ -40,13 +61,15 @@ func (r *Repository) CreateUser(ctx context.Context, username, password, email,
 	query := `
 		INSERT INTO users (username, password_hash, email, nickname, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, NOW(), NOW())
-		RETURNING id, username, email, nickname, avatar, bio, created_at, updated_at
+		RETURNING id, username, email, email_verified_at IS NOT NULL, totp_enabled_at IS NOT NULL, nickname, avatar, bio, created_at, updated_at
 	`
 
 	err = r.db.QueryRowContext(ctx, query, username, string(hashedPassword), email, nickname).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
+		&user.EmailVerified,
+		&user.TwoFactorEnabled,
 		&user.Nickname,
 		&user.Avatar,
 		&user.Bio,
@@ -65,7 +88,7 @@ func (r *Repository) CreateUser(ctx context.Context, username, password, email,
 func (r *Repository) GetUserByUsername(ctx context.Context, username string) (*User, error) {
 	user := &User{}
 	query := `
-		SELECT id, username, password_hash, email, nickname, avatar, bio, created_at, updated_at
+		SELECT id, username, password_hash, email, email_verified_at IS NOT NULL, totp_enabled_at IS NOT NULL, nickname, avatar, bio, created_at, updated_at
 		FROM users
 		WHERE username = $1
 	`
@@ -75,6 +98,8 @@ func (r *Repository) GetUserByUsername(ctx context.Context, username string) (*U
 		&user.Username,
 		&user.PasswordHash,
 		&user.Email,
+		&user.EmailVerified,
+		&user.TwoFactorEnabled,
 		&user.Nickname,
 		&user.Avatar,
 		&user.Bio,
@@ -83,7 +108,7 @@ func (r *Repository) GetUserByUsername(ctx context.Context, username string) (*U
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user not found")
+		return nil, ErrUserNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
@@ -96,7 +121,7 @@ func (r *Repository) GetUserByUsername(ctx context.Context, username string) (*U
 func (r *Repository) GetUserByID(ctx context.Context, userID int64) (*User, error) {
 	user := &User{}
 	query := `
-		SELECT id, username, email, nickname, avatar, bio, created_at, updated_at
+		SELECT id, username, email, email_verified_at IS NOT NULL, totp_enabled_at IS NOT NULL, nickname, avatar, bio, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
@@ -105,6 +130,8 @@ func (r *Repository) GetUserByID(ctx context.Context, userID int64) (*User, erro
 		&user.ID,
 		&user.Username,
 		&user.Email,
+		&user.EmailVerified,
+		&user.TwoFactorEnabled,
 		&user.Nickname,
 		&user.Avatar,
 		&user.Bio,
@@ -113,7 +140,39 @@ func (r *Repository) GetUserByID(ctx context.Context, userID int64) (*User, erro
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user not found")
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetUserByEmail retrieves a user by email (excludes password hash)
+func (r *Repository) GetUserByEmail(ctx context.Context, email string) (*User, error) {
+	user := &User{}
+	query := `
+		SELECT id, username, email, email_verified_at IS NOT NULL, totp_enabled_at IS NOT NULL, nickname, avatar, bio, created_at, updated_at
+		FROM users
+		WHERE email = $1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, email).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.EmailVerified,
+		&user.TwoFactorEnabled,
+		&user.Nickname,
+		&user.Avatar,
+		&user.Bio,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
@@ -145,3 +204,304 @@ func (r *Repository) UpdateUser(ctx context.Context, userID int64, nickname, ava
 func (r *Repository) VerifyPassword(hashedPassword, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 }
+
+// UpdatePassword replaces a user's password
+func (r *Repository) UpdatePassword(ctx context.Context, userID int64, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	query := `UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2`
+	if _, err := r.db.ExecContext(ctx, query, string(hashedPassword), userID); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	return nil
+}
+
+// MarkEmailVerified marks the user's email as verified if it is still the given address
+func (r *Repository) MarkEmailVerified(ctx context.Context, userID int64, email string) (bool, error) {
+	query := `
+		UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+		WHERE id = $1 AND email = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, email)
+	if err != nil {
+		return false, fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// CreateAccountToken stores a token hash, replacing the user's unused tokens
+// of the same purpose
+func (r *Repository) CreateAccountToken(ctx context.Context, userID int64, purpose, tokenHash, email string, expiresAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM account_tokens
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL
+	`, userID, purpose)
+	if err != nil {
+		return fmt.Errorf("failed to replace account tokens: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO account_tokens (token_hash, user_id, purpose, email, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, tokenHash, userID, purpose, email, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create account token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// LastAccountTokenAt returns when the user's latest token of a purpose was
+// created, or the zero time if there is none
+func (r *Repository) LastAccountTokenAt(ctx context.Context, userID int64, purpose string) (time.Time, error) {
+	var createdAt sql.NullTime
+	query := `
+		SELECT MAX(created_at)
+		FROM account_tokens
+		WHERE user_id = $1 AND purpose = $2
+	`
+
+	if err := r.db.QueryRowContext(ctx, query, userID, purpose).Scan(&createdAt); err != nil {
+		return time.Time{}, fmt.Errorf("failed to get account token: %w", err)
+	}
+
+	return createdAt.Time, nil
+}
+
+// ConsumeAccountToken marks an unused, unexpired token as used and returns
+// it. Concurrent attempts are serialized by the row update, so a token can
+// be consumed only once.
+func (r *Repository) ConsumeAccountToken(ctx context.Context, purpose, tokenHash string) (*AccountToken, error) {
+	token := &AccountToken{}
+	query := `
+		UPDATE account_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id, email
+	`
+
+	err := r.db.QueryRowContext(ctx, query, tokenHash, purpose).Scan(&token.UserID, &token.Email)
+	if err == sql.ErrNoRows {
+		return nil, ErrInvalidAccountToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume account token: %w", err)
+	}
+
+	return token, nil
+}
+
+// GetTOTP retrieves a user's TOTP enrollment; Secret is empty if the user
+// has not enrolled
+func (r *Repository) GetTOTP(ctx context.Context, userID int64) (*TOTPState, error) {
+	var secret sql.NullString
+	var lastStep sql.NullInt64
+	state := &TOTPState{}
+	query := `
+		SELECT totp_secret, totp_enabled_at IS NOT NULL, totp_last_step
+		FROM users
+		WHERE id = $1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&secret, &state.Enabled, &lastStep)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get TOTP state: %w", err)
+	}
+
+	state.Secret = secret.String
+	state.LastStep = lastStep.Int64
+	return state, nil
+}
+
+// SetTOTPSecret stores a pending TOTP secret, replacing any earlier
+// unconfirmed one. It returns false if 2FA is already enabled.
+func (r *Repository) SetTOTPSecret(ctx context.Context, userID int64, secret string) (bool, error) {
+	query := `
+		UPDATE users
+		SET totp_secret = $1, totp_last_step = NULL, updated_at = NOW()
+		WHERE id = $2 AND totp_enabled_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, secret, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to set TOTP secret: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to set TOTP secret: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// EnableTOTP activates the pending secret and stores the recovery code
+// hashes. It returns false if there is no pending secret.
+func (r *Repository) EnableTOTP(ctx context.Context, userID, step int64, codeHashes []string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE users
+		SET totp_enabled_at = NOW(), totp_last_step = $1, updated_at = NOW()
+		WHERE id = $2 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL
+	`, step, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to enable TOTP: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to enable TOTP: %w", err)
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+// DisableTOTP removes the TOTP secret and recovery codes
+func (r *Repository) DisableTOTP(ctx context.Context, userID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users
+		SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL, updated_at = NOW()
+		WHERE id = $1
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to disable TOTP: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UseTOTPStep records a TOTP time step as used. It returns false if the
+// step is not newer than the last accepted one, i.e. the code was replayed.
+func (r *Repository) UseTOTPStep(ctx context.Context, userID, step int64) (bool, error) {
+	query := `
+		UPDATE users
+		SET totp_last_step = $1
+		WHERE id = $2 AND totp_enabled_at IS NOT NULL AND (totp_last_step IS NULL OR totp_last_step < $1)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, step, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to use TOTP code: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to use TOTP code: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// ReplaceRecoveryCodes replaces all of a user's recovery codes
+func (r *Repository) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeRecoveryCode marks an unused recovery code as used. It returns
+// false if the code is unknown or was already used.
+func (r *Repository) ConsumeRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	query := `
+		UPDATE recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID int64, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	for _, hash := range codeHashes {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO recovery_codes (code_hash, user_id)
+			VALUES ($1, $2)
+		`, hash, userID)
+		if err != nil {
+			return fmt.Errorf("failed to create recovery code: %w", err)
+		}
+	}
+
+	return nil
+}
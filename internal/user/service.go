@@ -2,16 +2,47 @@ package user
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/dollarkillerx/im-system/pkg/auth"
 	"github.com/dollarkillerx/im-system/pkg/logger"
+	"github.com/dollarkillerx/im-system/pkg/mailer"
 	"go.uber.org/zap"
 )
 
+var (
+	// ErrRefreshDisabled is returned by RefreshToken when no refresh token store is configured
+	ErrRefreshDisabled = errors.New("refresh tokens are not enabled")
+	// ErrInvalidToken is returned for malformed, forged or expired tokens
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrTokenRevoked is returned for tokens on the revocation denylist
+	ErrTokenRevoked = errors.New("token has been revoked")
+	// ErrUserNotFound is returned when no user matches the lookup
+	ErrUserNotFound = errors.New("user not found")
+)
+
 type Service struct {
 	repo       UserRepository
 	jwtManager *auth.JWTManager
+	refresh    *auth.RefreshStore
+	revocation *auth.RevocationStore
+	mailer     mailer.Mailer
+	emails     AccountEmails
+	kicker     DeviceKicker
+	limiter    *LoginLimiter
+	challenges *ChallengeStore
+	totpIssuer string
+
+	passwordPolicy PasswordPolicy
+}
+
+// Session holds the tokens issued at login or refresh
+type Session struct {
+	AccessToken      string
+	ExpiresAt        int64
+	RefreshToken     string
+	RefreshExpiresAt int64
 }
 
 func NewService(repo UserRepository, jwtManager *auth.JWTManager) *Service {
@@ -21,6 +52,13 @@ func NewService(repo UserRepository, jwtManager *auth.JWTManager) *Service {
 	}
 }
 
+// WithTokenStores enables refresh tokens and token revocation
+func (s *Service) WithTokenStores(refresh *auth.RefreshStore, revocation *auth.RevocationStore) *Service {
+	s.refresh = refresh
+	s.revocation = revocation
+	return s
+}
+
 // Register registers a new user
 func (s *Service) Register(ctx context.Context, username, password, email, nickname string) (int64, error) {
 	// Check if user already exists
@@ -29,6 +67,10 @@ func (s *Service) Register(ctx context.Context, username, password, email, nickn
 		return 0, fmt.Errorf("username already exists")
 	}
 
+	if err := s.checkPassword(password); err != nil {
+		return 0, err
+	}
+
 	user, err := s.repo.CreateUser(ctx, username, password, email, nickname)
 	if err != nil {
 		logger.Log.Error("Failed to create user",
@@ -46,36 +88,136 @@ func (s *Service) Register(ctx context.Context, username, password, email, nickn
 	return user.ID, nil
 }
 
-// Login authenticates a user and generates a token
-func (s *Service) Login(ctx context.Context, username, password, deviceID string) (int64, string, int64, *User, error) {
+// Login authenticates a user and issues an access token, plus a refresh
+// token when refresh tokens are enabled. clientIP is used for login
+// throttling and may be empty. For users with two-factor authentication no
+// token is issued yet: a *TwoFactorChallengeError carries the challenge to
+// complete with LoginTwoFactor.
+func (s *Service) Login(ctx context.Context, username, password, deviceID, clientIP string) (int64, *Session, *User, error) {
+	if err := s.checkLoginAllowed(ctx, username, clientIP); err != nil {
+		return 0, nil, nil, err
+	}
+
 	user, err := s.repo.GetUserByUsername(ctx, username)
 	if err != nil {
-		return 0, "", 0, nil, fmt.Errorf("invalid credentials")
+		s.recordLoginFailure(ctx, username, clientIP)
+		return 0, nil, nil, ErrInvalidCredentials
 	}
 
 	if err := s.repo.VerifyPassword(user.PasswordHash, password); err != nil {
-		return 0, "", 0, nil, fmt.Errorf("invalid credentials")
+		s.recordLoginFailure(ctx, username, clientIP)
+		return 0, nil, nil, ErrInvalidCredentials
+	}
+
+	// With 2FA the failures are only cleared once the second factor passes,
+	// so that codes cannot be guessed through a stream of fresh challenges
+	if user.TwoFactorEnabled {
+		return 0, nil, nil, s.issueChallenge(ctx, user.ID, deviceID)
 	}
 
-	token, err := s.jwtManager.Generate(user.ID, deviceID)
+	s.resetLoginFailures(ctx, username)
+
+	session, err := s.issueSession(ctx, user, deviceID)
 	if err != nil {
-		logger.Log.Error("Failed to generate token",
-			zap.Int64("user_id", user.ID),
-			zap.Error(err),
-		)
-		return 0, "", 0, nil, fmt.Errorf("failed to generate token: %w", err)
+		return 0, nil, nil, err
 	}
 
-	claims, _ := s.jwtManager.Validate(token)
-	expiresAt := claims.ExpiresAt.Unix()
+	return user.ID, session, user, nil
+}
+
+// issueSession issues the access token, and a refresh token when refresh
+// tokens are enabled, for a user who completed login
+func (s *Service) issueSession(ctx context.Context, user *User, deviceID string) (*Session, error) {
+	session, err := s.generateAccessToken(user.ID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.refresh != nil {
+		refreshToken, refreshExpiresAt, err := s.refresh.Issue(ctx, user.ID, deviceID)
+		if err != nil {
+			logger.Log.Error("Failed to issue refresh token",
+				zap.Int64("user_id", user.ID),
+				zap.Error(err),
+			)
+			return nil, fmt.Errorf("failed to issue refresh token: %w", err)
+		}
+		session.RefreshToken = refreshToken
+		session.RefreshExpiresAt = refreshExpiresAt.Unix()
+	}
 
 	logger.Log.Info("User logged in successfully",
 		zap.Int64("user_id", user.ID),
-		zap.String("username", username),
+		zap.String("username", user.Username),
 		zap.String("device_id", deviceID),
 	)
 
-	return user.ID, token, expiresAt, user, nil
+	return session, nil
+}
+
+// RefreshToken exchanges a refresh token for a new access token and a new
+// refresh token. Presenting an already rotated refresh token revokes the
+// whole device, since the token may have been stolen.
+func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (*Session, error) {
+	if s.refresh == nil {
+		return nil, ErrRefreshDisabled
+	}
+
+	userID, deviceID, newRefreshToken, refreshExpiresAt, err := s.refresh.Rotate(ctx, refreshToken)
+	if errors.Is(err, auth.ErrRefreshTokenReused) {
+		logger.Log.Warn("Refresh token reused, revoking device",
+			zap.Int64("user_id", userID),
+			zap.String("device_id", deviceID),
+		)
+		if s.revocation != nil {
+			if revokeErr := s.revocation.RevokeDevice(ctx, userID, deviceID); revokeErr != nil {
+				logger.Log.Error("Failed to revoke device",
+					zap.Int64("user_id", userID),
+					zap.String("device_id", deviceID),
+					zap.Error(revokeErr),
+				)
+			}
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.generateAccessToken(userID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	session.RefreshToken = newRefreshToken
+	session.RefreshExpiresAt = refreshExpiresAt.Unix()
+
+	return session, nil
+}
+
+// Logout revokes the access token and ends the refresh session of its device
+func (s *Service) Logout(ctx context.Context, token string) error {
+	claims, err := s.validateClaims(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	if s.revocation != nil {
+		if err := s.revocation.RevokeToken(ctx, claims); err != nil {
+			return err
+		}
+	}
+	if s.refresh != nil {
+		if err := s.refresh.Revoke(ctx, claims.UserID, claims.DeviceID); err != nil {
+			return err
+		}
+	}
+
+	logger.Log.Info("User logged out",
+		zap.Int64("user_id", claims.UserID),
+		zap.String("device_id", claims.DeviceID),
+	)
+
+	return nil
 }
 
 // GetUserInfo retrieves user information
@@ -93,12 +235,48 @@ func (s *Service) UpdateUserInfo(ctx context.Context, userID int64, nickname, av
 	return s.repo.UpdateUser(ctx, userID, nickname, avatar, bio)
 }
 
-// ValidateToken validates a JWT token
+// ValidateToken validates a JWT token and checks that it was not revoked
 func (s *Service) ValidateToken(ctx context.Context, token string) (int64, string, error) {
-	claims, err := s.jwtManager.Validate(token)
+	claims, err := s.validateClaims(ctx, token)
 	if err != nil {
 		return 0, "", err
 	}
 
 	return claims.UserID, claims.DeviceID, nil
 }
+
+func (s *Service) validateClaims(ctx context.Context, token string) (*auth.Claims, error) {
+	claims, err := s.jwtManager.Validate(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if s.revocation != nil {
+		revoked, err := s.revocation.IsRevoked(ctx, claims)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check token revocation: %w", err)
+		}
+		if revoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+
+	return claims, nil
+}
+
+// generateAccessToken issues an access token for a device
+func (s *Service) generateAccessToken(userID int64, deviceID string) (*Session, error) {
+	token, expiresAt, err := s.jwtManager.Generate(userID, deviceID)
+	if err != nil {
+		logger.Log.Error("Failed to generate token",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return &Session{
+		AccessToken: token,
+		ExpiresAt:   expiresAt.Unix(),
+	}, nil
+}
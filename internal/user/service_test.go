@@ -6,8 +6,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/dollarkillerx/im-system/pkg/auth"
 	"github.com/dollarkillerx/im-system/pkg/logger"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -25,11 +27,26 @@ type MockUserRepository struct {
 	createUser        func(ctx context.Context, username, password, email, nickname string) (*User, error)
 	updateUser        func(ctx context.Context, userID int64, nickname, avatar, bio *string) error
 	verifyPassword    func(hashedPassword, password string) error
+	accountTokens     map[string]*mockAccountToken
+	totp              map[int64]*TOTPState
+	recoveryCodes     map[int64]map[string]bool
+}
+
+type mockAccountToken struct {
+	userID    int64
+	purpose   string
+	email     string
+	expiresAt time.Time
+	createdAt time.Time
+	used      bool
 }
 
 func newMockUserRepository() *MockUserRepository {
 	return &MockUserRepository{
-		users: make(map[string]*User),
+		users:         make(map[string]*User),
+		accountTokens: make(map[string]*mockAccountToken),
+		totp:          make(map[int64]*TOTPState),
+		recoveryCodes: make(map[int64]map[string]bool),
 	}
 }
 
@@ -99,6 +116,134 @@ func (m *MockUserRepository) UpdateUser(ctx context.Context, userID int64, nickn
 	return errors.New("user not found")
 }
 
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) (*User, error) {
+	for _, user := range m.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, userID int64, password string) error {
+	for _, user := range m.users {
+		if user.ID == userID {
+			user.PasswordHash = "hashed_" + password
+			return nil
+		}
+	}
+	return ErrUserNotFound
+}
+
+func (m *MockUserRepository) MarkEmailVerified(ctx context.Context, userID int64, email string) (bool, error) {
+	for _, user := range m.users {
+		if user.ID == userID && user.Email == email {
+			user.EmailVerified = true
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MockUserRepository) CreateAccountToken(ctx context.Context, userID int64, purpose, tokenHash, email string, expiresAt time.Time) error {
+	for hash, token := range m.accountTokens {
+		if token.userID == userID && token.purpose == purpose && !token.used {
+			delete(m.accountTokens, hash)
+		}
+	}
+	m.accountTokens[tokenHash] = &mockAccountToken{userID: userID, purpose: purpose, email: email, expiresAt: expiresAt, createdAt: time.Now()}
+	return nil
+}
+
+func (m *MockUserRepository) LastAccountTokenAt(ctx context.Context, userID int64, purpose string) (time.Time, error) {
+	var last time.Time
+	for _, token := range m.accountTokens {
+		if token.userID == userID && token.purpose == purpose && token.createdAt.After(last) {
+			last = token.createdAt
+		}
+	}
+	return last, nil
+}
+
+func (m *MockUserRepository) ConsumeAccountToken(ctx context.Context, purpose, tokenHash string) (*AccountToken, error) {
+	token, ok := m.accountTokens[tokenHash]
+	if !ok || token.used || token.purpose != purpose || !time.Now().Before(token.expiresAt) {
+		return nil, ErrInvalidAccountToken
+	}
+	token.used = true
+	return &AccountToken{UserID: token.userID, Email: token.email}, nil
+}
+
+func (m *MockUserRepository) GetTOTP(ctx context.Context, userID int64) (*TOTPState, error) {
+	state, ok := m.totp[userID]
+	if !ok {
+		return &TOTPState{}, nil
+	}
+	copied := *state
+	return &copied, nil
+}
+
+func (m *MockUserRepository) SetTOTPSecret(ctx context.Context, userID int64, secret string) (bool, error) {
+	if state, ok := m.totp[userID]; ok && state.Enabled {
+		return false, nil
+	}
+	m.totp[userID] = &TOTPState{Secret: secret}
+	return true, nil
+}
+
+func (m *MockUserRepository) EnableTOTP(ctx context.Context, userID, step int64, codeHashes []string) (bool, error) {
+	state, ok := m.totp[userID]
+	if !ok || state.Secret == "" || state.Enabled {
+		return false, nil
+	}
+	state.Enabled = true
+	state.LastStep = step
+	m.setTwoFactorEnabled(userID, true)
+	return true, m.ReplaceRecoveryCodes(ctx, userID, codeHashes)
+}
+
+func (m *MockUserRepository) DisableTOTP(ctx context.Context, userID int64) error {
+	delete(m.totp, userID)
+	delete(m.recoveryCodes, userID)
+	m.setTwoFactorEnabled(userID, false)
+	return nil
+}
+
+func (m *MockUserRepository) UseTOTPStep(ctx context.Context, userID, step int64) (bool, error) {
+	state, ok := m.totp[userID]
+	if !ok || !state.Enabled || step <= state.LastStep {
+		return false, nil
+	}
+	state.LastStep = step
+	return true, nil
+}
+
+func (m *MockUserRepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	codes := make(map[string]bool, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes[hash] = false
+	}
+	m.recoveryCodes[userID] = codes
+	return nil
+}
+
+func (m *MockUserRepository) ConsumeRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	used, ok := m.recoveryCodes[userID][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	m.recoveryCodes[userID][codeHash] = true
+	return true, nil
+}
+
+func (m *MockUserRepository) setTwoFactorEnabled(userID int64, enabled bool) {
+	for _, user := range m.users {
+		if user.ID == userID {
+			user.TwoFactorEnabled = enabled
+		}
+	}
+}
+
 func (m *MockUserRepository) VerifyPassword(hashedPassword, password string) error {
 	if m.verifyPassword != nil {
 		return m.verifyPassword(hashedPassword, password)
@@ -244,7 +389,7 @@ func TestService_Login(t *testing.T) {
 			jwtManager := auth.NewJWTManager("test-secret", 1*time.Hour)
 			service := NewService(repo, jwtManager)
 
-			userID, token, expiresAt, user, err := service.Login(context.Background(), tt.username, tt.password, tt.deviceID)
+			userID, session, user, err := service.Login(context.Background(), tt.username, tt.password, tt.deviceID, "")
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -252,13 +397,14 @@ func TestService_Login(t *testing.T) {
 					assert.Contains(t, err.Error(), tt.errMsg)
 				}
 				assert.Equal(t, int64(0), userID)
-				assert.Empty(t, token)
+				assert.Nil(t, session)
 				assert.Nil(t, user)
 			} else {
 				require.NoError(t, err)
 				assert.Greater(t, userID, int64(0))
-				assert.NotEmpty(t, token)
-				assert.Greater(t, expiresAt, time.Now().Unix())
+				assert.NotEmpty(t, session.AccessToken)
+				assert.Greater(t, session.ExpiresAt, time.Now().Unix())
+				assert.Empty(t, session.RefreshToken)
 				assert.NotNil(t, user)
 				assert.Equal(t, tt.username, user.Username)
 			}
@@ -381,7 +527,7 @@ func TestService_ValidateToken(t *testing.T) {
 		{
 			name: "valid token",
 			setupToken: func() string {
-				token, _ := jwtManager.Generate(100, "device-001")
+				token, _, _ := jwtManager.Generate(100, "device-001")
 				return token
 			},
 			wantUserID: 100,
@@ -412,3 +558,87 @@ func TestService_ValidateToken(t *testing.T) {
 		})
 	}
 }
+
+// setupTokenService creates a service with refresh tokens and revocation
+// backed by miniredis and a single registered user
+func setupTokenService(t *testing.T) *Service {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	repo := newMockUserRepository()
+	repo.users["testuser"] = &User{
+		ID:           100,
+		Username:     "testuser",
+		PasswordHash: "hashed_password123",
+	}
+
+	jwtManager := auth.NewJWTManager("test-secret", 15*time.Minute)
+	return NewService(repo, jwtManager).WithTokenStores(
+		auth.NewRefreshStore(client, time.Hour),
+		auth.NewRevocationStore(client, 15*time.Minute),
+	)
+}
+
+func TestService_RefreshToken(t *testing.T) {
+	service := setupTokenService(t)
+	ctx := context.Background()
+
+	_, session, _, err := service.Login(ctx, "testuser", "password123", "device-001", "")
+	require.NoError(t, err)
+	require.NotEmpty(t, session.RefreshToken)
+	assert.Greater(t, session.RefreshExpiresAt, session.ExpiresAt)
+
+	refreshed, err := service.RefreshToken(ctx, session.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, session.RefreshToken, refreshed.RefreshToken)
+
+	userID, deviceID, err := service.ValidateToken(ctx, refreshed.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), userID)
+	assert.Equal(t, "device-001", deviceID)
+
+	// presenting the rotated token again ends the session
+	_, err = service.RefreshToken(ctx, session.RefreshToken)
+	assert.ErrorIs(t, err, auth.ErrRefreshTokenReused)
+
+	_, err = service.RefreshToken(ctx, refreshed.RefreshToken)
+	assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+
+	_, _, err = service.ValidateToken(ctx, refreshed.AccessToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+}
+
+func TestService_RefreshTokenDisabled(t *testing.T) {
+	service := NewService(newMockUserRepository(), auth.NewJWTManager("test-secret", time.Hour))
+
+	_, err := service.RefreshToken(context.Background(), "refresh-token")
+	assert.ErrorIs(t, err, ErrRefreshDisabled)
+}
+
+func TestService_Logout(t *testing.T) {
+	service := setupTokenService(t)
+	ctx := context.Background()
+
+	_, session, _, err := service.Login(ctx, "testuser", "password123", "device-001", "")
+	require.NoError(t, err)
+	_, other, _, err := service.Login(ctx, "testuser", "password123", "device-002", "")
+	require.NoError(t, err)
+
+	require.NoError(t, service.Logout(ctx, session.AccessToken))
+
+	_, _, err = service.ValidateToken(ctx, session.AccessToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+
+	_, err = service.RefreshToken(ctx, session.RefreshToken)
+	assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+
+	// logging out twice is rejected
+	assert.ErrorIs(t, service.Logout(ctx, session.AccessToken), ErrTokenRevoked)
+
+	// other devices stay signed in
+	_, _, err = service.ValidateToken(ctx, other.AccessToken)
+	assert.NoError(t, err)
+	_, err = service.RefreshToken(ctx, other.RefreshToken)
+	assert.NoError(t, err)
+}
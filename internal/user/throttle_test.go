@@ -0,0 +1,157 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/dollarkillerx/im-system/pkg/auth"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func setupThrottledService(t *testing.T, policy LoginThrottle) (*Service, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	repo := newMockUserRepository()
+	repo.users["testuser"] = &User{
+		ID:           100,
+		Username:     "testuser",
+		PasswordHash: "hashed_password123",
+	}
+
+	jwtManager := auth.NewJWTManager("test-secret", 15*time.Minute)
+	service := NewService(repo, jwtManager).WithLoginLimiter(NewLoginLimiter(client, policy))
+	return service, mr
+}
+
+func loginThrottled(t *testing.T, err error) *LoginThrottledError {
+	t.Helper()
+	var throttled *LoginThrottledError
+	require.True(t, errors.As(err, &throttled), "expected throttled error, got %v", err)
+	assert.ErrorIs(t, err, ErrLoginThrottled)
+	return throttled
+}
+
+func TestService_LoginProgressiveDelay(t *testing.T) {
+	service, mr := setupThrottledService(t, LoginThrottle{
+		Window:          15 * time.Minute,
+		MaxUserAttempts: 5,
+		BaseDelay:       time.Second,
+		MaxDelay:        3 * time.Second,
+	})
+	ctx := context.Background()
+
+	_, _, _, err := service.Login(ctx, "testuser", "wrong", "device-001", "10.0.0.1")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	// the next attempt has to wait, even with the right password
+	_, _, _, err = service.Login(ctx, "testuser", "password123", "device-001", "10.0.0.1")
+	throttled := loginThrottled(t, err)
+	assert.False(t, throttled.Locked)
+	assert.LessOrEqual(t, throttled.RetryAfter, time.Second)
+
+	// the delay doubles per failure up to the cap
+	mr.FastForward(time.Second)
+	_, _, _, err = service.Login(ctx, "testuser", "wrong", "device-001", "10.0.0.1")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	assert.Equal(t, 2*time.Second, mr.TTL(loginKey(loginWaitKeyPrefix, "user", "testuser")))
+
+	mr.FastForward(2 * time.Second)
+	_, _, _, err = service.Login(ctx, "testuser", "wrong", "device-001", "10.0.0.1")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	assert.Equal(t, 3*time.Second, mr.TTL(loginKey(loginWaitKeyPrefix, "user", "testuser")))
+
+	// a successful login clears the failures
+	mr.FastForward(3 * time.Second)
+	_, _, _, err = service.Login(ctx, "testuser", "password123", "device-001", "10.0.0.1")
+	require.NoError(t, err)
+	assert.False(t, mr.Exists(loginKey(loginFailKeyPrefix, "user", "testuser")))
+}
+
+func TestService_LoginUserLockout(t *testing.T) {
+	service, mr := setupThrottledService(t, LoginThrottle{
+		Window:          15 * time.Minute,
+		MaxUserAttempts: 3,
+		Lockout:         10 * time.Minute,
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_, _, _, err := service.Login(ctx, "testuser", "wrong", "device-001", "")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+
+	_, _, _, err := service.Login(ctx, "testuser", "password123", "device-001", "")
+	throttled := loginThrottled(t, err)
+	assert.True(t, throttled.Locked)
+	assert.Equal(t, 10*time.Minute, throttled.RetryAfter)
+
+	// the lockout ends on its own
+	mr.FastForward(10 * time.Minute)
+	_, _, _, err = service.Login(ctx, "testuser", "password123", "device-001", "")
+	assert.NoError(t, err)
+}
+
+func TestService_LoginIPLockout(t *testing.T) {
+	service, _ := setupThrottledService(t, LoginThrottle{
+		Window:        15 * time.Minute,
+		MaxIPAttempts: 3,
+		Lockout:       10 * time.Minute,
+	})
+	ctx := context.Background()
+
+	// failures against unknown usernames count towards the IP
+	for _, username := range []string{"alice", "bob", "carol"} {
+		_, _, _, err := service.Login(ctx, username, "wrong", "device-001", "10.0.0.1")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+
+	_, _, _, err := service.Login(ctx, "testuser", "password123", "device-001", "10.0.0.1")
+	assert.True(t, loginThrottled(t, err).Locked)
+
+	// other clients are unaffected
+	_, _, _, err = service.Login(ctx, "testuser", "password123", "device-001", "10.0.0.2")
+	assert.NoError(t, err)
+}
+
+func TestService_LoginThrottleFailsOpen(t *testing.T) {
+	service, mr := setupThrottledService(t, LoginThrottle{
+		Window:          15 * time.Minute,
+		MaxUserAttempts: 1,
+		Lockout:         10 * time.Minute,
+	})
+	mr.Close()
+
+	_, _, _, err := service.Login(context.Background(), "testuser", "password123", "device-001", "10.0.0.1")
+	assert.NoError(t, err)
+}
+
+func TestLoginThrottledStatus(t *testing.T) {
+	err := loginThrottledStatus(&LoginThrottledError{RetryAfter: 90 * time.Second, Locked: true})
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+	require.Len(t, st.Details(), 2)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, "LOGIN_LOCKED", info.Reason)
+	retry, ok := st.Details()[1].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	assert.Equal(t, 90*time.Second, retry.RetryDelay.AsDuration())
+
+	// A delay short of the lockout carries its own reason
+	st = status.Convert(loginThrottledStatus(&LoginThrottledError{RetryAfter: 2 * time.Second}))
+	require.Len(t, st.Details(), 2)
+	info, ok = st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, "LOGIN_THROTTLED", info.Reason)
+}
@@ -0,0 +1,332 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dollarkillerx/im-system/pkg/auth"
+	"github.com/dollarkillerx/im-system/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	recoveryCodeCount = 10
+	// recoveryCodeLength is the number of base32 characters per code (50 bits)
+	recoveryCodeLength = 10
+
+	defaultTOTPIssuer = "IM System"
+)
+
+var (
+	// ErrTwoFactorRequired is returned by Login when a second factor is needed
+	ErrTwoFactorRequired = errors.New("two-factor authentication required")
+	// ErrTwoFactorUnavailable is returned when a user has 2FA enabled but the
+	// service has no challenge store to complete the login
+	ErrTwoFactorUnavailable = errors.New("two-factor authentication is not available")
+	// ErrTwoFactorEnabled is returned when enrolling while 2FA is already enabled
+	ErrTwoFactorEnabled = errors.New("two-factor authentication already enabled")
+	// ErrTwoFactorNotEnabled is returned when disabling 2FA that is not enabled
+	ErrTwoFactorNotEnabled = errors.New("two-factor authentication not enabled")
+	// ErrTOTPNotEnrolled is returned when confirming without a pending enrollment
+	ErrTOTPNotEnrolled = errors.New("no pending authenticator enrollment")
+	// ErrInvalidTwoFactorCode is returned for wrong, reused or malformed codes
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+)
+
+// TwoFactorChallengeError is returned by Login for users with two-factor
+// authentication. The challenge token is exchanged together with a code
+// through LoginTwoFactor.
+type TwoFactorChallengeError struct {
+	Challenge string
+	ExpiresAt time.Time
+}
+
+func (e *TwoFactorChallengeError) Error() string {
+	return ErrTwoFactorRequired.Error()
+}
+
+func (e *TwoFactorChallengeError) Unwrap() error {
+	return ErrTwoFactorRequired
+}
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// WithTwoFactor enables the second login step. issuer is the account label
+// shown in authenticator apps.
+func (s *Service) WithTwoFactor(challenges *ChallengeStore, issuer string) *Service {
+	if issuer == "" {
+		issuer = defaultTOTPIssuer
+	}
+	s.challenges = challenges
+	s.totpIssuer = issuer
+	return s
+}
+
+// LoginTwoFactor completes a login started by Login with an authenticator
+// code or a recovery code. A challenge is single-use and is dropped after
+// too many wrong codes, which sends the user back to the password step.
+// Wrong codes also count as failed logins of the user and clientIP, so the
+// login limiter delays and locks out code guessing like password guessing.
+func (s *Service) LoginTwoFactor(ctx context.Context, challenge, code, clientIP string) (int64, *Session, *User, error) {
+	if s.challenges == nil {
+		return 0, nil, nil, ErrInvalidChallenge
+	}
+
+	userID, deviceID, err := s.challenges.Get(ctx, challenge)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	if err := s.checkLoginAllowed(ctx, user.Username, clientIP); err != nil {
+		return 0, nil, nil, err
+	}
+
+	if err := s.verifySecondFactor(ctx, userID, code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			s.recordLoginFailure(ctx, user.Username, clientIP)
+			if remaining, failErr := s.challenges.Fail(ctx, challenge); failErr == nil && remaining == 0 {
+				logger.Log.Warn("Login challenge dropped after too many invalid codes",
+					zap.Int64("user_id", userID),
+					zap.String("device_id", deviceID),
+				)
+			}
+		}
+		return 0, nil, nil, err
+	}
+
+	if err := s.challenges.Consume(ctx, challenge); err != nil {
+		return 0, nil, nil, err
+	}
+
+	s.resetLoginFailures(ctx, user.Username)
+
+	session, err := s.issueSession(ctx, user, deviceID)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	return user.ID, session, user, nil
+}
+
+// EnrollTOTP generates a new authenticator secret for the token's user and
+// returns it with its otpauth:// URI. 2FA is enabled only once ConfirmTOTP
+// proves the authenticator was set up; enrolling again replaces an
+// unconfirmed secret.
+func (s *Service) EnrollTOTP(ctx context.Context, token string) (string, string, error) {
+	claims, err := s.validateClaims(ctx, token)
+	if err != nil {
+		return "", "", err
+	}
+
+	user, err := s.repo.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return "", "", err
+	}
+	if user.TwoFactorEnabled {
+		return "", "", ErrTwoFactorEnabled
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	stored, err := s.repo.SetTOTPSecret(ctx, user.ID, secret)
+	if err != nil {
+		return "", "", err
+	}
+	if !stored {
+		return "", "", ErrTwoFactorEnabled
+	}
+
+	return secret, auth.TOTPURI(s.totpIssuer, user.Username, secret), nil
+}
+
+// ConfirmTOTP enables 2FA once the user enters a code from the enrolled
+// authenticator and returns the recovery codes. They are only stored
+// hashed, so this is the only time they can be shown.
+func (s *Service) ConfirmTOTP(ctx context.Context, token, code string) ([]string, error) {
+	claims, err := s.validateClaims(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := s.repo.GetTOTP(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if state.Enabled {
+		return nil, ErrTwoFactorEnabled
+	}
+	if state.Secret == "" {
+		return nil, ErrTOTPNotEnrolled
+	}
+
+	step, ok := auth.ValidateTOTP(state.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	enabled, err := s.repo.EnableTOTP(ctx, claims.UserID, step, hashes)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, ErrTOTPNotEnrolled
+	}
+
+	logger.Log.Info("Two-factor authentication enabled",
+		zap.Int64("user_id", claims.UserID),
+		zap.String("device_id", claims.DeviceID),
+	)
+
+	return codes, nil
+}
+
+// DisableTOTP turns 2FA off after checking a current code or recovery code
+func (s *Service) DisableTOTP(ctx context.Context, token, code string) error {
+	claims, err := s.validateClaims(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	if err := s.verifySecondFactor(ctx, claims.UserID, code); err != nil {
+		return err
+	}
+
+	if err := s.repo.DisableTOTP(ctx, claims.UserID); err != nil {
+		return err
+	}
+
+	logger.Log.Info("Two-factor authentication disabled",
+		zap.Int64("user_id", claims.UserID),
+		zap.String("device_id", claims.DeviceID),
+	)
+
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking
+// a current code or recovery code
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, token, code string) ([]string, error) {
+	claims, err := s.validateClaims(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.verifySecondFactor(ctx, claims.UserID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(ctx, claims.UserID, hashes); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("Recovery codes regenerated",
+		zap.Int64("user_id", claims.UserID),
+	)
+
+	return codes, nil
+}
+
+// issueChallenge starts the second login step
+func (s *Service) issueChallenge(ctx context.Context, userID int64, deviceID string) error {
+	if s.challenges == nil {
+		logger.Log.Error("Two-factor login attempted without a challenge store",
+			zap.Int64("user_id", userID),
+		)
+		return ErrTwoFactorUnavailable
+	}
+
+	challenge, expiresAt, err := s.challenges.Issue(ctx, userID, deviceID)
+	if err != nil {
+		return err
+	}
+
+	return &TwoFactorChallengeError{Challenge: challenge, ExpiresAt: expiresAt}
+}
+
+// verifySecondFactor accepts a TOTP code, each time step at most once, or
+// an unused recovery code, which is then used up
+func (s *Service) verifySecondFactor(ctx context.Context, userID int64, code string) error {
+	state, err := s.repo.GetTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !state.Enabled {
+		return ErrTwoFactorNotEnabled
+	}
+
+	if step, ok := auth.ValidateTOTP(state.Secret, code, time.Now()); ok {
+		fresh, err := s.repo.UseTOTPStep(ctx, userID, step)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if len(normalized) != recoveryCodeLength {
+		return ErrInvalidTwoFactorCode
+	}
+
+	used, err := s.repo.ConsumeRecoveryCode(ctx, userID, hashAccountToken(normalized))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+
+	logger.Log.Info("Recovery code used",
+		zap.Int64("user_id", userID),
+	)
+
+	return nil
+}
+
+// newRecoveryCodes returns recovery codes formatted as xxxxx-xxxxx and the
+// hashes to store
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	buf := make([]byte, 7)
+	for i := 0; i < recoveryCodeCount; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(buf)[:recoveryCodeLength])
+		codes = append(codes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+		hashes = append(hashes, hashAccountToken(code))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode ignores case, dashes and spaces
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
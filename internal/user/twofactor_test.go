@@ -0,0 +1,254 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/dollarkillerx/im-system/pkg/auth"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTwoFactorService(t *testing.T, maxAttempts int) (*Service, *MockUserRepository) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	repo := newMockUserRepository()
+	repo.users["testuser"] = &User{
+		ID:           100,
+		Username:     "testuser",
+		PasswordHash: "hashed_password123",
+	}
+
+	jwtManager := auth.NewJWTManager("test-secret", 15*time.Minute)
+	service := NewService(repo, jwtManager).
+		WithTwoFactor(NewChallengeStore(client, 5*time.Minute, maxAttempts), "IM Test")
+	return service, repo
+}
+
+// enableTwoFactor enrolls and confirms an authenticator for testuser and
+// returns its secret and recovery codes
+func enableTwoFactor(t *testing.T, service *Service) (string, []string) {
+	ctx := context.Background()
+
+	_, session, _, err := service.Login(ctx, "testuser", "password123", "device-001", "")
+	require.NoError(t, err)
+
+	secret, uri, err := service.EnrollTOTP(ctx, session.AccessToken)
+	require.NoError(t, err)
+	assert.Contains(t, uri, "otpauth://totp/IM%20Test:testuser?")
+	assert.Contains(t, uri, "secret="+secret)
+
+	code, err := auth.TOTPCode(secret, time.Now())
+	require.NoError(t, err)
+	recoveryCodes, err := service.ConfirmTOTP(ctx, session.AccessToken, code)
+	require.NoError(t, err)
+	return secret, recoveryCodes
+}
+
+// startTwoFactorLogin runs the password step and returns the challenge
+func startTwoFactorLogin(t *testing.T, service *Service) string {
+	_, session, _, err := service.Login(context.Background(), "testuser", "password123", "device-002", "")
+	assert.Nil(t, session)
+	assert.ErrorIs(t, err, ErrTwoFactorRequired)
+
+	var challenge *TwoFactorChallengeError
+	require.True(t, errors.As(err, &challenge))
+	require.NotEmpty(t, challenge.Challenge)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), challenge.ExpiresAt, 5*time.Second)
+	return challenge.Challenge
+}
+
+func TestService_EnrollTOTP(t *testing.T) {
+	service, repo := setupTwoFactorService(t, 5)
+	ctx := context.Background()
+
+	_, session, _, err := service.Login(ctx, "testuser", "password123", "device-001", "")
+	require.NoError(t, err)
+
+	_, err = service.ConfirmTOTP(ctx, session.AccessToken, "123456")
+	assert.ErrorIs(t, err, ErrTOTPNotEnrolled)
+
+	secret, _, err := service.EnrollTOTP(ctx, session.AccessToken)
+	require.NoError(t, err)
+
+	// a wrong code leaves 2FA disabled
+	_, err = service.ConfirmTOTP(ctx, session.AccessToken, "000000")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	assert.False(t, repo.users["testuser"].TwoFactorEnabled)
+
+	code, err := auth.TOTPCode(secret, time.Now())
+	require.NoError(t, err)
+	recoveryCodes, err := service.ConfirmTOTP(ctx, session.AccessToken, code)
+	require.NoError(t, err)
+	assert.Len(t, recoveryCodes, recoveryCodeCount)
+	assert.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, recoveryCodes[0])
+	assert.True(t, repo.users["testuser"].TwoFactorEnabled)
+
+	// enrolling again would replace the active secret
+	_, _, err = service.EnrollTOTP(ctx, session.AccessToken)
+	assert.ErrorIs(t, err, ErrTwoFactorEnabled)
+}
+
+func TestService_LoginTwoFactor(t *testing.T) {
+	service, _ := setupTwoFactorService(t, 5)
+	ctx := context.Background()
+	secret, _ := enableTwoFactor(t, service)
+
+	challenge := startTwoFactorLogin(t, service)
+
+	_, _, _, err := service.LoginTwoFactor(ctx, challenge, "000000", "")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+
+	// the code used to confirm enrollment is spent, so take the next period's
+	code, err := auth.TOTPCode(secret, time.Now().Add(30*time.Second))
+	require.NoError(t, err)
+	userID, session, user, err := service.LoginTwoFactor(ctx, challenge, code, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), userID)
+	assert.Equal(t, "testuser", user.Username)
+
+	claims, err := service.jwtManager.Validate(session.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "device-002", claims.DeviceID)
+
+	// a challenge completes one login only
+	_, _, _, err = service.LoginTwoFactor(ctx, challenge, code, "")
+	assert.ErrorIs(t, err, ErrInvalidChallenge)
+
+	// and a code cannot be replayed with a new challenge
+	_, _, _, err = service.LoginTwoFactor(ctx, startTwoFactorLogin(t, service), code, "")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+}
+
+func TestService_LoginTwoFactorRecoveryCode(t *testing.T) {
+	service, _ := setupTwoFactorService(t, 5)
+	ctx := context.Background()
+	_, recoveryCodes := enableTwoFactor(t, service)
+
+	// case and dashes are ignored
+	_, session, _, err := service.LoginTwoFactor(ctx, startTwoFactorLogin(t, service), " "+strings.ToUpper(strings.ReplaceAll(recoveryCodes[0], "-", "")), "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, session.AccessToken)
+
+	// each recovery code works once
+	_, _, _, err = service.LoginTwoFactor(ctx, startTwoFactorLogin(t, service), recoveryCodes[0], "")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+}
+
+func TestService_LoginTwoFactorAttemptLimit(t *testing.T) {
+	service, _ := setupTwoFactorService(t, 3)
+	ctx := context.Background()
+	secret, _ := enableTwoFactor(t, service)
+
+	challenge := startTwoFactorLogin(t, service)
+	for i := 0; i < 3; i++ {
+		_, _, _, err := service.LoginTwoFactor(ctx, challenge, "000000", "")
+		assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	}
+
+	// the challenge is gone, even for a valid code
+	code, err := auth.TOTPCode(secret, time.Now().Add(30*time.Second))
+	require.NoError(t, err)
+	_, _, _, err = service.LoginTwoFactor(ctx, challenge, code, "")
+	assert.ErrorIs(t, err, ErrInvalidChallenge)
+}
+
+func TestService_LoginTwoFactorUnavailable(t *testing.T) {
+	repo := newMockUserRepository()
+	repo.users["testuser"] = &User{
+		ID:               100,
+		Username:         "testuser",
+		PasswordHash:     "hashed_password123",
+		TwoFactorEnabled: true,
+	}
+	service := NewService(repo, auth.NewJWTManager("test-secret", 15*time.Minute))
+
+	_, session, _, err := service.Login(context.Background(), "testuser", "password123", "device-001", "")
+	assert.ErrorIs(t, err, ErrTwoFactorUnavailable)
+	assert.Nil(t, session)
+}
+
+func TestService_DisableTOTP(t *testing.T) {
+	service, repo := setupTwoFactorService(t, 5)
+	ctx := context.Background()
+	_, recoveryCodes := enableTwoFactor(t, service)
+
+	_, session, _, err := service.LoginTwoFactor(ctx, startTwoFactorLogin(t, service), recoveryCodes[0], "")
+	require.NoError(t, err)
+
+	err = service.DisableTOTP(ctx, session.AccessToken, "000000")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+
+	require.NoError(t, service.DisableTOTP(ctx, session.AccessToken, recoveryCodes[1]))
+	assert.False(t, repo.users["testuser"].TwoFactorEnabled)
+
+	// login is a single step again
+	_, session, _, err = service.Login(ctx, "testuser", "password123", "device-002", "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, session.AccessToken)
+
+	err = service.DisableTOTP(ctx, session.AccessToken, recoveryCodes[2])
+	assert.ErrorIs(t, err, ErrTwoFactorNotEnabled)
+}
+
+func TestService_RegenerateRecoveryCodes(t *testing.T) {
+	service, _ := setupTwoFactorService(t, 5)
+	ctx := context.Background()
+	_, oldCodes := enableTwoFactor(t, service)
+
+	_, session, _, err := service.LoginTwoFactor(ctx, startTwoFactorLogin(t, service), oldCodes[0], "")
+	require.NoError(t, err)
+
+	newCodes, err := service.RegenerateRecoveryCodes(ctx, session.AccessToken, oldCodes[1])
+	require.NoError(t, err)
+	assert.Len(t, newCodes, recoveryCodeCount)
+
+	// the old codes stop working
+	_, _, _, err = service.LoginTwoFactor(ctx, startTwoFactorLogin(t, service), oldCodes[2], "")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	_, _, _, err = service.LoginTwoFactor(ctx, startTwoFactorLogin(t, service), newCodes[0], "")
+	assert.NoError(t, err)
+}
+
+func TestService_LoginTwoFactorLockout(t *testing.T) {
+	service, _ := setupTwoFactorService(t, 5)
+	ctx := context.Background()
+	secret, _ := enableTwoFactor(t, service)
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	service.WithLoginLimiter(NewLoginLimiter(client, LoginThrottle{
+		Window:          15 * time.Minute,
+		MaxUserAttempts: 3,
+		Lockout:         10 * time.Minute,
+	}))
+
+	// a correct password does not clear the failures of earlier challenges
+	pending := startTwoFactorLogin(t, service)
+	for i := 0; i < 3; i++ {
+		_, _, _, err := service.LoginTwoFactor(ctx, startTwoFactorLogin(t, service), "000000", "10.0.0.1")
+		assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	}
+
+	// the account is locked for both steps, even with a valid code
+	_, _, _, err := service.Login(ctx, "testuser", "password123", "device-002", "10.0.0.1")
+	assert.True(t, loginThrottled(t, err).Locked)
+
+	code, err := auth.TOTPCode(secret, time.Now().Add(30*time.Second))
+	require.NoError(t, err)
+	_, _, _, err = service.LoginTwoFactor(ctx, pending, code, "10.0.0.1")
+	assert.True(t, loginThrottled(t, err).Locked)
+
+	mr.FastForward(10 * time.Minute)
+	_, session, _, err := service.LoginTwoFactor(ctx, pending, code, "10.0.0.1")
+	require.NoError(t, err)
+	assert.NotEmpty(t, session.AccessToken)
+}
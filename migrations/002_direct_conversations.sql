@@ -0,0 +1,23 @@
+-- Direct conversation index: at most one direct conversation per user pair
+CREATE TABLE direct_conversations (
+    user_low BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_high BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    conv_id BIGINT NOT NULL UNIQUE REFERENCES conversations(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (user_low, user_high),
+    CHECK (user_low < user_high)
+);
+
+-- Backfill existing two-member direct conversations, keeping the oldest one per pair
+INSERT INTO direct_conversations (user_low, user_high, conv_id)
+SELECT DISTINCT ON (user_low, user_high) user_low, user_high, conv_id
+FROM (
+    SELECT cm.conv_id, MIN(cm.user_id) AS user_low, MAX(cm.user_id) AS user_high
+    FROM conversations c
+    JOIN conversation_members cm ON cm.conv_id = c.id
+    WHERE c.type = 'direct'
+    GROUP BY cm.conv_id
+    HAVING COUNT(*) = 2
+) pairs
+ORDER BY user_low, user_high, conv_id
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,20 @@
+-- Client idempotency keys for message sends, scoped per conversation and sender
+CREATE TABLE message_idempotency (
+    conv_id BIGINT NOT NULL,
+    sender_id BIGINT NOT NULL,
+    idempotency_key VARCHAR(128) NOT NULL,
+    msg_id UUID NOT NULL,
+    seq BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (conv_id, sender_id, idempotency_key)
+);
+
+CREATE INDEX idx_message_idempotency_created_at ON message_idempotency (created_at);
+
+-- Function to purge idempotency keys older than the message retention period
+CREATE OR REPLACE FUNCTION purge_message_idempotency()
+RETURNS void AS $$
+BEGIN
+    DELETE FROM message_idempotency WHERE created_at < NOW() - INTERVAL '30 days';
+END;
+$$ LANGUAGE plpgsql;
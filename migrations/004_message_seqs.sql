@@ -0,0 +1,25 @@
+-- Message sequence index: guarantees (conv_id, seq) is unique across all message partitions
+CREATE TABLE message_seqs (
+    conv_id BIGINT NOT NULL,
+    seq BIGINT NOT NULL,
+    msg_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (conv_id, seq)
+);
+
+CREATE INDEX idx_message_seqs_created_at ON message_seqs (created_at);
+
+-- Backfill existing messages, keeping the earliest message for any duplicated seq
+INSERT INTO message_seqs (conv_id, seq, msg_id, created_at)
+SELECT DISTINCT ON (conv_id, seq) conv_id, seq, msg_id, created_at
+FROM messages
+ORDER BY conv_id, seq, created_at
+ON CONFLICT DO NOTHING;
+
+-- Function to purge sequence index entries older than the message retention period
+CREATE OR REPLACE FUNCTION purge_message_seqs()
+RETURNS void AS $$
+BEGIN
+    DELETE FROM message_seqs WHERE created_at < NOW() - INTERVAL '30 days';
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,26 @@
+-- Track in-place edits of messages
+ALTER TABLE messages ADD COLUMN edited_at TIMESTAMPTZ;
+
+-- Message edit history
+CREATE TABLE message_edits (
+    id BIGSERIAL PRIMARY KEY,
+    conv_id BIGINT NOT NULL,
+    msg_id UUID NOT NULL,
+    editor_id BIGINT NOT NULL,
+    old_body JSONB NOT NULL,
+    new_body JSONB NOT NULL,
+    event_msg_id UUID NOT NULL,
+    event_seq BIGINT NOT NULL,
+    edited_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_message_edits_msg ON message_edits (conv_id, msg_id, edited_at);
+CREATE INDEX idx_message_edits_edited_at ON message_edits (edited_at);
+
+-- Function to purge edit history older than the message retention period
+CREATE OR REPLACE FUNCTION purge_message_edits()
+RETURNS void AS $$
+BEGIN
+    DELETE FROM message_edits WHERE edited_at < NOW() - INTERVAL '30 days';
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,25 @@
+-- Track email verification
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+
+-- Single-use account tokens (password reset, email verification).
+-- Only the SHA-256 hash of a token is stored.
+CREATE TABLE account_tokens (
+    token_hash CHAR(64) PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(20) NOT NULL CHECK (purpose IN ('password_reset', 'email_verify')),
+    email VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_account_tokens_user ON account_tokens (user_id, purpose);
+CREATE INDEX idx_account_tokens_expires_at ON account_tokens (expires_at);
+
+-- Function to purge expired and used account tokens
+CREATE OR REPLACE FUNCTION purge_account_tokens()
+RETURNS void AS $$
+BEGIN
+    DELETE FROM account_tokens WHERE expires_at < NOW() OR used_at IS NOT NULL;
+END;
+$$ LANGUAGE plpgsql;
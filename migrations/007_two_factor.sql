@@ -0,0 +1,16 @@
+-- TOTP two-factor authentication.
+-- totp_secret is set at enrollment; 2FA is active once totp_enabled_at is set.
+-- totp_last_step is the last accepted time step, so a code cannot be replayed.
+ALTER TABLE users ADD COLUMN totp_secret VARCHAR(64);
+ALTER TABLE users ADD COLUMN totp_enabled_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN totp_last_step BIGINT;
+
+-- Single-use recovery codes for when the authenticator is lost.
+-- Only the SHA-256 hash of a code is stored.
+CREATE TABLE recovery_codes (
+    code_hash CHAR(64) NOT NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, code_hash)
+);
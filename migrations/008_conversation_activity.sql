@@ -0,0 +1,28 @@
+-- Last activity time per conversation, so the inbox can be ordered and
+-- paginated without looking up the latest message of every conversation.
+-- next_conv_seq stamps it whenever a message is inserted.
+ALTER TABLE conversation_seq ADD COLUMN last_active_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+UPDATE conversation_seq cs
+SET last_active_at = COALESCE(
+    (SELECT MAX(m.created_at) FROM messages m WHERE m.conv_id = cs.conv_id),
+    c.created_at
+)
+FROM conversations c
+WHERE c.id = cs.conv_id;
+
+CREATE OR REPLACE FUNCTION next_conv_seq(p_conv_id BIGINT)
+RETURNS BIGINT AS $$
+DECLARE
+    v_seq BIGINT;
+BEGIN
+    INSERT INTO conversation_seq (conv_id, current_seq, last_active_at)
+    VALUES (p_conv_id, 1, NOW())
+    ON CONFLICT (conv_id)
+    DO UPDATE SET current_seq = conversation_seq.current_seq + 1,
+                  last_active_at = NOW()
+    RETURNING current_seq INTO v_seq;
+
+    RETURN v_seq;
+END;
+$$ LANGUAGE plpgsql;
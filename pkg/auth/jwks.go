@@ -0,0 +1,260 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWKSPath is where the user service publishes its JWKS document
+const JWKSPath = "/.well-known/jwks.json"
+
+const (
+	// jwksMinRefetch limits how often an unknown kid triggers a refetch
+	jwksMinRefetch = 10 * time.Second
+	// defaultJWKSRefresh is used when no refresh interval is configured
+	defaultJWKSRefresh = 5 * time.Minute
+)
+
+// JWK is a public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set document
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public halves of the published keys
+func (s *KeySet) JWKS() *JWKS {
+	published := s.Published()
+	doc := &JWKS{Keys: make([]JWK, 0, len(published))}
+	for _, key := range published {
+		jwk, err := toJWK(key)
+		if err != nil {
+			continue
+		}
+		doc.Keys = append(doc.Keys, jwk)
+	}
+	return doc
+}
+
+// JWKSHandler serves the key set's JWKS document
+func JWKSHandler(keys *KeySet) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		_ = json.NewEncoder(w).Encode(keys.JWKS())
+	})
+}
+
+// JWKSClient verifies tokens with the keys published at a JWKS URL. The
+// document is cached for refreshInterval and refetched early when a token
+// names an unknown kid, so keys published ahead of a rotation are picked up.
+// Fetches happen outside the lock, one at a time, and at most once per
+// jwksMinRefetch whether or not the previous fetch succeeded.
+type JWKSClient struct {
+	url             string
+	refreshInterval time.Duration
+	httpClient      *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]*SigningKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	lastErr     error
+	// fetching is closed when the fetch in progress finishes
+	fetching chan struct{}
+}
+
+// NewJWKSClient creates a JWKS client; the document is fetched on first use
+func NewJWKSClient(url string, refreshInterval time.Duration) *JWKSClient {
+	if refreshInterval <= 0 {
+		refreshInterval = defaultJWKSRefresh
+	}
+	return &JWKSClient{
+		url:             url,
+		refreshInterval: refreshInterval,
+		httpClient:      &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// SigningKey always fails: JWKS only carries public keys
+func (c *JWKSClient) SigningKey() (*SigningKey, error) {
+	return nil, ErrNoSigningKey
+}
+
+// VerificationKey returns a published key by its kid
+func (c *JWKSClient) VerificationKey(kid string) (*SigningKey, error) {
+	for {
+		c.mu.Lock()
+		key, ok := c.keys[kid]
+		if ok && time.Since(c.fetchedAt) < c.refreshInterval {
+			c.mu.Unlock()
+			return key, nil
+		}
+
+		// A cached key stays usable while a refresh is in progress
+		if wait := c.fetching; wait != nil {
+			c.mu.Unlock()
+			if ok {
+				return key, nil
+			}
+			<-wait
+			continue
+		}
+
+		if time.Since(c.attemptedAt) < jwksMinRefetch {
+			lastErr := c.lastErr
+			c.mu.Unlock()
+			// Keep verifying with the cached keys while the issuer is unreachable
+			if ok {
+				return key, nil
+			}
+			if lastErr != nil {
+				return nil, lastErr
+			}
+			return nil, fmt.Errorf("%w: %s", ErrUnknownKey, kid)
+		}
+
+		done := make(chan struct{})
+		c.fetching = done
+		c.attemptedAt = time.Now()
+		c.mu.Unlock()
+
+		keys, err := c.fetch(context.Background())
+
+		c.mu.Lock()
+		if err == nil {
+			c.keys = keys
+			c.fetchedAt = time.Now()
+		}
+		c.lastErr = err
+		c.fetching = nil
+		close(done)
+		c.mu.Unlock()
+	}
+}
+
+// fetch downloads and parses the JWKS document
+func (c *JWKSClient) fetch(ctx context.Context) (map[string]*SigningKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	var doc JWKS
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	return ParseJWKS(&doc)
+}
+
+// ParseJWKS converts a JWKS document into verification keys by kid. Keys
+// with an unsupported type or algorithm are skipped.
+func ParseJWKS(doc *JWKS) (map[string]*SigningKey, error) {
+	keys := make(map[string]*SigningKey, len(doc.Keys))
+	for _, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := fromJWK(jwk)
+		if err != nil {
+			return nil, err
+		}
+		if key != nil {
+			keys[key.ID] = key
+		}
+	}
+	return keys, nil
+}
+
+func toJWK(key *SigningKey) (JWK, error) {
+	switch public := key.Public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: key.ID,
+			Use: "sig",
+			Alg: key.Method.Alg(),
+			N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Kid: key.ID,
+			Use: "sig",
+			Alg: key.Method.Alg(),
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(public),
+		}, nil
+	default:
+		return JWK{}, fmt.Errorf("unsupported public key type %T", key.Public)
+	}
+}
+
+func fromJWK(jwk JWK) (*SigningKey, error) {
+	switch {
+	case jwk.Kty == "RSA" && jwk.Alg == jwt.SigningMethodRS256.Alg():
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWK %s modulus: %w", jwk.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWK %s exponent: %w", jwk.Kid, err)
+		}
+		return &SigningKey{
+			ID:     jwk.Kid,
+			Method: jwt.SigningMethodRS256,
+			Public: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())},
+		}, nil
+	case jwk.Kty == "OKP" && jwk.Crv == "Ed25519" && jwk.Alg == jwt.SigningMethodEdDSA.Alg():
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid JWK %s public key", jwk.Kid)
+		}
+		return &SigningKey{
+			ID:     jwk.Kid,
+			Method: jwt.SigningMethodEdDSA,
+			Public: ed25519.PublicKey(x),
+		}, nil
+	default:
+		return nil, nil
+	}
+}
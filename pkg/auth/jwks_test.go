@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWKSClient_VerifiesPublishedKeys(t *testing.T) {
+	keys, err := NewKeySet(time.Hour,
+		newEd25519Key(t, "ed-1", time.Time{}),
+		newRSAKey(t, "rsa-1", time.Now().Add(-time.Minute)),
+	)
+	require.NoError(t, err)
+
+	var fetches atomic.Int32
+	handler := JWKSHandler(keys)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	issuer := NewJWTManager("", time.Hour).WithKeys(keys)
+	verifier := NewJWTManager("", time.Hour).WithKeys(NewJWKSClient(server.URL, time.Minute))
+
+	token, _, err := issuer.Generate(100, "device-001")
+	require.NoError(t, err)
+
+	claims, err := verifier.Validate(token)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), claims.UserID)
+
+	// the document is cached
+	_, err = verifier.Validate(token)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	// verifiers cannot sign
+	_, _, err = verifier.Generate(100, "device-001")
+	assert.ErrorIs(t, err, ErrNoSigningKey)
+}
+
+func TestJWKSClient_RefetchesUnknownKid(t *testing.T) {
+	keys, err := NewKeySet(time.Hour, newEd25519Key(t, "ed-1", time.Time{}))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(JWKSHandler(keys))
+	defer server.Close()
+
+	client := NewJWKSClient(server.URL, time.Hour)
+	_, err = client.VerificationKey("ed-1")
+	require.NoError(t, err)
+
+	require.NoError(t, keys.Add(newEd25519Key(t, "ed-2", time.Now().Add(time.Hour))))
+
+	// within the refetch limit the cached document is used
+	_, err = client.VerificationKey("ed-2")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	client.attemptedAt = time.Now().Add(-jwksMinRefetch)
+	key, err := client.VerificationKey("ed-2")
+	require.NoError(t, err)
+	assert.Equal(t, "ed-2", key.ID)
+	assert.Nil(t, key.Private)
+}
+
+func TestJWKSClient_KeepsCachedKeysWhenIssuerDown(t *testing.T) {
+	keys, err := NewKeySet(time.Hour, newEd25519Key(t, "ed-1", time.Time{}))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(JWKSHandler(keys))
+	client := NewJWKSClient(server.URL, time.Minute)
+	_, err = client.VerificationKey("ed-1")
+	require.NoError(t, err)
+	server.Close()
+
+	client.fetchedAt = time.Now().Add(-time.Hour)
+	client.attemptedAt = client.fetchedAt
+	_, err = client.VerificationKey("ed-1")
+	assert.NoError(t, err)
+}
+
+func TestJWKSClient_FetchesOnceWhileIssuerDown(t *testing.T) {
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewJWKSClient(server.URL, time.Minute)
+
+	// concurrent lookups share the fetch in progress
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = client.VerificationKey("unknown")
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.ErrorContains(t, err, "unexpected status 503")
+	}
+	assert.Equal(t, int32(1), fetches.Load())
+
+	// a failed fetch backs off like a successful one
+	_, err := client.VerificationKey("other")
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), fetches.Load())
+}
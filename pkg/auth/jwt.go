@@ -1,57 +1,152 @@
 package auth
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+const (
+	// DefaultIssuer is the iss claim of tokens issued by the user service
+	DefaultIssuer = "im-system"
+	// DefaultAudience is the aud claim every service accepts
+	DefaultAudience = "im-api"
+)
+
+func init() {
+	// Token times carry milliseconds so that revocation can tell a token
+	// issued right after a revocation from one issued right before it
+	jwt.TimePrecision = time.Millisecond
+}
+
+// ErrLegacyToken is returned for tokens in the pre-sub format once the
+// legacy window has closed
+var ErrLegacyToken = errors.New("legacy token format is no longer accepted")
+
+// Claims carries the user ID in the standard sub claim. UserID is filled in
+// by Validate; it is only serialized by legacy tokens, which predate sub.
 type Claims struct {
-	UserID   int64  `json:"user_id"`
+	UserID   int64  `json:"user_id,omitempty"`
 	DeviceID string `json:"device_id"`
 	jwt.RegisteredClaims
 }
 
 type JWTManager struct {
-	secretKey string
-	expiry    time.Duration
+	secretKey   string
+	keys        KeyProvider
+	expiry      time.Duration
+	issuer      string
+	audience    string
+	leeway      time.Duration
+	legacyUntil time.Time
 }
 
+// NewJWTManager creates a manager that signs and verifies HS256 tokens with
+// a shared secret. An empty secret disables HS256.
 func NewJWTManager(secretKey string, expiry time.Duration) *JWTManager {
 	return &JWTManager{
 		secretKey: secretKey,
 		expiry:    expiry,
+		issuer:    DefaultIssuer,
+		audience:  DefaultAudience,
+	}
+}
+
+// JWTOptions holds the optional token settings; zero values keep the defaults
+type JWTOptions struct {
+	// Issuer and Audience are written to and required of tokens
+	Issuer   string
+	Audience string
+	// Leeway is the clock skew tolerated when checking exp, nbf and iat
+	Leeway time.Duration
+	// LegacyUntil accepts tokens in the old format (user ID in user_id, no
+	// sub or iss) until this time. Once every legacy token has expired the
+	// window can be closed by leaving it zero.
+	LegacyUntil time.Time
+}
+
+// WithOptions applies the non-zero options
+func (m *JWTManager) WithOptions(opts JWTOptions) *JWTManager {
+	if opts.Issuer != "" {
+		m.issuer = opts.Issuer
 	}
+	if opts.Audience != "" {
+		m.audience = opts.Audience
+	}
+	m.leeway = opts.Leeway
+	m.legacyUntil = opts.LegacyUntil
+	return m
+}
+
+// WithKeys switches signing to the provider's asymmetric keys (RS256 or
+// EdDSA). Tokens carrying a kid are verified with the provider; HS256 tokens
+// without a kid are still accepted while a secret is set, so tokens issued
+// before the switch stay valid until they expire.
+func (m *JWTManager) WithKeys(keys KeyProvider) *JWTManager {
+	m.keys = keys
+	return m
 }
 
-// Generate creates a new JWT token
-func (m *JWTManager) Generate(userID int64, deviceID string) (string, error) {
+// Generate creates a new JWT access token and returns it with its expiry.
+// Each token carries a unique ID (jti) so it can be revoked on its own.
+func (m *JWTManager) Generate(userID int64, deviceID string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := jwt.NewNumericDate(now.Add(m.expiry))
 	claims := &Claims{
-		UserID:   userID,
 		DeviceID: deviceID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.expiry)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Audience:  jwt.ClaimStrings{"im-api"},
+			ID:        uuid.NewString(),
+			Subject:   strconv.FormatInt(userID, 10),
+			Issuer:    m.issuer,
+			Audience:  jwt.ClaimStrings{m.audience},
+			ExpiresAt: expiresAt,
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
+	if m.keys != nil {
+		key, err := m.keys.SigningKey()
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		token := jwt.NewWithClaims(key.Method, claims)
+		token.Header["kid"] = key.ID
+		signed, err := token.SignedString(key.Private)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		return signed, expiresAt.Time, nil
+	}
+
+	if m.secretKey == "" {
+		return "", time.Time{}, ErrNoSigningKey
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(m.secretKey))
+	signed, err := token.SignedString([]byte(m.secretKey))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt.Time, nil
 }
 
-// Validate validates a JWT token and returns the claims
+// Validate validates a JWT token and returns the claims. The signature,
+// audience, expiry and issue time are always checked, allowing for the
+// configured clock skew. Tokens with a sub must carry the configured issuer;
+// tokens without one are legacy tokens and only accepted within the legacy
+// window.
 func (m *JWTManager) Validate(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(
 		tokenString,
 		&Claims{},
-		func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(m.secretKey), nil
-		},
+		m.keyFunc,
+		jwt.WithAudience(m.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(m.leeway),
 	)
 
 	if err != nil {
@@ -63,5 +158,45 @@ func (m *JWTManager) Validate(tokenString string) (*Claims, error) {
 		return nil, fmt.Errorf("invalid token")
 	}
 
+	if claims.Subject == "" {
+		if !time.Now().Before(m.legacyUntil) {
+			return nil, ErrLegacyToken
+		}
+		return claims, nil
+	}
+
+	if claims.Issuer != m.issuer {
+		return nil, fmt.Errorf("invalid token: unexpected issuer %q", claims.Issuer)
+	}
+
+	userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token subject: %w", err)
+	}
+	claims.UserID = userID
+
 	return claims, nil
 }
+
+// keyFunc picks the verification key for a token. The algorithm must match
+// the key it names, so a public key can never be used as an HMAC secret.
+func (m *JWTManager) keyFunc(token *jwt.Token) (interface{}, error) {
+	if kid, ok := token.Header["kid"].(string); ok && m.keys != nil {
+		key, err := m.keys.VerificationKey(kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %v for key %s", token.Header["alg"], kid)
+		}
+		return key.Public, nil
+	}
+
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	if m.secretKey == "" {
+		return nil, errors.New("HS256 tokens are not accepted")
+	}
+	return []byte(m.secretKey), nil
+}
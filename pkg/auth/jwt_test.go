@@ -51,7 +51,7 @@ func TestJWTManager_Generate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			token, err := manager.Generate(tt.userID, tt.deviceID)
+			token, _, err := manager.Generate(tt.userID, tt.deviceID)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -86,7 +86,7 @@ func TestJWTManager_Validate(t *testing.T) {
 		{
 			name: "valid token",
 			setupToken: func() string {
-				token, _ := manager.Generate(100, "device-100")
+				token, _, _ := manager.Generate(100, "device-100")
 				return token
 			},
 			wantUserID: 100,
@@ -97,7 +97,7 @@ func TestJWTManager_Validate(t *testing.T) {
 			name: "invalid signature",
 			setupToken: func() string {
 				wrongManager := NewJWTManager("wrong-secret", 1*time.Hour)
-				token, _ := wrongManager.Generate(200, "device-200")
+				token, _, _ := wrongManager.Generate(200, "device-200")
 				return token
 			},
 			wantErr:     true,
@@ -107,7 +107,7 @@ func TestJWTManager_Validate(t *testing.T) {
 			name: "expired token",
 			setupToken: func() string {
 				expiredManager := NewJWTManager(secretKey, -1*time.Hour)
-				token, _ := expiredManager.Generate(300, "device-300")
+				token, _, _ := expiredManager.Generate(300, "device-300")
 				return token
 			},
 			wantErr:     true,
@@ -157,7 +157,7 @@ func TestJWTManager_TokenExpiration(t *testing.T) {
 	// Create manager with very short expiration
 	manager := NewJWTManager("test-secret", 1*time.Millisecond)
 
-	token, err := manager.Generate(999, "device-999")
+	token, _, err := manager.Generate(999, "device-999")
 	require.NoError(t, err)
 
 	// Wait for token to expire
@@ -171,7 +171,7 @@ func TestJWTManager_TokenExpiration(t *testing.T) {
 func TestJWTManager_ValidateAudience(t *testing.T) {
 	manager := NewJWTManager("test-secret", 1*time.Hour)
 
-	token, err := manager.Generate(777, "device-777")
+	token, _, err := manager.Generate(777, "device-777")
 	require.NoError(t, err)
 
 	claims, err := manager.Validate(token)
@@ -181,3 +181,91 @@ func TestJWTManager_ValidateAudience(t *testing.T) {
 	// Verify audience is set correctly
 	assert.Contains(t, claims.Audience, "im-api")
 }
+
+func TestJWTManager_StandardClaims(t *testing.T) {
+	manager := NewJWTManager("test-secret", time.Hour)
+
+	token, expiresAt, err := manager.Generate(42, "device-042")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Second)
+
+	// the user ID travels in sub, not in a custom claim
+	raw := jwt.MapClaims{}
+	_, _, err = jwt.NewParser().ParseUnverified(token, raw)
+	require.NoError(t, err)
+	assert.Equal(t, "42", raw["sub"])
+	assert.Equal(t, DefaultIssuer, raw["iss"])
+	assert.NotContains(t, raw, "user_id")
+
+	claims, err := manager.Validate(token)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), claims.UserID)
+	assert.Equal(t, "device-042", claims.DeviceID)
+	assert.True(t, claims.ExpiresAt.Time.Equal(expiresAt))
+}
+
+func TestJWTManager_ValidateIssuerAndAudience(t *testing.T) {
+	token, _, err := NewJWTManager("test-secret", time.Hour).Generate(42, "device-042")
+	require.NoError(t, err)
+
+	_, err = NewJWTManager("test-secret", time.Hour).WithOptions(JWTOptions{Issuer: "other-issuer"}).Validate(token)
+	assert.ErrorContains(t, err, "unexpected issuer")
+
+	_, err = NewJWTManager("test-secret", time.Hour).WithOptions(JWTOptions{Audience: "other-api"}).Validate(token)
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+}
+
+func TestJWTManager_Leeway(t *testing.T) {
+	issuer := NewJWTManager("test-secret", -10*time.Second)
+	token, _, err := issuer.Generate(42, "device-042")
+	require.NoError(t, err)
+
+	_, err = NewJWTManager("test-secret", time.Hour).Validate(token)
+	assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+
+	_, err = NewJWTManager("test-secret", time.Hour).WithOptions(JWTOptions{Leeway: 30 * time.Second}).Validate(token)
+	assert.NoError(t, err)
+}
+
+func TestJWTManager_LegacyTokens(t *testing.T) {
+	legacy := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+		UserID:   42,
+		DeviceID: "device-042",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Audience:  jwt.ClaimStrings{DefaultAudience},
+		},
+	})
+	token, err := legacy.SignedString([]byte("test-secret"))
+	require.NoError(t, err)
+
+	_, err = NewJWTManager("test-secret", time.Hour).Validate(token)
+	assert.ErrorIs(t, err, ErrLegacyToken)
+
+	claims, err := NewJWTManager("test-secret", time.Hour).
+		WithOptions(JWTOptions{LegacyUntil: time.Now().Add(time.Hour)}).
+		Validate(token)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), claims.UserID)
+
+	_, err = NewJWTManager("test-secret", time.Hour).
+		WithOptions(JWTOptions{LegacyUntil: time.Now().Add(-time.Minute)}).
+		Validate(token)
+	assert.ErrorIs(t, err, ErrLegacyToken)
+}
+
+func TestJWTManager_WithOptions(t *testing.T) {
+	token, _, err := NewJWTManager("test-secret", time.Hour).Generate(42, "device-042")
+	require.NoError(t, err)
+
+	// Empty issuer and audience keep the defaults
+	_, err = NewJWTManager("test-secret", time.Hour).WithOptions(JWTOptions{Leeway: time.Second}).Validate(token)
+	assert.NoError(t, err)
+
+	_, err = NewJWTManager("test-secret", time.Hour).WithOptions(JWTOptions{Issuer: "other-issuer"}).Validate(token)
+	assert.ErrorContains(t, err, "unexpected issuer")
+
+	_, err = NewJWTManager("test-secret", time.Hour).WithOptions(JWTOptions{Audience: "other-api"}).Validate(token)
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+}
@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrNoSigningKey is returned when no private key is active for signing
+	ErrNoSigningKey = errors.New("no active signing key")
+	// ErrUnknownKey is returned when a token's kid matches no published key
+	ErrUnknownKey = errors.New("unknown signing key")
+)
+
+// KeyProvider resolves the keys used to sign and verify tokens
+type KeyProvider interface {
+	// SigningKey returns the key new tokens are signed with
+	SigningKey() (*SigningKey, error)
+	// VerificationKey returns the key identified by a token's kid header
+	VerificationKey(kid string) (*SigningKey, error)
+}
+
+// SigningKey is an asymmetric token key identified by its kid. Keys taken
+// from a JWKS document only carry the public half.
+type SigningKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.Signer
+	Public  crypto.PublicKey
+	// ActiveFrom is when the key starts signing; a zero time means immediately
+	ActiveFrom time.Time
+}
+
+// LoadSigningKey reads a PEM encoded RSA or Ed25519 private key. algorithm
+// is RS256 or EdDSA and must match the key type.
+func LoadSigningKey(id, algorithm, path string, activeFrom time.Time) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key %s: %w", id, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s is not PEM encoded", id)
+	}
+
+	var private any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %w", id, err)
+	}
+
+	return NewSigningKey(id, algorithm, private, activeFrom)
+}
+
+// NewSigningKey wraps a *rsa.PrivateKey (RS256) or ed25519.PrivateKey (EdDSA)
+func NewSigningKey(id, algorithm string, private any, activeFrom time.Time) (*SigningKey, error) {
+	if id == "" {
+		return nil, errors.New("signing key id is required")
+	}
+
+	switch key := private.(type) {
+	case *rsa.PrivateKey:
+		if algorithm != jwt.SigningMethodRS256.Alg() {
+			return nil, fmt.Errorf("signing key %s: RSA key cannot be used with %q", id, algorithm)
+		}
+		return &SigningKey{ID: id, Method: jwt.SigningMethodRS256, Private: key, Public: &key.PublicKey, ActiveFrom: activeFrom}, nil
+	case ed25519.PrivateKey:
+		if algorithm != jwt.SigningMethodEdDSA.Alg() {
+			return nil, fmt.Errorf("signing key %s: Ed25519 key cannot be used with %q", id, algorithm)
+		}
+		return &SigningKey{ID: id, Method: jwt.SigningMethodEdDSA, Private: key, Public: key.Public(), ActiveFrom: activeFrom}, nil
+	default:
+		return nil, fmt.Errorf("signing key %s: unsupported key type %T", id, private)
+	}
+}
+
+// KeySet holds the signing keys of the issuing service. Rotation is
+// scheduled through ActiveFrom: the newest active key signs, a key is
+// published ahead of its activation so verifiers already know it when the
+// first token arrives, and a superseded key stays valid for one token
+// lifetime so tokens signed before the switch keep working.
+type KeySet struct {
+	mu       sync.RWMutex
+	keys     []*SigningKey
+	tokenTTL time.Duration
+	now      func() time.Time
+}
+
+// NewKeySet creates a key set; tokenTTL is the access token lifetime
+func NewKeySet(tokenTTL time.Duration, keys ...*SigningKey) (*KeySet, error) {
+	s := &KeySet{
+		tokenTTL: tokenTTL,
+		now:      time.Now,
+	}
+	for _, key := range keys {
+		if err := s.Add(key); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Add adds a private key to the set, e.g. the next key of a rotation
+func (s *KeySet) Add(key *SigningKey) error {
+	if key.Private == nil {
+		return fmt.Errorf("signing key %s has no private key", key.ID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.keys {
+		if existing.ID == key.ID {
+			return fmt.Errorf("duplicate signing key id %s", key.ID)
+		}
+	}
+
+	s.keys = append(s.keys, key)
+	sort.SliceStable(s.keys, func(i, j int) bool {
+		return s.keys[i].ActiveFrom.Before(s.keys[j].ActiveFrom)
+	})
+	return nil
+}
+
+// SigningKey returns the most recently activated key
+func (s *KeySet) SigningKey() (*SigningKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.now()
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		if !s.keys[i].ActiveFrom.After(now) {
+			return s.keys[i], nil
+		}
+	}
+	return nil, ErrNoSigningKey
+}
+
+// VerificationKey returns a published key by its kid
+func (s *KeySet) VerificationKey(kid string) (*SigningKey, error) {
+	for _, key := range s.Published() {
+		if key.ID == kid {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownKey, kid)
+}
+
+// Published returns the keys verifiers should accept: upcoming keys, the
+// current signing key and keys superseded less than one token lifetime ago
+func (s *KeySet) Published() []*SigningKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.now()
+	published := make([]*SigningKey, 0, len(s.keys))
+	for i, key := range s.keys {
+		if i+1 < len(s.keys) {
+			successor := s.keys[i+1].ActiveFrom
+			if !successor.After(now) && now.Sub(successor) > s.tokenTTL {
+				continue
+			}
+		}
+		published = append(published, key)
+	}
+	return published
+}
+
+// KeyFile describes a private key file of the issuing service
+type KeyFile struct {
+	ID        string
+	Algorithm string
+	Path      string
+	// ActiveFrom is when the key starts signing; a zero time means immediately
+	ActiveFrom time.Time
+}
+
+// LoadKeySet loads the signing keys of the issuing service. It returns nil
+// when no keys are given, leaving HS256 in place.
+func LoadKeySet(tokenTTL time.Duration, files []KeyFile) (*KeySet, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]*SigningKey, 0, len(files))
+	for _, file := range files {
+		key, err := LoadSigningKey(file.ID, file.Algorithm, file.Path, file.ActiveFrom)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return NewKeySet(tokenTTL, keys...)
+}
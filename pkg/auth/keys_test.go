@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEd25519Key(t *testing.T, id string, activeFrom time.Time) *SigningKey {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := NewSigningKey(id, "EdDSA", private, activeFrom)
+	require.NoError(t, err)
+	return key
+}
+
+func newRSAKey(t *testing.T, id string, activeFrom time.Time) *SigningKey {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key, err := NewSigningKey(id, "RS256", private, activeFrom)
+	require.NoError(t, err)
+	return key
+}
+
+func TestJWTManager_AsymmetricKeys(t *testing.T) {
+	for _, key := range []*SigningKey{
+		newEd25519Key(t, "ed-1", time.Time{}),
+		newRSAKey(t, "rsa-1", time.Time{}),
+	} {
+		t.Run(key.Method.Alg(), func(t *testing.T) {
+			keys, err := NewKeySet(time.Hour, key)
+			require.NoError(t, err)
+			manager := NewJWTManager("", time.Hour).WithKeys(keys)
+
+			token, _, err := manager.Generate(100, "device-001")
+			require.NoError(t, err)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+			require.NoError(t, err)
+			assert.Equal(t, key.ID, parsed.Header["kid"])
+			assert.Equal(t, key.Method.Alg(), parsed.Header["alg"])
+
+			claims, err := manager.Validate(token)
+			require.NoError(t, err)
+			assert.Equal(t, int64(100), claims.UserID)
+		})
+	}
+}
+
+func TestJWTManager_LegacyHS256(t *testing.T) {
+	legacy, _, err := NewJWTManager("test-secret", time.Hour).Generate(100, "device-001")
+	require.NoError(t, err)
+
+	keys, err := NewKeySet(time.Hour, newEd25519Key(t, "ed-1", time.Time{}))
+	require.NoError(t, err)
+
+	// tokens issued before the switch stay valid while the secret is kept
+	_, err = NewJWTManager("test-secret", time.Hour).WithKeys(keys).Validate(legacy)
+	assert.NoError(t, err)
+
+	_, err = NewJWTManager("", time.Hour).WithKeys(keys).Validate(legacy)
+	assert.Error(t, err)
+}
+
+func TestJWTManager_RejectsAlgorithmConfusion(t *testing.T) {
+	key := newRSAKey(t, "rsa-1", time.Time{})
+	keys, err := NewKeySet(time.Hour, key)
+	require.NoError(t, err)
+	manager := NewJWTManager("test-secret", time.Hour).WithKeys(keys)
+
+	// an HS256 token naming the RSA key, signed with its public key bytes
+	publicDER, err := x509.MarshalPKIXPublicKey(key.Public)
+	require.NoError(t, err)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+		UserID: 1,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+	forged.Header["kid"] = key.ID
+	token, err := forged.SignedString(publicDER)
+	require.NoError(t, err)
+
+	_, err = manager.Validate(token)
+	assert.Error(t, err)
+}
+
+func TestKeySet_ScheduledRotation(t *testing.T) {
+	now := time.Now()
+	current := newEd25519Key(t, "key-1", now.Add(-24*time.Hour))
+	next := newEd25519Key(t, "key-2", now.Add(time.Hour))
+
+	keys, err := NewKeySet(15*time.Minute, next, current)
+	require.NoError(t, err)
+	keys.now = func() time.Time { return now }
+	manager := NewJWTManager("", 15*time.Minute).WithKeys(keys)
+
+	// the next key is published before it signs
+	signing, err := keys.SigningKey()
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", signing.ID)
+	assert.Len(t, keys.JWKS().Keys, 2)
+
+	oldToken, _, err := manager.Generate(100, "device-001")
+	require.NoError(t, err)
+
+	// after activation the new key signs and in-flight tokens still verify
+	keys.now = func() time.Time { return now.Add(time.Hour + 5*time.Minute) }
+	signing, err = keys.SigningKey()
+	require.NoError(t, err)
+	assert.Equal(t, "key-2", signing.ID)
+
+	_, err = keys.VerificationKey("key-1")
+	assert.NoError(t, err)
+
+	// one token lifetime later the old key is withdrawn
+	keys.now = func() time.Time { return now.Add(time.Hour + 20*time.Minute) }
+	_, err = keys.VerificationKey("key-1")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	assert.Len(t, keys.JWKS().Keys, 1)
+
+	_, err = manager.Validate(oldToken)
+	assert.Error(t, err)
+}
+
+func TestNewSigningKey_AlgorithmMismatch(t *testing.T) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	_, err = NewSigningKey("ed-1", "RS256", private, time.Time{})
+	assert.Error(t, err)
+}
+
+func TestLoadKeySet(t *testing.T) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	keys, err := LoadKeySet(time.Hour, []KeyFile{
+		{ID: "2026-10", Algorithm: "EdDSA", Path: path, ActiveFrom: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+	})
+	require.NoError(t, err)
+
+	keys.now = func() time.Time { return time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC) }
+	signing, err := keys.SigningKey()
+	require.NoError(t, err)
+	assert.Equal(t, "2026-10", signing.ID)
+
+	keys.now = func() time.Time { return time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC) }
+	_, err = keys.SigningKey()
+	assert.ErrorIs(t, err, ErrNoSigningKey)
+
+	// no keys configured keeps HS256
+	keys, err = LoadKeySet(time.Hour, nil)
+	require.NoError(t, err)
+	assert.Nil(t, keys)
+}
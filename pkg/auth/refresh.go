@@ -0,0 +1,225 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// refreshSessionKeyPrefix maps a user's device to the hash of its
+	// current refresh token
+	refreshSessionKeyPrefix = "refresh_session:"
+	// refreshTokenKeyPrefix maps a refresh token hash to its session. Keys of
+	// rotated tokens are kept until they expire so that reuse is detected.
+	refreshTokenKeyPrefix = "refresh_token:"
+	// refreshDevicesKeyPrefix lists the devices of a user with a refresh session
+	refreshDevicesKeyPrefix = "refresh_devices:"
+
+	refreshTokenBytes = 32
+)
+
+var (
+	// ErrInvalidRefreshToken is returned for unknown, expired or logged out refresh tokens
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token
+	// is presented again; the session is ended because the token may be stolen
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+// refreshRotateScript swaps the session's current token hash for a new one
+// if it still holds the presented one. A session that has moved on to
+// another token means the presented token was reused, so the session is
+// deleted. The user's device set is extended along with the session so that
+// RevokeUser still finds it. Returns 1 on rotation, 0 if the session is gone
+// and -1 on reuse.
+var refreshRotateScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current ~= ARGV[1] then
+  if current then
+    redis.call('DEL', KEYS[1])
+    return -1
+  end
+  return 0
+end
+redis.call('SET', KEYS[1], ARGV[2], 'EX', ARGV[4])
+redis.call('SET', KEYS[2], ARGV[3], 'EX', ARGV[4])
+redis.call('SADD', KEYS[3], ARGV[5])
+redis.call('EXPIRE', KEYS[3], ARGV[4])
+return 1
+`)
+
+// refreshSession identifies the device a refresh token belongs to
+type refreshSession struct {
+	UserID   int64  `json:"user_id"`
+	DeviceID string `json:"device_id"`
+}
+
+// RefreshStore persists one rotating refresh token per user device in Redis.
+// Only token hashes are stored.
+type RefreshStore struct {
+	redis *redis.Client
+	ttl   time.Duration
+}
+
+// NewRefreshStore creates a refresh token store; ttl is the refresh token lifetime
+func NewRefreshStore(redisClient *redis.Client, ttl time.Duration) *RefreshStore {
+	return &RefreshStore{
+		redis: redisClient,
+		ttl:   ttl,
+	}
+}
+
+// Issue starts a new refresh session for a device, replacing any previous one
+func (s *RefreshStore) Issue(ctx context.Context, userID int64, deviceID string) (string, time.Time, error) {
+	token, hash, err := newRefreshToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	session, err := json.Marshal(refreshSession{UserID: userID, DeviceID: deviceID})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to marshal refresh session: %w", err)
+	}
+
+	sessionKey := refreshSessionKey(userID, deviceID)
+	previous, err := s.redis.Get(ctx, sessionKey).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", time.Time{}, fmt.Errorf("failed to get refresh session: %w", err)
+	}
+
+	// Tokens of a previous login become invalid rather than reused
+	pipe := s.redis.TxPipeline()
+	if previous != "" {
+		pipe.Del(ctx, refreshTokenKeyPrefix+previous)
+	}
+	pipe.Set(ctx, sessionKey, hash, s.ttl)
+	pipe.Set(ctx, refreshTokenKeyPrefix+hash, session, s.ttl)
+	pipe.SAdd(ctx, refreshDevicesKey(userID), deviceID)
+	pipe.Expire(ctx, refreshDevicesKey(userID), s.ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return token, time.Now().Add(s.ttl), nil
+}
+
+// Rotate exchanges a refresh token for a new one and returns the device it
+// belongs to. The user and device are also returned with
+// ErrRefreshTokenReused so the caller can revoke the device.
+func (s *RefreshStore) Rotate(ctx context.Context, token string) (int64, string, string, time.Time, error) {
+	hash := hashRefreshToken(token)
+
+	data, err := s.redis.Get(ctx, refreshTokenKeyPrefix+hash).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, "", "", time.Time{}, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return 0, "", "", time.Time{}, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	var session refreshSession
+	if err := json.Unmarshal([]byte(data), &session); err != nil {
+		return 0, "", "", time.Time{}, fmt.Errorf("invalid refresh session: %w", err)
+	}
+
+	newToken, newHash, err := newRefreshToken()
+	if err != nil {
+		return 0, "", "", time.Time{}, err
+	}
+
+	result, err := refreshRotateScript.Run(ctx, s.redis,
+		[]string{
+			refreshSessionKey(session.UserID, session.DeviceID),
+			refreshTokenKeyPrefix + newHash,
+			refreshDevicesKey(session.UserID),
+		},
+		hash, newHash, data, int64(s.ttl/time.Second), session.DeviceID).Int()
+	if err != nil {
+		return 0, "", "", time.Time{}, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	switch result {
+	case 1:
+		return session.UserID, session.DeviceID, newToken, time.Now().Add(s.ttl), nil
+	case -1:
+		return session.UserID, session.DeviceID, "", time.Time{}, ErrRefreshTokenReused
+	default:
+		return 0, "", "", time.Time{}, ErrInvalidRefreshToken
+	}
+}
+
+// Revoke ends the refresh session of a device
+func (s *RefreshStore) Revoke(ctx context.Context, userID int64, deviceID string) error {
+	if err := revokeRefreshSession(ctx, s.redis, userID, deviceID); err != nil {
+		return fmt.Errorf("failed to revoke refresh session: %w", err)
+	}
+	return nil
+}
+
+// revokeRefreshSession deletes a device's session and its current token
+func revokeRefreshSession(ctx context.Context, rdb *redis.Client, userID int64, deviceID string) error {
+	sessionKey := refreshSessionKey(userID, deviceID)
+	current, err := rdb.Get(ctx, sessionKey).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+
+	pipe := rdb.TxPipeline()
+	pipe.Del(ctx, sessionKey)
+	if current != "" {
+		pipe.Del(ctx, refreshTokenKeyPrefix+current)
+	}
+	pipe.SRem(ctx, refreshDevicesKey(userID), deviceID)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// revokeRefreshSessions ends the refresh sessions of all of a user's devices
+// except exceptDeviceID
+func revokeRefreshSessions(ctx context.Context, rdb *redis.Client, userID int64, exceptDeviceID string) error {
+	deviceIDs, err := rdb.SMembers(ctx, refreshDevicesKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, deviceID := range deviceIDs {
+		if deviceID == exceptDeviceID {
+			continue
+		}
+		if err := revokeRefreshSession(ctx, rdb, userID, deviceID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newRefreshToken() (string, string, error) {
+	buf := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashRefreshToken(token), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func refreshSessionKey(userID int64, deviceID string) string {
+	return fmt.Sprintf("%s%d:%s", refreshSessionKeyPrefix, userID, deviceID)
+}
+
+func refreshDevicesKey(userID int64) string {
+	return fmt.Sprintf("%s%d", refreshDevicesKeyPrefix, userID)
+}
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRefreshStore(t *testing.T) (*RefreshStore, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewRefreshStore(client, time.Hour), mr
+}
+
+func TestRefreshStore_Rotate(t *testing.T) {
+	store, mr := setupRefreshStore(t)
+	ctx := context.Background()
+
+	token, expiresAt, err := store.Issue(ctx, 100, "device-001")
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Second)
+
+	// only the hash is stored
+	for _, key := range mr.Keys() {
+		assert.NotContains(t, key, token)
+	}
+
+	userID, deviceID, rotated, _, err := store.Rotate(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), userID)
+	assert.Equal(t, "device-001", deviceID)
+	assert.NotEqual(t, token, rotated)
+
+	// the rotated token keeps working
+	_, _, next, _, err := store.Rotate(ctx, rotated)
+	require.NoError(t, err)
+	assert.NotEmpty(t, next)
+
+	_, _, _, _, err = store.Rotate(ctx, "unknown-token")
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestRefreshStore_ReuseEndsSession(t *testing.T) {
+	store, _ := setupRefreshStore(t)
+	ctx := context.Background()
+
+	token, _, err := store.Issue(ctx, 100, "device-001")
+	require.NoError(t, err)
+	_, _, rotated, _, err := store.Rotate(ctx, token)
+	require.NoError(t, err)
+
+	// presenting the old token again reports the device it belonged to
+	userID, deviceID, _, _, err := store.Rotate(ctx, token)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	assert.Equal(t, int64(100), userID)
+	assert.Equal(t, "device-001", deviceID)
+
+	// and the current token no longer works either
+	_, _, _, _, err = store.Rotate(ctx, rotated)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestRefreshStore_IssueReplacesSession(t *testing.T) {
+	store, _ := setupRefreshStore(t)
+	ctx := context.Background()
+
+	first, _, err := store.Issue(ctx, 100, "device-001")
+	require.NoError(t, err)
+	second, _, err := store.Issue(ctx, 100, "device-001")
+	require.NoError(t, err)
+
+	// a token from the previous login is invalid, not reused
+	_, _, _, _, err = store.Rotate(ctx, first)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	_, _, _, _, err = store.Rotate(ctx, second)
+	require.NoError(t, err)
+
+	// other devices have their own sessions
+	other, _, err := store.Issue(ctx, 100, "device-002")
+	require.NoError(t, err)
+	require.NoError(t, store.Revoke(ctx, 100, "device-001"))
+	_, _, _, _, err = store.Rotate(ctx, other)
+	assert.NoError(t, err)
+}
+
+func TestRefreshStore_Revoke(t *testing.T) {
+	store, _ := setupRefreshStore(t)
+	ctx := context.Background()
+
+	token, _, err := store.Issue(ctx, 100, "device-001")
+	require.NoError(t, err)
+	require.NoError(t, store.Revoke(ctx, 100, "device-001"))
+
+	_, _, _, _, err = store.Rotate(ctx, token)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
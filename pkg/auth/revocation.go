@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	revokedDeviceKeyPrefix = "revoked_device:"
+	revokedTokenKeyPrefix  = "revoked_token:"
+	// revokedUserKeyPrefix holds a hash with the latest revocation time
+	// ("at"), the device exempted from it ("except") and the time up to
+	// which every device is revoked ("all")
+	revokedUserKeyPrefix = "revoked_user:"
+
+	// legacyRevocationCutoff tells revocation times stored in seconds by
+	// earlier releases apart from the millisecond times stored now
+	legacyRevocationCutoff = 1e12
+)
+
+// revokeUserScript records a user revocation without narrowing an earlier
+// one. The previous latest revocation still covers the device the new one
+// exempts, so unless both exempt the same device its time is folded into
+// "all" before it is replaced.
+var revokeUserScript = redis.NewScript(`
+local at = tonumber(redis.call('HGET', KEYS[1], 'at') or '0')
+local except = redis.call('HGET', KEYS[1], 'except') or ''
+local all = tonumber(redis.call('HGET', KEYS[1], 'all') or '0')
+if at > 0 and at < 1e12 then
+  at = at * 1000
+end
+if at > 0 and except ~= ARGV[2] and at > all then
+  all = at
+end
+redis.call('HSET', KEYS[1], 'at', ARGV[1], 'except', ARGV[2], 'all', string.format('%d', all))
+redis.call('EXPIRE', KEYS[1], ARGV[3])
+return 1
+`)
+
+// RevocationChecker reports whether a validated token has been revoked
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, claims *Claims) (bool, error)
+}
+
+// RevocationStore is the Redis-backed token denylist. Single tokens are
+// revoked by their ID (jti) until they expire. Revoking a device invalidates
+// every token issued to it up to that moment and ends its refresh session;
+// tokens issued by a later login are accepted again. Revocation times and
+// token issue times are compared in milliseconds.
+type RevocationStore struct {
+	redis    *redis.Client
+	tokenTTL time.Duration
+}
+
+// NewRevocationStore creates a revocation store. tokenTTL is the token
+// lifetime: once it has passed, every revoked token has expired anyway and
+// the revocation record can be dropped.
+func NewRevocationStore(redisClient *redis.Client, tokenTTL time.Duration) *RevocationStore {
+	return &RevocationStore{
+		redis:    redisClient,
+		tokenTTL: tokenTTL,
+	}
+}
+
+// RevokeToken denylists a single token until it expires. Tokens without an
+// ID cannot be told apart, so their whole device is revoked instead.
+func (s *RevocationStore) RevokeToken(ctx context.Context, claims *Claims) error {
+	if claims.ID == "" {
+		return s.RevokeDevice(ctx, claims.UserID, claims.DeviceID)
+	}
+
+	ttl := s.tokenTTL
+	if claims.ExpiresAt != nil {
+		ttl = time.Until(claims.ExpiresAt.Time)
+	}
+	if ttl <= 0 {
+		return nil
+	}
+
+	if err := s.redis.Set(ctx, revokedTokenKeyPrefix+claims.ID, 1, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return nil
+}
+
+// RevokeDevice invalidates all tokens issued to a device so far, including
+// its refresh token
+func (s *RevocationStore) RevokeDevice(ctx context.Context, userID int64, deviceID string) error {
+	key := revokedDeviceKey(userID, deviceID)
+	if err := s.redis.Set(ctx, key, time.Now().UnixMilli(), s.tokenTTL).Err(); err != nil {
+		return fmt.Errorf("failed to revoke device: %w", err)
+	}
+	if err := revokeRefreshSession(ctx, s.redis, userID, deviceID); err != nil {
+		return fmt.Errorf("failed to revoke refresh session: %w", err)
+	}
+	return nil
+}
+
+// RevokeUser invalidates all tokens issued to a user's devices so far and
+// ends their refresh sessions, except for exceptDeviceID (e.g. the device
+// that changed the password); an empty exceptDeviceID revokes every device.
+// The exemption only applies to this revocation: tokens an earlier one
+// revoked stay revoked.
+func (s *RevocationStore) RevokeUser(ctx context.Context, userID int64, exceptDeviceID string) error {
+	err := revokeUserScript.Run(ctx, s.redis,
+		[]string{revokedUserKey(userID)},
+		time.Now().UnixMilli(), exceptDeviceID, int64(s.tokenTTL/time.Second),
+	).Err()
+	if err != nil {
+		return fmt.Errorf("failed to revoke user: %w", err)
+	}
+	if err := revokeRefreshSessions(ctx, s.redis, userID, exceptDeviceID); err != nil {
+		return fmt.Errorf("failed to revoke refresh sessions: %w", err)
+	}
+	return nil
+}
+
+// IsRevoked reports whether the token was revoked by its ID or issued at or
+// before a revocation of its device or user. A token issued in the same
+// millisecond as the revocation is treated as revoked.
+func (s *RevocationStore) IsRevoked(ctx context.Context, claims *Claims) (bool, error) {
+	pipe := s.redis.Pipeline()
+	var tokenRevoked *redis.IntCmd
+	if claims.ID != "" {
+		tokenRevoked = pipe.Exists(ctx, revokedTokenKeyPrefix+claims.ID)
+	}
+	deviceRevoked := pipe.Get(ctx, revokedDeviceKey(claims.UserID, claims.DeviceID))
+	userRevoked := pipe.HMGet(ctx, revokedUserKey(claims.UserID), "at", "except", "all")
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return false, fmt.Errorf("failed to check revocation: %w", err)
+	}
+
+	if tokenRevoked != nil && tokenRevoked.Val() > 0 {
+		return true, nil
+	}
+
+	if val, err := deviceRevoked.Result(); err == nil {
+		revoked, err := issuedBy(claims, val)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	} else if !errors.Is(err, redis.Nil) {
+		return false, fmt.Errorf("failed to check revocation: %w", err)
+	}
+
+	fields := userRevoked.Val()
+	if len(fields) == 3 {
+		at, _ := fields[0].(string)
+		except, _ := fields[1].(string)
+		if at != "" && (except == "" || except != claims.DeviceID) {
+			revoked, err := issuedBy(claims, at)
+			if err != nil || revoked {
+				return revoked, err
+			}
+		}
+		if all, _ := fields[2].(string); all != "" {
+			return issuedBy(claims, all)
+		}
+	}
+
+	return false, nil
+}
+
+// issuedBy reports whether the token was issued at or before a revocation time
+func issuedBy(claims *Claims, revokedAtVal string) (bool, error) {
+	revokedAt, err := strconv.ParseInt(revokedAtVal, 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("invalid revocation record: %w", err)
+	}
+	if revokedAt < legacyRevocationCutoff {
+		revokedAt *= 1000
+	}
+
+	if claims.IssuedAt == nil {
+		return true, nil
+	}
+	return claims.IssuedAt.UnixMilli() <= revokedAt, nil
+}
+
+func revokedDeviceKey(userID int64, deviceID string) string {
+	return fmt.Sprintf("%s%d:%s", revokedDeviceKeyPrefix, userID, deviceID)
+}
+
+func revokedUserKey(userID int64) string {
+	return fmt.Sprintf("%s%d", revokedUserKeyPrefix, userID)
+}
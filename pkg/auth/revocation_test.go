@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRevocationStore(t *testing.T) (*RevocationStore, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewRevocationStore(client, time.Hour), mr
+}
+
+func claimsIssuedAt(userID int64, deviceID string, iat time.Time) *Claims {
+	return &Claims{
+		UserID:   userID,
+		DeviceID: deviceID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt: jwt.NewNumericDate(iat),
+		},
+	}
+}
+
+func TestRevocationStore_RevokeDevice(t *testing.T) {
+	store, mr := setupRevocationStore(t)
+	ctx := context.Background()
+
+	issued := claimsIssuedAt(100, "device-001", time.Now().Add(-time.Minute))
+
+	revoked, err := store.IsRevoked(ctx, issued)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	require.NoError(t, store.RevokeDevice(ctx, 100, "device-001"))
+
+	revoked, err = store.IsRevoked(ctx, issued)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// other devices of the same user are unaffected
+	revoked, err = store.IsRevoked(ctx, claimsIssuedAt(100, "device-002", time.Now().Add(-time.Minute)))
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	// a later login issues a token that is accepted again
+	revoked, err = store.IsRevoked(ctx, claimsIssuedAt(100, "device-001", time.Now().Add(time.Minute)))
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	// the record lives as long as a token can
+	assert.Equal(t, time.Hour, mr.TTL(revokedDeviceKey(100, "device-001")))
+}
+
+func TestRevocationStore_MissingIssuedAt(t *testing.T) {
+	store, _ := setupRevocationStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, store.RevokeDevice(ctx, 100, "device-001"))
+
+	revoked, err := store.IsRevoked(ctx, &Claims{UserID: 100, DeviceID: "device-001"})
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestRevocationStore_RevokeToken(t *testing.T) {
+	store, mr := setupRevocationStore(t)
+	ctx := context.Background()
+
+	claims := claimsIssuedAt(100, "device-001", time.Now())
+	claims.ID = "token-1"
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(10 * time.Minute))
+
+	require.NoError(t, store.RevokeToken(ctx, claims))
+
+	revoked, err := store.IsRevoked(ctx, claims)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// the denylist entry lives until the token expires
+	ttl := mr.TTL(revokedTokenKeyPrefix + "token-1")
+	assert.Greater(t, ttl, 9*time.Minute)
+	assert.LessOrEqual(t, ttl, 10*time.Minute)
+
+	// other tokens of the same device are unaffected
+	other := claimsIssuedAt(100, "device-001", time.Now())
+	other.ID = "token-2"
+	revoked, err = store.IsRevoked(ctx, other)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestRevocationStore_RevokeDeviceEndsRefreshSession(t *testing.T) {
+	store, _ := setupRevocationStore(t)
+	refresh := NewRefreshStore(store.redis, time.Hour)
+	ctx := context.Background()
+
+	token, _, err := refresh.Issue(ctx, 100, "device-001")
+	require.NoError(t, err)
+
+	require.NoError(t, store.RevokeDevice(ctx, 100, "device-001"))
+
+	_, _, _, _, err = refresh.Rotate(ctx, token)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestRevocationStore_RevokeUser(t *testing.T) {
+	store, _ := setupRevocationStore(t)
+	refresh := NewRefreshStore(store.redis, time.Hour)
+	ctx := context.Background()
+
+	current, _, err := refresh.Issue(ctx, 100, "device-001")
+	require.NoError(t, err)
+	other, _, err := refresh.Issue(ctx, 100, "device-002")
+	require.NoError(t, err)
+
+	issued := time.Now().Add(-time.Minute)
+	require.NoError(t, store.RevokeUser(ctx, 100, "device-001"))
+
+	// the exempted device keeps its tokens and refresh session
+	revoked, err := store.IsRevoked(ctx, claimsIssuedAt(100, "device-001", issued))
+	require.NoError(t, err)
+	assert.False(t, revoked)
+	_, _, _, _, err = refresh.Rotate(ctx, current)
+	assert.NoError(t, err)
+
+	// every other device is signed out
+	revoked, err = store.IsRevoked(ctx, claimsIssuedAt(100, "device-002", issued))
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	_, _, _, _, err = refresh.Rotate(ctx, other)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	// including devices that had no refresh session
+	revoked, err = store.IsRevoked(ctx, claimsIssuedAt(100, "device-003", issued))
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// other users and later logins are unaffected
+	revoked, err = store.IsRevoked(ctx, claimsIssuedAt(200, "device-002", issued))
+	require.NoError(t, err)
+	assert.False(t, revoked)
+	revoked, err = store.IsRevoked(ctx, claimsIssuedAt(100, "device-002", time.Now().Add(time.Minute)))
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	// without an exemption all devices are revoked
+	require.NoError(t, store.RevokeUser(ctx, 100, ""))
+	revoked, err = store.IsRevoked(ctx, claimsIssuedAt(100, "device-001", issued))
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestRevocationStore_RevokeUserAfterRotations(t *testing.T) {
+	store, mr := setupRevocationStore(t)
+	refresh := NewRefreshStore(store.redis, time.Hour)
+	ctx := context.Background()
+
+	token, _, err := refresh.Issue(ctx, 100, "device-002")
+	require.NoError(t, err)
+
+	// the session outlives the login that started it by being rotated
+	for i := 0; i < 3; i++ {
+		mr.FastForward(50 * time.Minute)
+		_, _, token, _, err = refresh.Rotate(ctx, token)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, store.RevokeUser(ctx, 100, "device-001"))
+
+	_, _, _, _, err = refresh.Rotate(ctx, token)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestRevocationStore_RevokeUserNeverNarrows(t *testing.T) {
+	store, _ := setupRevocationStore(t)
+	ctx := context.Background()
+
+	beforeAll := time.Now().Add(-time.Minute)
+	require.NoError(t, store.RevokeUser(ctx, 100, ""))
+	time.Sleep(5 * time.Millisecond)
+	between := time.Now()
+	time.Sleep(5 * time.Millisecond)
+
+	// a password change from device-001 must not bring back its revoked tokens
+	require.NoError(t, store.RevokeUser(ctx, 100, "device-001"))
+
+	revoked, err := store.IsRevoked(ctx, claimsIssuedAt(100, "device-001", beforeAll))
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, err = store.IsRevoked(ctx, claimsIssuedAt(100, "device-001", between))
+	require.NoError(t, err)
+	assert.False(t, revoked)
+	revoked, err = store.IsRevoked(ctx, claimsIssuedAt(100, "device-002", between))
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// a later change from another device covers device-001 but not the
+	// tokens device-002 got after the previous change
+	time.Sleep(5 * time.Millisecond)
+	later := time.Now()
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, store.RevokeUser(ctx, 100, "device-002"))
+
+	revoked, err = store.IsRevoked(ctx, claimsIssuedAt(100, "device-001", later))
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, err = store.IsRevoked(ctx, claimsIssuedAt(100, "device-002", between))
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, err = store.IsRevoked(ctx, claimsIssuedAt(100, "device-002", later))
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestRevocationStore_SubSecondIssueTimes(t *testing.T) {
+	store, mr := setupRevocationStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, store.RevokeUser(ctx, 100, "device-001"))
+	time.Sleep(5 * time.Millisecond)
+
+	// a login right after the revocation, within the same second, is accepted
+	manager := NewJWTManager("test-secret", time.Hour)
+	token, _, err := manager.Generate(100, "device-002")
+	require.NoError(t, err)
+	claims, err := manager.Validate(token)
+	require.NoError(t, err)
+
+	revoked, err := store.IsRevoked(ctx, claims)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	// records written in seconds by earlier releases are still honored
+	mr.Set(revokedDeviceKey(100, "device-002"), strconv.FormatInt(time.Now().Add(time.Second).Unix(), 10))
+	revoked, err = store.IsRevoked(ctx, claims)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/dollarkillerx/im-system/pkg/auth"
 	"github.com/spf13/viper"
 )
 
@@ -13,10 +14,15 @@ type Config struct {
 	Database DatabaseConfig `mapstructure:"database"`
 	Redis    RedisConfig    `mapstructure:"redis"`
 	JWT      JWTConfig      `mapstructure:"jwt"`
+	Internal InternalConfig `mapstructure:"internal"`
 	S3       S3Config       `mapstructure:"s3"`
 	Log      LogConfig      `mapstructure:"log"`
 	Message  MessageConfig  `mapstructure:"message"`
 	File     FileConfig     `mapstructure:"file"`
+	Presence PresenceConfig `mapstructure:"presence"`
+	Devices  DevicesConfig  `mapstructure:"devices"`
+	Mail     MailConfig     `mapstructure:"mail"`
+	Account  AccountConfig  `mapstructure:"account"`
 }
 
 type ServerConfig struct {
@@ -49,6 +55,7 @@ type MessageSvcConfig struct {
 
 type UserConfig struct {
 	GRPCPort int `mapstructure:"grpc_port"`
+	HTTPPort int `mapstructure:"http_port"`
 }
 
 type FileSvcConfig struct {
@@ -78,8 +85,46 @@ type RedisConfig struct {
 }
 
 type JWTConfig struct {
-	Secret string        `mapstructure:"secret"`
-	Expiry time.Duration `mapstructure:"expiry"`
+	Secret        string        `mapstructure:"secret"`
+	Expiry        time.Duration `mapstructure:"expiry"`
+	RefreshExpiry time.Duration `mapstructure:"refresh_expiry"`
+
+	Issuer            string        `mapstructure:"issuer"`
+	Audience          string        `mapstructure:"audience"`
+	Leeway            time.Duration `mapstructure:"leeway"`
+	LegacyTokensUntil string        `mapstructure:"legacy_tokens_until"`
+
+	Keys        []JWTKeyConfig `mapstructure:"keys"`
+	JWKSURL     string         `mapstructure:"jwks_url"`
+	JWKSRefresh time.Duration  `mapstructure:"jwks_refresh"`
+}
+
+// Options returns the token settings for auth.JWTManager.WithOptions
+func (c JWTConfig) Options() (auth.JWTOptions, error) {
+	opts := auth.JWTOptions{
+		Issuer:   c.Issuer,
+		Audience: c.Audience,
+		Leeway:   c.Leeway,
+	}
+	if c.LegacyTokensUntil != "" {
+		until, err := time.Parse(time.RFC3339, c.LegacyTokensUntil)
+		if err != nil {
+			return auth.JWTOptions{}, fmt.Errorf("invalid jwt.legacy_tokens_until: %w", err)
+		}
+		opts.LegacyUntil = until
+	}
+	return opts, nil
+}
+
+type JWTKeyConfig struct {
+	ID             string `mapstructure:"id"`
+	Algorithm      string `mapstructure:"algorithm"`
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	ActiveFrom     string `mapstructure:"active_from"`
+}
+
+type InternalConfig struct {
+	Token string `mapstructure:"token"`
 }
 
 type S3Config struct {
@@ -98,8 +143,75 @@ type LogConfig struct {
 }
 
 type MessageConfig struct {
-	RetentionDays int `mapstructure:"retention_days"`
-	MaxPullLimit  int `mapstructure:"max_pull_limit"`
+	RetentionDays     int           `mapstructure:"retention_days"`
+	MaxPullLimit      int           `mapstructure:"max_pull_limit"`
+	IdempotencyWindow time.Duration `mapstructure:"idempotency_window"`
+	RecallWindow      time.Duration `mapstructure:"recall_window"`
+
+	GroupReadReceipts          string `mapstructure:"group_read_receipts"`
+	GroupReadReceiptMaxMembers int    `mapstructure:"group_read_receipt_max_members"`
+}
+
+type PresenceConfig struct {
+	Debounce      time.Duration `mapstructure:"debounce"`
+	SweepInterval time.Duration `mapstructure:"sweep_interval"`
+}
+
+type DevicesConfig struct {
+	MaxDevices     int            `mapstructure:"max_devices"`
+	PlatformLimits map[string]int `mapstructure:"platform_limits"`
+}
+
+type MailConfig struct {
+	Driver string     `mapstructure:"driver"`
+	From   string     `mapstructure:"from"`
+	File   string     `mapstructure:"file"`
+	SMTP   SMTPConfig `mapstructure:"smtp"`
+}
+
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+type AccountConfig struct {
+	PasswordResetTTL time.Duration `mapstructure:"password_reset_ttl"`
+	PasswordResetURL string        `mapstructure:"password_reset_url"`
+	EmailVerifyTTL   time.Duration `mapstructure:"email_verify_ttl"`
+	EmailVerifyURL   string        `mapstructure:"email_verify_url"`
+
+	EmailVerifyResendInterval time.Duration `mapstructure:"email_verify_resend_interval"`
+
+	PasswordPolicy PasswordPolicyConfig `mapstructure:"password_policy"`
+	LoginThrottle  LoginThrottleConfig  `mapstructure:"login_throttle"`
+	TwoFactor      TwoFactorConfig      `mapstructure:"two_factor"`
+}
+
+type TwoFactorConfig struct {
+	Issuer       string        `mapstructure:"issuer"`
+	ChallengeTTL time.Duration `mapstructure:"challenge_ttl"`
+	MaxAttempts  int           `mapstructure:"max_attempts"`
+}
+
+type LoginThrottleConfig struct {
+	Enabled           bool          `mapstructure:"enabled"`
+	Window            time.Duration `mapstructure:"window"`
+	MaxUserAttempts   int           `mapstructure:"max_user_attempts"`
+	MaxIPAttempts     int           `mapstructure:"max_ip_attempts"`
+	Lockout           time.Duration `mapstructure:"lockout"`
+	BaseDelay         time.Duration `mapstructure:"base_delay"`
+	MaxDelay          time.Duration `mapstructure:"max_delay"`
+	TrustForwardedFor bool          `mapstructure:"trust_forwarded_for"`
+}
+
+type PasswordPolicyConfig struct {
+	MinLength     int  `mapstructure:"min_length"`
+	MaxLength     int  `mapstructure:"max_length"`
+	RequireLetter bool `mapstructure:"require_letter"`
+	RequireDigit  bool `mapstructure:"require_digit"`
+	RequireSymbol bool `mapstructure:"require_symbol"`
 }
 
 type FileConfig struct {
@@ -131,6 +243,10 @@ func Load(configPath string) (*Config, error) {
 
 	v.BindEnv("jwt.secret", "JWT_SECRET")
 	v.BindEnv("jwt.expiry", "JWT_EXPIRY")
+	v.BindEnv("jwt.refresh_expiry", "JWT_REFRESH_EXPIRY")
+	v.BindEnv("jwt.jwks_url", "JWT_JWKS_URL")
+
+	v.BindEnv("internal.token", "INTERNAL_TOKEN")
 
 	v.BindEnv("s3.endpoint", "S3_ENDPOINT")
 	v.BindEnv("s3.region", "S3_REGION")
@@ -143,10 +259,18 @@ func Load(configPath string) (*Config, error) {
 	v.BindEnv("server.router.grpc_port", "ROUTER_GRPC_PORT")
 	v.BindEnv("server.message.grpc_port", "MESSAGE_GRPC_PORT")
 	v.BindEnv("server.user.grpc_port", "USER_GRPC_PORT")
+	v.BindEnv("server.user.http_port", "USER_HTTP_PORT")
 	v.BindEnv("server.file.http_port", "FILE_HTTP_PORT")
 	v.BindEnv("server.file.mode", "FILE_MODE")
 	v.BindEnv("server.file.max_file_size", "FILE_MAX_SIZE")
 
+	v.BindEnv("mail.driver", "MAIL_DRIVER")
+	v.BindEnv("mail.from", "MAIL_FROM")
+	v.BindEnv("mail.smtp.host", "SMTP_HOST")
+	v.BindEnv("mail.smtp.port", "SMTP_PORT")
+	v.BindEnv("mail.smtp.username", "SMTP_USERNAME")
+	v.BindEnv("mail.smtp.password", "SMTP_PASSWORD")
+
 	v.BindEnv("log.level", "LOG_LEVEL")
 
 	// Read config file
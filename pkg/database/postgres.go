@@ -50,7 +50,7 @@ func NewPostgresDB(cfg *config.DatabaseConfig) (*sql.DB, error) {
 	return db, nil
 }
 
-// managePartitions creates daily partitions and drops old ones
+// managePartitions creates daily partitions, drops old ones and purges expired message metadata and account tokens
 func managePartitions(db *sql.DB) {
 	ticker := time.NewTicker(1 * time.Hour)
 	defer ticker.Stop()
@@ -62,6 +62,13 @@ func managePartitions(db *sql.DB) {
 
 		// Drop old partitions
 		dropOldPartitions(db)
+
+		// Purge expired idempotency keys, sequence index entries, edit history
+		// and used or expired account tokens
+		purgeIdempotencyKeys(db)
+		purgeMessageSeqs(db)
+		purgeMessageEdits(db)
+		purgeAccountTokens(db)
 	}
 }
 
@@ -91,3 +98,31 @@ func dropOldPartitions(db *sql.DB) {
 		logger.Log.Error("Failed to drop old partitions", zap.Error(err))
 	}
 }
+
+func purgeIdempotencyKeys(db *sql.DB) {
+	_, err := db.Exec("SELECT purge_message_idempotency()")
+	if err != nil {
+		logger.Log.Error("Failed to purge idempotency keys", zap.Error(err))
+	}
+}
+
+func purgeMessageSeqs(db *sql.DB) {
+	_, err := db.Exec("SELECT purge_message_seqs()")
+	if err != nil {
+		logger.Log.Error("Failed to purge message seqs", zap.Error(err))
+	}
+}
+
+func purgeMessageEdits(db *sql.DB) {
+	_, err := db.Exec("SELECT purge_message_edits()")
+	if err != nil {
+		logger.Log.Error("Failed to purge message edits", zap.Error(err))
+	}
+}
+
+func purgeAccountTokens(db *sql.DB) {
+	_, err := db.Exec("SELECT purge_account_tokens()")
+	if err != nil {
+		logger.Log.Error("Failed to purge account tokens", zap.Error(err))
+	}
+}
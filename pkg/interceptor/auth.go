@@ -2,6 +2,7 @@ package interceptor
 
 import (
 	"context"
+	"crypto/subtle"
 	"strings"
 
 	"github.com/dollarkillerx/im-system/pkg/auth"
@@ -16,12 +17,16 @@ import (
 const (
 	authorizationHeader = "authorization"
 	bearerPrefix        = "bearer "
+	internalTokenHeader = "x-internal-token"
 )
 
 // AuthInterceptor JWT 认证拦截器
 type AuthInterceptor struct {
-	jwtManager    *auth.JWTManager
-	publicMethods map[string]bool // 不需要认证的方法
+	jwtManager      *auth.JWTManager
+	publicMethods   map[string]bool // 不需要认证的方法
+	internalMethods map[string]bool // 仅限内部服务调用的方法
+	internalToken   string          // 内部服务调用共享密钥
+	revocation      auth.RevocationChecker
 }
 
 // NewAuthInterceptor 创建认证拦截器
@@ -32,11 +37,27 @@ func NewAuthInterceptor(jwtManager *auth.JWTManager, publicMethods []string) *Au
 	}
 
 	return &AuthInterceptor{
-		jwtManager:    jwtManager,
-		publicMethods: methodsMap,
+		jwtManager:      jwtManager,
+		publicMethods:   methodsMap,
+		internalMethods: make(map[string]bool),
 	}
 }
 
+// WithInternalMethods 设置仅限内部服务调用的方法，这些方法使用共享密钥认证而不是客户端 JWT
+func (a *AuthInterceptor) WithInternalMethods(internalToken string, methods []string) *AuthInterceptor {
+	a.internalToken = internalToken
+	for _, method := range methods {
+		a.internalMethods[method] = true
+	}
+	return a
+}
+
+// WithRevocationChecker 设置令牌吊销检查，被吊销的令牌 (如被踢下线的设备) 将被拒绝
+func (a *AuthInterceptor) WithRevocationChecker(checker auth.RevocationChecker) *AuthInterceptor {
+	a.revocation = checker
+	return a
+}
+
 // Unary 一元 RPC 拦截器
 func (a *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
 	return func(
@@ -54,6 +75,14 @@ func (a *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
 			return handler(ctx, req)
 		}
 
+		// 内部方法使用共享密钥认证
+		if a.internalMethods[info.FullMethod] {
+			if err := a.authorizeInternal(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}
+
 		// 验证 Token
 		claims, err := a.authorize(ctx)
 		if err != nil {
@@ -85,6 +114,14 @@ func (a *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
 			return handler(srv, stream)
 		}
 
+		// 内部方法使用共享密钥认证
+		if a.internalMethods[info.FullMethod] {
+			if err := a.authorizeInternal(stream.Context()); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}
+
 		// 验证 Token
 		claims, err := a.authorize(stream.Context())
 		if err != nil {
@@ -125,9 +162,49 @@ func (a *AuthInterceptor) authorize(ctx context.Context) (*auth.Claims, error) {
 		return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
 	}
 
+	// 吊销状态无法确认时拒绝请求
+	if a.revocation != nil {
+		revoked, err := a.revocation.IsRevoked(ctx, claims)
+		if err != nil {
+			logger.Log.Error("Failed to check token revocation", zap.Error(err))
+			return nil, status.Errorf(codes.Unavailable, "failed to check token revocation")
+		}
+		if revoked {
+			return nil, status.Errorf(codes.Unauthenticated, "token has been revoked")
+		}
+	}
+
 	return claims, nil
 }
 
+// authorizeInternal 验证内部服务调用的共享密钥
+func (a *AuthInterceptor) authorizeInternal(ctx context.Context) error {
+	if a.internalToken == "" {
+		return status.Errorf(codes.PermissionDenied, "internal authentication is not configured")
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return status.Errorf(codes.Unauthenticated, "metadata is not provided")
+	}
+
+	values := md[internalTokenHeader]
+	if len(values) == 0 {
+		return status.Errorf(codes.Unauthenticated, "internal token is not provided")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(values[0]), []byte(a.internalToken)) != 1 {
+		return status.Errorf(codes.PermissionDenied, "invalid internal token")
+	}
+
+	return nil
+}
+
+// WithInternalToken 在出站 context 中附加内部服务调用密钥
+func WithInternalToken(ctx context.Context, internalToken string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, internalTokenHeader, internalToken)
+}
+
 // authServerStream 包装的 ServerStream，携带用户信息
 type authServerStream struct {
 	grpc.ServerStream
@@ -7,11 +7,14 @@ import (
 
 // ChainConfig 拦截器链配置
 type ChainConfig struct {
-	JWTManager     *auth.JWTManager
-	PublicMethods  []string
-	EnableAuth     bool
-	EnableLogging  bool
-	EnableRecovery bool
+	JWTManager        *auth.JWTManager
+	PublicMethods     []string
+	InternalMethods   []string // 仅限内部服务调用的方法，使用 InternalToken 认证
+	InternalToken     string
+	RevocationChecker auth.RevocationChecker // 令牌吊销检查，为空时不检查
+	EnableAuth        bool
+	EnableLogging     bool
+	EnableRecovery    bool
 }
 
 // ChainUnaryInterceptors 创建一元拦截器链
@@ -30,7 +33,9 @@ func ChainUnaryInterceptors(config ChainConfig) []grpc.UnaryServerInterceptor {
 
 	// Auth 在最内层，最后执行
 	if config.EnableAuth && config.JWTManager != nil {
-		authInterceptor := NewAuthInterceptor(config.JWTManager, config.PublicMethods)
+		authInterceptor := NewAuthInterceptor(config.JWTManager, config.PublicMethods).
+			WithInternalMethods(config.InternalToken, config.InternalMethods).
+			WithRevocationChecker(config.RevocationChecker)
 		interceptors = append(interceptors, authInterceptor.Unary())
 	}
 
@@ -53,7 +58,9 @@ func ChainStreamInterceptors(config ChainConfig) []grpc.StreamServerInterceptor
 
 	// Auth 在最内层
 	if config.EnableAuth && config.JWTManager != nil {
-		authInterceptor := NewAuthInterceptor(config.JWTManager, config.PublicMethods)
+		authInterceptor := NewAuthInterceptor(config.JWTManager, config.PublicMethods).
+			WithInternalMethods(config.InternalToken, config.InternalMethods).
+			WithRevocationChecker(config.RevocationChecker)
 		interceptors = append(interceptors, authInterceptor.Stream())
 	}
 
@@ -0,0 +1,52 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dollarkillerx/im-system/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogMailer_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail", "outbox.log")
+	m := NewLogMailer(path)
+
+	require.NoError(t, m.Send(context.Background(), &Message{To: "alice@example.com", Subject: "first", Body: "hello"}))
+	require.NoError(t, m.Send(context.Background(), &Message{To: "bob@example.com", Subject: "second", Body: "world"}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "To: alice@example.com\nSubject: first\n\nhello")
+	assert.Contains(t, string(data), "To: bob@example.com\nSubject: second\n\nworld")
+}
+
+func TestNew(t *testing.T) {
+	m, err := New(&config.MailConfig{Driver: "smtp", From: "no-reply@example.com"})
+	require.NoError(t, err)
+	assert.IsType(t, &SMTPMailer{}, m)
+
+	m, err = New(&config.MailConfig{Driver: "log"})
+	require.NoError(t, err)
+	assert.IsType(t, &LogMailer{}, m)
+
+	// no driver disables email instead of silently logging it
+	m, err = New(&config.MailConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, m)
+
+	_, err = New(&config.MailConfig{Driver: "carrier-pigeon"})
+	assert.Error(t, err)
+}
+
+func TestSMTPMailer_Format(t *testing.T) {
+	m := NewSMTPMailer(&config.MailConfig{From: "no-reply@example.com"})
+	raw := string(m.format(&Message{To: "alice@example.com", Subject: "Hi", Body: "line 1\nline 2"}))
+
+	assert.Contains(t, raw, "From: no-reply@example.com\r\n")
+	assert.Contains(t, raw, "To: alice@example.com\r\n")
+	assert.Contains(t, raw, "\r\n\r\nline 1\r\nline 2")
+}
@@ -65,3 +65,11 @@ func (cr ConversationRole) CanSendMessage(convType ConversationType) bool {
 func (cr ConversationRole) CanManageMembers() bool {
 	return cr == ConversationRoleOwner || cr == ConversationRoleAdmin
 }
+
+// CanModerateMessages checks if the role can recall messages sent by other members
+func (cr ConversationRole) CanModerateMessages(convType ConversationType) bool {
+	if convType == ConversationTypeDirect {
+		return false
+	}
+	return cr == ConversationRoleOwner || cr == ConversationRoleAdmin
+}
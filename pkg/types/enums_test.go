@@ -294,3 +294,50 @@ func TestConversationRole_CanManageMembers(t *testing.T) {
 		})
 	}
 }
+
+func TestConversationRole_CanModerateMessages(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     ConversationRole
+		convType ConversationType
+		want     bool
+	}{
+		{
+			name:     "owner can moderate group",
+			role:     ConversationRoleOwner,
+			convType: ConversationTypeGroup,
+			want:     true,
+		},
+		{
+			name:     "admin can moderate channel",
+			role:     ConversationRoleAdmin,
+			convType: ConversationTypeChannel,
+			want:     true,
+		},
+		{
+			name:     "owner cannot moderate direct",
+			role:     ConversationRoleOwner,
+			convType: ConversationTypeDirect,
+			want:     false,
+		},
+		{
+			name:     "publisher cannot moderate channel",
+			role:     ConversationRolePublisher,
+			convType: ConversationTypeChannel,
+			want:     false,
+		},
+		{
+			name:     "member cannot moderate group",
+			role:     ConversationRoleMember,
+			convType: ConversationTypeGroup,
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.role.CanModerateMessages(tt.convType)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -16,7 +16,9 @@ sleep 10
 
 # 3. 运行数据库迁移
 echo "🗄️  Running database migrations..."
-PGPASSWORD=impassword psql -h localhost -U imuser -d im_system -f migrations/001_init_schema.sql
+for f in migrations/*.sql; do
+    PGPASSWORD=impassword psql -h localhost -U imuser -d im_system -f "$f"
+done
 
 # 4. 初始化 MinIO bucket
 echo "🪣 Initializing MinIO bucket..."